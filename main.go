@@ -19,11 +19,24 @@ import (
 	"github.com/sapcc/go-bits/must"
 	"github.com/sapcc/go-bits/osext"
 	"github.com/spf13/viper"
+	"go.xyrillian.de/gg/gsql"
+	"go.xyrillian.de/gg/pgruntime"
 
+	"github.com/sapcc/hermes/pkg/alerts"
 	"github.com/sapcc/hermes/pkg/api"
+	"github.com/sapcc/hermes/pkg/database"
+	"github.com/sapcc/hermes/pkg/export"
+	"github.com/sapcc/hermes/pkg/forwarding"
+	"github.com/sapcc/hermes/pkg/hermes"
 	"github.com/sapcc/hermes/pkg/identity"
+	"github.com/sapcc/hermes/pkg/integrity"
+	"github.com/sapcc/hermes/pkg/retention"
 	"github.com/sapcc/hermes/pkg/routing"
+	"github.com/sapcc/hermes/pkg/routing/bucket"
+	"github.com/sapcc/hermes/pkg/searches"
 	"github.com/sapcc/hermes/pkg/storage"
+	"github.com/sapcc/hermes/pkg/subscriptions"
+	"github.com/sapcc/hermes/pkg/webhook"
 )
 
 const version = "1.2.0"
@@ -41,6 +54,10 @@ func main() {
 		os.Exit(0)
 	}
 
+	if flag.Arg(0) == "verify-bundle" {
+		os.Exit(export.VerifyCommand(flag.Args()[1:], os.Stdout, os.Stderr))
+	}
+
 	setDefaultConfig()
 	readConfig(configPath)
 
@@ -55,9 +72,78 @@ func main() {
 
 	keystoneDriver := configuredKeystoneDriver()
 	storageDriver := configuredStorageDriver()
-	routingStore := configuredRoutingStore(ctx)
+	db, dbTarget := configuredDatabase(ctx)
+	routingStore := configuredRoutingStore(db, dbTarget)
+	redactor := must.Return(hermes.NewRedactorFromConfig())
+	webhookGuard := must.Return(webhook.NewGuardFromConfig())
+
+	opts := []api.Option{
+		api.WithRedactor(redactor),
+		api.WithWebhookGuard(webhookGuard),
+		api.WithSummarizer(must.Return(hermes.NewSummarizerFromConfig())),
+		api.WithSavedSearchStore(configuredSavedSearchStore(db)),
+		api.WithEventStream(api.EventStreamConfig{
+			PollInterval:            viper.GetDuration("stream.poll_interval"),
+			HeartbeatInterval:       viper.GetDuration("stream.heartbeat_interval"),
+			Lookback:                viper.GetDuration("stream.lookback"),
+			MaxResume:               viper.GetDuration("stream.max_resume"),
+			MaxConnectionsPerTenant: viper.GetInt("stream.max_connections_per_tenant"),
+		}),
+	}
+	if verifier, mode := configuredBucketVerifier(); verifier != nil {
+		opts = append(opts, api.WithBucketVerifier(verifier, mode))
+	}
+	if tv, ok := keystoneDriver.(*gopherpolicy.TokenValidator); ok {
+		opts = append(opts, api.WithNameResolver(identity.NewNameResolver(tv.IdentityV3)))
+		syncer := routing.NewDomainSyncer(routingStore, identity.NewProjectLister(tv.IdentityV3))
+		syncer.Interval = viper.GetDuration("dataplane.domain_sync_interval")
+		go syncer.Run(ctx)
+	}
+	if integrityStore := configuredIntegrityStore(db); integrityStore != nil {
+		sealer := integrity.NewSealer(integrityStore, storageDriver)
+		sealer.Interval = viper.GetDuration("integrity.interval")
+		sealer.SettleDelay = viper.GetDuration("integrity.settle_delay")
+		go sealer.Run(ctx)
+		opts = append(opts, api.WithIntegrityStore(integrityStore))
+	}
+	if alertStore := configuredAlertStore(db); alertStore != nil {
+		evaluator := alerts.NewEvaluator(alertStore, storageDriver, alerts.NewWebhookNotifier(webhookGuard))
+		evaluator.Interval = viper.GetDuration("alerts.interval")
+		go evaluator.Run(ctx)
+		opts = append(opts, api.WithAlertStore(alertStore))
+	}
+	if subscriptionStore := configuredSubscriptionStore(db); subscriptionStore != nil {
+		worker := subscriptions.NewWorker(subscriptionStore, storageDriver, webhookGuard)
+		worker.Redactor = redactor
+		worker.Interval = viper.GetDuration("subscriptions.interval")
+		worker.SettleDelay = viper.GetDuration("subscriptions.settle_delay")
+		worker.MaxAttempts = viper.GetInt("subscriptions.max_attempts")
+		go worker.Run(ctx)
+		opts = append(opts, api.WithSubscriptionStore(subscriptionStore))
+	}
+	if targets := must.Return(forwarding.NewTargetsFromConfig()); len(targets) > 0 {
+		forwarder := must.Return(forwarding.NewForwarder(configuredForwardingStore(db), storageDriver, targets))
+		forwarder.Redactor = redactor
+		forwarder.Interval = viper.GetDuration("forwarding.interval")
+		forwarder.SettleDelay = viper.GetDuration("forwarding.settle_delay")
+		if hostname := viper.GetString("forwarding.hostname"); hostname != "" {
+			forwarder.Hostname = hostname
+		}
+		logg.Info("forwarding events to %d syslog targets", len(targets))
+		go forwarder.Run(ctx)
+	}
+	if enforcer := configuredRetentionEnforcer(db, routingStore, keystoneDriver); enforcer != nil {
+		enforcer.Interval = viper.GetDuration("dataplane.retention_interval")
+		go enforcer.Run(ctx)
+	}
+
+	if keyPath := viper.GetString("export.signing_key_path"); keyPath != "" {
+		signer := must.Return(export.LoadSigner(keyPath))
+		logg.Info("signing export bundles with key %s", must.Return(signer.KeyID()))
+		opts = append(opts, api.WithExporter(export.NewExporter(storageDriver, signer)))
+	}
 
-	must.Succeed(api.Server(ctx, keystoneDriver, storageDriver, routingStore, auditor))
+	must.Succeed(api.Server(ctx, keystoneDriver, storageDriver, routingStore, auditor, opts...))
 }
 
 func parseCmdlineFlags() {
@@ -67,6 +153,7 @@ func parseCmdlineFlags() {
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
 		flag.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nCommands:\n  verify-bundle -key <public-key.pem> <bundle.tar.gz>\n\tverifies the signature and digests of an export bundle\n")
 	}
 	flag.Parse()
 }
@@ -75,9 +162,32 @@ func setDefaultConfig() {
 	viper.SetDefault("hermes.keystone_driver", "keystone")
 	viper.SetDefault("hermes.storage_driver", "opensearch")
 	viper.SetDefault("hermes.routing_store_driver", "postgres")
+	viper.SetDefault("keystone.name_cache_time", 3600)
+	viper.SetDefault("keystone.name_cache_size", 10000)
+	viper.SetDefault("keystone.name_max_lookups", 50)
 	viper.SetDefault("API.ListenAddress", "0.0.0.0:8788")
 	viper.SetDefault("opensearch.url", "http://localhost:9200")
 	viper.SetDefault("opensearch.max_result_window", "20000")
+	viper.SetDefault("stream.poll_interval", "2s")
+	viper.SetDefault("stream.heartbeat_interval", "15s")
+	viper.SetDefault("stream.lookback", "1m")
+	viper.SetDefault("stream.max_resume", "1h")
+	viper.SetDefault("stream.max_connections_per_tenant", 10)
+	viper.SetDefault("dataplane.bucket_verification", "off")
+	viper.SetDefault("dataplane.domain_sync_interval", "5m")
+	viper.SetDefault("dataplane.retention_enabled", false)
+	viper.SetDefault("dataplane.retention_interval", "1h")
+	viper.SetDefault("integrity.enabled", false)
+	viper.SetDefault("integrity.interval", "1m")
+	viper.SetDefault("integrity.settle_delay", "5m")
+	viper.SetDefault("alerts.enabled", false)
+	viper.SetDefault("alerts.interval", "1m")
+	viper.SetDefault("subscriptions.enabled", false)
+	viper.SetDefault("subscriptions.interval", "10s")
+	viper.SetDefault("subscriptions.settle_delay", "1m")
+	viper.SetDefault("subscriptions.max_attempts", 8)
+	viper.SetDefault("forwarding.interval", "10s")
+	viper.SetDefault("forwarding.settle_delay", "1m")
 }
 
 func readConfig(configPath *string) {
@@ -140,17 +250,144 @@ func configuredStorageDriver() storage.Storage {
 	}
 }
 
-func configuredRoutingStore(ctx context.Context) routing.Store {
+// configuredDatabase connects to the hermez database, or returns nil when
+// hermes.routing_store_driver is "mock". The routing store and the stores of
+// the other subsystems share the database, or use in-memory stores without it.
+func configuredDatabase(ctx context.Context) (*gsql.DB, pgruntime.ConnectionTarget) {
 	driverName := viper.GetString("hermes.routing_store_driver")
 	switch driverName {
 	case "postgres":
-		return must.Return(routing.NewPostgres(ctx))
+		db, target, err := database.Connect(ctx,
+			routing.DBMigrations,
+			integrity.DBMigrations,
+			searches.DBMigrations,
+			alerts.DBMigrations,
+			subscriptions.DBMigrations,
+			forwarding.DBMigrations,
+		)
+		must.Succeed(err)
+		return db, target
 	case "mock":
-		return routing.NewMock()
+		return nil, pgruntime.ConnectionTarget{}
 	default:
 		logg.Fatal("unknown routing_store_driver %q", driverName)
-		return nil // unreachable
+		return nil, pgruntime.ConnectionTarget{} // unreachable
+	}
+}
+
+func configuredRoutingStore(db *gsql.DB, target pgruntime.ConnectionTarget) routing.Store {
+	if db != nil {
+		return must.Return(routing.NewPostgres(db, target))
+	}
+	return routing.NewMock()
+}
+
+// configuredBucketVerifier returns the verifier for the target buckets of
+// dataplane configs, or nil when dataplane.bucket_verification is "off".
+func configuredBucketVerifier() (bucket.Verifier, bucket.Mode) {
+	mode, err := bucket.ParseMode(viper.GetString("dataplane.bucket_verification"))
+	if err != nil {
+		logg.Fatal("invalid dataplane.bucket_verification: %s", err.Error())
+	}
+	if mode == bucket.ModeOff {
+		return nil, mode
+	}
+	endpoint := viper.GetString("dataplane.bucket_endpoint")
+	if endpoint == "" {
+		logg.Fatal("dataplane.bucket_endpoint is required when dataplane.bucket_verification is %q", mode)
+	}
+	verifier, err := bucket.NewHTTPVerifier(endpoint)
+	if err != nil {
+		logg.Fatal("invalid dataplane.bucket_endpoint: %s", err.Error())
+	}
+	logg.Info("verifying dataplane target buckets at %s (mode %q)", endpoint, mode)
+	return verifier, mode
+}
+
+// configuredIntegrityStore returns the store for the tamper-evident hash chains,
+// or nil when integrity.enabled is not set. Without the database, an in-memory
+// chain store is used.
+func configuredIntegrityStore(db *gsql.DB) integrity.Store {
+	if !viper.GetBool("integrity.enabled") {
+		return nil
+	}
+	if db != nil {
+		return integrity.NewPostgres(db)
+	}
+	return integrity.NewMock()
+}
+
+// configuredSavedSearchStore returns the store for saved searches.
+func configuredSavedSearchStore(db *gsql.DB) searches.Store {
+	if db != nil {
+		return searches.NewPostgres(db)
+	}
+	return searches.NewMock()
+}
+
+// configuredAlertStore returns the store for alert rules and alert state, or
+// nil when alerts.enabled is not set.
+func configuredAlertStore(db *gsql.DB) alerts.Store {
+	if !viper.GetBool("alerts.enabled") {
+		return nil
+	}
+	if db != nil {
+		return alerts.NewPostgres(db)
+	}
+	return alerts.NewMock()
+}
+
+// configuredSubscriptionStore returns the store for webhook subscriptions and
+// their deliveries, or nil when subscriptions.enabled is not set.
+func configuredSubscriptionStore(db *gsql.DB) subscriptions.Store {
+	if !viper.GetBool("subscriptions.enabled") {
+		return nil
+	}
+	if db != nil {
+		return subscriptions.NewPostgres(db)
+	}
+	return subscriptions.NewMock()
+}
+
+// configuredForwardingStore returns the store for the cursors of the syslog
+// forwarder.
+func configuredForwardingStore(db *gsql.DB) forwarding.Store {
+	if db != nil {
+		return forwarding.NewPostgres(db)
+	}
+	return forwarding.NewMock()
+}
+
+// configuredRetentionEnforcer returns the job that enforces the retention of
+// routed events, or nil when dataplane.retention_enabled is not set. Its lock
+// is held in the database. A "file://" endpoint selects a local directory
+// instead of Swift, for development.
+func configuredRetentionEnforcer(db *gsql.DB, routingStore routing.Store, keystoneDriver gopherpolicy.Validator) *retention.Enforcer {
+	if !viper.GetBool("dataplane.retention_enabled") {
+		return nil
+	}
+	endpoint := viper.GetString("dataplane.retention_endpoint")
+	if endpoint == "" {
+		logg.Fatal("dataplane.retention_endpoint is required when dataplane.retention_enabled is set")
+	}
+
+	var objectStore retention.ObjectStore
+	if root, ok := strings.CutPrefix(endpoint, "file://"); ok {
+		objectStore = retention.NewFilesystem(root)
+	} else {
+		tv, ok := keystoneDriver.(*gopherpolicy.TokenValidator)
+		if !ok {
+			logg.Fatal("dataplane.retention_endpoint %q requires the keystone driver", endpoint)
+		}
+		objectStore = retention.NewSwift(tv.IdentityV3.ProviderClient, endpoint)
+	}
+
+	var store retention.Store = retention.NewMock()
+	if db != nil {
+		store = retention.NewPostgres(db)
 	}
+	logg.Info("enforcing retention of routed events at %s", endpoint)
+	return retention.NewEnforcer(store, routingStore, objectStore)
 }
 
 // configuredAuditor builds the audit event publisher.
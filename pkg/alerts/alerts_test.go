@@ -0,0 +1,310 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/sapcc/go-bits/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapcc/hermes/pkg/hermes"
+	"github.com/sapcc/hermes/pkg/storage"
+	"github.com/sapcc/hermes/pkg/webhook"
+)
+
+// recordingNotifier remembers all notifications and fails while failing is set.
+type recordingNotifier struct {
+	notifications []Notification
+	failing       bool
+}
+
+func (r *recordingNotifier) Notify(_ context.Context, _ string, n Notification) error {
+	if r.failing {
+		return errors.New("webhook unavailable")
+	}
+	r.notifications = append(r.notifications, n)
+	return nil
+}
+
+// take returns and forgets the recorded notifications.
+func (r *recordingNotifier) take() []Notification {
+	result := r.notifications
+	r.notifications = nil
+	return result
+}
+
+type evaluatorTest struct {
+	store     *Mock
+	events    *storage.Memory
+	notifier  *recordingNotifier
+	clock     *mock.Clock
+	evaluator *Evaluator
+	eventSeq  int
+}
+
+func newEvaluatorTest(t *testing.T, rules ...Rule) *evaluatorTest {
+	t.Helper()
+	et := &evaluatorTest{
+		store:    NewMock(),
+		events:   storage.NewMemory(100),
+		notifier: &recordingNotifier{},
+		clock:    mock.NewClock(),
+	}
+	et.clock.StepBy(24 * time.Hour)
+	et.evaluator = NewEvaluator(et.store, et.events, et.notifier)
+	et.evaluator.Now = et.clock.Now
+	for _, rule := range rules {
+		require.NoError(t, et.store.CreateRule(t.Context(), rule))
+	}
+	return et
+}
+
+// addEvent stores an event that happened one second ago.
+func (et *evaluatorTest) addEvent(projectID, action, outcome, initiatorID string) {
+	et.eventSeq++
+	et.events.Add([]string{projectID}, cadf.Event{
+		ID:        fmt.Sprintf("event-%d", et.eventSeq),
+		EventTime: et.clock.Now().Add(-time.Second).UTC().Format("2006-01-02T15:04:05.000000+00:00"),
+		Action:    cadf.Action(action),
+		Outcome:   cadf.Outcome(outcome),
+		Initiator: cadf.Resource{ID: initiatorID},
+	})
+}
+
+func (et *evaluatorTest) alerts(t *testing.T, ruleID string) []Alert {
+	t.Helper()
+	alerts, err := et.store.ListAlerts(t.Context(), ruleID)
+	require.NoError(t, err)
+	return alerts
+}
+
+func TestEvaluateThreshold(t *testing.T) {
+	rule := Rule{
+		ID:            "rule-1",
+		ProjectID:     "project-a",
+		Name:          "role assignment deleted",
+		Filter:        hermes.FieldFilter{Action: "delete", Outcome: "success"},
+		Threshold:     1,
+		WindowSeconds: 300,
+		WebhookURL:    "https://example.com/hook",
+		Enabled:       true,
+	}
+	et := newEvaluatorTest(t, rule)
+
+	// no matching events: nothing happens
+	et.addEvent("project-a", "create", "success", "alice")
+	et.addEvent("project-b", "delete", "success", "alice")
+	require.NoError(t, et.evaluator.EvaluateAll(t.Context()))
+	assert.Empty(t, et.notifier.take())
+	assert.Empty(t, et.alerts(t, rule.ID))
+
+	// a matching event fires the rule once
+	et.addEvent("project-a", "delete", "success", "alice")
+	firingSince := et.clock.Now().UTC()
+	require.NoError(t, et.evaluator.EvaluateAll(t.Context()))
+	assert.Equal(t, []Notification{{
+		Status:        StatusFiring,
+		RuleID:        rule.ID,
+		RuleName:      rule.Name,
+		ProjectID:     rule.ProjectID,
+		Count:         1,
+		Threshold:     1,
+		WindowSeconds: 300,
+		FiringSince:   firingSince,
+	}}, et.notifier.take())
+
+	et.clock.StepBy(time.Minute)
+	require.NoError(t, et.evaluator.EvaluateAll(t.Context()))
+	assert.Empty(t, et.notifier.take(), "a firing alert is notified only once")
+	alerts := et.alerts(t, rule.ID)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, StatusFiring, alerts[0].Status)
+	assert.Equal(t, firingSince, alerts[0].FiringSince)
+	assert.Equal(t, et.clock.Now().UTC(), alerts[0].LastEvaluatedAt)
+
+	// once the event leaves the window, the alert resolves
+	et.clock.StepBy(5 * time.Minute)
+	resolvedAt := et.clock.Now().UTC()
+	require.NoError(t, et.evaluator.EvaluateAll(t.Context()))
+	notifications := et.notifier.take()
+	require.Len(t, notifications, 1)
+	assert.Equal(t, StatusResolved, notifications[0].Status)
+	assert.Equal(t, 0, notifications[0].Count)
+	assert.Equal(t, &resolvedAt, notifications[0].ResolvedAt)
+	assert.Empty(t, et.alerts(t, rule.ID))
+}
+
+func TestEvaluateGroupBy(t *testing.T) {
+	rule := Rule{
+		ID:            "rule-1",
+		ProjectID:     "project-a",
+		Name:          "failed actions per initiator",
+		Filter:        hermes.FieldFilter{Outcome: "failure"},
+		Threshold:     3,
+		WindowSeconds: 300,
+		GroupBy:       GroupByInitiatorID,
+		WebhookURL:    "https://example.com/hook",
+		Enabled:       true,
+	}
+	et := newEvaluatorTest(t, rule)
+
+	for range 3 {
+		et.addEvent("project-a", "update", "failure", "alice")
+	}
+	for range 2 {
+		et.addEvent("project-a", "update", "failure", "bob")
+	}
+	et.addEvent("project-a", "update", "success", "bob")
+	require.NoError(t, et.evaluator.EvaluateAll(t.Context()))
+	notifications := et.notifier.take()
+	require.Len(t, notifications, 1)
+	assert.Equal(t, GroupByInitiatorID, notifications[0].GroupBy)
+	assert.Equal(t, "alice", notifications[0].GroupKey)
+	assert.Equal(t, 3, notifications[0].Count)
+
+	et.clock.StepBy(time.Minute)
+	et.addEvent("project-a", "update", "failure", "bob")
+	require.NoError(t, et.evaluator.EvaluateAll(t.Context()))
+	notifications = et.notifier.take()
+	require.Len(t, notifications, 1)
+	assert.Equal(t, "bob", notifications[0].GroupKey)
+
+	alerts := et.alerts(t, rule.ID)
+	require.Len(t, alerts, 2)
+	assert.Equal(t, "alice", alerts[0].GroupKey)
+	assert.Equal(t, "bob", alerts[1].GroupKey)
+}
+
+func TestEvaluateRetriesNotifications(t *testing.T) {
+	rule := Rule{
+		ID:            "rule-1",
+		ProjectID:     "project-a",
+		Name:          "deletes",
+		Filter:        hermes.FieldFilter{Action: "delete"},
+		Threshold:     1,
+		WindowSeconds: 60,
+		WebhookURL:    "https://example.com/hook",
+		Enabled:       true,
+	}
+	et := newEvaluatorTest(t, rule)
+	et.addEvent("project-a", "delete", "success", "alice")
+
+	// while the webhook fails, the alert is kept as not notified
+	et.notifier.failing = true
+	require.NoError(t, et.evaluator.EvaluateAll(t.Context()))
+	alerts := et.alerts(t, rule.ID)
+	require.Len(t, alerts, 1)
+	assert.False(t, alerts[0].Notified)
+
+	et.notifier.failing = false
+	require.NoError(t, et.evaluator.EvaluateAll(t.Context()))
+	require.Len(t, et.notifier.take(), 1)
+	assert.True(t, et.alerts(t, rule.ID)[0].Notified)
+
+	// a failed resolve notification is retried as well
+	et.clock.StepBy(2 * time.Minute)
+	et.notifier.failing = true
+	require.NoError(t, et.evaluator.EvaluateAll(t.Context()))
+	alerts = et.alerts(t, rule.ID)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, StatusResolved, alerts[0].Status)
+
+	et.notifier.failing = false
+	require.NoError(t, et.evaluator.EvaluateAll(t.Context()))
+	notifications := et.notifier.take()
+	require.Len(t, notifications, 1)
+	assert.Equal(t, StatusResolved, notifications[0].Status)
+	assert.Empty(t, et.alerts(t, rule.ID))
+}
+
+func TestEvaluateSkipsUnannouncedAndDisabled(t *testing.T) {
+	rule := Rule{
+		ID:            "rule-1",
+		ProjectID:     "project-a",
+		Name:          "deletes",
+		Filter:        hermes.FieldFilter{Action: "delete"},
+		Threshold:     1,
+		WindowSeconds: 60,
+		WebhookURL:    "https://example.com/hook",
+		Enabled:       true,
+	}
+	et := newEvaluatorTest(t, rule)
+	et.addEvent("project-a", "delete", "success", "alice")
+
+	// an alert that resolves before its firing notification went out is dropped silently
+	et.notifier.failing = true
+	require.NoError(t, et.evaluator.EvaluateAll(t.Context()))
+	et.notifier.failing = false
+	et.clock.StepBy(2 * time.Minute)
+	require.NoError(t, et.evaluator.EvaluateAll(t.Context()))
+	assert.Empty(t, et.notifier.take())
+	assert.Empty(t, et.alerts(t, rule.ID))
+
+	// disabled rules are not evaluated, and their alerts are discarded
+	et.addEvent("project-a", "delete", "success", "alice")
+	require.NoError(t, et.evaluator.EvaluateAll(t.Context()))
+	require.Len(t, et.notifier.take(), 1)
+	rule.Enabled = false
+	require.NoError(t, et.store.UpdateRule(t.Context(), rule))
+	require.NoError(t, et.evaluator.EvaluateAll(t.Context()))
+	assert.Empty(t, et.notifier.take())
+	assert.Empty(t, et.alerts(t, rule.ID))
+}
+
+func TestEvaluateHonorsLock(t *testing.T) {
+	rule := Rule{ID: "rule-1", ProjectID: "project-a", Name: "all", Threshold: 1, WindowSeconds: 60, Enabled: true}
+	et := newEvaluatorTest(t, rule)
+	et.addEvent("project-a", "delete", "success", "alice")
+
+	release, ok, err := et.store.TryLock(t.Context())
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NoError(t, et.evaluator.EvaluateAll(t.Context()))
+	assert.Empty(t, et.notifier.take(), "no evaluation while another process holds the lock")
+
+	release()
+	require.NoError(t, et.evaluator.EvaluateAll(t.Context()))
+	assert.Len(t, et.notifier.take(), 1)
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var received Notification
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	// the test server listens on a loopback address, which must be allowed explicitly
+	notifier := NewWebhookNotifier(webhook.Guard{AllowedNetworks: []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")}})
+	n := Notification{Status: StatusFiring, RuleID: "rule-1", RuleName: "deletes", Count: 4, Threshold: 1}
+	require.NoError(t, notifier.Notify(t.Context(), server.URL, n))
+	assert.Equal(t, n, received)
+
+	status = http.StatusServiceUnavailable
+	err := notifier.Notify(t.Context(), server.URL, n)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "503")
+
+	// without the exception, internal addresses are refused when connecting
+	received = Notification{}
+	status = http.StatusNoContent
+	err = NewWebhookNotifier(webhook.Guard{}).Notify(t.Context(), server.URL, n)
+	require.ErrorIs(t, err, webhook.ErrForbiddenAddress)
+	assert.Equal(t, Notification{}, received)
+}
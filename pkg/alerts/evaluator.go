@@ -0,0 +1,211 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package alerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/sapcc/go-bits/logg"
+
+	"github.com/sapcc/hermes/pkg/hermes"
+	"github.com/sapcc/hermes/pkg/storage"
+)
+
+// Evaluator periodically evaluates all enabled rules and notifies their
+// webhooks about alerts that start or stop firing.
+type Evaluator struct {
+	Store    Store
+	Storage  storage.Storage
+	Notifier Notifier
+	// Interval is the time between two evaluation runs.
+	Interval time.Duration
+	// Now returns the current time. Tests replace it with a mock clock.
+	Now func() time.Time
+}
+
+// NewEvaluator builds an Evaluator with the default interval.
+func NewEvaluator(store Store, eventStore storage.Storage, notifier Notifier) *Evaluator {
+	return &Evaluator{
+		Store:    store,
+		Storage:  eventStore,
+		Notifier: notifier,
+		Interval: time.Minute,
+		Now:      time.Now,
+	}
+}
+
+// Run evaluates all rules every Interval until ctx is cancelled.
+func (e *Evaluator) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.Interval)
+	defer ticker.Stop()
+	for {
+		err := e.EvaluateAll(ctx)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			logg.Error("alerts: evaluation failed: %s", err.Error())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// EvaluateAll evaluates every rule once. If another replica holds the
+// evaluation lock, nothing is done. Errors of individual rules are logged
+// and do not stop the evaluation of the other rules.
+func (e *Evaluator) EvaluateAll(ctx context.Context) error {
+	release, ok, err := e.Store.TryLock(ctx)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		logg.Debug("alerts: another process is evaluating the alert rules")
+		return nil
+	}
+	defer release()
+
+	rules, err := e.Store.ListRules(ctx, "")
+	if err != nil {
+		return err
+	}
+	now := e.Now().UTC()
+	failed := 0
+	for _, rule := range rules {
+		err := e.EvaluateRule(ctx, rule, now)
+		if errors.Is(err, context.Canceled) {
+			return err
+		}
+		if err != nil {
+			logg.Error("alerts: cannot evaluate rule %s in project %s: %s", rule.ID, rule.ProjectID, err.Error())
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d rules could not be evaluated", failed, len(rules))
+	}
+	return nil
+}
+
+// EvaluateRule counts the events of the rule's window ending at now and
+// updates the rule's alerts. Disabling a rule discards its alerts without
+// notification.
+func (e *Evaluator) EvaluateRule(ctx context.Context, rule Rule, now time.Time) error {
+	existing, err := e.Store.ListAlerts(ctx, rule.ID)
+	if err != nil {
+		return err
+	}
+	if !rule.Enabled {
+		for _, alert := range existing {
+			if err := e.Store.DeleteAlert(ctx, rule.ID, alert.GroupKey); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	counts, err := e.count(ctx, rule, now)
+	if err != nil {
+		return err
+	}
+
+	alerts := make(map[string]Alert, len(existing))
+	for _, alert := range existing {
+		alerts[alert.GroupKey] = alert
+	}
+	for groupKey, count := range counts {
+		if count < rule.Threshold {
+			continue
+		}
+		alert, exists := alerts[groupKey]
+		delete(alerts, groupKey)
+		if !exists || alert.Status == StatusResolved {
+			alert = Alert{
+				RuleID:      rule.ID,
+				ProjectID:   rule.ProjectID,
+				GroupKey:    groupKey,
+				Status:      StatusFiring,
+				FiringSince: now,
+			}
+		}
+		alert.Count = count
+		alert.LastEvaluatedAt = now
+		if !alert.Notified {
+			alert.Notified = e.notify(ctx, rule, alert)
+		}
+		if err := e.Store.SaveAlert(ctx, alert); err != nil {
+			return err
+		}
+	}
+
+	// the remaining alerts are below the threshold now
+	for groupKey, alert := range alerts {
+		if alert.Status == StatusFiring {
+			if !alert.Notified {
+				// nobody was told that it fired, so nobody needs to be told that it stopped
+				if err := e.Store.DeleteAlert(ctx, rule.ID, groupKey); err != nil {
+					return err
+				}
+				continue
+			}
+			alert.Status = StatusResolved
+			alert.ResolvedAt = &now
+			alert.Notified = false
+		}
+		alert.Count = counts[groupKey]
+		alert.LastEvaluatedAt = now
+		if e.notify(ctx, rule, alert) {
+			err = e.Store.DeleteAlert(ctx, rule.ID, groupKey)
+		} else {
+			err = e.Store.SaveAlert(ctx, alert)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// count returns the number of events per group in [now - window, now).
+// Without GroupBy, the only group is "".
+func (e *Evaluator) count(ctx context.Context, rule Rule, now time.Time) (map[string]int, error) {
+	filter := rule.Filter.Between(now.Add(-rule.Window()), now)
+	if rule.GroupBy == GroupByNone {
+		filter.Limit = 1
+		_, total, err := hermes.GetEvents(ctx, filter, rule.ProjectID, e.Storage, nil)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]int{"": total}, nil
+	}
+
+	counts := make(map[string]int)
+	_, err := hermes.ForEachEvent(ctx, filter, rule.ProjectID, e.Storage, nil, 1000, func(event *cadf.Event) error {
+		counts[rule.GroupBy.key(event)]++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// notify sends the notification for the alert's current status and reports
+// whether the webhook accepted it. Failed notifications are retried on the
+// next evaluation.
+func (e *Evaluator) notify(ctx context.Context, rule Rule, alert Alert) bool {
+	err := e.Notifier.Notify(ctx, rule.WebhookURL, newNotification(rule, alert))
+	if err != nil {
+		logg.Error("alerts: cannot notify webhook of rule %s in project %s about %s alert: %s",
+			rule.ID, rule.ProjectID, alert.Status, err.Error())
+		return false
+	}
+	logg.Info("alerts: rule %s in project %s is %s (group %q, count %d)",
+		rule.ID, rule.ProjectID, alert.Status, alert.GroupKey, alert.Count)
+	return true
+}
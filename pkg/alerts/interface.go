@@ -0,0 +1,54 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package alerts
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrNotFound is returned by Store.GetRule and Store.UpdateRule when no rule has the given ID.
+	ErrNotFound = errors.New("alerts: alert rule not found")
+	// ErrDuplicateName is returned by Store.CreateRule and Store.UpdateRule when
+	// the project already has a rule with the same name.
+	ErrDuplicateName = errors.New("alerts: an alert rule with this name already exists in the project")
+)
+
+// Store is the persistence interface for alert rules and alert state.
+// The Postgres implementation is the production backend;
+// the Mock implementation is used in unit tests.
+type Store interface {
+	// ListRules returns the rules of a project, ordered by name.
+	// If projectID is empty, the rules of all projects are returned.
+	ListRules(ctx context.Context, projectID string) ([]Rule, error)
+
+	// GetRule returns the rule with the given ID, or ErrNotFound.
+	GetRule(ctx context.Context, id string) (*Rule, error)
+
+	// CreateRule stores a new rule.
+	CreateRule(ctx context.Context, rule Rule) error
+
+	// UpdateRule replaces all fields of an existing rule except ID, ProjectID
+	// and the created_* fields. The alerts of the rule are kept.
+	UpdateRule(ctx context.Context, rule Rule) error
+
+	// DeleteRule removes the rule with the given ID and its alerts.
+	// Returns (false, nil) if it did not exist.
+	DeleteRule(ctx context.Context, id string) (bool, error)
+
+	// ListAlerts returns the alerts of a rule, ordered by group key.
+	ListAlerts(ctx context.Context, ruleID string) ([]Alert, error)
+
+	// SaveAlert creates or replaces the alert of alert.RuleID and alert.GroupKey.
+	SaveAlert(ctx context.Context, alert Alert) error
+
+	// DeleteAlert removes an alert. Deleting a non-existent alert is not an error.
+	DeleteAlert(ctx context.Context, ruleID, groupKey string) error
+
+	// TryLock acquires the evaluation lock without waiting, so that only one
+	// hermez replica evaluates the rules at a time. If ok is true, the caller
+	// must call release when done.
+	TryLock(ctx context.Context) (release func(), ok bool, err error)
+}
@@ -0,0 +1,150 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package alerts
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Mock implements Store with in-memory storage for use in unit tests.
+type Mock struct {
+	mu     sync.RWMutex
+	rules  map[string]Rule
+	alerts map[string]map[string]Alert // rule ID -> group key -> alert
+	locked sync.Mutex
+}
+
+// NewMock creates an empty Mock store.
+func NewMock() *Mock {
+	return &Mock{
+		rules:  make(map[string]Rule),
+		alerts: make(map[string]map[string]Alert),
+	}
+}
+
+// ListRules implements the Store interface.
+func (m *Mock) ListRules(_ context.Context, projectID string) ([]Rule, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []Rule
+	for _, r := range m.rules {
+		if projectID == "" || r.ProjectID == projectID {
+			result = append(result, r)
+		}
+	}
+	slices.SortFunc(result, func(lhs, rhs Rule) int {
+		return cmp.Or(strings.Compare(lhs.ProjectID, rhs.ProjectID), strings.Compare(lhs.Name, rhs.Name))
+	})
+	return result, nil
+}
+
+// GetRule implements the Store interface.
+func (m *Mock) GetRule(_ context.Context, id string) (*Rule, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	r, ok := m.rules[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &r, nil
+}
+
+// CreateRule implements the Store interface.
+func (m *Mock) CreateRule(_ context.Context, rule Rule) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.nameTaken(rule) {
+		return ErrDuplicateName
+	}
+	m.rules[rule.ID] = rule
+	return nil
+}
+
+// UpdateRule implements the Store interface.
+func (m *Mock) UpdateRule(_ context.Context, rule Rule) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	existing, ok := m.rules[rule.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if m.nameTaken(rule) {
+		return ErrDuplicateName
+	}
+	rule.ProjectID = existing.ProjectID
+	rule.CreatedAt = existing.CreatedAt
+	rule.CreatedBy = existing.CreatedBy
+	m.rules[rule.ID] = rule
+	return nil
+}
+
+// DeleteRule implements the Store interface.
+func (m *Mock) DeleteRule(_ context.Context, id string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, existed := m.rules[id]
+	delete(m.rules, id)
+	delete(m.alerts, id)
+	return existed, nil
+}
+
+// ListAlerts implements the Store interface.
+func (m *Mock) ListAlerts(_ context.Context, ruleID string) ([]Alert, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []Alert
+	for _, a := range m.alerts[ruleID] {
+		result = append(result, a)
+	}
+	slices.SortFunc(result, func(lhs, rhs Alert) int {
+		return strings.Compare(lhs.GroupKey, rhs.GroupKey)
+	})
+	return result, nil
+}
+
+// SaveAlert implements the Store interface.
+func (m *Mock) SaveAlert(_ context.Context, alert Alert) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.rules[alert.RuleID]; !ok {
+		return ErrNotFound
+	}
+	if m.alerts[alert.RuleID] == nil {
+		m.alerts[alert.RuleID] = make(map[string]Alert)
+	}
+	m.alerts[alert.RuleID][alert.GroupKey] = alert
+	return nil
+}
+
+// DeleteAlert implements the Store interface.
+func (m *Mock) DeleteAlert(_ context.Context, ruleID, groupKey string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.alerts[ruleID], groupKey)
+	return nil
+}
+
+// TryLock implements the Store interface.
+func (m *Mock) TryLock(_ context.Context) (release func(), ok bool, err error) {
+	if !m.locked.TryLock() {
+		return nil, false, nil
+	}
+	return m.locked.Unlock, true, nil
+}
+
+func (m *Mock) nameTaken(rule Rule) bool {
+	for _, r := range m.rules {
+		if r.ID != rule.ID && r.ProjectID == rule.ProjectID && r.Name == rule.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// Ensure Mock implements Store.
+var _ Store = (*Mock)(nil)
@@ -0,0 +1,60 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/bininfo"
+
+	"github.com/sapcc/hermes/pkg/webhook"
+)
+
+// Notifier delivers notifications about alert state changes.
+type Notifier interface {
+	Notify(ctx context.Context, webhookURL string, n Notification) error
+}
+
+// WebhookNotifier POSTs notifications as JSON to the rule's webhook URL.
+// Any response status other than 2xx is an error.
+type WebhookNotifier struct {
+	Client *http.Client
+}
+
+// NewWebhookNotifier builds a WebhookNotifier with a 10-second timeout per
+// request that only connects to the addresses allowed by the guard.
+func NewWebhookNotifier(guard webhook.Guard) *WebhookNotifier {
+	return &WebhookNotifier{Client: guard.NewClient(10 * time.Second)}
+}
+
+// Notify implements the Notifier interface.
+func (w *WebhookNotifier) Notify(ctx context.Context, webhookURL string, n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", bininfo.Component()+"-alerts")
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) //nolint:errcheck // only drained for connection reuse
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return nil
+}
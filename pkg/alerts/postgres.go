@@ -0,0 +1,294 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package alerts
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/sapcc/go-bits/logg"
+	"go.xyrillian.de/gg/gsql"
+)
+
+const (
+	// uniqueViolation is the SQLSTATE for unique constraint violations.
+	uniqueViolation = "23505"
+	// evaluationLockID is the key of the Postgres advisory lock taken by TryLock
+	// ("hermes" in ASCII, followed by a number per lock).
+	evaluationLockID int64 = 0x6865726d65730001
+)
+
+// DBMigrations contains the SQL migrations for the tables of this package in
+// the hermez database (see package database).
+var DBMigrations = map[int64]string{
+	4: `
+		-- Per-project alert rules and the state of their firing alerts.
+		CREATE TABLE IF NOT EXISTS alert_rules (
+			id             UUID         PRIMARY KEY,
+			project_id     VARCHAR(64)  NOT NULL,
+			name           VARCHAR(255) NOT NULL,
+			description    TEXT         NOT NULL DEFAULT '',
+			filter         JSONB        NOT NULL DEFAULT '{}',
+			threshold      INTEGER      NOT NULL,
+			window_seconds INTEGER      NOT NULL,
+			group_by       VARCHAR(32)  NOT NULL DEFAULT '',
+			webhook_url    TEXT         NOT NULL,
+			enabled        BOOLEAN      NOT NULL DEFAULT TRUE,
+			created_at     TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+			created_by     VARCHAR(64)  NOT NULL DEFAULT '',
+			updated_at     TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+			updated_by     VARCHAR(64)  NOT NULL DEFAULT '',
+			UNIQUE (project_id, name)
+		);
+
+		CREATE TABLE IF NOT EXISTS alert_states (
+			rule_id           UUID         NOT NULL REFERENCES alert_rules (id) ON DELETE CASCADE,
+			project_id        VARCHAR(64)  NOT NULL,
+			group_key         TEXT         NOT NULL DEFAULT '',
+			status            VARCHAR(16)  NOT NULL,
+			count             INTEGER      NOT NULL,
+			firing_since      TIMESTAMPTZ  NOT NULL,
+			resolved_at       TIMESTAMPTZ,
+			last_evaluated_at TIMESTAMPTZ  NOT NULL,
+			notified          BOOLEAN      NOT NULL DEFAULT FALSE,
+			PRIMARY KEY (rule_id, group_key)
+		);
+	`,
+}
+
+// Postgres implements Store using the hermez PostgreSQL database.
+type Postgres struct {
+	db *gsql.DB
+}
+
+// NewPostgres wraps an already connected and migrated database.
+func NewPostgres(db *gsql.DB) *Postgres {
+	return &Postgres{db: db}
+}
+
+const ruleColumns = `id, project_id, name, description, filter, threshold, window_seconds, group_by,
+	webhook_url, enabled, created_at, created_by, updated_at, updated_by`
+
+const alertColumns = `rule_id, project_id, group_key, status, count, firing_since, resolved_at,
+	last_evaluated_at, notified`
+
+// ListRules implements the Store interface.
+func (p *Postgres) ListRules(ctx context.Context, projectID string) ([]Rule, error) {
+	rows, err := p.db.QueryContext(ctx,
+		`SELECT `+ruleColumns+` FROM alert_rules
+		  WHERE $1 = '' OR project_id = $1
+		  ORDER BY project_id, name`,
+		projectID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("alerts: cannot list rules for project %q: %w", projectID, err)
+	}
+	defer rows.Close()
+
+	var result []Rule
+	for rows.Next() {
+		r, err := scanRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("alerts: cannot list rules for project %q: %w", projectID, err)
+		}
+		result = append(result, *r)
+	}
+	return result, rows.Err()
+}
+
+// GetRule implements the Store interface.
+func (p *Postgres) GetRule(ctx context.Context, id string) (*Rule, error) {
+	r, err := scanRule(p.db.QueryRowContext(ctx,
+		`SELECT `+ruleColumns+` FROM alert_rules WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("alerts: cannot get rule %s: %w", id, err)
+	}
+	return r, nil
+}
+
+// CreateRule implements the Store interface.
+func (p *Postgres) CreateRule(ctx context.Context, r Rule) error {
+	filter, err := json.Marshal(r.Filter)
+	if err != nil {
+		return err
+	}
+	_, err = p.db.ExecContext(ctx,
+		`INSERT INTO alert_rules (`+ruleColumns+`)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`,
+		r.ID, r.ProjectID, r.Name, r.Description, filter, r.Threshold, r.WindowSeconds, string(r.GroupBy),
+		r.WebhookURL, r.Enabled, r.CreatedAt, r.CreatedBy, r.UpdatedAt, r.UpdatedBy,
+	)
+	if isUniqueViolation(err) {
+		return ErrDuplicateName
+	}
+	if err != nil {
+		return fmt.Errorf("alerts: cannot create rule in project %s: %w", r.ProjectID, err)
+	}
+	return nil
+}
+
+// UpdateRule implements the Store interface.
+func (p *Postgres) UpdateRule(ctx context.Context, r Rule) error {
+	filter, err := json.Marshal(r.Filter)
+	if err != nil {
+		return err
+	}
+	result, err := p.db.ExecContext(ctx,
+		`UPDATE alert_rules
+		    SET name = $2, description = $3, filter = $4, threshold = $5, window_seconds = $6, group_by = $7,
+		        webhook_url = $8, enabled = $9, updated_at = $10, updated_by = $11
+		  WHERE id = $1`,
+		r.ID, r.Name, r.Description, filter, r.Threshold, r.WindowSeconds, string(r.GroupBy),
+		r.WebhookURL, r.Enabled, r.UpdatedAt, r.UpdatedBy,
+	)
+	if isUniqueViolation(err) {
+		return ErrDuplicateName
+	}
+	if err != nil {
+		return fmt.Errorf("alerts: cannot update rule %s: %w", r.ID, err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("alerts: cannot get rows affected after update of rule %s: %w", r.ID, err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteRule implements the Store interface.
+// The alerts of the rule are removed by ON DELETE CASCADE.
+func (p *Postgres) DeleteRule(ctx context.Context, id string) (bool, error) {
+	result, err := p.db.ExecContext(ctx, `DELETE FROM alert_rules WHERE id = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("alerts: cannot delete rule %s: %w", id, err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("alerts: cannot get rows affected after delete of rule %s: %w", id, err)
+	}
+	return n > 0, nil
+}
+
+// ListAlerts implements the Store interface.
+func (p *Postgres) ListAlerts(ctx context.Context, ruleID string) ([]Alert, error) {
+	rows, err := p.db.QueryContext(ctx,
+		`SELECT `+alertColumns+` FROM alert_states WHERE rule_id = $1 ORDER BY group_key`, ruleID)
+	if err != nil {
+		return nil, fmt.Errorf("alerts: cannot list alerts of rule %s: %w", ruleID, err)
+	}
+	defer rows.Close()
+
+	var result []Alert
+	for rows.Next() {
+		var (
+			a          Alert
+			status     string
+			resolvedAt sql.NullTime
+		)
+		err := rows.Scan(&a.RuleID, &a.ProjectID, &a.GroupKey, &status, &a.Count, &a.FiringSince, &resolvedAt,
+			&a.LastEvaluatedAt, &a.Notified)
+		if err != nil {
+			return nil, fmt.Errorf("alerts: cannot list alerts of rule %s: %w", ruleID, err)
+		}
+		a.Status = Status(status)
+		if resolvedAt.Valid {
+			a.ResolvedAt = &resolvedAt.Time
+		}
+		result = append(result, a)
+	}
+	return result, rows.Err()
+}
+
+// SaveAlert implements the Store interface.
+func (p *Postgres) SaveAlert(ctx context.Context, a Alert) error {
+	_, err := p.db.ExecContext(ctx,
+		`INSERT INTO alert_states (`+alertColumns+`)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		 ON CONFLICT (rule_id, group_key) DO UPDATE
+		   SET status = EXCLUDED.status, count = EXCLUDED.count, firing_since = EXCLUDED.firing_since,
+		       resolved_at = EXCLUDED.resolved_at, last_evaluated_at = EXCLUDED.last_evaluated_at,
+		       notified = EXCLUDED.notified`,
+		a.RuleID, a.ProjectID, a.GroupKey, string(a.Status), a.Count, a.FiringSince, a.ResolvedAt,
+		a.LastEvaluatedAt, a.Notified,
+	)
+	if err != nil {
+		return fmt.Errorf("alerts: cannot save alert of rule %s: %w", a.RuleID, err)
+	}
+	return nil
+}
+
+// DeleteAlert implements the Store interface.
+func (p *Postgres) DeleteAlert(ctx context.Context, ruleID, groupKey string) error {
+	_, err := p.db.ExecContext(ctx,
+		`DELETE FROM alert_states WHERE rule_id = $1 AND group_key = $2`, ruleID, groupKey)
+	if err != nil {
+		return fmt.Errorf("alerts: cannot delete alert of rule %s: %w", ruleID, err)
+	}
+	return nil
+}
+
+// TryLock implements the Store interface using a session-level advisory lock.
+// The lock is held on a dedicated connection, which is returned to the pool on release.
+func (p *Postgres) TryLock(ctx context.Context) (release func(), ok bool, err error) {
+	conn, err := p.db.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("alerts: cannot get connection for evaluation lock: %w", err)
+	}
+	err = conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, evaluationLockID).Scan(&ok)
+	if err != nil || !ok {
+		conn.Close()
+		if err != nil {
+			return nil, false, fmt.Errorf("alerts: cannot take evaluation lock: %w", err)
+		}
+		return nil, false, nil
+	}
+	release = func() {
+		// use a fresh context: the lock must be released even if ctx was cancelled
+		_, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, evaluationLockID)
+		if err != nil {
+			logg.Error("alerts: cannot release evaluation lock: %s", err.Error())
+		}
+		conn.Close()
+	}
+	return release, true, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanRule(row rowScanner) (*Rule, error) {
+	var (
+		r       Rule
+		filter  []byte
+		groupBy string
+	)
+	err := row.Scan(&r.ID, &r.ProjectID, &r.Name, &r.Description, &filter, &r.Threshold, &r.WindowSeconds, &groupBy,
+		&r.WebhookURL, &r.Enabled, &r.CreatedAt, &r.CreatedBy, &r.UpdatedAt, &r.UpdatedBy)
+	if err != nil {
+		return nil, err
+	}
+	r.GroupBy = GroupBy(groupBy)
+	if err := json.Unmarshal(filter, &r.Filter); err != nil {
+		return nil, fmt.Errorf("cannot decode filter of rule %s: %w", r.ID, err)
+	}
+	return &r, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation
+}
+
+// Ensure Postgres implements Store.
+var _ Store = (*Postgres)(nil)
@@ -0,0 +1,167 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+// Package alerts evaluates per-project alert rules against the event storage
+// and notifies a webhook when an alert starts or stops firing.
+//
+// A rule counts the events that match its filter within a sliding time window.
+// When the count reaches the threshold, the rule fires. With GroupBy, events
+// are counted separately per initiator or target, and each group fires on its
+// own. The Evaluator keeps the state of firing alerts in the Store, so that
+// every transition is notified exactly once even across restarts.
+package alerts
+
+import (
+	"time"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/sapcc/go-bits/must"
+
+	"github.com/sapcc/hermes/pkg/hermes"
+)
+
+// GroupBy selects the event attribute by which a rule counts separately.
+type GroupBy string
+
+const (
+	// GroupByNone counts all matching events together.
+	GroupByNone GroupBy = ""
+	// GroupByInitiatorID counts per initiator.id.
+	GroupByInitiatorID GroupBy = "initiator_id"
+	// GroupByInitiatorName counts per initiator.name.
+	GroupByInitiatorName GroupBy = "initiator_name"
+	// GroupByTargetID counts per target.id.
+	GroupByTargetID GroupBy = "target_id"
+)
+
+// IsValid returns whether g is one of the known groupings.
+func (g GroupBy) IsValid() bool {
+	switch g {
+	case GroupByNone, GroupByInitiatorID, GroupByInitiatorName, GroupByTargetID:
+		return true
+	default:
+		return false
+	}
+}
+
+// key returns the group of an event.
+func (g GroupBy) key(event *cadf.Event) string {
+	switch g {
+	case GroupByInitiatorID:
+		return event.Initiator.ID
+	case GroupByInitiatorName:
+		return event.Initiator.Name
+	case GroupByTargetID:
+		return event.Target.ID
+	default:
+		return ""
+	}
+}
+
+// Rule is an alert rule owned by a project.
+type Rule struct {
+	ID          string             `json:"id"`
+	ProjectID   string             `json:"project_id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Filter      hermes.FieldFilter `json:"filter"`
+	// Threshold is the number of matching events within the window at which the rule fires.
+	Threshold     int     `json:"threshold"`
+	WindowSeconds int     `json:"window_seconds"`
+	GroupBy       GroupBy `json:"group_by"`
+	// WebhookURL receives a POST request with a Notification on every state change.
+	WebhookURL string    `json:"webhook_url"`
+	Enabled    bool      `json:"enabled"`
+	CreatedAt  time.Time `json:"created_at"`
+	CreatedBy  string    `json:"created_by"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	UpdatedBy  string    `json:"updated_by"`
+}
+
+// Window returns the length of the sliding window.
+func (r Rule) Window() time.Duration {
+	return time.Duration(r.WindowSeconds) * time.Second
+}
+
+// Render implements the audittools.Target interface so Rule can be
+// used directly in audittools.Event.Target.
+//
+// The webhook URL is left out because it may contain credentials.
+func (r Rule) Render() cadf.Resource {
+	return cadf.Resource{
+		TypeURI:   "service/hermes/alert-rule",
+		ID:        r.ID,
+		Name:      r.Name,
+		ProjectID: r.ProjectID,
+		Attachments: []cadf.Attachment{
+			must.Return(cadf.NewJSONAttachment("payload", map[string]any{
+				"name":           r.Name,
+				"filter":         r.Filter,
+				"threshold":      r.Threshold,
+				"window_seconds": r.WindowSeconds,
+				"group_by":       r.GroupBy,
+				"enabled":        r.Enabled,
+			})),
+		},
+	}
+}
+
+// Status is the state of an alert.
+type Status string
+
+const (
+	// StatusFiring means that the rule's threshold is reached.
+	StatusFiring Status = "firing"
+	// StatusResolved means that the count dropped below the threshold, but the
+	// webhook has not accepted the notification yet. Once it has, the alert
+	// is deleted.
+	StatusResolved Status = "resolved"
+)
+
+// Alert is the state of one rule (and one group, if the rule has GroupBy)
+// whose threshold was reached.
+type Alert struct {
+	RuleID    string `json:"rule_id"`
+	ProjectID string `json:"project_id"`
+	// GroupKey is the value of the rule's GroupBy attribute, or empty.
+	GroupKey string `json:"group_key"`
+	Status   Status `json:"status"`
+	// Count is the number of matching events at the last evaluation.
+	Count           int        `json:"count"`
+	FiringSince     time.Time  `json:"firing_since"`
+	ResolvedAt      *time.Time `json:"resolved_at,omitempty"`
+	LastEvaluatedAt time.Time  `json:"last_evaluated_at"`
+	// Notified is whether the webhook has accepted the notification for the current status.
+	Notified bool `json:"notified"`
+}
+
+// Notification is the JSON body that is POSTed to a rule's webhook.
+type Notification struct {
+	Status        Status     `json:"status"`
+	RuleID        string     `json:"rule_id"`
+	RuleName      string     `json:"rule_name"`
+	ProjectID     string     `json:"project_id"`
+	GroupBy       GroupBy    `json:"group_by,omitempty"`
+	GroupKey      string     `json:"group_key,omitempty"`
+	Count         int        `json:"count"`
+	Threshold     int        `json:"threshold"`
+	WindowSeconds int        `json:"window_seconds"`
+	FiringSince   time.Time  `json:"firing_since"`
+	ResolvedAt    *time.Time `json:"resolved_at,omitempty"`
+}
+
+func newNotification(rule Rule, alert Alert) Notification {
+	return Notification{
+		Status:        alert.Status,
+		RuleID:        rule.ID,
+		RuleName:      rule.Name,
+		ProjectID:     rule.ProjectID,
+		GroupBy:       rule.GroupBy,
+		GroupKey:      alert.GroupKey,
+		Count:         alert.Count,
+		Threshold:     rule.Threshold,
+		WindowSeconds: rule.WindowSeconds,
+		FiringSince:   alert.FiringSince,
+		ResolvedAt:    alert.ResolvedAt,
+	}
+}
@@ -0,0 +1,357 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/sapcc/go-bits/audittools"
+	"github.com/sapcc/go-bits/gopherpolicy"
+	"github.com/sapcc/go-bits/logg"
+	"github.com/sapcc/go-bits/respondwith"
+
+	"github.com/sapcc/hermes/pkg/alerts"
+	"github.com/sapcc/hermes/pkg/hermes"
+	"github.com/sapcc/hermes/pkg/webhook"
+)
+
+const (
+	minAlertWindowSeconds = 60
+	maxAlertWindowSeconds = 24 * 60 * 60
+)
+
+// alertRuleRequest is the shape accepted on POST and PUT.
+// We use strict decoding (DisallowUnknownFields) so unknown fields → 400.
+type alertRuleRequest struct {
+	Name          string             `json:"name"`
+	Description   string             `json:"description"`
+	Filter        hermes.FieldFilter `json:"filter"`
+	Threshold     int                `json:"threshold"`
+	WindowSeconds int                `json:"window_seconds"`
+	GroupBy       alerts.GroupBy     `json:"group_by"`
+	WebhookURL    string             `json:"webhook_url"`
+	// Enabled defaults to true.
+	Enabled *bool `json:"enabled"`
+}
+
+// alertRuleList is the response body of GET /v1/projects/{project_id}/alert-rules.
+type alertRuleList struct {
+	AlertRules []alerts.Rule `json:"alert_rules"`
+}
+
+// alertList is the response body of GET /v1/projects/{project_id}/alert-rules/{alert_rule_id}/alerts.
+type alertList struct {
+	Alerts []alerts.Alert `json:"alerts"`
+}
+
+// ListAlertRules handles GET /v1/projects/{project_id}/alert-rules.
+func (p *v1Provider) ListAlertRules(res http.ResponseWriter, req *http.Request) {
+	projectID := mux.Vars(req)["project_id"]
+	if _, ok := p.authAlertRules(res, req, projectID, "alert_rule:list"); !ok {
+		return
+	}
+
+	rules, err := p.alertStore.ListRules(req.Context(), projectID)
+	if err != nil {
+		logg.Error("alert-rules LIST: storage error for project %s: %s", projectID, err)
+		respondwith.ObfuscatedErrorText(res, err)
+		return
+	}
+	if rules == nil {
+		rules = []alerts.Rule{}
+	}
+	ReturnESJSON(res, http.StatusOK, alertRuleList{AlertRules: rules})
+}
+
+// CreateAlertRule handles POST /v1/projects/{project_id}/alert-rules.
+// Returns 201 with the saved document.
+// An audit event is emitted for every attempt — successful or not.
+func (p *v1Provider) CreateAlertRule(res http.ResponseWriter, req *http.Request) {
+	projectID := mux.Vars(req)["project_id"]
+	token, ok := p.authAlertRules(res, req, projectID, "alert_rule:manage")
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	userID := token.Context.Auth["user_id"]
+	if userID == "" {
+		http.Error(res, "token missing user identity", http.StatusUnauthorized)
+		return
+	}
+	rule := alerts.Rule{
+		ID:        uuid.NewString(),
+		ProjectID: projectID,
+		CreatedAt: now,
+		CreatedBy: userID,
+		UpdatedAt: now,
+		UpdatedBy: userID,
+	}
+	recordAttempt := func(reasonCode int) {
+		p.auditor.Record(audittools.Event{
+			Time:       now,
+			Request:    req,
+			User:       token,
+			ReasonCode: reasonCode,
+			Action:     cadf.CreateAction,
+			Target:     rule,
+		})
+	}
+
+	var body alertRuleRequest
+	status, err := decodeJSONBody(res, req, &body)
+	if err == nil {
+		status, err = applyAlertRuleRequest(&rule, body, p.webhookGuard)
+	}
+	if err != nil {
+		http.Error(res, err.Error(), status)
+		recordAttempt(status)
+		return
+	}
+
+	err = p.alertStore.CreateRule(req.Context(), rule)
+	if errors.Is(err, alerts.ErrDuplicateName) {
+		http.Error(res, err.Error(), http.StatusConflict)
+		recordAttempt(http.StatusConflict)
+		return
+	}
+	if err != nil {
+		logg.Error("alert-rules POST: storage error for project %s: %s", projectID, err)
+		respondwith.ObfuscatedErrorText(res, err)
+		recordAttempt(http.StatusInternalServerError)
+		return
+	}
+
+	logg.Info("alert-rules POST: project=%s id=%s created_by=%s", projectID, rule.ID, userID)
+	recordAttempt(http.StatusCreated)
+	ReturnESJSON(res, http.StatusCreated, rule)
+}
+
+// GetAlertRule handles GET /v1/projects/{project_id}/alert-rules/{alert_rule_id}.
+func (p *v1Provider) GetAlertRule(res http.ResponseWriter, req *http.Request) {
+	projectID := mux.Vars(req)["project_id"]
+	if _, ok := p.authAlertRules(res, req, projectID, "alert_rule:list"); !ok {
+		return
+	}
+	rule, ok := p.findAlertRule(res, req, projectID)
+	if !ok {
+		return
+	}
+	ReturnESJSON(res, http.StatusOK, rule)
+}
+
+// UpdateAlertRule handles PUT /v1/projects/{project_id}/alert-rules/{alert_rule_id}.
+// Replaces all user-settable fields. Returns 200 with the saved document.
+// An audit event is emitted for every attempt on an existing rule.
+func (p *v1Provider) UpdateAlertRule(res http.ResponseWriter, req *http.Request) {
+	projectID := mux.Vars(req)["project_id"]
+	token, ok := p.authAlertRules(res, req, projectID, "alert_rule:manage")
+	if !ok {
+		return
+	}
+	rule, ok := p.findAlertRule(res, req, projectID)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	userID := token.Context.Auth["user_id"]
+	recordAttempt := func(reasonCode int) {
+		p.auditor.Record(audittools.Event{
+			Time:       now,
+			Request:    req,
+			User:       token,
+			ReasonCode: reasonCode,
+			Action:     cadf.UpdateAction,
+			Target:     *rule,
+		})
+	}
+
+	var body alertRuleRequest
+	status, err := decodeJSONBody(res, req, &body)
+	if err == nil {
+		status, err = applyAlertRuleRequest(rule, body, p.webhookGuard)
+	}
+	if err != nil {
+		http.Error(res, err.Error(), status)
+		recordAttempt(status)
+		return
+	}
+	rule.UpdatedAt = now
+	rule.UpdatedBy = userID
+
+	err = p.alertStore.UpdateRule(req.Context(), *rule)
+	switch {
+	case errors.Is(err, alerts.ErrDuplicateName):
+		http.Error(res, err.Error(), http.StatusConflict)
+		recordAttempt(http.StatusConflict)
+		return
+	case errors.Is(err, alerts.ErrNotFound):
+		// deleted concurrently
+		http.Error(res, "alert rule not found", http.StatusNotFound)
+		return
+	case err != nil:
+		logg.Error("alert-rules PUT: storage error for rule %s: %s", rule.ID, err)
+		respondwith.ObfuscatedErrorText(res, err)
+		recordAttempt(http.StatusInternalServerError)
+		return
+	}
+
+	logg.Info("alert-rules PUT: project=%s id=%s enabled=%t updated_by=%s", projectID, rule.ID, rule.Enabled, userID)
+	recordAttempt(http.StatusOK)
+	ReturnESJSON(res, http.StatusOK, rule)
+}
+
+// DeleteAlertRule handles DELETE /v1/projects/{project_id}/alert-rules/{alert_rule_id}.
+// Returns 204. The rule's alerts are deleted without notification.
+func (p *v1Provider) DeleteAlertRule(res http.ResponseWriter, req *http.Request) {
+	projectID := mux.Vars(req)["project_id"]
+	token, ok := p.authAlertRules(res, req, projectID, "alert_rule:manage")
+	if !ok {
+		return
+	}
+	rule, ok := p.findAlertRule(res, req, projectID)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	recordAttempt := func(reasonCode int) {
+		p.auditor.Record(audittools.Event{
+			Time:       now,
+			Request:    req,
+			User:       token,
+			ReasonCode: reasonCode,
+			Action:     cadf.DeleteAction,
+			Target:     *rule,
+		})
+	}
+	deleted, err := p.alertStore.DeleteRule(req.Context(), rule.ID)
+	if err != nil {
+		logg.Error("alert-rules DELETE: storage error for rule %s: %s", rule.ID, err)
+		respondwith.ObfuscatedErrorText(res, err)
+		recordAttempt(http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		// deleted concurrently
+		http.Error(res, "alert rule not found", http.StatusNotFound)
+		return
+	}
+
+	recordAttempt(http.StatusNoContent)
+	logg.Info("alert-rules DELETE: project=%s id=%s deleted_by=%s", projectID, rule.ID, token.Context.Auth["user_id"])
+	res.WriteHeader(http.StatusNoContent)
+}
+
+// ListAlerts handles GET /v1/projects/{project_id}/alert-rules/{alert_rule_id}/alerts.
+// Returns the rule's firing alerts and resolved alerts whose notification is still pending.
+func (p *v1Provider) ListAlerts(res http.ResponseWriter, req *http.Request) {
+	projectID := mux.Vars(req)["project_id"]
+	if _, ok := p.authAlertRules(res, req, projectID, "alert_rule:list"); !ok {
+		return
+	}
+	rule, ok := p.findAlertRule(res, req, projectID)
+	if !ok {
+		return
+	}
+
+	result, err := p.alertStore.ListAlerts(req.Context(), rule.ID)
+	if err != nil {
+		logg.Error("alert-rules ALERTS: storage error for rule %s: %s", rule.ID, err)
+		respondwith.ObfuscatedErrorText(res, err)
+		return
+	}
+	if result == nil {
+		result = []alerts.Alert{}
+	}
+	ReturnESJSON(res, http.StatusOK, alertList{Alerts: result})
+}
+
+// authAlertRules performs the project-scoped auth check for the alert-rule
+// endpoints and ensures that a store is configured.
+func (p *v1Provider) authAlertRules(res http.ResponseWriter, req *http.Request, pathProjectID, rule string) (*gopherpolicy.Token, bool) {
+	token, ok := p.authProjectScoped(res, req, pathProjectID, rule)
+	if !ok {
+		return nil, false
+	}
+	if p.alertStore == nil {
+		http.Error(res, "alerting is not enabled on this server", http.StatusNotImplemented)
+		return nil, false
+	}
+	return token, true
+}
+
+// findAlertRule loads the rule from the path. Rules of other projects are
+// reported as not found.
+func (p *v1Provider) findAlertRule(res http.ResponseWriter, req *http.Request, projectID string) (*alerts.Rule, bool) {
+	id := mux.Vars(req)["alert_rule_id"]
+	if _, err := uuid.Parse(id); err != nil {
+		http.Error(res, "Invalid alert rule ID format", http.StatusBadRequest)
+		return nil, false
+	}
+	rule, err := p.alertStore.GetRule(req.Context(), id)
+	if errors.Is(err, alerts.ErrNotFound) || (err == nil && rule.ProjectID != projectID) {
+		http.Error(res, "alert rule not found", http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		logg.Error("alert-rules GET: storage error for rule %s: %s", id, err)
+		respondwith.ObfuscatedErrorText(res, err)
+		return nil, false
+	}
+	return rule, true
+}
+
+// applyAlertRuleRequest validates the request body and copies it into rule.
+// On error, it returns the HTTP status to respond with.
+func applyAlertRuleRequest(rule *alerts.Rule, body alertRuleRequest, guard webhook.Guard) (int, error) {
+	name := strings.TrimSpace(body.Name)
+	if name == "" || len(name) > maxSavedSearchNameLength {
+		return http.StatusBadRequest, fmt.Errorf("name must be between 1 and %d characters", maxSavedSearchNameLength)
+	}
+	if len(body.Description) > maxSavedSearchDescriptionLength {
+		return http.StatusBadRequest, fmt.Errorf("description must be at most %d characters", maxSavedSearchDescriptionLength)
+	}
+	if body.Threshold < 1 {
+		return http.StatusBadRequest, errors.New("threshold must be at least 1")
+	}
+	if body.WindowSeconds < minAlertWindowSeconds || body.WindowSeconds > maxAlertWindowSeconds {
+		return http.StatusBadRequest, fmt.Errorf("window_seconds must be between %d and %d", minAlertWindowSeconds, maxAlertWindowSeconds)
+	}
+	if !body.GroupBy.IsValid() {
+		return http.StatusBadRequest, fmt.Errorf("group_by must be empty, %q, %q or %q",
+			alerts.GroupByInitiatorID, alerts.GroupByInitiatorName, alerts.GroupByTargetID)
+	}
+	if !isWebhookURL(body.WebhookURL) {
+		return http.StatusBadRequest, errors.New("webhook_url must be an absolute http or https URL")
+	}
+	if err := guard.CheckURL(body.WebhookURL); err != nil {
+		return http.StatusUnprocessableEntity, fmt.Errorf("webhook_url is not allowed: %w", err)
+	}
+
+	rule.Name = name
+	rule.Description = body.Description
+	rule.Filter = body.Filter
+	rule.Threshold = body.Threshold
+	rule.WindowSeconds = body.WindowSeconds
+	rule.GroupBy = body.GroupBy
+	rule.WebhookURL = body.WebhookURL
+	rule.Enabled = body.Enabled == nil || *body.Enabled
+	return http.StatusOK, nil
+}
+
+// isWebhookURL returns whether rawURL is an absolute http or https URL.
+func isWebhookURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != ""
+}
@@ -0,0 +1,189 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/sapcc/go-bits/audittools"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapcc/hermes/pkg/alerts"
+	"github.com/sapcc/hermes/pkg/hermes"
+	"github.com/sapcc/hermes/pkg/webhook"
+)
+
+const alertRulesPath = "/v1/projects/" + testProjectID + "/alert-rules"
+
+func validAlertRuleBody() map[string]any {
+	return map[string]any{
+		"name":           "failed actions",
+		"filter":         map[string]string{"outcome": "failure"},
+		"threshold":      50,
+		"window_seconds": 300,
+		"group_by":       "initiator_id",
+		"webhook_url":    "https://alerts.example.com/hook",
+	}
+}
+
+func TestAlertRules_CRUD(t *testing.T) {
+	store := alerts.NewMock()
+	auditor := audittools.NewMockAuditor()
+	admin := newTestUser(t, auditor, testProjectID, "admin", nil, WithAlertStore(store))
+	viewer := newTestUser(t, auditor, testProjectID, "viewer", []string{"alert_rule:manage"}, WithAlertStore(store))
+	neighbor := newTestUser(t, auditor, "test-project-2", "carol", nil, WithAlertStore(store))
+
+	rec := viewer.do(http.MethodPost, alertRulesPath, validAlertRuleBody())
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = admin.do(http.MethodPost, alertRulesPath, validAlertRuleBody())
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var rule alerts.Rule
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &rule))
+	assert.Equal(t, testProjectID, rule.ProjectID)
+	assert.Equal(t, hermes.FieldFilter{Outcome: "failure"}, rule.Filter)
+	assert.Equal(t, alerts.GroupByInitiatorID, rule.GroupBy)
+	assert.True(t, rule.Enabled, "rules are enabled by default")
+	auditor.ExpectEvents(t, cadf.Event{
+		Action:      cadf.CreateAction,
+		Outcome:     cadf.SuccessOutcome,
+		Reason:      cadf.Reason{ReasonType: "HTTP", ReasonCode: "201"},
+		Target:      rule.Render(),
+		RequestPath: alertRulesPath,
+	})
+
+	rec = admin.do(http.MethodPost, alertRulesPath, validAlertRuleBody())
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = viewer.do(http.MethodGet, alertRulesPath, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list alertRuleList
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list.AlertRules, 1)
+	assert.Equal(t, rule.ID, list.AlertRules[0].ID)
+
+	// rules of other projects are not visible
+	rulePath := alertRulesPath + "/" + rule.ID
+	rec = neighbor.do(http.MethodGet, "/v1/projects/test-project-2/alert-rules/"+rule.ID, nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	body := validAlertRuleBody()
+	body["threshold"] = 10
+	body["enabled"] = false
+	rec = admin.do(http.MethodPut, rulePath, body)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var updated alerts.Rule
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &updated))
+	assert.Equal(t, 10, updated.Threshold)
+	assert.False(t, updated.Enabled)
+	assert.Equal(t, rule.CreatedAt, updated.CreatedAt)
+
+	rec = admin.do(http.MethodDelete, rulePath, nil)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	rec = admin.do(http.MethodGet, rulePath, nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestAlertRules_Validation(t *testing.T) {
+	admin := newTestUser(t, audittools.NewNullAuditor(), testProjectID, "admin", nil, WithAlertStore(alerts.NewMock()))
+	tt := []struct {
+		name   string
+		modify func(body map[string]any)
+	}{
+		{"MissingName", func(body map[string]any) { delete(body, "name") }},
+		{"ZeroThreshold", func(body map[string]any) { body["threshold"] = 0 }},
+		{"ShortWindow", func(body map[string]any) { body["window_seconds"] = 10 }},
+		{"LongWindow", func(body map[string]any) { body["window_seconds"] = 7 * 24 * 3600 }},
+		{"UnknownGroupBy", func(body map[string]any) { body["group_by"] = "observer_type" }},
+		{"MissingWebhook", func(body map[string]any) { delete(body, "webhook_url") }},
+		{"RelativeWebhook", func(body map[string]any) { body["webhook_url"] = "/hook" }},
+		{"WebhookScheme", func(body map[string]any) { body["webhook_url"] = "file:///etc/passwd" }},
+		{"UnknownFilter", func(body map[string]any) { body["filter"] = map[string]string{"project_id": "other"} }},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			body := validAlertRuleBody()
+			tc.modify(body)
+			rec := admin.do(http.MethodPost, alertRulesPath, body)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestAlertRules_WebhookGuard(t *testing.T) {
+	admin := newTestUser(t, audittools.NewNullAuditor(), testProjectID, "admin", nil, WithAlertStore(alerts.NewMock()))
+	for _, webhookURL := range []string{
+		"http://alerts.example.com/hook",
+		"https://localhost:8080/hook",
+		"https://127.0.0.1/hook",
+		"https://[::1]/hook",
+		"https://[::ffff:127.0.0.1]/hook",
+		"https://0.0.0.0/hook",
+		"https://10.1.2.3/hook",
+		"https://192.168.0.1/hook",
+		"https://169.254.169.254/latest/meta-data",
+		"https://[fe80::1]/hook",
+	} {
+		body := validAlertRuleBody()
+		body["webhook_url"] = webhookURL
+		rec := admin.do(http.MethodPost, alertRulesPath, body)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, "%s: %s", webhookURL, rec.Body.String())
+	}
+
+	// operators can allow internal networks
+	admin = newTestUser(t, audittools.NewNullAuditor(), testProjectID, "admin", nil, WithAlertStore(alerts.NewMock()),
+		WithWebhookGuard(webhook.Guard{AllowedNetworks: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}}))
+	body := validAlertRuleBody()
+	body["webhook_url"] = "https://10.1.2.3/hook"
+	rec := admin.do(http.MethodPost, alertRulesPath, body)
+	assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	body["webhook_url"] = "https://192.168.0.1/hook"
+	rec = admin.do(http.MethodPost, alertRulesPath, body)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+}
+
+func TestAlertRules_ListAlerts(t *testing.T) {
+	store := alerts.NewMock()
+	admin := newTestUser(t, audittools.NewNullAuditor(), testProjectID, "admin", nil, WithAlertStore(store))
+	rec := admin.do(http.MethodPost, alertRulesPath, validAlertRuleBody())
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var rule alerts.Rule
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &rule))
+
+	alertsPath := alertRulesPath + "/" + rule.ID + "/alerts"
+	rec = admin.do(http.MethodGet, alertsPath, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"alerts":[]}`, rec.Body.String())
+
+	since := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, store.SaveAlert(t.Context(), alerts.Alert{
+		RuleID:          rule.ID,
+		ProjectID:       testProjectID,
+		GroupKey:        "alice",
+		Status:          alerts.StatusFiring,
+		Count:           51,
+		FiringSince:     since,
+		LastEvaluatedAt: since,
+		Notified:        true,
+	}))
+	rec = admin.do(http.MethodGet, alertsPath, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list alertList
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list.Alerts, 1)
+	assert.Equal(t, "alice", list.Alerts[0].GroupKey)
+	assert.Equal(t, 51, list.Alerts[0].Count)
+}
+
+func TestAlertRules_NotEnabled(t *testing.T) {
+	admin := newTestUser(t, audittools.NewNullAuditor(), testProjectID, "admin", nil)
+	rec := admin.do(http.MethodGet, alertRulesPath, nil)
+	assert.Equal(t, http.StatusNotImplemented, rec.Code)
+}
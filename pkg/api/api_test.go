@@ -4,6 +4,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"os"
@@ -12,12 +13,16 @@ import (
 
 	policy "github.com/databus23/goslo.policy"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sapcc/go-api-declarations/cadf"
 	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/sapcc/go-bits/audittools"
 	"github.com/sapcc/go-bits/httpapi"
 	"github.com/sapcc/go-bits/mock"
 
+	"github.com/sapcc/hermes/pkg/hermes"
 	"github.com/sapcc/hermes/pkg/routing"
 	"github.com/sapcc/hermes/pkg/storage"
 	"github.com/sapcc/hermes/pkg/test"
@@ -73,6 +78,10 @@ func Test_API(t *testing.T) {
 		{"Metadata", "GET", "/v1/", http.StatusOK, "fixtures/api-metadata.json"},
 		{"EventDetails", "GET", "/v1/events/7be6c4ff-b761-5f1f-b234-f5d41616c2cd", http.StatusOK, "fixtures/event-details.json"},
 		{"EventList", "GET", "/v1/events?event_type=identity.project.deleted&offset=10", http.StatusOK, "fixtures/event-list.json"},
+		{"EventDetailsOCSF", "GET", "/v1/events/7be6c4ff-b761-5f1f-b234-f5d41616c2cd?format=ocsf", http.StatusOK, "fixtures/event-details-ocsf.json"},
+		{"EventListOCSF", "GET", "/v1/events?format=ocsf&limit=2", http.StatusOK, "fixtures/event-list-ocsf.json"},
+		{"EventDetailsCSV", "GET", "/v1/events/7be6c4ff-b761-5f1f-b234-f5d41616c2cd?format=csv", http.StatusBadRequest, ""},
+		{"AttributesOCSF", "GET", "/v1/attributes/action?limit=10&format=ocsf", http.StatusBadRequest, ""},
 		{"Attributes", "GET", "/v1/attributes/resource_type?limit=10", http.StatusOK, "fixtures/attributes.json"},
 		{"AttributesKnownName", "GET", "/v1/attributes/action?limit=10", http.StatusOK, "fixtures/attributes.json"},
 		{"AttributesUnknownName", "GET", "/v1/attributes/observer.id.keyword", http.StatusBadRequest, ""},
@@ -156,3 +165,139 @@ func TestListEvents_ParameterParsing(t *testing.T) {
 		})
 	}
 }
+
+func TestGetEventDetails_Redaction(t *testing.T) {
+	router := setupTest(t)
+	// the default test setup does not configure redaction, so the event is returned in full
+	test.APIRequest{
+		Method:           "GET",
+		Path:             "/v1/events/7be6c4ff-b761-5f1f-b234-f5d41616c2cd",
+		ExpectStatusCode: http.StatusOK,
+		ExpectJSON:       "fixtures/event-details.json",
+	}.Check(t, router)
+
+	redactor, err := hermes.NewRedactor(hermes.Operators{ProjectIDs: []string{"a759dcc2a2384a76b0386bb985952373"}}, []hermes.RedactionRule{
+		{Field: "initiator.host.address", Policy: "event:show_initiator_host", Action: hermes.RedactMask},
+		{Field: "initiator.host.agent", Policy: "event:show_initiator_host", Action: hermes.RedactRemove},
+		{Field: "attachments", Policy: "event:show_attachments", Action: hermes.RedactRemove},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	enforcer := mock.NewEnforcer()
+	enforcer.Forbid("event:show_initiator_host")
+	enforcer.Forbid("event:show_attachments")
+	prometheus.DefaultRegisterer = prometheus.NewPedanticRegistry()
+	v1API := NewV1API(mock.NewValidator(enforcer, nil), storage.Mock{}, routing.NewMock(),
+		audittools.NewNullAuditor(), WithRedactor(redactor))
+
+	test.APIRequest{
+		Method:           "GET",
+		Path:             "/v1/events/7be6c4ff-b761-5f1f-b234-f5d41616c2cd",
+		ExpectStatusCode: http.StatusOK,
+		ExpectJSON:       "fixtures/event-details-redacted.json",
+	}.Check(t, httpapi.Compose(v1API))
+}
+
+// staticNames is a hermes.NameResolver with fixed names.
+type staticNames map[string]string
+
+func (n staticNames) ResolveNames(_ context.Context, _ hermes.NameKind, ids []string) (map[string]string, error) {
+	result := make(map[string]string)
+	for _, id := range ids {
+		if name, ok := n[id]; ok {
+			result[id] = name
+		}
+	}
+	return result, nil
+}
+
+// TestEnrichParameter checks that the enrich parameter reaches
+// hermes.EventView on all endpoints that support it. The enrichment itself
+// is tested in package hermes.
+func TestEnrichParameter(t *testing.T) {
+	// without a name resolver (e.g. with the mock Keystone driver), enrichment is not available
+	rec := getWithAccept(newFormatTest(t), "/v1/events?enrich=names", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	validator := mock.NewValidator(mock.NewEnforcer(), map[string]string{"project_id": testProjectID})
+	events := storage.NewMemory(100)
+	eventID := "a5fe3a49-0d6f-4b1d-8f4f-000000000001"
+	events.Add([]string{testProjectID}, cadf.Event{
+		ID:        eventID,
+		EventTime: "2026-01-01T12:00:00.000000+00:00",
+		Action:    cadf.DeleteAction,
+		Outcome:   cadf.SuccessOutcome,
+		Initiator: cadf.Resource{TypeURI: "service/security/account/user", ID: "user-1", ProjectID: testProjectID},
+		Target:    cadf.Resource{TypeURI: "data/security/account/user", ID: "user-2"},
+	})
+	prometheus.DefaultRegisterer = prometheus.NewPedanticRegistry()
+	names := staticNames{"user-1": "alice", "user-2": "bob", testProjectID: "project one"}
+	handler := httpapi.Compose(NewV1API(validator, events, routing.NewMock(), audittools.NewNullAuditor(), WithNameResolver(names)))
+
+	for _, path := range []string{"/v1/events?enrich=ids", "/v1/events/" + eventID + "?enrich=ids", "/v1/export?enrich=ids&format=csv"} {
+		rec = getWithAccept(handler, path, "")
+		assert.Equal(t, http.StatusBadRequest, rec.Code, path)
+		assert.Contains(t, rec.Body.String(), `unknown value "ids" for enrich`, path)
+	}
+
+	// names are only filled in on request
+	rec = getWithAccept(handler, "/v1/events/"+eventID, "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.NotContains(t, rec.Body.String(), "alice")
+
+	rec = getWithAccept(handler, "/v1/events/"+eventID+"?enrich=names", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var event cadf.Event
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &event))
+	assert.Equal(t, "alice", event.Initiator.Name)
+	assert.Equal(t, "bob", event.Target.Name)
+
+	for _, path := range []string{
+		"/v1/events?enrich=names&format=csv&columns=initiator.name,target.name",
+		"/v1/export?enrich=names&format=csv&columns=initiator.name,target.name",
+	} {
+		rec = getWithAccept(handler, path, "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, "initiator.name,target.name\nalice,bob\n", rec.Body.String(), path)
+	}
+}
+
+func TestGetEventDetails_Diff(t *testing.T) {
+	events := storage.NewMemory(100)
+	target := cadf.Resource{TypeURI: "network/security-group", ID: "b4f5ec6b-7d16-4e6b-a5b5-2a5ae6e1f40c"}
+	events.Add([]string{testProjectID},
+		cadf.Event{
+			ID: "0b56bd8e-4a38-4a9a-93a0-8d0b4e5bd3a1", EventTime: "2026-01-01T12:00:00.000000+00:00",
+			Action: cadf.CreateAction, Outcome: cadf.SuccessOutcome, Target: target,
+			Attachments: []cadf.Attachment{{Name: "payload", TypeURI: "mime:application/json",
+				Content: `{"name": "web", "description": "", "stateful": true}`}},
+		},
+		cadf.Event{
+			ID: "2c9c4bd3-7d1a-4a55-b6b0-9e8f8d9f6c02", EventTime: "2026-01-02T12:00:00.000000+00:00",
+			Action: cadf.UpdateAction, Outcome: cadf.SuccessOutcome, Target: target,
+			Attachments: []cadf.Attachment{{Name: "payload", TypeURI: "mime:application/json",
+				Content: `{"name": "web", "description": "HTTP only", "stateful": false}`}},
+		},
+	)
+	prometheus.DefaultRegisterer = prometheus.NewPedanticRegistry()
+	validator := mock.NewValidator(mock.NewEnforcer(), map[string]string{"project_id": testProjectID})
+	router := httpapi.Compose(NewV1API(validator, events, routing.NewMock(), audittools.NewNullAuditor()))
+
+	test.APIRequest{
+		Method:           "GET",
+		Path:             "/v1/events/2c9c4bd3-7d1a-4a55-b6b0-9e8f8d9f6c02?diff=true",
+		ExpectStatusCode: http.StatusOK,
+		ExpectJSON:       "fixtures/event-details-diff.json",
+	}.Check(t, router)
+	test.APIRequest{
+		Method:           "GET",
+		Path:             "/v1/events/2c9c4bd3-7d1a-4a55-b6b0-9e8f8d9f6c02?diff=yes",
+		ExpectStatusCode: http.StatusBadRequest,
+	}.Check(t, router)
+	test.APIRequest{
+		Method:           "GET",
+		Path:             "/v1/events/2c9c4bd3-7d1a-4a55-b6b0-9e8f8d9f6c02?diff=true&format=ocsf",
+		ExpectStatusCode: http.StatusBadRequest,
+	}.Check(t, router)
+}
@@ -13,8 +13,16 @@ import (
 	"github.com/sapcc/go-bits/gopherpolicy"
 	"github.com/sapcc/go-bits/httpapi"
 
+	"github.com/sapcc/hermes/pkg/alerts"
+	"github.com/sapcc/hermes/pkg/export"
+	"github.com/sapcc/hermes/pkg/hermes"
+	"github.com/sapcc/hermes/pkg/integrity"
 	"github.com/sapcc/hermes/pkg/routing"
+	"github.com/sapcc/hermes/pkg/routing/bucket"
+	"github.com/sapcc/hermes/pkg/searches"
 	"github.com/sapcc/hermes/pkg/storage"
+	"github.com/sapcc/hermes/pkg/subscriptions"
+	"github.com/sapcc/hermes/pkg/webhook"
 )
 
 // VersionData is used by version advertisement handlers.
@@ -34,10 +42,122 @@ type versionLinkData struct {
 
 // v1Provider provides backward compatibility for existing handler methods
 type v1Provider struct {
-	validator    gopherpolicy.Validator
-	storage      storage.Storage
-	routingStore routing.Store
-	auditor      audittools.Auditor
+	validator         gopherpolicy.Validator
+	storage           storage.Storage
+	routingStore      routing.Store
+	auditor           audittools.Auditor
+	redactor          *hermes.Redactor
+	nameResolver      hermes.NameResolver
+	summarizer        *hermes.Summarizer
+	integrityStore    integrity.Store
+	exporter          *export.Exporter
+	searchStore       searches.Store
+	alertStore        alerts.Store
+	subscriptionStore subscriptions.Store
+	streamConfig      EventStreamConfig
+	streamConnections *connectionLimiter
+	bucketVerifier    bucket.Verifier
+	bucketMode        bucket.Mode
+	webhookGuard      webhook.Guard
+}
+
+// Option configures optional subsystems of the v1 API.
+// Options are applied by NewV1API after the mandatory dependencies are set.
+type Option func(*v1Provider)
+
+// WithRedactor enables policy-driven field redaction on event reads.
+func WithRedactor(redactor *hermes.Redactor) Option {
+	return func(p *v1Provider) {
+		p.redactor = redactor
+	}
+}
+
+// WithSummarizer adds a human-readable summary to every event in event lists
+// and event details.
+func WithSummarizer(summarizer *hermes.Summarizer) Option {
+	return func(p *v1Provider) {
+		p.summarizer = summarizer
+	}
+}
+
+// WithNameResolver enables the enrich=names parameter of GET /v1/events and
+// GET /v1/events/:event_id.
+func WithNameResolver(resolver hermes.NameResolver) Option {
+	return func(p *v1Provider) {
+		p.nameResolver = resolver
+	}
+}
+
+// WithIntegrityStore enables GET /v1/integrity/verify against the given hash chain store.
+func WithIntegrityStore(store integrity.Store) Option {
+	return func(p *v1Provider) {
+		p.integrityStore = store
+	}
+}
+
+// WithExporter enables GET /v1/export and GET /v1/export/public-key.
+func WithExporter(exporter *export.Exporter) Option {
+	return func(p *v1Provider) {
+		p.exporter = exporter
+	}
+}
+
+// WithSavedSearchStore enables the saved-search endpoints under
+// /v1/projects/{project_id}/saved-searches.
+func WithSavedSearchStore(store searches.Store) Option {
+	return func(p *v1Provider) {
+		p.searchStore = store
+	}
+}
+
+// WithAlertStore enables the alert-rule endpoints under
+// /v1/projects/{project_id}/alert-rules.
+func WithAlertStore(store alerts.Store) Option {
+	return func(p *v1Provider) {
+		p.alertStore = store
+	}
+}
+
+// WithSubscriptionStore enables the webhook subscription endpoints under
+// /v1/projects/{project_id}/subscriptions.
+func WithSubscriptionStore(store subscriptions.Store) Option {
+	return func(p *v1Provider) {
+		p.subscriptionStore = store
+	}
+}
+
+// WithWebhookGuard overrides the default webhook.Guard that checks the
+// webhook URLs of alert rules and subscriptions when they are written.
+func WithWebhookGuard(guard webhook.Guard) Option {
+	return func(p *v1Provider) {
+		p.webhookGuard = guard
+	}
+}
+
+// WithEventStream overrides DefaultEventStreamConfig for GET /v1/events/stream.
+func WithEventStream(config EventStreamConfig) Option {
+	return func(p *v1Provider) {
+		p.streamConfig = config
+	}
+}
+
+// WithBucketVerifier checks the target buckets of dataplane configs and sinks
+// with the given verifier when they are written. In bucket.ModeWarn, failures
+// are only logged; in bucket.ModeEnforce, they are rejected with 422.
+func WithBucketVerifier(verifier bucket.Verifier, mode bucket.Mode) Option {
+	return func(p *v1Provider) {
+		p.bucketVerifier = verifier
+		p.bucketMode = mode
+	}
+}
+
+// eventView builds the hermes.EventView for the caller identified by token.
+func (p *v1Provider) eventView(token *gopherpolicy.Token) *hermes.EventView {
+	return &hermes.EventView{
+		Redactor:   p.redactor,
+		Caller:     token,
+		Summarizer: p.summarizer,
+	}
 }
 
 // AuthHandler wraps endpoint handlers with consistent auth logic.
@@ -81,20 +201,25 @@ type V1API struct {
 //
 //	validator := gopherpolicy.NewValidator(enforcer, logger)
 //	storage := opensearch.NewStorage(config)
-//	api := NewV1API(validator, storage, routingStore, auditor)
-func NewV1API(validator gopherpolicy.Validator, storageInterface storage.Storage, routingStore routing.Store, auditor audittools.Auditor) *V1API {
+//	api := NewV1API(validator, storage, routingStore, auditor, WithRedactor(redactor))
+func NewV1API(validator gopherpolicy.Validator, storageInterface storage.Storage, routingStore routing.Store, auditor audittools.Auditor, opts ...Option) *V1API {
 	api := &V1API{
 		validator:    validator,
 		storage:      storageInterface,
 		routingStore: routingStore,
 		auditor:      auditor,
 		provider: &v1Provider{
-			validator:    validator,
-			storage:      storageInterface,
-			routingStore: routingStore,
-			auditor:      auditor,
+			validator:         validator,
+			storage:           storageInterface,
+			routingStore:      routingStore,
+			auditor:           auditor,
+			streamConfig:      DefaultEventStreamConfig(),
+			streamConnections: newConnectionLimiter(),
 		},
 	}
+	for _, opt := range opts {
+		opt(api.provider)
+	}
 
 	api.versionData = VersionData{
 		Status: "CURRENT",
@@ -128,20 +253,132 @@ func (api *V1API) AddTo(r *mux.Router) {
 	r.Methods("GET").Path("/v1/events").Handler(
 		InstrumentDuration("ListEvents")(InstrumentResponseSize("ListEvents")(http.HandlerFunc(api.listEvents))))
 
+	// must be registered before /v1/events/{event_id}
+	r.Methods("GET").Path("/v1/events/stream").Handler(
+		InstrumentDuration("StreamEvents")(InstrumentResponseSize("StreamEvents")(http.HandlerFunc(api.streamEvents))))
+
 	r.Methods("GET").Path("/v1/events/{event_id}").Handler(
 		InstrumentDuration("GetEventDetails")(InstrumentResponseSize("GetEventDetails")(http.HandlerFunc(api.getEventDetails))))
 
 	r.Methods("GET").Path("/v1/attributes/{attribute_name}").Handler(
 		InstrumentDuration("GetAttributes")(InstrumentResponseSize("GetAttributes")(http.HandlerFunc(api.getAttributes))))
 
+	r.Methods("GET").Path("/v1/export").Handler(
+		InstrumentDuration("ExportEvents")(InstrumentResponseSize("ExportEvents")(http.HandlerFunc(api.exportEvents))))
+
+	r.Methods("GET").Path("/v1/export/public-key").Handler(
+		InstrumentDuration("GetExportPublicKey")(InstrumentResponseSize("GetExportPublicKey")(http.HandlerFunc(api.getExportPublicKey))))
+
+	r.Methods("GET").Path("/v1/integrity/verify").Handler(
+		InstrumentDuration("VerifyIntegrity")(InstrumentResponseSize("VerifyIntegrity")(http.HandlerFunc(api.verifyIntegrity))))
+
+	r.Methods("POST").Path("/v1/validate").Handler(
+		InstrumentDuration("ValidateEvents")(InstrumentResponseSize("ValidateEvents")(http.HandlerFunc(api.validateEvents))))
+
+	r.Methods("GET").Path("/v1/dataplane-configs").Handler(
+		InstrumentDuration("ListDataplaneConfigs")(InstrumentResponseSize("ListDataplaneConfigs")(http.HandlerFunc(api.listDataplaneConfigs))))
+
+	r.Methods("GET").Path("/v1/dataplane-configs/changes").Handler(
+		InstrumentDuration("GetDataplaneConfigChanges")(InstrumentResponseSize("GetDataplaneConfigChanges")(http.HandlerFunc(api.getDataplaneConfigChanges))))
+
 	r.Methods("GET").Path("/v1/projects/{project_id}/dataplane-config").Handler(
 		InstrumentDuration("GetDataplaneConfig")(InstrumentResponseSize("GetDataplaneConfig")(http.HandlerFunc(api.getDataplaneConfig))))
 
 	r.Methods("PUT").Path("/v1/projects/{project_id}/dataplane-config").Handler(
 		InstrumentDuration("PutDataplaneConfig")(InstrumentResponseSize("PutDataplaneConfig")(http.HandlerFunc(api.putDataplaneConfig))))
 
+	r.Methods("PATCH").Path("/v1/projects/{project_id}/dataplane-config").Handler(
+		InstrumentDuration("PatchDataplaneConfig")(InstrumentResponseSize("PatchDataplaneConfig")(http.HandlerFunc(api.patchDataplaneConfig))))
+
 	r.Methods("DELETE").Path("/v1/projects/{project_id}/dataplane-config").Handler(
 		InstrumentDuration("DeleteDataplaneConfig")(InstrumentResponseSize("DeleteDataplaneConfig")(http.HandlerFunc(api.deleteDataplaneConfig))))
+
+	r.Methods("GET").Path("/v1/projects/{project_id}/dataplane-config/effective").Handler(
+		InstrumentDuration("GetEffectiveDataplaneConfig")(InstrumentResponseSize("GetEffectiveDataplaneConfig")(http.HandlerFunc(api.getEffectiveDataplaneConfig))))
+
+	r.Methods("GET").Path("/v1/projects/{project_id}/dataplane-config/history").Handler(
+		InstrumentDuration("GetDataplaneConfigHistory")(InstrumentResponseSize("GetDataplaneConfigHistory")(http.HandlerFunc(api.getDataplaneConfigHistory))))
+
+	r.Methods("POST").Path("/v1/projects/{project_id}/dataplane-config/rollback").Handler(
+		InstrumentDuration("RollbackDataplaneConfig")(InstrumentResponseSize("RollbackDataplaneConfig")(http.HandlerFunc(api.rollbackDataplaneConfig))))
+
+	r.Methods("POST").Path("/v1/projects/{project_id}/dataplane-config/preview").Handler(
+		InstrumentDuration("PreviewDataplaneFilter")(InstrumentResponseSize("PreviewDataplaneFilter")(http.HandlerFunc(api.previewDataplaneFilter))))
+
+	r.Methods("GET").Path("/v1/domains/{domain_id}/dataplane-config").Handler(
+		InstrumentDuration("GetDomainDataplaneConfig")(InstrumentResponseSize("GetDomainDataplaneConfig")(http.HandlerFunc(api.getDomainDataplaneConfig))))
+
+	r.Methods("PUT").Path("/v1/domains/{domain_id}/dataplane-config").Handler(
+		InstrumentDuration("PutDomainDataplaneConfig")(InstrumentResponseSize("PutDomainDataplaneConfig")(http.HandlerFunc(api.putDomainDataplaneConfig))))
+
+	r.Methods("DELETE").Path("/v1/domains/{domain_id}/dataplane-config").Handler(
+		InstrumentDuration("DeleteDomainDataplaneConfig")(InstrumentResponseSize("DeleteDomainDataplaneConfig")(http.HandlerFunc(api.deleteDomainDataplaneConfig))))
+
+	r.Methods("GET").Path("/v1/projects/{project_id}/dataplane-config/sinks").Handler(
+		InstrumentDuration("ListDataplaneSinks")(InstrumentResponseSize("ListDataplaneSinks")(http.HandlerFunc(api.listDataplaneSinks))))
+
+	r.Methods("GET").Path("/v1/projects/{project_id}/dataplane-config/sinks/{sink_name}").Handler(
+		InstrumentDuration("GetDataplaneSink")(InstrumentResponseSize("GetDataplaneSink")(http.HandlerFunc(api.getDataplaneSink))))
+
+	r.Methods("PUT").Path("/v1/projects/{project_id}/dataplane-config/sinks/{sink_name}").Handler(
+		InstrumentDuration("PutDataplaneSink")(InstrumentResponseSize("PutDataplaneSink")(http.HandlerFunc(api.putDataplaneSink))))
+
+	r.Methods("DELETE").Path("/v1/projects/{project_id}/dataplane-config/sinks/{sink_name}").Handler(
+		InstrumentDuration("DeleteDataplaneSink")(InstrumentResponseSize("DeleteDataplaneSink")(http.HandlerFunc(api.deleteDataplaneSink))))
+
+	r.Methods("GET").Path("/v1/projects/{project_id}/saved-searches").Handler(
+		InstrumentDuration("ListSavedSearches")(InstrumentResponseSize("ListSavedSearches")(http.HandlerFunc(api.listSavedSearches))))
+
+	r.Methods("POST").Path("/v1/projects/{project_id}/saved-searches").Handler(
+		InstrumentDuration("CreateSavedSearch")(InstrumentResponseSize("CreateSavedSearch")(http.HandlerFunc(api.createSavedSearch))))
+
+	r.Methods("GET").Path("/v1/projects/{project_id}/saved-searches/{saved_search_id}").Handler(
+		InstrumentDuration("GetSavedSearch")(InstrumentResponseSize("GetSavedSearch")(http.HandlerFunc(api.getSavedSearch))))
+
+	r.Methods("PUT").Path("/v1/projects/{project_id}/saved-searches/{saved_search_id}").Handler(
+		InstrumentDuration("UpdateSavedSearch")(InstrumentResponseSize("UpdateSavedSearch")(http.HandlerFunc(api.updateSavedSearch))))
+
+	r.Methods("DELETE").Path("/v1/projects/{project_id}/saved-searches/{saved_search_id}").Handler(
+		InstrumentDuration("DeleteSavedSearch")(InstrumentResponseSize("DeleteSavedSearch")(http.HandlerFunc(api.deleteSavedSearch))))
+
+	r.Methods("GET").Path("/v1/projects/{project_id}/saved-searches/{saved_search_id}/events").Handler(
+		InstrumentDuration("ExecuteSavedSearch")(InstrumentResponseSize("ExecuteSavedSearch")(http.HandlerFunc(api.executeSavedSearch))))
+
+	r.Methods("GET").Path("/v1/projects/{project_id}/alert-rules").Handler(
+		InstrumentDuration("ListAlertRules")(InstrumentResponseSize("ListAlertRules")(http.HandlerFunc(api.listAlertRules))))
+
+	r.Methods("POST").Path("/v1/projects/{project_id}/alert-rules").Handler(
+		InstrumentDuration("CreateAlertRule")(InstrumentResponseSize("CreateAlertRule")(http.HandlerFunc(api.createAlertRule))))
+
+	r.Methods("GET").Path("/v1/projects/{project_id}/alert-rules/{alert_rule_id}").Handler(
+		InstrumentDuration("GetAlertRule")(InstrumentResponseSize("GetAlertRule")(http.HandlerFunc(api.getAlertRule))))
+
+	r.Methods("PUT").Path("/v1/projects/{project_id}/alert-rules/{alert_rule_id}").Handler(
+		InstrumentDuration("UpdateAlertRule")(InstrumentResponseSize("UpdateAlertRule")(http.HandlerFunc(api.updateAlertRule))))
+
+	r.Methods("DELETE").Path("/v1/projects/{project_id}/alert-rules/{alert_rule_id}").Handler(
+		InstrumentDuration("DeleteAlertRule")(InstrumentResponseSize("DeleteAlertRule")(http.HandlerFunc(api.deleteAlertRule))))
+
+	r.Methods("GET").Path("/v1/projects/{project_id}/alert-rules/{alert_rule_id}/alerts").Handler(
+		InstrumentDuration("ListAlerts")(InstrumentResponseSize("ListAlerts")(http.HandlerFunc(api.listAlerts))))
+
+	r.Methods("GET").Path("/v1/projects/{project_id}/subscriptions").Handler(
+		InstrumentDuration("ListSubscriptions")(InstrumentResponseSize("ListSubscriptions")(http.HandlerFunc(api.listSubscriptions))))
+
+	r.Methods("POST").Path("/v1/projects/{project_id}/subscriptions").Handler(
+		InstrumentDuration("CreateSubscription")(InstrumentResponseSize("CreateSubscription")(http.HandlerFunc(api.createSubscription))))
+
+	r.Methods("GET").Path("/v1/projects/{project_id}/subscriptions/{subscription_id}").Handler(
+		InstrumentDuration("GetSubscription")(InstrumentResponseSize("GetSubscription")(http.HandlerFunc(api.getSubscription))))
+
+	r.Methods("PUT").Path("/v1/projects/{project_id}/subscriptions/{subscription_id}").Handler(
+		InstrumentDuration("UpdateSubscription")(InstrumentResponseSize("UpdateSubscription")(http.HandlerFunc(api.updateSubscription))))
+
+	r.Methods("DELETE").Path("/v1/projects/{project_id}/subscriptions/{subscription_id}").Handler(
+		InstrumentDuration("DeleteSubscription")(InstrumentResponseSize("DeleteSubscription")(http.HandlerFunc(api.deleteSubscription))))
+
+	r.Methods("GET").Path("/v1/projects/{project_id}/subscriptions/{subscription_id}/dead-letters").Handler(
+		InstrumentDuration("ListDeadLetters")(InstrumentResponseSize("ListDeadLetters")(http.HandlerFunc(api.listDeadLetters))))
 }
 
 // Handler methods for V1API
@@ -165,6 +402,12 @@ func (api *V1API) listEvents(w http.ResponseWriter, r *http.Request) {
 	api.provider.ListEvents(w, r)
 }
 
+// streamEvents handles GET /v1/events/stream
+func (api *V1API) streamEvents(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/events/stream")
+	api.provider.StreamEvents(w, r)
+}
+
 // getEventDetails handles GET /v1/events/{event_id}
 func (api *V1API) getEventDetails(w http.ResponseWriter, r *http.Request) {
 	httpapi.IdentifyEndpoint(r, "/v1/events/:event_id")
@@ -181,6 +424,42 @@ func (api *V1API) getAttributes(w http.ResponseWriter, r *http.Request) {
 	api.provider.GetAttributes(w, r)
 }
 
+// exportEvents handles GET /v1/export
+func (api *V1API) exportEvents(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/export")
+	api.provider.ExportEvents(w, r)
+}
+
+// getExportPublicKey handles GET /v1/export/public-key
+func (api *V1API) getExportPublicKey(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/export/public-key")
+	api.provider.GetExportPublicKey(w, r)
+}
+
+// verifyIntegrity handles GET /v1/integrity/verify
+func (api *V1API) verifyIntegrity(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/integrity/verify")
+	api.provider.VerifyIntegrity(w, r)
+}
+
+// validateEvents handles POST /v1/validate
+func (api *V1API) validateEvents(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/validate")
+	api.provider.ValidateEvents(w, r)
+}
+
+// listDataplaneConfigs handles GET /v1/dataplane-configs
+func (api *V1API) listDataplaneConfigs(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/dataplane-configs")
+	api.provider.ListDataplaneConfigs(w, r)
+}
+
+// getDataplaneConfigChanges handles GET /v1/dataplane-configs/changes
+func (api *V1API) getDataplaneConfigChanges(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/dataplane-configs/changes")
+	api.provider.GetDataplaneConfigChanges(w, r)
+}
+
 // getDataplaneConfig handles GET /v1/projects/{project_id}/dataplane-config
 func (api *V1API) getDataplaneConfig(w http.ResponseWriter, r *http.Request) {
 	httpapi.IdentifyEndpoint(r, "/v1/projects/:project_id/dataplane-config")
@@ -193,8 +472,188 @@ func (api *V1API) putDataplaneConfig(w http.ResponseWriter, r *http.Request) {
 	api.provider.PutDataplaneConfig(w, r)
 }
 
+// patchDataplaneConfig handles PATCH /v1/projects/{project_id}/dataplane-config
+func (api *V1API) patchDataplaneConfig(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/projects/:project_id/dataplane-config")
+	api.provider.PatchDataplaneConfig(w, r)
+}
+
 // deleteDataplaneConfig handles DELETE /v1/projects/{project_id}/dataplane-config
 func (api *V1API) deleteDataplaneConfig(w http.ResponseWriter, r *http.Request) {
 	httpapi.IdentifyEndpoint(r, "/v1/projects/:project_id/dataplane-config")
 	api.provider.DeleteDataplaneConfig(w, r)
 }
+
+// getEffectiveDataplaneConfig handles GET /v1/projects/{project_id}/dataplane-config/effective
+func (api *V1API) getEffectiveDataplaneConfig(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/projects/:project_id/dataplane-config/effective")
+	api.provider.GetEffectiveDataplaneConfig(w, r)
+}
+
+// getDataplaneConfigHistory handles GET /v1/projects/{project_id}/dataplane-config/history
+func (api *V1API) getDataplaneConfigHistory(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/projects/:project_id/dataplane-config/history")
+	api.provider.GetDataplaneConfigHistory(w, r)
+}
+
+// rollbackDataplaneConfig handles POST /v1/projects/{project_id}/dataplane-config/rollback
+func (api *V1API) rollbackDataplaneConfig(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/projects/:project_id/dataplane-config/rollback")
+	api.provider.RollbackDataplaneConfig(w, r)
+}
+
+// previewDataplaneFilter handles POST /v1/projects/{project_id}/dataplane-config/preview
+func (api *V1API) previewDataplaneFilter(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/projects/:project_id/dataplane-config/preview")
+	api.provider.PreviewDataplaneFilter(w, r)
+}
+
+// getDomainDataplaneConfig handles GET /v1/domains/{domain_id}/dataplane-config
+func (api *V1API) getDomainDataplaneConfig(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/domains/:domain_id/dataplane-config")
+	api.provider.GetDomainDataplaneConfig(w, r)
+}
+
+// putDomainDataplaneConfig handles PUT /v1/domains/{domain_id}/dataplane-config
+func (api *V1API) putDomainDataplaneConfig(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/domains/:domain_id/dataplane-config")
+	api.provider.PutDomainDataplaneConfig(w, r)
+}
+
+// deleteDomainDataplaneConfig handles DELETE /v1/domains/{domain_id}/dataplane-config
+func (api *V1API) deleteDomainDataplaneConfig(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/domains/:domain_id/dataplane-config")
+	api.provider.DeleteDomainDataplaneConfig(w, r)
+}
+
+// listDataplaneSinks handles GET /v1/projects/{project_id}/dataplane-config/sinks
+func (api *V1API) listDataplaneSinks(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/projects/:project_id/dataplane-config/sinks")
+	api.provider.ListDataplaneSinks(w, r)
+}
+
+// getDataplaneSink handles GET /v1/projects/{project_id}/dataplane-config/sinks/{sink_name}
+func (api *V1API) getDataplaneSink(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/projects/:project_id/dataplane-config/sinks/:sink_name")
+	api.provider.GetDataplaneSink(w, r)
+}
+
+// putDataplaneSink handles PUT /v1/projects/{project_id}/dataplane-config/sinks/{sink_name}
+func (api *V1API) putDataplaneSink(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/projects/:project_id/dataplane-config/sinks/:sink_name")
+	api.provider.PutDataplaneSink(w, r)
+}
+
+// deleteDataplaneSink handles DELETE /v1/projects/{project_id}/dataplane-config/sinks/{sink_name}
+func (api *V1API) deleteDataplaneSink(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/projects/:project_id/dataplane-config/sinks/:sink_name")
+	api.provider.DeleteDataplaneSink(w, r)
+}
+
+// listSavedSearches handles GET /v1/projects/{project_id}/saved-searches
+func (api *V1API) listSavedSearches(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/projects/:project_id/saved-searches")
+	api.provider.ListSavedSearches(w, r)
+}
+
+// createSavedSearch handles POST /v1/projects/{project_id}/saved-searches
+func (api *V1API) createSavedSearch(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/projects/:project_id/saved-searches")
+	api.provider.CreateSavedSearch(w, r)
+}
+
+// getSavedSearch handles GET /v1/projects/{project_id}/saved-searches/{saved_search_id}
+func (api *V1API) getSavedSearch(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/projects/:project_id/saved-searches/:saved_search_id")
+	api.provider.GetSavedSearch(w, r)
+}
+
+// updateSavedSearch handles PUT /v1/projects/{project_id}/saved-searches/{saved_search_id}
+func (api *V1API) updateSavedSearch(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/projects/:project_id/saved-searches/:saved_search_id")
+	api.provider.UpdateSavedSearch(w, r)
+}
+
+// deleteSavedSearch handles DELETE /v1/projects/{project_id}/saved-searches/{saved_search_id}
+func (api *V1API) deleteSavedSearch(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/projects/:project_id/saved-searches/:saved_search_id")
+	api.provider.DeleteSavedSearch(w, r)
+}
+
+// executeSavedSearch handles GET /v1/projects/{project_id}/saved-searches/{saved_search_id}/events
+func (api *V1API) executeSavedSearch(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/projects/:project_id/saved-searches/:saved_search_id/events")
+	api.provider.ExecuteSavedSearch(w, r)
+}
+
+// listAlertRules handles GET /v1/projects/{project_id}/alert-rules
+func (api *V1API) listAlertRules(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/projects/:project_id/alert-rules")
+	api.provider.ListAlertRules(w, r)
+}
+
+// createAlertRule handles POST /v1/projects/{project_id}/alert-rules
+func (api *V1API) createAlertRule(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/projects/:project_id/alert-rules")
+	api.provider.CreateAlertRule(w, r)
+}
+
+// getAlertRule handles GET /v1/projects/{project_id}/alert-rules/{alert_rule_id}
+func (api *V1API) getAlertRule(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/projects/:project_id/alert-rules/:alert_rule_id")
+	api.provider.GetAlertRule(w, r)
+}
+
+// updateAlertRule handles PUT /v1/projects/{project_id}/alert-rules/{alert_rule_id}
+func (api *V1API) updateAlertRule(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/projects/:project_id/alert-rules/:alert_rule_id")
+	api.provider.UpdateAlertRule(w, r)
+}
+
+// deleteAlertRule handles DELETE /v1/projects/{project_id}/alert-rules/{alert_rule_id}
+func (api *V1API) deleteAlertRule(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/projects/:project_id/alert-rules/:alert_rule_id")
+	api.provider.DeleteAlertRule(w, r)
+}
+
+// listAlerts handles GET /v1/projects/{project_id}/alert-rules/{alert_rule_id}/alerts
+func (api *V1API) listAlerts(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/projects/:project_id/alert-rules/:alert_rule_id/alerts")
+	api.provider.ListAlerts(w, r)
+}
+
+// listSubscriptions handles GET /v1/projects/{project_id}/subscriptions
+func (api *V1API) listSubscriptions(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/projects/:project_id/subscriptions")
+	api.provider.ListSubscriptions(w, r)
+}
+
+// createSubscription handles POST /v1/projects/{project_id}/subscriptions
+func (api *V1API) createSubscription(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/projects/:project_id/subscriptions")
+	api.provider.CreateSubscription(w, r)
+}
+
+// getSubscription handles GET /v1/projects/{project_id}/subscriptions/{subscription_id}
+func (api *V1API) getSubscription(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/projects/:project_id/subscriptions/:subscription_id")
+	api.provider.GetSubscription(w, r)
+}
+
+// updateSubscription handles PUT /v1/projects/{project_id}/subscriptions/{subscription_id}
+func (api *V1API) updateSubscription(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/projects/:project_id/subscriptions/:subscription_id")
+	api.provider.UpdateSubscription(w, r)
+}
+
+// deleteSubscription handles DELETE /v1/projects/{project_id}/subscriptions/{subscription_id}
+func (api *V1API) deleteSubscription(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/projects/:project_id/subscriptions/:subscription_id")
+	api.provider.DeleteSubscription(w, r)
+}
+
+// listDeadLetters handles GET /v1/projects/{project_id}/subscriptions/{subscription_id}/dead-letters
+func (api *V1API) listDeadLetters(w http.ResponseWriter, r *http.Request) {
+	httpapi.IdentifyEndpoint(r, "/v1/projects/:project_id/subscriptions/:subscription_id/dead-letters")
+	api.provider.ListDeadLetters(w, r)
+}
@@ -0,0 +1,219 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sapcc/go-bits/logg"
+	"github.com/sapcc/go-bits/respondwith"
+
+	"github.com/sapcc/hermes/pkg/routing"
+)
+
+const (
+	// defaultChangesLimit and maxChangesLimit bound the number of changes per
+	// response of GET /v1/dataplane-configs/changes.
+	defaultChangesLimit = 100
+	maxChangesLimit     = 1000
+	// defaultChangesWait and maxChangesWait bound how long a long-poll request
+	// waits for the next change.
+	defaultChangesWait = 30 * time.Second
+	maxChangesWait     = 60 * time.Second
+)
+
+// dataplaneConfigChanges is the response of GET /v1/dataplane-configs/changes.
+// NextSince is the position to pass as ?since= in the next request.
+type dataplaneConfigChanges struct {
+	Changes   []routing.Change `json:"changes"`
+	NextSince int64            `json:"next_since"`
+}
+
+// GetDataplaneConfigChanges handles GET /v1/dataplane-configs/changes.
+// Returns the changes of the dataplane configs of all projects after the
+// position ?since=, oldest first. Without ?since=, only the current position
+// is returned. If there is no change yet, the request waits for up to ?wait=
+// seconds (long-poll). With "Accept: text/event-stream", the changes are
+// streamed as Server-Sent Events instead, resuming from Last-Event-ID.
+// Positions that are older than routing.ChangeRetention yield 410.
+func (p *v1Provider) GetDataplaneConfigChanges(res http.ResponseWriter, req *http.Request) {
+	if _, ok := p.AuthHandler(res, req, "dataplane_config:list"); !ok {
+		return
+	}
+
+	query := req.URL.Query()
+	limit, wait := defaultChangesLimit, defaultChangesWait
+	if s := query.Get("limit"); s != "" {
+		var err error
+		limit, err = strconv.Atoi(s)
+		if err != nil || limit < 1 || limit > maxChangesLimit {
+			http.Error(res, fmt.Sprintf("limit must be between 1 and %d", maxChangesLimit), http.StatusBadRequest)
+			return
+		}
+	}
+	if s := query.Get("wait"); s != "" {
+		seconds, err := strconv.Atoi(s)
+		if err != nil || seconds < 0 || time.Duration(seconds)*time.Second > maxChangesWait {
+			http.Error(res, fmt.Sprintf("wait must be between 0 and %d seconds", int(maxChangesWait.Seconds())), http.StatusBadRequest)
+			return
+		}
+		wait = time.Duration(seconds) * time.Second
+	}
+	since, hasSince, err := parseChangePosition(query.Get("since"))
+	if err != nil {
+		http.Error(res, "since "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if lastEventID := req.Header.Get("Last-Event-ID"); lastEventID != "" {
+		since, hasSince, err = parseChangePosition(lastEventID)
+		if err != nil {
+			http.Error(res, "Last-Event-ID "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	ctx := req.Context()
+	if !hasSince {
+		since, err = p.routingStore.LatestChange(ctx)
+		if err != nil {
+			logg.Error("dataplane-config changes: storage error: %s", err)
+			respondwith.ObfuscatedErrorText(res, err)
+			return
+		}
+	}
+
+	changes, err := p.routingStore.Changes(ctx, since, limit)
+	if err != nil {
+		respondWithChangesError(res, err)
+		return
+	}
+
+	if strings.Contains(req.Header.Get("Accept"), "text/event-stream") {
+		p.streamDataplaneConfigChanges(res, req, since, limit, changes)
+		return
+	}
+
+	// long-poll: only the start position is waited for, later pages are returned immediately
+	if len(changes) == 0 && hasSince && wait > 0 {
+		waitCtx, cancel := context.WithTimeout(ctx, wait)
+		err := p.routingStore.WaitForChanges(waitCtx, since)
+		cancel()
+		switch {
+		case err == nil:
+			changes, err = p.routingStore.Changes(ctx, since, limit)
+			if err != nil {
+				respondWithChangesError(res, err)
+				return
+			}
+		case ctx.Err() != nil:
+			return
+		case !errors.Is(err, context.DeadlineExceeded):
+			logg.Error("dataplane-config changes: storage error: %s", err)
+			respondwith.ObfuscatedErrorText(res, err)
+			return
+		}
+	}
+
+	result := dataplaneConfigChanges{Changes: changes, NextSince: since}
+	if len(changes) > 0 {
+		result.NextSince = changes[len(changes)-1].Sequence
+	}
+	if result.Changes == nil {
+		result.Changes = []routing.Change{}
+	}
+	ReturnESJSON(res, http.StatusOK, result)
+}
+
+// streamDataplaneConfigChanges serves GET /v1/dataplane-configs/changes as
+// Server-Sent Events, starting with the given changes after since, until
+// the client disconnects.
+func (p *v1Provider) streamDataplaneConfigChanges(res http.ResponseWriter, req *http.Request, since int64, limit int, changes []routing.Change) {
+	// the server's write timeout is meant for regular requests, not for streams
+	rc := http.NewResponseController(res)
+	_ = rc.SetWriteDeadline(time.Time{}) //nolint:errcheck // not every ResponseWriter supports deadlines
+	res.Header().Set("Content-Type", "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	if _, err := fmt.Fprintf(res, "retry: %d\n\n", p.streamConfig.PollInterval.Milliseconds()); err != nil || rc.Flush() != nil {
+		return
+	}
+
+	ctx := req.Context()
+	for {
+		for _, change := range changes {
+			data, err := json.Marshal(change)
+			if err != nil {
+				return
+			}
+			if _, err := fmt.Fprintf(res, "id: %d\nevent: change\ndata: %s\n\n", change.Sequence, data); err != nil {
+				return
+			}
+			since = change.Sequence
+		}
+		if len(changes) > 0 && rc.Flush() != nil {
+			return
+		}
+
+		// without a full page, wait for the next change, sending heartbeats meanwhile
+		if len(changes) < limit {
+			waitCtx, cancel := context.WithTimeout(ctx, p.streamConfig.HeartbeatInterval)
+			err := p.routingStore.WaitForChanges(waitCtx, since)
+			cancel()
+			switch {
+			case ctx.Err() != nil:
+				return
+			case errors.Is(err, context.DeadlineExceeded):
+				if _, err := fmt.Fprint(res, ": heartbeat\n\n"); err != nil || rc.Flush() != nil {
+					return
+				}
+				changes = nil
+				continue
+			case err != nil:
+				// the client reconnects after the retry interval and resumes via Last-Event-ID
+				logg.Error("dataplane-config changes: storage error: %s", err)
+				return
+			}
+		}
+
+		var err error
+		changes, err = p.routingStore.Changes(ctx, since, limit)
+		if err != nil {
+			if ctx.Err() == nil {
+				logg.Error("dataplane-config changes: cannot stream changes after %d: %s", since, err)
+			}
+			return
+		}
+	}
+}
+
+// respondWithChangesError reports an error of routing.Store.Changes.
+func respondWithChangesError(res http.ResponseWriter, err error) {
+	if errors.Is(err, routing.ErrChangesExpired) {
+		http.Error(res, "changes after this position are no longer retained; reload all configs and start from the current position", http.StatusGone)
+		return
+	}
+	logg.Error("dataplane-config changes: storage error: %s", err)
+	respondwith.ObfuscatedErrorText(res, err)
+}
+
+// parseChangePosition parses the value of ?since= or Last-Event-ID.
+// An empty value means that no position was given.
+func parseChangePosition(value string) (int64, bool, error) {
+	if value == "" {
+		return 0, false, nil
+	}
+	position, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || position < 0 {
+		return 0, false, errors.New("must be a non-negative integer")
+	}
+	return position, true, nil
+}
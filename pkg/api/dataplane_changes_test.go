@@ -0,0 +1,155 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapcc/hermes/pkg/routing"
+)
+
+const dataplaneChangesPath = "/v1/dataplane-configs/changes"
+
+func getDataplaneChanges(t *testing.T, handler http.Handler, query string) dataplaneConfigChanges {
+	t.Helper()
+	rec := dataplaneRequest(t, handler, http.MethodGet, dataplaneChangesPath+query, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var changes dataplaneConfigChanges
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &changes))
+	return changes
+}
+
+func TestDataplaneConfig_Changes(t *testing.T) {
+	handler, routingStore, _ := setupDataplaneTest(t)
+
+	// without ?since=, only the current position is returned
+	changes := getDataplaneChanges(t, handler, "")
+	assert.Empty(t, changes.Changes)
+	assert.Equal(t, int64(0), changes.NextSince)
+
+	rec := putJSON(t, handler, map[string]any{"enabled": true, "target_bucket": "bucket-1"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	rec = dataplaneRequest(t, handler, http.MethodPut, dataplaneSinksPath+"/dr", map[string]any{"target_bucket": "dr-bucket"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	rec = dataplaneRequest(t, handler, http.MethodDelete, dataplaneConfigPath, nil)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	changes = getDataplaneChanges(t, handler, "?since=0")
+	require.Len(t, changes.Changes, 3)
+	var summary []string
+	for _, c := range changes.Changes {
+		assert.Equal(t, testProjectID, c.ProjectID)
+		summary = append(summary, string(c.Operation)+"@"+dataplaneETag(c.Version))
+	}
+	assert.Equal(t, []string{`upsert@"1"`, `upsert@"2"`, `delete@"2"`}, summary)
+	assert.Equal(t, int64(3), changes.NextSince)
+
+	changes = getDataplaneChanges(t, handler, "?since=1&limit=1")
+	require.Len(t, changes.Changes, 1)
+	assert.Equal(t, int64(2), changes.Changes[0].Sequence)
+	assert.Equal(t, int64(2), changes.NextSince)
+
+	// long-poll
+	changes = getDataplaneChanges(t, handler, "?since=3&wait=0")
+	assert.Empty(t, changes.Changes)
+	assert.Equal(t, int64(3), changes.NextSince)
+	result := make(chan dataplaneConfigChanges)
+	go func() {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, dataplaneChangesPath+"?since=3&wait=5", http.NoBody)
+		req.Header.Set("X-Auth-Token", "something")
+		handler.ServeHTTP(rec, req)
+		var changes dataplaneConfigChanges
+		_ = json.Unmarshal(rec.Body.Bytes(), &changes) //nolint:errcheck // checked via the result below
+		result <- changes
+	}()
+	rec = putJSON(t, handler, map[string]any{"enabled": false})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	select {
+	case changes = <-result:
+		require.Len(t, changes.Changes, 1)
+		assert.Equal(t, routing.ChangeUpsert, changes.Changes[0].Operation)
+		assert.Equal(t, int64(4), changes.NextSince)
+	case <-time.After(5 * time.Second):
+		t.Fatal("long-poll did not return after a change")
+	}
+
+	// positions before the retained changes are gone
+	routingStore.ExpireChanges(2)
+	rec = dataplaneRequest(t, handler, http.MethodGet, dataplaneChangesPath+"?since=1", nil)
+	assert.Equal(t, http.StatusGone, rec.Code)
+	assert.Len(t, getDataplaneChanges(t, handler, "?since=2").Changes, 2)
+
+	for _, query := range []string{"?since=-1", "?since=abc", "?limit=0", "?limit=1001", "?wait=61", "?wait=-1"} {
+		rec := dataplaneRequest(t, handler, http.MethodGet, dataplaneChangesPath+query, nil)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
+
+func TestDataplaneConfig_ChangesStream(t *testing.T) {
+	handler, _, _ := setupDataplaneTest(t)
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	for _, bucket := range []string{"bucket-1", "bucket-2"} {
+		rec := putJSON(t, handler, map[string]any{"enabled": true, "target_bucket": bucket})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	open := func(query, lastEventID string) <-chan sseMessage {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+dataplaneChangesPath+query, http.NoBody)
+		require.NoError(t, err)
+		req.Header.Set("X-Auth-Token", "something")
+		req.Header.Set("Accept", "text/event-stream")
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+		return readSSE(resp.Body)
+	}
+	nextChange := func(messages <-chan sseMessage) (string, routing.Change) {
+		t.Helper()
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case msg, ok := <-messages:
+				require.True(t, ok, "stream closed unexpectedly")
+				if msg.Event == "" {
+					continue
+				}
+				assert.Equal(t, "change", msg.Event)
+				var change routing.Change
+				require.NoError(t, json.Unmarshal([]byte(msg.Data), &change))
+				return msg.ID, change
+			case <-timeout:
+				t.Fatal("timed out waiting for a change")
+			}
+		}
+	}
+
+	// the stream starts after ?since= and continues with new changes
+	messages := open("?since=1", "")
+	id, change := nextChange(messages)
+	assert.Equal(t, "2", id)
+	assert.Equal(t, int64(2), change.Version)
+	rec := dataplaneRequest(t, handler, http.MethodDelete, dataplaneConfigPath, nil)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	id, change = nextChange(messages)
+	assert.Equal(t, "3", id)
+	assert.Equal(t, routing.ChangeDelete, change.Operation)
+
+	// Last-Event-ID takes precedence over ?since=
+	id, _ = nextChange(open("?since=0", "2"))
+	assert.Equal(t, "3", id)
+}
@@ -6,8 +6,11 @@ package api
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,6 +22,8 @@ import (
 	"github.com/sapcc/go-bits/respondwith"
 
 	"github.com/sapcc/hermes/pkg/routing"
+	"github.com/sapcc/hermes/pkg/routing/bucket"
+	"github.com/sapcc/hermes/pkg/routing/filter"
 )
 
 // s3BucketNamePattern enforces RFC-1123-subset S3 bucket name rules:
@@ -27,40 +32,55 @@ import (
 // separately because they are prohibited by both AWS S3 and Ceph RGW.
 var s3BucketNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9\-]{1,61}[a-z0-9]$`)
 
+// validateTargetBucket checks a non-empty target_bucket against s3BucketNamePattern.
+func validateTargetBucket(bucket string) error {
+	if !s3BucketNamePattern.MatchString(bucket) {
+		return errors.New("target_bucket must be 3–63 chars, lowercase letters/digits/hyphens only, start and end with letter or digit")
+	}
+	if strings.Contains(bucket, "--") {
+		return errors.New("target_bucket must not contain consecutive hyphens")
+	}
+	return nil
+}
+
 // dataplaneConfigRequest is the shape accepted on PUT.
 // We use strict decoding (DisallowUnknownFields) so unknown fields → 400.
 type dataplaneConfigRequest struct {
-	Enabled      bool   `json:"enabled"`
-	TargetBucket string `json:"target_bucket"`
+	Enabled       bool   `json:"enabled"`
+	TargetBucket  string `json:"target_bucket"`
+	Filter        string `json:"filter"`
+	RetentionDays int    `json:"retention_days"`
+	GraceMinutes  int    `json:"grace_minutes"`
+	RateLimit     int    `json:"rate_limit"`
 }
 
 // GetDataplaneConfig handles GET /v1/projects/{project_id}/dataplane-config.
 // Returns 200 with the default (disabled) payload when no config exists.
+// Stored configs carry an ETag; a matching If-None-Match yields 304.
 func (p *v1Provider) GetDataplaneConfig(res http.ResponseWriter, req *http.Request) {
 	projectID := mux.Vars(req)["project_id"]
 	if _, ok := p.authDataplaneConfig(res, req, projectID); !ok {
 		return
 	}
 
-	cfg, err := p.routingStore.Get(req.Context(), projectID)
-	if errors.Is(err, routing.ErrNotFound) {
-		cfg = nil // treat as default
-	} else if err != nil {
-		logg.Error("dataplane-config GET: storage error for project %s: %s", projectID, err)
-		respondwith.ObfuscatedErrorText(res, err)
+	cfg, ok := p.getDataplaneConfigOrDefault(res, req, projectID)
+	if !ok {
 		return
 	}
-
-	if cfg == nil {
-		def := routing.DefaultDataplaneConfig(projectID)
-		ReturnESJSON(res, http.StatusOK, def)
-		return
+	if cfg.Version > 0 {
+		etag := dataplaneETag(cfg.Version)
+		res.Header().Set("ETag", etag)
+		if slices.Contains(parseETags(req.Header.Get("If-None-Match")), etag) {
+			res.WriteHeader(http.StatusNotModified)
+			return
+		}
 	}
 	ReturnESJSON(res, http.StatusOK, cfg)
 }
 
 // PutDataplaneConfig handles PUT /v1/projects/{project_id}/dataplane-config.
 // Idempotent create-or-replace. Returns 200 with the saved document.
+// If-Match and If-None-Match: * make the write conditional (412 on mismatch).
 // An audit event is emitted for every attempt — successful or not.
 func (p *v1Provider) PutDataplaneConfig(res http.ResponseWriter, req *http.Request) {
 	projectID := mux.Vars(req)["project_id"]
@@ -93,6 +113,13 @@ func (p *v1Provider) PutDataplaneConfig(res http.ResponseWriter, req *http.Reque
 		})
 	}
 
+	pre, status, err := parseDataplanePrecondition(req)
+	if err != nil {
+		http.Error(res, err.Error(), status)
+		recordAttempt(status, nil)
+		return
+	}
+
 	// Content-Type enforcement
 	if ct := req.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
 		http.Error(res, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
@@ -112,51 +139,76 @@ func (p *v1Provider) PutDataplaneConfig(res http.ResponseWriter, req *http.Reque
 		return
 	}
 
-	// Validate target_bucket whenever it is non-empty — regardless of enabled flag.
-	// This prevents storing an invalid bucket name that would silently break routing
-	// if the config is later re-enabled without updating the bucket.
-	if body.TargetBucket != "" {
-		if !s3BucketNamePattern.MatchString(body.TargetBucket) {
-			http.Error(res, "target_bucket must be 3–63 chars, lowercase letters/digits/hyphens only, start and end with letter or digit", http.StatusBadRequest)
-			recordAttempt(http.StatusBadRequest, nil)
+	put := routing.DataplaneConfig{
+		ProjectID:     projectID,
+		Enabled:       body.Enabled,
+		TargetBucket:  body.TargetBucket,
+		Filter:        body.Filter,
+		RetentionDays: body.RetentionDays,
+		GraceMinutes:  body.GraceMinutes,
+		RateLimit:     body.RateLimit,
+		UpdatedAt:     now,
+		UpdatedBy:     userID,
+	}
+
+	// The bucket is verified before the transaction, so that the project is
+	// not locked while the object storage is queried.
+	current := routing.DefaultDataplaneConfig(projectID)
+	if put.Enabled && put.TargetBucket != "" {
+		current, ok = p.getDataplaneConfigOrDefault(res, req, projectID)
+		if !ok {
+			recordAttempt(http.StatusInternalServerError, nil)
 			return
 		}
-		if strings.Contains(body.TargetBucket, "--") {
-			http.Error(res, "target_bucket must not contain consecutive hyphens", http.StatusBadRequest)
-			recordAttempt(http.StatusBadRequest, nil)
+		if status, err := p.verifyTargetBucket(req, current, routing.DefaultSinkName, put.Enabled, put.TargetBucket); err != nil {
+			http.Error(res, err.Error(), status)
+			recordAttempt(status, nil)
 			return
 		}
 	}
 
-	// When enabled, a non-empty bucket is required.
-	if body.Enabled && body.TargetBucket == "" {
-		http.Error(res, "target_bucket is required when enabled is true", http.StatusBadRequest)
-		recordAttempt(http.StatusBadRequest, nil)
+	// The config replaces the stored one within the store transaction, so
+	// that the kept sinks and the duplicate bucket check see concurrent
+	// changes to the sinks.
+	var (
+		rejectStatus int
+		rejectErr    error
+	)
+	cfg, err := p.routingStore.Update(req.Context(), projectID, pre, func(cfg *routing.DataplaneConfig) error {
+		if put.Enabled && put.TargetBucket != "" && sinkChanged(current, *cfg, routing.DefaultSinkName) {
+			// the verification above relied on the sink that was replaced since
+			return routing.ErrPreconditionFailed
+		}
+		put.Sinks, put.Version = cfg.Sinks, cfg.Version
+		*cfg = put
+		rejectStatus, rejectErr = validateDataplaneConfig(*cfg)
+		return rejectErr
+	})
+	switch {
+	case rejectErr != nil:
+		http.Error(res, rejectErr.Error(), rejectStatus)
+		recordAttempt(rejectStatus, nil)
 		return
-	}
-
-	cfg := routing.DataplaneConfig{
-		ProjectID:    projectID,
-		Enabled:      body.Enabled,
-		TargetBucket: body.TargetBucket,
-		UpdatedAt:    now,
-		UpdatedBy:    userID,
-	}
-
-	if err := p.routingStore.Upsert(req.Context(), cfg); err != nil {
+	case errors.Is(err, routing.ErrPreconditionFailed):
+		http.Error(res, "dataplane-config was modified concurrently", http.StatusPreconditionFailed)
+		recordAttempt(http.StatusPreconditionFailed, &put)
+		return
+	case err != nil:
 		logg.Error("dataplane-config PUT: storage error for project %s: %s", projectID, err)
 		respondwith.ObfuscatedErrorText(res, err)
-		recordAttempt(http.StatusInternalServerError, &cfg)
+		recordAttempt(http.StatusInternalServerError, &put)
 		return
 	}
 
 	logg.Info("dataplane-config PUT: project=%s enabled=%v updated_by=%s", projectID, cfg.Enabled, userID)
-	recordAttempt(http.StatusOK, &cfg)
+	recordAttempt(http.StatusOK, cfg)
+	res.Header().Set("ETag", dataplaneETag(cfg.Version))
 	ReturnESJSON(res, http.StatusOK, cfg)
 }
 
 // DeleteDataplaneConfig handles DELETE /v1/projects/{project_id}/dataplane-config.
 // Idempotent — deleting a non-existent config returns 204.
+// If-Match and If-None-Match: * make the delete conditional (412 on mismatch).
 // An audit event is emitted only when a config actually existed and was removed;
 // no-op deletes are silent (no spurious events for resources that never existed).
 func (p *v1Provider) DeleteDataplaneConfig(res http.ResponseWriter, req *http.Request) {
@@ -173,19 +225,35 @@ func (p *v1Provider) DeleteDataplaneConfig(res http.ResponseWriter, req *http.Re
 		return
 	}
 
-	deleted, err := p.routingStore.Delete(req.Context(), projectID)
-	if err != nil {
-		logg.Error("dataplane-config DELETE: storage error for project %s: %s", projectID, err)
-		respondwith.ObfuscatedErrorText(res, err)
-		// Emit failure event — the attempt was made even though storage failed.
+	recordAttempt := func(reasonCode int) {
 		p.auditor.Record(audittools.Event{
 			Time:       now,
 			Request:    req,
 			User:       token,
-			ReasonCode: http.StatusInternalServerError,
+			ReasonCode: reasonCode,
 			Action:     cadf.DeleteAction,
 			Target:     routing.DataplaneConfig{ProjectID: projectID, UpdatedBy: userID},
 		})
+	}
+
+	pre, status, err := parseDataplanePrecondition(req)
+	if err != nil {
+		http.Error(res, err.Error(), status)
+		recordAttempt(status)
+		return
+	}
+
+	deleted, err := p.routingStore.DeleteIf(req.Context(), projectID, userID, pre)
+	if errors.Is(err, routing.ErrPreconditionFailed) {
+		http.Error(res, "dataplane-config was modified concurrently", http.StatusPreconditionFailed)
+		recordAttempt(http.StatusPreconditionFailed)
+		return
+	}
+	if err != nil {
+		logg.Error("dataplane-config DELETE: storage error for project %s: %s", projectID, err)
+		respondwith.ObfuscatedErrorText(res, err)
+		// Emit failure event — the attempt was made even though storage failed.
+		recordAttempt(http.StatusInternalServerError)
 		return
 	}
 
@@ -193,20 +261,161 @@ func (p *v1Provider) DeleteDataplaneConfig(res http.ResponseWriter, req *http.Re
 	// A DELETE on a non-existent config is a safe no-op — recording it would
 	// pollute the audit trail with spurious events for resources that never existed.
 	if deleted {
-		p.auditor.Record(audittools.Event{
-			Time:       now,
-			Request:    req,
-			User:       token,
-			ReasonCode: http.StatusNoContent,
-			Action:     cadf.DeleteAction,
-			Target:     routing.DataplaneConfig{ProjectID: projectID, UpdatedBy: userID},
-		})
+		recordAttempt(http.StatusNoContent)
 	}
 
 	logg.Info("dataplane-config DELETE: project=%s updated_by=%s deleted=%v", projectID, userID, deleted)
 	res.WriteHeader(http.StatusNoContent)
 }
 
+// verifyTargetBucket checks a bucket that the sink of the given name starts
+// routing into with the configured bucket.Verifier, using the token of the
+// caller. Buckets that the stored sink already routes into are not checked
+// again, so that e.g. changes of the filter do not depend on the object
+// storage. On rejection, it returns the HTTP status to respond with.
+func (p *v1Provider) verifyTargetBucket(req *http.Request, stored routing.DataplaneConfig, sinkName string, enabled bool, bucketName string) (int, error) {
+	if p.bucketVerifier == nil || p.bucketMode == bucket.ModeOff || !enabled || bucketName == "" {
+		return 0, nil
+	}
+	if sink := stored.Sink(sinkName); sink != nil && sink.Enabled && sink.Bucket() == bucketName {
+		return 0, nil
+	}
+	return p.verifyBucket(req, stored.ProjectID, "project "+stored.ProjectID, bucketName)
+}
+
+// verifyBucket checks a bucket in the account of the given project with the
+// configured bucket.Verifier. owner names the project in error messages.
+func (p *v1Provider) verifyBucket(req *http.Request, projectID, owner, bucketName string) (int, error) {
+	err := p.bucketVerifier.Verify(req.Context(), bucket.Request{
+		ProjectID: projectID,
+		Bucket:    bucketName,
+		Token:     req.Header.Get("X-Auth-Token"),
+	})
+	status, reason := http.StatusUnprocessableEntity, ""
+	switch {
+	case err == nil:
+		return 0, nil
+	case errors.Is(err, bucket.ErrNotFound):
+		reason = fmt.Sprintf("bucket %q does not exist in %s", bucketName, owner)
+	case errors.Is(err, bucket.ErrNotOwned):
+		reason = fmt.Sprintf("bucket %q is not owned by %s", bucketName, owner)
+	case errors.Is(err, bucket.ErrNotWritable):
+		reason = fmt.Sprintf("bucket %q does not accept writes from %s", bucketName, owner)
+	default:
+		logg.Error("dataplane-config: cannot verify bucket %s of %s: %s", bucketName, owner, err)
+		status, reason = http.StatusServiceUnavailable, fmt.Sprintf("cannot verify bucket %q, please try again later", bucketName)
+	}
+	if p.bucketMode == bucket.ModeWarn {
+		logg.Info("dataplane-config: accepting unverified bucket (verification mode %q): %s", p.bucketMode, reason)
+		return 0, nil
+	}
+	return status, errors.New(reason)
+}
+
+// sinkChanged reports whether the sink of the given name routes differently
+// in two versions of a config, e.g. because it was replaced after
+// verifyTargetBucket looked at the earlier version.
+func sinkChanged(before, after routing.DataplaneConfig, sinkName string) bool {
+	a, b := before.Sink(sinkName), after.Sink(sinkName)
+	if a == nil || b == nil {
+		return a != b
+	}
+	return a.Enabled != b.Enabled || a.Destination() != b.Destination()
+}
+
+// validateDataplaneConfig checks the fields of a config before it is written.
+// cfg.Sinks must hold the stored sinks of the project. On error, it returns
+// the HTTP status to respond with.
+func validateDataplaneConfig(cfg routing.DataplaneConfig) (int, error) {
+	// Validate target_bucket whenever it is non-empty — regardless of enabled flag.
+	// This prevents storing an invalid bucket name that would silently break routing
+	// if the config is later re-enabled without updating the bucket.
+	if cfg.TargetBucket != "" {
+		if err := validateTargetBucket(cfg.TargetBucket); err != nil {
+			return http.StatusBadRequest, err
+		}
+	}
+
+	// When enabled, a non-empty bucket is required.
+	if cfg.Enabled && cfg.TargetBucket == "" {
+		return http.StatusBadRequest, errors.New("target_bucket is required when enabled is true")
+	}
+
+	// Compile-check the filter so that log-router never sees an invalid expression.
+	if cfg.Filter != "" {
+		if _, err := filter.Compile(cfg.Filter); err != nil {
+			return http.StatusBadRequest, err
+		}
+	}
+
+	if cfg.RetentionDays < 0 || cfg.RetentionDays > routing.MaxRetentionDays {
+		return http.StatusBadRequest, fmt.Errorf("retention_days must be between 0 (keep forever) and %d", routing.MaxRetentionDays)
+	}
+	if cfg.GraceMinutes < 0 || cfg.GraceMinutes > routing.MaxGraceMinutes {
+		return http.StatusBadRequest, fmt.Errorf("grace_minutes must be between 0 and %d", routing.MaxGraceMinutes)
+	}
+	if cfg.RateLimit < 0 || cfg.RateLimit > routing.MaxRateLimit {
+		return http.StatusBadRequest, fmt.Errorf("rate_limit must be between 0 (unlimited) and %d events per second", routing.MaxRateLimit)
+	}
+
+	// Routing into a bucket that another sink already uses would store every event twice.
+	if cfg.TargetBucket != "" {
+		if other := sinkUsingDestination(cfg, cfg.TargetBucket, routing.DefaultSinkName); other != "" {
+			return http.StatusConflict, fmt.Errorf("target_bucket is already used by sink %q", other)
+		}
+	}
+	return http.StatusOK, nil
+}
+
+// dataplaneETag returns the ETag of the config with the given version.
+func dataplaneETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// parseETags splits the value of an If-Match or If-None-Match header.
+func parseETags(header string) []string {
+	var result []string
+	for tag := range strings.SplitSeq(header, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// parseDataplanePrecondition translates the If-Match and If-None-Match headers
+// of a write into a routing.Precondition. If-None-Match is only supported with
+// "*", since the ETags of a project are never reused. On error, it returns the
+// HTTP status to respond with.
+func parseDataplanePrecondition(req *http.Request) (routing.Precondition, int, error) {
+	var pre routing.Precondition
+	if req.Header.Get("If-None-Match") != "" {
+		if req.Header.Get("If-None-Match") != "*" {
+			return pre, http.StatusBadRequest, errors.New(`If-None-Match only supports "*"`)
+		}
+		pre.IfNotExists = true
+	}
+	tags := parseETags(req.Header.Get("If-Match"))
+	if len(tags) == 0 {
+		return pre, http.StatusOK, nil
+	}
+	if slices.Contains(tags, "*") {
+		pre.IfExists = true
+		return pre, http.StatusOK, nil
+	}
+	for _, tag := range tags {
+		// weak and foreign ETags never match
+		version, err := strconv.ParseInt(strings.Trim(tag, `"`), 10, 64)
+		if err == nil && tag == dataplaneETag(version) {
+			pre.IfVersion = append(pre.IfVersion, version)
+		}
+	}
+	if len(pre.IfVersion) == 0 {
+		return pre, http.StatusPreconditionFailed, errors.New("If-Match does not match any version of dataplane-config")
+	}
+	return pre, http.StatusOK, nil
+}
+
 // authDataplaneConfig validates the Keystone token against the
 // "dataplane_config:manage" policy rule and enforces that the path
 // project_id matches the token's project scope.
@@ -214,12 +423,22 @@ func (p *v1Provider) DeleteDataplaneConfig(res http.ResponseWriter, req *http.Re
 // Returns the token and true on success; writes the error response and
 // returns false on failure.
 func (p *v1Provider) authDataplaneConfig(res http.ResponseWriter, req *http.Request, pathProjectID string) (*gopherpolicy.Token, bool) {
+	return p.authProjectScoped(res, req, pathProjectID, "dataplane_config:manage")
+}
+
+// authProjectScoped validates the Keystone token against the given policy rule
+// and enforces that the path project_id matches the token's project scope.
+// Unlike AuthHandler, it does not allow overriding the scope with query parameters.
+//
+// Returns the token and true on success; writes the error response and
+// returns false on failure.
+func (p *v1Provider) authProjectScoped(res http.ResponseWriter, req *http.Request, pathProjectID, rule string) (*gopherpolicy.Token, bool) {
 	token := p.validator.CheckToken(req)
 	token.Context.Request = mux.Vars(req)
 	token.Context.Request["domain_id"] = token.Context.Auth["domain_id"]
 	token.Context.Request["project_id"] = token.Context.Auth["project_id"]
 
-	if !token.Require(res, "dataplane_config:manage") {
+	if !token.Require(res, rule) {
 		return nil, false
 	}
 
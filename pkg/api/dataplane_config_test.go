@@ -5,11 +5,13 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -24,6 +26,7 @@ import (
 	"github.com/sapcc/go-bits/must"
 
 	"github.com/sapcc/hermes/pkg/routing"
+	"github.com/sapcc/hermes/pkg/routing/bucket"
 	"github.com/sapcc/hermes/pkg/storage"
 	"github.com/sapcc/hermes/pkg/test"
 )
@@ -91,7 +94,17 @@ func payloadAttachment(enabled bool, targetBucket string) []cadf.Attachment {
 // setupDataplaneTest creates a handler where the mock token is scoped to testProjectID.
 // This is needed because authDataplaneConfig enforces path-vs-token project match.
 // Returns the handler, the routing mock store, and the mock auditor for event assertions.
-func setupDataplaneTest(t *testing.T) (http.Handler, *routing.Mock, *audittools.MockAuditor) {
+// opts configure optional subsystems like for NewV1API.
+func setupDataplaneTest(t *testing.T, opts ...Option) (http.Handler, *routing.Mock, *audittools.MockAuditor) {
+	t.Helper()
+	return setupDataplaneTestWithAuth(t, map[string]string{
+		"project_id": testProjectID,
+		"user_id":    "user-abc",
+	}, opts...)
+}
+
+// setupDataplaneTestWithAuth is like setupDataplaneTest, but the token has the given auth attributes.
+func setupDataplaneTestWithAuth(t *testing.T, auth map[string]string, opts ...Option) (http.Handler, *routing.Mock, *audittools.MockAuditor) {
 	t.Helper()
 
 	policyBytes, err := os.ReadFile("../test/policy.json")
@@ -108,16 +121,13 @@ func setupDataplaneTest(t *testing.T) (http.Handler, *routing.Mock, *audittools.
 	}
 	viper.Set("hermes.PolicyEnforcer", policyEnforcer)
 
-	validator := mock.NewValidator(mock.NewEnforcer(), map[string]string{
-		"project_id": testProjectID,
-		"user_id":    "user-abc",
-	})
+	validator := mock.NewValidator(mock.NewEnforcer(), auth)
 	routingStore := routing.NewMock()
 	mockAuditor := audittools.NewMockAuditor()
 
 	prometheus.DefaultRegisterer = prometheus.NewPedanticRegistry()
 
-	v1API := NewV1API(validator, storage.Mock{}, routingStore, mockAuditor)
+	v1API := NewV1API(validator, storage.Mock{}, routingStore, mockAuditor, opts...)
 	return httpapi.Compose(v1API, NewVersionAPI(v1API.VersionData()), NewMetricsAPI()), routingStore, mockAuditor
 }
 
@@ -378,3 +388,172 @@ func TestDataplaneConfig_DisabledPutAcceptsEmptyBucket(t *testing.T) {
 		dataplaneTarget(payloadAttachment(false, "")),
 	))
 }
+
+// TestDataplaneConfig_BucketVerification proves that buckets are verified when
+// routing into them starts, and that failures are rejected with 422.
+func TestDataplaneConfig_BucketVerification(t *testing.T) {
+	store := bucket.NewFakeObjectStore()
+	store.AddToken("something", testProjectID)
+	store.AddBucket(testProjectID, "my-bucket", false)
+	store.AddBucket("other-project", "foreign-bucket", false)
+	store.AddBucket(testProjectID, "read-only-bucket", true)
+	server := httptest.NewServer(store)
+	t.Cleanup(server.Close)
+	verifier, err := bucket.NewHTTPVerifier(server.URL + "/AUTH_%(project_id)s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler, _, auditor := setupDataplaneTest(t, WithBucketVerifier(verifier, bucket.ModeEnforce))
+
+	for _, tc := range []struct {
+		bucket string
+		status int
+		reason string
+	}{
+		{"missing-bucket", http.StatusUnprocessableEntity, "does not exist"},
+		{"foreign-bucket", http.StatusUnprocessableEntity, "does not exist in project " + testProjectID},
+		{"read-only-bucket", http.StatusUnprocessableEntity, "does not accept writes"},
+		{"my-bucket", http.StatusOK, ""},
+	} {
+		rec := putJSON(t, handler, map[string]any{"enabled": true, "target_bucket": tc.bucket})
+		if rec.Code != tc.status {
+			t.Fatalf("PUT with bucket %s: expected %d, got %d: %s", tc.bucket, tc.status, rec.Code, rec.Body.String())
+		}
+		if !strings.Contains(rec.Body.String(), tc.reason) {
+			t.Errorf("PUT with bucket %s: expected %q in response, got %q", tc.bucket, tc.reason, rec.Body.String())
+		}
+	}
+	if objects := store.Objects(testProjectID, "my-bucket"); len(objects) != 0 {
+		t.Errorf("write probe was not deleted: %v", objects)
+	}
+	events := auditor.RecordedEvents()
+	if len(events) != 4 || events[0].Reason.ReasonCode != "422" {
+		t.Errorf("expected 4 audit events starting with a 422, got %#v", events)
+	}
+
+	// disabled buckets and sinks are not verified
+	rec := dataplaneRequest(t, handler, http.MethodPut, dataplaneSinksPath+"/dr", map[string]any{"enabled": false, "target_bucket": "missing-bucket"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PUT of disabled sink: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec = dataplaneRequest(t, handler, http.MethodPut, dataplaneSinksPath+"/dr", map[string]any{"enabled": true, "target_bucket": "missing-bucket"})
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("PUT of enabled sink: expected 422, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// buckets that are routed into already are not verified again
+	server.Close()
+	rec = patchJSON(t, handler, `{"filter": "event.action != \"read\""}`, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PATCH of filter: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec = putJSON(t, handler, map[string]any{"enabled": true, "target_bucket": "my-bucket"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PUT of unchanged bucket: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	// new buckets cannot be accepted while the object storage is unavailable
+	rec = patchJSON(t, handler, `{"target_bucket": "other-bucket"}`, nil)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("PATCH of bucket: expected 503, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// in warn mode, failures are only logged
+	handler, _, _ = setupDataplaneTest(t, WithBucketVerifier(verifier, bucket.ModeWarn))
+	rec = putJSON(t, handler, map[string]any{"enabled": true, "target_bucket": "missing-bucket"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PUT in warn mode: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+// verifierFunc adapts a function to the bucket.Verifier interface.
+type verifierFunc func(ctx context.Context, req bucket.Request) error
+
+func (f verifierFunc) Verify(ctx context.Context, req bucket.Request) error {
+	return f(ctx, req)
+}
+
+// TestDataplaneConfig_PutConcurrentSinks proves that PUT merges the sinks
+// within the store transaction, so that it sees sinks written while the
+// bucket was verified.
+func TestDataplaneConfig_PutConcurrentSinks(t *testing.T) {
+	var store *routing.Mock
+	verifier := verifierFunc(func(ctx context.Context, req bucket.Request) error {
+		return store.UpsertSink(ctx, routing.Sink{ProjectID: req.ProjectID, Name: "dr", Enabled: true, Type: routing.SinkS3, TargetBucket: "my-bucket"})
+	})
+	handler, store, _ := setupDataplaneTest(t, WithBucketVerifier(verifier, bucket.ModeEnforce))
+
+	rec := putJSON(t, handler, map[string]any{"enabled": true, "target_bucket": "my-bucket"})
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("PUT: expected 409, got %d: %s", rec.Code, rec.Body.String())
+	}
+	cfg, err := store.Get(t.Context(), testProjectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Sinks) != 1 || cfg.Sinks[0].Name != "dr" {
+		t.Errorf("expected only the concurrent sink, got %#v", cfg.Sinks)
+	}
+
+	// a default sink written while the bucket was verified fails the request
+	verifier = func(ctx context.Context, req bucket.Request) error {
+		return store.UpsertSink(ctx, routing.Sink{ProjectID: req.ProjectID, Name: routing.DefaultSinkName, Enabled: true, Type: routing.SinkS3, TargetBucket: "other-bucket"})
+	}
+	handler, store, _ = setupDataplaneTest(t, WithBucketVerifier(verifier, bucket.ModeEnforce))
+	rec = putJSON(t, handler, map[string]any{"enabled": true, "target_bucket": "new-bucket"})
+	if rec.Code != http.StatusPreconditionFailed {
+		t.Fatalf("PUT: expected 412, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+// TestDataplaneConfig_RetentionAndRateLimit proves that retention_days,
+// grace_minutes and rate_limit round-trip through PUT, PATCH and GET, are
+// bounded and audited.
+func TestDataplaneConfig_RetentionAndRateLimit(t *testing.T) {
+	handler, _, auditor := setupDataplaneTest(t)
+
+	rec := putJSON(t, handler, map[string]any{"enabled": true, "target_bucket": "my-bucket", "retention_days": 30, "grace_minutes": 60, "rate_limit": 500})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PUT: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	auditor.ExpectEvents(t, updateEvent(http.StatusOK, dataplaneTarget([]cadf.Attachment{
+		must.Return(cadf.NewJSONAttachment("payload", map[string]any{
+			"enabled":        true,
+			"target_bucket":  "my-bucket",
+			"retention_days": 30,
+			"grace_minutes":  60,
+			"rate_limit":     500,
+		})),
+	})))
+	cfg := getDataplaneConfig(t, handler)
+	if cfg.RetentionDays != 30 || cfg.GraceMinutes != 60 || cfg.RateLimit != 500 {
+		t.Errorf("expected retention_days=30, grace_minutes=60 and rate_limit=500, got %d, %d and %d", cfg.RetentionDays, cfg.GraceMinutes, cfg.RateLimit)
+	}
+
+	// null resets to keep forever and unlimited
+	rec = patchJSON(t, handler, `{"retention_days": null, "grace_minutes": null, "rate_limit": 10}`, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PATCH: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	cfg = getDataplaneConfig(t, handler)
+	if cfg.RetentionDays != 0 || cfg.GraceMinutes != 0 || cfg.RateLimit != 10 {
+		t.Errorf("expected retention_days=0, grace_minutes=0 and rate_limit=10, got %d, %d and %d", cfg.RetentionDays, cfg.GraceMinutes, cfg.RateLimit)
+	}
+
+	for _, body := range []map[string]any{
+		{"enabled": true, "target_bucket": "my-bucket", "retention_days": -1},
+		{"enabled": true, "target_bucket": "my-bucket", "retention_days": routing.MaxRetentionDays + 1},
+		{"enabled": true, "target_bucket": "my-bucket", "grace_minutes": -1},
+		{"enabled": true, "target_bucket": "my-bucket", "grace_minutes": routing.MaxGraceMinutes + 1},
+		{"enabled": true, "target_bucket": "my-bucket", "rate_limit": -1},
+		{"enabled": true, "target_bucket": "my-bucket", "rate_limit": routing.MaxRateLimit + 1},
+	} {
+		rec = putJSON(t, handler, body)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("PUT %v: expected 400, got %d", body, rec.Code)
+		}
+	}
+	rec = patchJSON(t, handler, `{"rate_limit": 1.5}`, nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("PATCH of fractional rate_limit: expected 400, got %d", rec.Code)
+	}
+}
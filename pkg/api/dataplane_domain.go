@@ -0,0 +1,243 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/sapcc/go-bits/audittools"
+	"github.com/sapcc/go-bits/gopherpolicy"
+	"github.com/sapcc/go-bits/logg"
+	"github.com/sapcc/go-bits/respondwith"
+
+	"github.com/sapcc/hermes/pkg/routing"
+	"github.com/sapcc/hermes/pkg/routing/bucket"
+)
+
+// domainConfigRequest is the shape accepted on PUT for domains. Retention and
+// rate limits can only be configured per project.
+type domainConfigRequest struct {
+	Enabled      bool   `json:"enabled"`
+	TargetBucket string `json:"target_bucket"`
+	Filter       string `json:"filter"`
+}
+
+// GetDomainDataplaneConfig handles GET /v1/domains/{domain_id}/dataplane-config.
+// Returns 200 with the default (disabled) payload when no config exists.
+func (p *v1Provider) GetDomainDataplaneConfig(res http.ResponseWriter, req *http.Request) {
+	domainID := mux.Vars(req)["domain_id"]
+	if _, ok := p.authDomainScoped(res, req, domainID, "dataplane_config:manage_domain"); !ok {
+		return
+	}
+
+	cfg, err := p.routingStore.GetDomain(req.Context(), domainID)
+	if errors.Is(err, routing.ErrNotFound) {
+		ReturnESJSON(res, http.StatusOK, routing.DefaultDomainConfig(domainID))
+		return
+	}
+	if err != nil {
+		logg.Error("domain dataplane-config GET: storage error for domain %s: %s", domainID, err)
+		respondwith.ObfuscatedErrorText(res, err)
+		return
+	}
+	ReturnESJSON(res, http.StatusOK, cfg)
+}
+
+// PutDomainDataplaneConfig handles PUT /v1/domains/{domain_id}/dataplane-config.
+// Idempotent create-or-replace. The config applies to all projects of the
+// domain without an enabled config of their own. The target bucket is
+// verified like for projects, but in the account of the domain itself, which
+// Keystone represents as a project with the ID of the domain.
+// An audit event is emitted for every attempt — successful or not.
+func (p *v1Provider) PutDomainDataplaneConfig(res http.ResponseWriter, req *http.Request) {
+	domainID := mux.Vars(req)["domain_id"]
+	token, ok := p.authDomainScoped(res, req, domainID, "dataplane_config:manage_domain")
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	userID := token.Context.Auth["user_id"]
+	if userID == "" {
+		http.Error(res, "token missing user identity", http.StatusUnauthorized)
+		return
+	}
+
+	recordAttempt := func(reasonCode int, cfg *routing.DomainConfig) {
+		target := routing.DomainConfig{DomainID: domainID, UpdatedBy: userID}
+		if cfg != nil {
+			target = *cfg
+		}
+		p.auditor.Record(audittools.Event{
+			Time:       now,
+			Request:    req,
+			User:       token,
+			ReasonCode: reasonCode,
+			Action:     cadf.UpdateAction,
+			Target:     target,
+		})
+	}
+
+	if ct := req.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		http.Error(res, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+		recordAttempt(http.StatusUnsupportedMediaType, nil)
+		return
+	}
+	req.Body = http.MaxBytesReader(res, req.Body, 64*1024)
+	decoder := json.NewDecoder(req.Body)
+	decoder.DisallowUnknownFields()
+	var body domainConfigRequest
+	if err := decoder.Decode(&body); err != nil {
+		http.Error(res, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		recordAttempt(http.StatusBadRequest, nil)
+		return
+	}
+
+	// a domain config has the same fields as the single-sink view of a project config
+	if status, err := validateDataplaneConfig(routing.DataplaneConfig{
+		Enabled:      body.Enabled,
+		TargetBucket: body.TargetBucket,
+		Filter:       body.Filter,
+	}); err != nil {
+		http.Error(res, err.Error(), status)
+		recordAttempt(status, nil)
+		return
+	}
+
+	if status, err := p.verifyDomainBucket(req, domainID, body.Enabled, body.TargetBucket); err != nil {
+		http.Error(res, err.Error(), status)
+		recordAttempt(status, nil)
+		return
+	}
+
+	cfg := routing.DomainConfig{
+		DomainID:     domainID,
+		Enabled:      body.Enabled,
+		TargetBucket: body.TargetBucket,
+		Filter:       body.Filter,
+		UpdatedAt:    now,
+		UpdatedBy:    userID,
+	}
+	if err := p.routingStore.UpsertDomain(req.Context(), cfg); err != nil {
+		logg.Error("domain dataplane-config PUT: storage error for domain %s: %s", domainID, err)
+		respondwith.ObfuscatedErrorText(res, err)
+		recordAttempt(http.StatusInternalServerError, &cfg)
+		return
+	}
+
+	logg.Info("domain dataplane-config PUT: domain=%s enabled=%v updated_by=%s", domainID, cfg.Enabled, userID)
+	recordAttempt(http.StatusOK, &cfg)
+	ReturnESJSON(res, http.StatusOK, cfg)
+}
+
+// verifyDomainBucket is like verifyTargetBucket, but for the bucket of a
+// domain config, which belongs to the domain's own account.
+func (p *v1Provider) verifyDomainBucket(req *http.Request, domainID string, enabled bool, bucketName string) (int, error) {
+	if p.bucketVerifier == nil || p.bucketMode == bucket.ModeOff || !enabled || bucketName == "" {
+		return 0, nil
+	}
+	stored, err := p.routingStore.GetDomain(req.Context(), domainID)
+	switch {
+	case errors.Is(err, routing.ErrNotFound):
+	case err != nil:
+		logg.Error("domain dataplane-config PUT: storage error for domain %s: %s", domainID, err)
+		return http.StatusInternalServerError, errors.New("internal server error")
+	case stored.Enabled && stored.TargetBucket == bucketName:
+		return 0, nil
+	}
+	return p.verifyBucket(req, domainID, "domain "+domainID, bucketName)
+}
+
+// DeleteDomainDataplaneConfig handles DELETE /v1/domains/{domain_id}/dataplane-config.
+// Idempotent — deleting a non-existent config returns 204. Like for project
+// configs, an audit event is emitted only when a config was actually removed.
+func (p *v1Provider) DeleteDomainDataplaneConfig(res http.ResponseWriter, req *http.Request) {
+	domainID := mux.Vars(req)["domain_id"]
+	token, ok := p.authDomainScoped(res, req, domainID, "dataplane_config:manage_domain")
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	userID := token.Context.Auth["user_id"]
+	if userID == "" {
+		http.Error(res, "token missing user identity", http.StatusUnauthorized)
+		return
+	}
+
+	recordAttempt := func(reasonCode int) {
+		p.auditor.Record(audittools.Event{
+			Time:       now,
+			Request:    req,
+			User:       token,
+			ReasonCode: reasonCode,
+			Action:     cadf.DeleteAction,
+			Target:     routing.DomainConfig{DomainID: domainID, UpdatedBy: userID},
+		})
+	}
+
+	deleted, err := p.routingStore.DeleteDomain(req.Context(), domainID)
+	if err != nil {
+		logg.Error("domain dataplane-config DELETE: storage error for domain %s: %s", domainID, err)
+		respondwith.ObfuscatedErrorText(res, err)
+		recordAttempt(http.StatusInternalServerError)
+		return
+	}
+	if deleted {
+		recordAttempt(http.StatusNoContent)
+	}
+
+	logg.Info("domain dataplane-config DELETE: domain=%s updated_by=%s deleted=%v", domainID, userID, deleted)
+	res.WriteHeader(http.StatusNoContent)
+}
+
+// GetEffectiveDataplaneConfig handles GET /v1/projects/{project_id}/dataplane-config/effective.
+// Returns the config that log-router applies to the project: its own config,
+// or else the config of its domain, or else the default (disabled) config.
+// The domain of the project is taken from the token.
+func (p *v1Provider) GetEffectiveDataplaneConfig(res http.ResponseWriter, req *http.Request) {
+	projectID := mux.Vars(req)["project_id"]
+	token, ok := p.authDataplaneConfig(res, req, projectID)
+	if !ok {
+		return
+	}
+
+	cfg, err := p.routingStore.EffectiveConfig(req.Context(), projectID, token.Context.Auth["project_domain_id"])
+	if err != nil {
+		logg.Error("dataplane-config effective: storage error for project %s: %s", projectID, err)
+		respondwith.ObfuscatedErrorText(res, err)
+		return
+	}
+	ReturnESJSON(res, http.StatusOK, cfg)
+}
+
+// authDomainScoped validates the Keystone token against the given policy rule
+// and enforces that the path domain_id matches the token's domain scope, like
+// authProjectScoped does for projects.
+//
+// Returns the token and true on success; writes the error response and
+// returns false on failure.
+func (p *v1Provider) authDomainScoped(res http.ResponseWriter, req *http.Request, pathDomainID, rule string) (*gopherpolicy.Token, bool) {
+	token := p.validator.CheckToken(req)
+	token.Context.Request = mux.Vars(req)
+	token.Context.Request["domain_id"] = token.Context.Auth["domain_id"]
+
+	if !token.Require(res, rule) {
+		return nil, false
+	}
+
+	// Cross-domain access check: path domain_id must match the token scope.
+	if token.Context.Auth["domain_id"] != pathDomainID {
+		http.Error(res, "domain_id in path does not match token scope", http.StatusForbidden)
+		return nil, false
+	}
+
+	return token, true
+}
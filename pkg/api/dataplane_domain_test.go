@@ -0,0 +1,220 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/sapcc/go-bits/must"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapcc/hermes/pkg/routing"
+	"github.com/sapcc/hermes/pkg/routing/bucket"
+)
+
+const (
+	testDomainID           = "test-domain-1"
+	domainDataplanePath    = "/v1/domains/" + testDomainID + "/dataplane-config"
+	effectiveDataplanePath = dataplaneConfigPath + "/effective"
+)
+
+func domainTarget(enabled bool, targetBucket string) cadf.Resource {
+	return cadf.Resource{
+		TypeURI:  "service/hermes/dataplane-domain-config",
+		ID:       testDomainID,
+		DomainID: testDomainID,
+		Attachments: []cadf.Attachment{
+			must.Return(cadf.NewJSONAttachment("payload", map[string]any{
+				"enabled":       enabled,
+				"target_bucket": targetBucket,
+			})),
+		},
+	}
+}
+
+func getEffectiveConfig(t *testing.T, handler http.Handler) routing.EffectiveConfig {
+	t.Helper()
+	rec := dataplaneRequest(t, handler, http.MethodGet, effectiveDataplanePath, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var cfg routing.EffectiveConfig
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &cfg))
+	return cfg
+}
+
+func TestDataplaneConfig_Domain(t *testing.T) {
+	handler, routingStore, auditor := setupDataplaneTestWithAuth(t, map[string]string{
+		"domain_id": testDomainID,
+		"user_id":   "user-abc",
+	})
+
+	rec := dataplaneRequest(t, handler, http.MethodGet, domainDataplanePath, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"domain_id":"`+testDomainID+`","enabled":false,"updated_at":"0001-01-01T00:00:00Z","updated_by":""}`, rec.Body.String())
+
+	rec = dataplaneRequest(t, handler, http.MethodPut, domainDataplanePath, map[string]any{"enabled": true})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = dataplaneRequest(t, handler, http.MethodPut, domainDataplanePath, map[string]any{"enabled": true, "target_bucket": "domain-bucket", "shards": 3})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = dataplaneRequest(t, handler, http.MethodPut, domainDataplanePath, map[string]any{"enabled": true, "target_bucket": "domain-bucket"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	failed := updateEvent(http.StatusBadRequest, cadf.Resource{
+		TypeURI:  "service/hermes/dataplane-domain-config",
+		ID:       testDomainID,
+		DomainID: testDomainID,
+		Attachments: []cadf.Attachment{
+			must.Return(cadf.NewJSONAttachment("payload", map[string]any{"enabled": false, "target_bucket": ""})),
+		},
+	})
+	failed.RequestPath = domainDataplanePath
+	succeeded := updateEvent(http.StatusOK, domainTarget(true, "domain-bucket"))
+	succeeded.RequestPath = domainDataplanePath
+	auditor.ExpectEvents(t, failed, failed, succeeded)
+
+	cfg, err := routingStore.GetDomain(t.Context(), testDomainID)
+	require.NoError(t, err)
+	assert.Equal(t, "domain-bucket", cfg.TargetBucket)
+	assert.Equal(t, "user-abc", cfg.UpdatedBy)
+
+	// other domains are out of scope
+	for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete} {
+		rec = dataplaneRequest(t, handler, method, "/v1/domains/other-domain/dataplane-config", map[string]any{"enabled": false})
+		assert.Equal(t, http.StatusForbidden, rec.Code, method)
+	}
+
+	rec = dataplaneRequest(t, handler, http.MethodDelete, domainDataplanePath, nil)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	rec = dataplaneRequest(t, handler, http.MethodDelete, domainDataplanePath, nil)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	deleted := deleteEvent(http.StatusNoContent, domainTarget(false, ""))
+	deleted.RequestPath = domainDataplanePath
+	auditor.ExpectEvents(t, deleted)
+	_, err = routingStore.GetDomain(t.Context(), testDomainID)
+	assert.ErrorIs(t, err, routing.ErrNotFound)
+}
+
+// TestDataplaneConfig_DomainBucketVerification proves that domain buckets are
+// verified in the account of the domain, not in that of any project.
+func TestDataplaneConfig_DomainBucketVerification(t *testing.T) {
+	store := bucket.NewFakeObjectStore()
+	store.AddToken("something", testDomainID)
+	store.AddBucket(testDomainID, "domain-bucket", false)
+	store.AddBucket(testProjectID, "project-bucket", false)
+	server := httptest.NewServer(store)
+	t.Cleanup(server.Close)
+	verifier, err := bucket.NewHTTPVerifier(server.URL + "/AUTH_%(project_id)s")
+	require.NoError(t, err)
+	handler, routingStore, auditor := setupDataplaneTestWithAuth(t, map[string]string{
+		"domain_id": testDomainID,
+		"user_id":   "user-abc",
+	}, WithBucketVerifier(verifier, bucket.ModeEnforce))
+
+	rec := dataplaneRequest(t, handler, http.MethodPut, domainDataplanePath, map[string]any{"enabled": true, "target_bucket": "project-bucket"})
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), "does not exist in domain "+testDomainID)
+	_, err = routingStore.GetDomain(t.Context(), testDomainID)
+	assert.ErrorIs(t, err, routing.ErrNotFound)
+
+	rec = dataplaneRequest(t, handler, http.MethodPut, domainDataplanePath, map[string]any{"enabled": true, "target_bucket": "domain-bucket"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	events := auditor.RecordedEvents()
+	require.Len(t, events, 2)
+	assert.Equal(t, "422", events[0].Reason.ReasonCode)
+
+	// disabled configs are not verified
+	rec = dataplaneRequest(t, handler, http.MethodPut, domainDataplanePath, map[string]any{"enabled": false, "target_bucket": "missing-bucket"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+}
+
+func TestDataplaneConfig_Effective(t *testing.T) {
+	handler, routingStore, _ := setupDataplaneTestWithAuth(t, map[string]string{
+		"project_id":        testProjectID,
+		"project_domain_id": testDomainID,
+		"user_id":           "user-abc",
+	})
+
+	cfg := getEffectiveConfig(t, handler)
+	assert.Equal(t, routing.SourceDefault, cfg.Source)
+	assert.False(t, cfg.Enabled)
+	assert.Empty(t, cfg.Sinks)
+
+	// projects without a config of their own inherit the domain config
+	require.NoError(t, routingStore.UpsertDomain(t.Context(), routing.DomainConfig{
+		DomainID: testDomainID, Enabled: true, TargetBucket: "domain-bucket", Filter: `event.action != "read"`,
+	}))
+	cfg = getEffectiveConfig(t, handler)
+	assert.Equal(t, routing.SourceDomain, cfg.Source)
+	assert.Equal(t, testDomainID, cfg.DomainID)
+	assert.True(t, cfg.Enabled)
+	assert.Equal(t, "domain-bucket", cfg.TargetBucket)
+	assert.Equal(t, `event.action != "read"`, cfg.Filter)
+	require.Len(t, cfg.Sinks, 1)
+	assert.Equal(t, routing.DefaultSinkName, cfg.Sinks[0].Name)
+
+	// an enabled config of the project overrides the domain config completely
+	require.Equal(t, http.StatusOK, putJSON(t, handler, map[string]any{"enabled": true, "target_bucket": "project-bucket"}).Code)
+	cfg = getEffectiveConfig(t, handler)
+	assert.Equal(t, routing.SourceProject, cfg.Source)
+	assert.Equal(t, "project-bucket", cfg.TargetBucket)
+	assert.Empty(t, cfg.Filter)
+
+	// a disabled one does not
+	require.Equal(t, http.StatusOK, putJSON(t, handler, map[string]any{"enabled": false}).Code)
+	assert.Equal(t, routing.SourceDomain, getEffectiveConfig(t, handler).Source)
+
+	// deleting it makes the project inherit again
+	require.Equal(t, http.StatusNoContent, dataplaneRequest(t, handler, http.MethodDelete, dataplaneConfigPath, nil).Code)
+	assert.Equal(t, routing.SourceDomain, getEffectiveConfig(t, handler).Source)
+}
+
+type staticProjectLister map[string][]string
+
+func (l staticProjectLister) ListProjects(_ context.Context, domainID string) ([]string, error) {
+	return l[domainID], nil
+}
+
+func TestDataplaneConfig_DomainSync(t *testing.T) {
+	// without project_domain_id in the token, the recorded domain of the project is used
+	handler, routingStore, _ := setupDataplaneTest(t)
+	require.NoError(t, routingStore.UpsertDomain(t.Context(), routing.DomainConfig{
+		DomainID: testDomainID, Enabled: true, TargetBucket: "domain-bucket", UpdatedAt: time.Now(),
+	}))
+	assert.Equal(t, routing.SourceDefault, getEffectiveConfig(t, handler).Source)
+	since := getDataplaneChanges(t, handler, "").NextSince
+
+	lister := staticProjectLister{testDomainID: {testProjectID, "test-project-2"}}
+	syncer := routing.NewDomainSyncer(routingStore, lister)
+	require.NoError(t, syncer.SyncAll(t.Context()))
+	cfg := getEffectiveConfig(t, handler)
+	assert.Equal(t, routing.SourceDomain, cfg.Source)
+	assert.Equal(t, testDomainID, cfg.DomainID)
+
+	// only projects that join or leave the domain show up in the change feed
+	require.NoError(t, syncer.SyncAll(t.Context()))
+	lister[testDomainID] = []string{testProjectID}
+	require.NoError(t, syncer.SyncAll(t.Context()))
+	changes := getDataplaneChanges(t, handler, "?since="+strconv.FormatInt(since, 10)).Changes
+	var projects []string
+	for _, c := range changes {
+		assert.Equal(t, routing.ChangeMembership, c.Operation)
+		assert.Equal(t, testDomainID, c.DomainID)
+		projects = append(projects, c.ProjectID)
+	}
+	assert.Equal(t, []string{testProjectID, "test-project-2", "test-project-2"}, projects)
+
+	// deleting the domain config forgets its projects
+	_, err := routingStore.DeleteDomain(t.Context(), testDomainID)
+	require.NoError(t, err)
+	cfg = getEffectiveConfig(t, handler)
+	assert.Equal(t, routing.SourceDefault, cfg.Source)
+	assert.Empty(t, cfg.DomainID)
+}
@@ -0,0 +1,149 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/sapcc/go-bits/audittools"
+	"github.com/sapcc/go-bits/logg"
+	"github.com/sapcc/go-bits/respondwith"
+
+	"github.com/sapcc/hermes/pkg/routing"
+)
+
+const (
+	// defaultHistoryLimit and maxHistoryLimit bound the page size of
+	// GET .../dataplane-config/history.
+	defaultHistoryLimit = 20
+	maxHistoryLimit     = 100
+)
+
+// rollbackAction is the CADF action of POST .../dataplane-config/rollback.
+const rollbackAction cadf.Action = cadf.UpdateAction + "/rollback"
+
+// dataplaneConfigHistory is the response of GET .../dataplane-config/history.
+// It is paginated like EventList.
+type dataplaneConfigHistory struct {
+	NextURL  string                 `json:"next,omitempty"`
+	PrevURL  string                 `json:"previous,omitempty"`
+	Versions []routing.HistoryEntry `json:"versions"`
+	Total    int                    `json:"total"`
+}
+
+// GetDataplaneConfigHistory handles GET /v1/projects/{project_id}/dataplane-config/history.
+// Returns the versions of the config, newest first.
+func (p *v1Provider) GetDataplaneConfigHistory(res http.ResponseWriter, req *http.Request) {
+	projectID := mux.Vars(req)["project_id"]
+	if _, ok := p.authDataplaneConfig(res, req, projectID); !ok {
+		return
+	}
+
+	query := req.URL.Query()
+	offset, limit := 0, defaultHistoryLimit
+	if s := query.Get("offset"); s != "" {
+		var err error
+		offset, err = strconv.Atoi(s)
+		if err != nil || offset < 0 {
+			http.Error(res, "offset must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+	}
+	if s := query.Get("limit"); s != "" {
+		var err error
+		limit, err = strconv.Atoi(s)
+		if err != nil || limit < 1 || limit > maxHistoryLimit {
+			http.Error(res, fmt.Sprintf("limit must be between 1 and %d", maxHistoryLimit), http.StatusBadRequest)
+			return
+		}
+	}
+
+	entries, total, err := p.routingStore.History(req.Context(), projectID, offset, limit)
+	if err != nil {
+		logg.Error("dataplane-config history: storage error for project %s: %s", projectID, err)
+		respondwith.ObfuscatedErrorText(res, err)
+		return
+	}
+
+	history := dataplaneConfigHistory{Versions: entries, Total: total}
+	pageURL := func(offset int) string {
+		query.Set("offset", strconv.Itoa(offset))
+		return fmt.Sprintf("%s://%s%s?%s", getProtocol(req), req.Host, req.URL.Path, query.Encode())
+	}
+	if offset+limit < total {
+		history.NextURL = pageURL(offset + limit)
+	}
+	if offset >= limit {
+		history.PrevURL = pageURL(offset - limit)
+	}
+	ReturnESJSON(res, http.StatusOK, history)
+}
+
+// RollbackDataplaneConfig handles POST /v1/projects/{project_id}/dataplane-config/rollback?version=N.
+// Restores the config of version N, including its sinks, as a new version.
+// Returns 200 with the restored config.
+// An audit event is emitted for every attempt — successful or not.
+func (p *v1Provider) RollbackDataplaneConfig(res http.ResponseWriter, req *http.Request) {
+	projectID := mux.Vars(req)["project_id"]
+	token, ok := p.authDataplaneConfig(res, req, projectID)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	userID := token.Context.Auth["user_id"]
+	if userID == "" {
+		http.Error(res, "token missing user identity", http.StatusUnauthorized)
+		return
+	}
+	recordAttempt := func(reasonCode int, cfg *routing.DataplaneConfig) {
+		target := routing.DataplaneConfig{ProjectID: projectID, UpdatedBy: userID}
+		if cfg != nil {
+			target = *cfg
+		}
+		p.auditor.Record(audittools.Event{
+			Time:       now,
+			Request:    req,
+			User:       token,
+			ReasonCode: reasonCode,
+			Action:     rollbackAction,
+			Target:     target,
+		})
+	}
+
+	version, err := strconv.ParseInt(req.URL.Query().Get("version"), 10, 64)
+	if err != nil || version < 1 {
+		http.Error(res, "version must be a positive integer", http.StatusBadRequest)
+		recordAttempt(http.StatusBadRequest, nil)
+		return
+	}
+
+	cfg, err := p.routingStore.Rollback(req.Context(), projectID, version, now, userID)
+	switch {
+	case errors.Is(err, routing.ErrVersionNotFound):
+		http.Error(res, fmt.Sprintf("version %d does not exist", version), http.StatusNotFound)
+		recordAttempt(http.StatusNotFound, nil)
+		return
+	case errors.Is(err, routing.ErrVersionDeleted):
+		http.Error(res, fmt.Sprintf("version %d records a deletion; use DELETE instead", version), http.StatusConflict)
+		recordAttempt(http.StatusConflict, nil)
+		return
+	case err != nil:
+		logg.Error("dataplane-config rollback: storage error for project %s: %s", projectID, err)
+		respondwith.ObfuscatedErrorText(res, err)
+		recordAttempt(http.StatusInternalServerError, nil)
+		return
+	}
+
+	logg.Info("dataplane-config rollback: project=%s version=%d updated_by=%s", projectID, version, userID)
+	recordAttempt(http.StatusOK, cfg)
+	res.Header().Set("ETag", dataplaneETag(cfg.Version))
+	ReturnESJSON(res, http.StatusOK, cfg)
+}
@@ -0,0 +1,198 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapcc/hermes/pkg/routing"
+)
+
+const (
+	dataplaneHistoryPath  = dataplaneConfigPath + "/history"
+	dataplaneRollbackPath = dataplaneConfigPath + "/rollback"
+)
+
+func getDataplaneHistory(t *testing.T, handler http.Handler, query string) dataplaneConfigHistory {
+	t.Helper()
+	rec := dataplaneRequest(t, handler, http.MethodGet, dataplaneHistoryPath+query, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var history dataplaneConfigHistory
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &history))
+	return history
+}
+
+func TestDataplaneConfig_History(t *testing.T) {
+	handler, _, _ := setupDataplaneTest(t)
+	assert.Empty(t, getDataplaneHistory(t, handler, "").Versions)
+
+	rec := putJSON(t, handler, map[string]any{"enabled": true, "target_bucket": "bucket-1"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	rec = dataplaneRequest(t, handler, http.MethodPut, dataplaneSinksPath+"/dr", map[string]any{"enabled": true, "target_bucket": "dr-bucket"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	rec = putJSON(t, handler, map[string]any{"enabled": true, "target_bucket": "bucket-2", "filter": `event.action != "read"`})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	// failed and no-op writes do not create versions
+	rec = putJSON(t, handler, map[string]any{"enabled": true})
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = dataplaneRequest(t, handler, http.MethodDelete, dataplaneSinksPath+"/other", nil)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	rec = dataplaneRequest(t, handler, http.MethodDelete, dataplaneConfigPath, nil)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	history := getDataplaneHistory(t, handler, "")
+	assert.Equal(t, 4, history.Total)
+	assert.Empty(t, history.NextURL)
+	var ops []routing.HistoryOperation
+	for _, e := range history.Versions {
+		ops = append(ops, e.Operation)
+		assert.Equal(t, "user-abc", e.ChangedBy)
+	}
+	assert.Equal(t, []routing.HistoryOperation{routing.HistoryDelete, routing.HistoryUpdate, routing.HistorySinkUpdate, routing.HistoryUpdate}, ops)
+	assert.Nil(t, history.Versions[0].Config)
+	cfg := history.Versions[1].Config
+	require.NotNil(t, cfg)
+	assert.Equal(t, "bucket-2", cfg.TargetBucket)
+	assert.Equal(t, `event.action != "read"`, cfg.Filter)
+	assert.Len(t, cfg.Sinks, 2)
+
+	// pagination
+	history = getDataplaneHistory(t, handler, "?limit=2&offset=1")
+	assert.Equal(t, []int64{3, 2}, []int64{history.Versions[0].Version, history.Versions[1].Version})
+	assert.Equal(t, "http://example.com"+dataplaneHistoryPath+"?limit=2&offset=3", history.NextURL)
+	assert.Empty(t, history.PrevURL)
+	history = getDataplaneHistory(t, handler, "?limit=2&offset=2")
+	assert.Empty(t, history.NextURL)
+	assert.Equal(t, "http://example.com"+dataplaneHistoryPath+"?limit=2&offset=0", history.PrevURL)
+
+	for _, query := range []string{"?limit=0", "?limit=101", "?offset=-1", "?offset=abc"} {
+		rec := dataplaneRequest(t, handler, http.MethodGet, dataplaneHistoryPath+query, nil)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
+
+func TestDataplaneConfig_Rollback(t *testing.T) {
+	handler, _, auditor := setupDataplaneTest(t)
+	rec := putJSON(t, handler, map[string]any{"enabled": true, "target_bucket": "bucket-1"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	rec = dataplaneRequest(t, handler, http.MethodPut, dataplaneSinksPath+"/dr", map[string]any{"enabled": true, "target_bucket": "dr-bucket"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	rec = dataplaneRequest(t, handler, http.MethodDelete, dataplaneConfigPath, nil)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	auditor.IgnoreEventsUntilNow()
+
+	// version 2 is restored including its sinks
+	rec = dataplaneRequest(t, handler, http.MethodPost, dataplaneRollbackPath+"?version=2", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	cfg := getDataplaneConfig(t, handler)
+	assert.True(t, cfg.Enabled)
+	assert.Equal(t, "bucket-1", cfg.TargetBucket)
+	assert.Equal(t, []string{"default", "dr"}, []string{cfg.Sinks[0].Name, cfg.Sinks[1].Name})
+
+	history := getDataplaneHistory(t, handler, "?limit=1")
+	assert.Equal(t, 4, history.Total)
+	assert.Equal(t, routing.HistoryRollback, history.Versions[0].Operation)
+	assert.Equal(t, int64(2), history.Versions[0].RestoredVersion)
+
+	// rolling back to version 1 removes the sink that was created later
+	rec = dataplaneRequest(t, handler, http.MethodPost, dataplaneRollbackPath+"?version=1", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Len(t, getDataplaneConfig(t, handler).Sinks, 1)
+
+	for _, tc := range []struct {
+		query  string
+		status int
+	}{
+		{"", http.StatusBadRequest},
+		{"?version=0", http.StatusBadRequest},
+		{"?version=9", http.StatusNotFound},
+		{"?version=3", http.StatusConflict}, // the deletion
+	} {
+		rec = dataplaneRequest(t, handler, http.MethodPost, dataplaneRollbackPath+tc.query, nil)
+		assert.Equal(t, tc.status, rec.Code, tc.query)
+	}
+
+	// every attempt is audited
+	events := auditor.RecordedEvents()
+	require.Len(t, events, 6)
+	assert.Equal(t, rollbackAction, events[0].Action)
+	assert.Equal(t, "200", events[0].Reason.ReasonCode)
+	assert.Equal(t, routing.DataplaneConfig{ProjectID: testProjectID, Enabled: true, TargetBucket: "bucket-1"}.Render().Attachments,
+		events[0].Target.Attachments)
+	assert.Equal(t, "409", events[5].Reason.ReasonCode)
+}
+
+// conditionalRequest issues a dataplane-config request with precondition headers.
+func conditionalRequest(t *testing.T, handler http.Handler, method string, body any, headers map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	var reqBody bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&reqBody).Encode(body))
+	}
+	req := httptest.NewRequest(method, dataplaneConfigPath, &reqBody)
+	req.Header.Set("X-Auth-Token", "something")
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestDataplaneConfig_ETag(t *testing.T) {
+	handler, _, auditor := setupDataplaneTest(t)
+	body := map[string]any{"enabled": true, "target_bucket": "bucket-1"}
+
+	// the default config has no ETag, and If-Match needs a stored config
+	rec := conditionalRequest(t, handler, http.MethodGet, nil, nil)
+	assert.Empty(t, rec.Header().Get("ETag"))
+	rec = conditionalRequest(t, handler, http.MethodPut, body, map[string]string{"If-Match": "*"})
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+
+	// create-only
+	rec = conditionalRequest(t, handler, http.MethodPut, body, map[string]string{"If-None-Match": "*"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, `"1"`, rec.Header().Get("ETag"))
+	rec = conditionalRequest(t, handler, http.MethodPut, body, map[string]string{"If-None-Match": "*"})
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+
+	rec = conditionalRequest(t, handler, http.MethodGet, nil, nil)
+	assert.Equal(t, `"1"`, rec.Header().Get("ETag"))
+	rec = conditionalRequest(t, handler, http.MethodGet, nil, map[string]string{"If-None-Match": `"1"`})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+
+	// the second of two editors that both read version 1 loses
+	rec = conditionalRequest(t, handler, http.MethodPut, body, map[string]string{"If-Match": `"1"`})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, `"2"`, rec.Header().Get("ETag"))
+	rec = conditionalRequest(t, handler, http.MethodPut, body, map[string]string{"If-Match": `"1"`})
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	rec = conditionalRequest(t, handler, http.MethodPut, body, map[string]string{"If-Match": `W/"2"`})
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+
+	// sink changes count as modifications of the config
+	rec = dataplaneRequest(t, handler, http.MethodPut, dataplaneSinksPath+"/dr", map[string]any{"target_bucket": "dr-bucket"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	rec = conditionalRequest(t, handler, http.MethodDelete, nil, map[string]string{"If-Match": `"2"`})
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	rec = conditionalRequest(t, handler, http.MethodDelete, nil, map[string]string{"If-None-Match": `"3"`})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = conditionalRequest(t, handler, http.MethodDelete, nil, map[string]string{"If-Match": `"1", "3"`})
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	// precondition failures are audited like other failed attempts
+	var reasonCodes []string
+	for _, event := range auditor.RecordedEvents() {
+		reasonCodes = append(reasonCodes, event.Reason.ReasonCode)
+	}
+	assert.Equal(t, []string{"412", "200", "412", "200", "412", "412", "200", "412", "400", "204"}, reasonCodes)
+}
@@ -0,0 +1,189 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sapcc/go-bits/logg"
+	"github.com/sapcc/go-bits/respondwith"
+
+	"github.com/sapcc/hermes/pkg/routing"
+)
+
+const (
+	// defaultConfigListLimit and maxConfigListLimit bound the page size of
+	// GET /v1/dataplane-configs.
+	defaultConfigListLimit = 100
+	maxConfigListLimit     = 1000
+)
+
+// dataplaneConfigList is the response of GET /v1/dataplane-configs.
+// It is paginated like EventList.
+type dataplaneConfigList struct {
+	NextURL string                    `json:"next,omitempty"`
+	PrevURL string                    `json:"previous,omitempty"`
+	Configs []routing.DataplaneConfig `json:"configs"`
+	Total   int                       `json:"total"`
+}
+
+// ListDataplaneConfigs handles GET /v1/dataplane-configs.
+// Returns the stored configs of all projects, ordered by project ID, for
+// cluster admins. Projects without a stored config are disabled and not listed.
+// With format=csv or "Accept: text/csv", all matching configs are exported as
+// CSV, ignoring limit and offset.
+func (p *v1Provider) ListDataplaneConfigs(res http.ResponseWriter, req *http.Request) {
+	if _, ok := p.AuthHandler(res, req, "dataplane_config:list"); !ok {
+		return
+	}
+
+	query := req.URL.Query()
+	filter, err := parseDataplaneListFilter(query)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	offset, limit := 0, defaultConfigListLimit
+	if s := query.Get("offset"); s != "" {
+		offset, err = strconv.Atoi(s)
+		if err != nil || offset < 0 {
+			http.Error(res, "offset must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+	}
+	if s := query.Get("limit"); s != "" {
+		limit, err = strconv.Atoi(s)
+		if err != nil || limit < 1 || limit > maxConfigListLimit {
+			http.Error(res, fmt.Sprintf("limit must be between 1 and %d", maxConfigListLimit), http.StatusBadRequest)
+			return
+		}
+	}
+	format, err := negotiateFormat(req)
+	if err == nil && format != formatDefault && format != formatJSON && format != formatCSV {
+		err = fmt.Errorf("format must be %q or %q", formatJSON, formatCSV)
+	}
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if format == formatCSV {
+		p.exportDataplaneConfigs(res, req, filter)
+		return
+	}
+
+	configs, total, err := p.routingStore.List(req.Context(), filter, offset, limit)
+	if err != nil {
+		logg.Error("dataplane-config list: storage error: %s", err)
+		respondwith.ObfuscatedErrorText(res, err)
+		return
+	}
+	list := dataplaneConfigList{Configs: configs, Total: total}
+	pageURL := func(offset int) string {
+		query.Set("offset", strconv.Itoa(offset))
+		return fmt.Sprintf("%s://%s%s?%s", getProtocol(req), req.Host, req.URL.Path, query.Encode())
+	}
+	if offset+limit < total {
+		list.NextURL = pageURL(offset + limit)
+	}
+	if offset >= limit {
+		list.PrevURL = pageURL(offset - limit)
+	}
+	ReturnESJSON(res, http.StatusOK, list)
+}
+
+// exportDataplaneConfigs writes all configs matching the filter as CSV, one
+// row per project. The enabled_sinks column lists the enabled sinks as
+// space-separated "name=bucket" pairs.
+func (p *v1Provider) exportDataplaneConfigs(res http.ResponseWriter, req *http.Request, filter routing.ListFilter) {
+	// collect everything first, so that errors can still be reported with a status code
+	var configs []routing.DataplaneConfig
+	for {
+		page, total, err := p.routingStore.List(req.Context(), filter, len(configs), maxConfigListLimit)
+		if err != nil {
+			logg.Error("dataplane-config list: storage error: %s", err)
+			respondwith.ObfuscatedErrorText(res, err)
+			return
+		}
+		configs = append(configs, page...)
+		if len(page) == 0 || len(configs) >= total {
+			break
+		}
+	}
+
+	fileName := fmt.Sprintf("hermes-dataplane-configs-%s.csv", time.Now().UTC().Format("20060102T150405Z"))
+	res.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	startListResponse(res, formatCSV)
+	w := csv.NewWriter(res)
+	_ = w.Write([]string{"project_id", "enabled", "target_bucket", "enabled_sinks", "filter", "retention_days", "grace_minutes", "rate_limit", "version", "updated_at", "updated_by"}) //nolint:errcheck // checked via w.Error()
+	for _, cfg := range configs {
+		var sinks []string
+		for _, sink := range cfg.Sinks {
+			if sink.Enabled {
+				sinks = append(sinks, sink.Name+"="+sink.Destination())
+			}
+		}
+		record := []string{
+			cfg.ProjectID,
+			strconv.FormatBool(cfg.Enabled),
+			cfg.TargetBucket,
+			strings.Join(sinks, " "),
+			cfg.Filter,
+			strconv.Itoa(cfg.RetentionDays),
+			strconv.Itoa(cfg.GraceMinutes),
+			strconv.Itoa(cfg.RateLimit),
+			strconv.FormatInt(cfg.Version, 10),
+			cfg.UpdatedAt.UTC().Format(time.RFC3339),
+			cfg.UpdatedBy,
+		}
+		for idx, value := range record {
+			record[idx] = csvCell(value)
+		}
+		_ = w.Write(record) //nolint:errcheck // checked via w.Error()
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		logg.Error("could not export dataplane configs: %s", err)
+	}
+}
+
+// parseDataplaneListFilter parses the filter parameters of GET /v1/dataplane-configs.
+// updated_at uses the syntax of the time parameter of GET /v1/events.
+func parseDataplaneListFilter(query url.Values) (routing.ListFilter, error) {
+	filter := routing.ListFilter{
+		TargetBucket: query.Get("target_bucket"),
+		UpdatedBy:    query.Get("updated_by"),
+	}
+	if s := query.Get("enabled"); s != "" {
+		enabled, err := strconv.ParseBool(s)
+		if err != nil {
+			return filter, fmt.Errorf("enabled must be true or false, got %q", s)
+		}
+		filter.Enabled = &enabled
+	}
+
+	timeRange, err := parseTimeFilter(query.Get("updated_at"))
+	if err != nil {
+		return filter, fmt.Errorf("invalid updated_at: %w", err)
+	}
+	for op, value := range timeRange {
+		if filter.UpdatedAt == nil {
+			filter.UpdatedAt = make(map[string]time.Time)
+		}
+		// parseTimeFilter accepted one of these layouts already
+		for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05-0700", "2006-01-02T15:04:05"} {
+			if t, err := time.Parse(layout, value); err == nil {
+				filter.UpdatedAt[op] = t
+				break
+			}
+		}
+	}
+	return filter, nil
+}
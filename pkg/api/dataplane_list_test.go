@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapcc/hermes/pkg/routing"
+)
+
+const dataplaneConfigsPath = "/v1/dataplane-configs"
+
+func listDataplaneConfigs(t *testing.T, handler http.Handler, query string) dataplaneConfigList {
+	t.Helper()
+	rec := dataplaneRequest(t, handler, http.MethodGet, dataplaneConfigsPath+query, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var list dataplaneConfigList
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	return list
+}
+
+func projectIDs(configs []routing.DataplaneConfig) []string {
+	ids := []string{}
+	for _, cfg := range configs {
+		ids = append(ids, cfg.ProjectID)
+	}
+	return ids
+}
+
+func TestDataplaneConfig_List(t *testing.T) {
+	handler, routingStore, _ := setupDataplaneTest(t)
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 12, 0, 0, 0, time.UTC) }
+	for _, cfg := range []routing.DataplaneConfig{
+		{ProjectID: "project-c", Enabled: true, TargetBucket: "bucket-c", UpdatedAt: day(3), UpdatedBy: "alice"},
+		{ProjectID: "project-a", Enabled: true, TargetBucket: "bucket-a", Filter: `event.action != "read"`, UpdatedAt: day(1), UpdatedBy: "alice"},
+		{ProjectID: "project-b", Enabled: false, TargetBucket: "bucket-b", UpdatedAt: day(2), UpdatedBy: "bob"},
+		{ProjectID: "project-d", Enabled: false, TargetBucket: "bucket-d", UpdatedAt: day(4), UpdatedBy: "bob"},
+	} {
+		require.NoError(t, routingStore.Upsert(t.Context(), cfg))
+	}
+	require.NoError(t, routingStore.UpsertSink(t.Context(), routing.Sink{ProjectID: "project-b", Name: "dr", Enabled: true, TargetBucket: "shared-dr", UpdatedAt: day(2)}))
+
+	list := listDataplaneConfigs(t, handler, "")
+	assert.Equal(t, []string{"project-a", "project-b", "project-c", "project-d"}, projectIDs(list.Configs))
+	assert.Equal(t, 4, list.Total)
+	assert.Len(t, list.Configs[1].Sinks, 2)
+
+	for query, expected := range map[string][]string{
+		// project-b routes into its enabled dr sink, although its default sink is disabled
+		"?enabled=true":                        {"project-a", "project-b", "project-c"},
+		"?enabled=false":                       {"project-d"},
+		"?target_bucket=shared-dr":             {"project-b"},
+		"?target_bucket=bucket-c":              {"project-c"},
+		"?updated_by=alice":                    {"project-a", "project-c"},
+		"?updated_at=gte:2026-10-02T12:00:00Z": {"project-b", "project-c", "project-d"},
+		"?updated_at=gt:2026-10-01T12:00:00Z,lt:2026-10-03T12:00:00Z": {"project-b"},
+		"?enabled=true&updated_by=bob":                                {"project-b"},
+		"?enabled=false&updated_by=alice":                             {},
+	} {
+		assert.Equal(t, expected, projectIDs(listDataplaneConfigs(t, handler, query).Configs), query)
+	}
+
+	// pagination
+	list = listDataplaneConfigs(t, handler, "?enabled=true&limit=1")
+	assert.Equal(t, []string{"project-a"}, projectIDs(list.Configs))
+	assert.Equal(t, 3, list.Total)
+	assert.Equal(t, "http://example.com"+dataplaneConfigsPath+"?enabled=true&limit=1&offset=1", list.NextURL)
+	list = listDataplaneConfigs(t, handler, "?enabled=true&limit=1&offset=2")
+	assert.Equal(t, []string{"project-c"}, projectIDs(list.Configs))
+	assert.Empty(t, list.NextURL)
+	assert.Equal(t, "http://example.com"+dataplaneConfigsPath+"?enabled=true&limit=1&offset=1", list.PrevURL)
+
+	for _, query := range []string{"?enabled=maybe", "?updated_at=after:2026-10-01T00:00:00Z", "?updated_at=gte:yesterday", "?limit=0", "?limit=1001", "?offset=-1", "?format=ndjson"} {
+		rec := dataplaneRequest(t, handler, http.MethodGet, dataplaneConfigsPath+query, nil)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
+
+func TestDataplaneConfig_ListCSV(t *testing.T) {
+	handler, routingStore, _ := setupDataplaneTest(t)
+	updatedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, routingStore.Upsert(t.Context(), routing.DataplaneConfig{ProjectID: "project-a", Enabled: true, TargetBucket: "bucket-a", Filter: `event.action != "read"`, RetentionDays: 30, GraceMinutes: 60, RateLimit: 100, UpdatedAt: updatedAt, UpdatedBy: "alice"}))
+	require.NoError(t, routingStore.UpsertSink(t.Context(), routing.Sink{ProjectID: "project-a", Name: "dr", Enabled: true, TargetBucket: "dr-bucket", UpdatedAt: updatedAt, UpdatedBy: "alice"}))
+	require.NoError(t, routingStore.UpsertSink(t.Context(), routing.Sink{ProjectID: "project-a", Name: "old", TargetBucket: "old-bucket", UpdatedAt: updatedAt, UpdatedBy: "alice"}))
+	require.NoError(t, routingStore.Upsert(t.Context(), routing.DataplaneConfig{ProjectID: "project-b", UpdatedAt: updatedAt, UpdatedBy: "bob"}))
+	// values that spreadsheets would run as formulas are neutralized
+	require.NoError(t, routingStore.Upsert(t.Context(), routing.DataplaneConfig{ProjectID: "project-c", Filter: `-1 < 0`, UpdatedAt: updatedAt, UpdatedBy: "=HYPERLINK()"}))
+
+	// limit and offset do not apply to the export
+	req := httptest.NewRequest(http.MethodGet, dataplaneConfigsPath+"?limit=1&offset=1", http.NoBody)
+	req.Header.Set("X-Auth-Token", "something")
+	req.Header.Set("Accept", "text/csv")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), "hermes-dataplane-configs-")
+
+	records, err := csv.NewReader(strings.NewReader(rec.Body.String())).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"project_id", "enabled", "target_bucket", "enabled_sinks", "filter", "retention_days", "grace_minutes", "rate_limit", "version", "updated_at", "updated_by"},
+		{"project-a", "true", "bucket-a", "default=bucket-a dr=dr-bucket", `event.action != "read"`, "30", "60", "100", "3", "2026-10-01T12:00:00Z", "alice"},
+		{"project-b", "false", "", "", "", "0", "0", "0", "1", "2026-10-01T12:00:00Z", "bob"},
+		{"project-c", "false", "", "", "'-1 < 0", "0", "0", "0", "1", "2026-10-01T12:00:00Z", "'=HYPERLINK()"},
+	}, records)
+}
@@ -0,0 +1,252 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/sapcc/go-bits/audittools"
+	"github.com/sapcc/go-bits/logg"
+	"github.com/sapcc/go-bits/respondwith"
+
+	"github.com/sapcc/hermes/pkg/routing"
+)
+
+// patchAction is the CADF action of PATCH .../dataplane-config.
+const patchAction cadf.Action = cadf.UpdateAction + "/patch"
+
+// dataplaneConfigPatch is a JSON merge patch (RFC 7396) of the fields of
+// dataplaneConfigRequest. Fields that are absent from the patch are nil;
+// fields that are null in the patch point to the zero value.
+type dataplaneConfigPatch struct {
+	Enabled       *bool
+	TargetBucket  *string
+	Filter        *string
+	RetentionDays *int
+	GraceMinutes  *int
+	RateLimit     *int
+}
+
+// readOnlyDataplaneConfigFields are the fields of routing.DataplaneConfig that
+// GET returns, but that cannot be patched.
+var readOnlyDataplaneConfigFields = []string{"project_id", "sinks", "version", "updated_at", "updated_by"}
+
+// parseDataplaneConfigPatch decodes a merge patch for a dataplane config.
+func parseDataplaneConfigPatch(buf []byte) (dataplaneConfigPatch, error) {
+	var (
+		patch  dataplaneConfigPatch
+		fields map[string]json.RawMessage
+	)
+	if err := json.Unmarshal(buf, &fields); err != nil || fields == nil {
+		return patch, errors.New("merge patch must be a JSON object")
+	}
+	var err error
+	for _, key := range slices.Sorted(maps.Keys(fields)) {
+		switch {
+		case key == "enabled":
+			patch.Enabled, err = mergePatchValue[bool](fields[key])
+		case key == "target_bucket":
+			patch.TargetBucket, err = mergePatchValue[string](fields[key])
+		case key == "filter":
+			patch.Filter, err = mergePatchValue[string](fields[key])
+		case key == "retention_days":
+			patch.RetentionDays, err = mergePatchValue[int](fields[key])
+		case key == "grace_minutes":
+			patch.GraceMinutes, err = mergePatchValue[int](fields[key])
+		case key == "rate_limit":
+			patch.RateLimit, err = mergePatchValue[int](fields[key])
+		case slices.Contains(readOnlyDataplaneConfigFields, key):
+			return patch, fmt.Errorf("field %q cannot be changed", key)
+		default:
+			return patch, fmt.Errorf("unknown field %q", key)
+		}
+		if err != nil {
+			return patch, fmt.Errorf("invalid value for field %q: %w", key, err)
+		}
+	}
+	return patch, nil
+}
+
+// mergePatchValue decodes one field of a merge patch. null removes the field,
+// which resets it to its zero value.
+func mergePatchValue[T any](raw json.RawMessage) (*T, error) {
+	var value T
+	if string(raw) != "null" {
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, err
+		}
+	}
+	return &value, nil
+}
+
+// applyTo merges the patch into cfg.
+func (patch dataplaneConfigPatch) applyTo(cfg *routing.DataplaneConfig) {
+	if patch.Enabled != nil {
+		cfg.Enabled = *patch.Enabled
+	}
+	if patch.TargetBucket != nil {
+		cfg.TargetBucket = *patch.TargetBucket
+	}
+	if patch.Filter != nil {
+		cfg.Filter = *patch.Filter
+	}
+	if patch.RetentionDays != nil {
+		cfg.RetentionDays = *patch.RetentionDays
+	}
+	if patch.GraceMinutes != nil {
+		cfg.GraceMinutes = *patch.GraceMinutes
+	}
+	if patch.RateLimit != nil {
+		cfg.RateLimit = *patch.RateLimit
+	}
+}
+
+// changes reports whether the patch changes any field of cfg.
+func (patch dataplaneConfigPatch) changes(cfg routing.DataplaneConfig) bool {
+	patched := cfg
+	patch.applyTo(&patched)
+	return patched.Enabled != cfg.Enabled || patched.TargetBucket != cfg.TargetBucket ||
+		patched.Filter != cfg.Filter || patched.RetentionDays != cfg.RetentionDays ||
+		patched.GraceMinutes != cfg.GraceMinutes || patched.RateLimit != cfg.RateLimit
+}
+
+// errPatchUnchanged aborts the store transaction of a patch that changes nothing.
+var errPatchUnchanged = errors.New("merge patch does not change the config")
+
+// PatchDataplaneConfig handles PATCH /v1/projects/{project_id}/dataplane-config.
+// Applies a JSON merge patch to the stored config (or the default config) and
+// validates the result like PUT. Returns 200 with the saved document.
+// If-Match and If-None-Match: * make the write conditional, like for PUT.
+// An audit event is emitted for every attempt — successful or not — except
+// for patches that change nothing, which return the config without writing.
+func (p *v1Provider) PatchDataplaneConfig(res http.ResponseWriter, req *http.Request) {
+	projectID := mux.Vars(req)["project_id"]
+	token, ok := p.authDataplaneConfig(res, req, projectID)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	userID := token.Context.Auth["user_id"]
+	if userID == "" {
+		http.Error(res, "token missing user identity", http.StatusUnauthorized)
+		return
+	}
+	recordAttempt := func(reasonCode int, cfg *routing.DataplaneConfig) {
+		target := routing.DataplaneConfig{ProjectID: projectID, UpdatedBy: userID}
+		if cfg != nil {
+			target = *cfg
+		}
+		p.auditor.Record(audittools.Event{
+			Time:       now,
+			Request:    req,
+			User:       token,
+			ReasonCode: reasonCode,
+			Action:     patchAction,
+			Target:     target,
+		})
+	}
+
+	pre, status, err := parseDataplanePrecondition(req)
+	if err != nil {
+		http.Error(res, err.Error(), status)
+		recordAttempt(status, nil)
+		return
+	}
+	if ct := req.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/merge-patch+json") {
+		http.Error(res, "Content-Type must be application/merge-patch+json", http.StatusUnsupportedMediaType)
+		recordAttempt(http.StatusUnsupportedMediaType, nil)
+		return
+	}
+	// Body size cap: 64 KiB, like for PUT
+	buf, err := io.ReadAll(http.MaxBytesReader(res, req.Body, 64*1024))
+	if err != nil {
+		http.Error(res, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		recordAttempt(http.StatusBadRequest, nil)
+		return
+	}
+	patch, err := parseDataplaneConfigPatch(buf)
+	if err != nil {
+		http.Error(res, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		recordAttempt(http.StatusBadRequest, nil)
+		return
+	}
+
+	// The bucket is verified before the transaction, so that the project is
+	// not locked while the object storage is queried.
+	verified := patch.Enabled != nil || patch.TargetBucket != nil
+	current := routing.DefaultDataplaneConfig(projectID)
+	if verified {
+		current, ok = p.getDataplaneConfigOrDefault(res, req, projectID)
+		if !ok {
+			recordAttempt(http.StatusInternalServerError, nil)
+			return
+		}
+		patched := current
+		patch.applyTo(&patched)
+		if status, err := p.verifyTargetBucket(req, current, routing.DefaultSinkName, patched.Enabled, patched.TargetBucket); err != nil {
+			http.Error(res, err.Error(), status)
+			recordAttempt(status, nil)
+			return
+		}
+	}
+
+	// The patch is applied within the store transaction, so that it cannot
+	// overwrite a concurrent change to the fields that it does not mention.
+	var (
+		rejectStatus int
+		rejectErr    error
+		unchanged    routing.DataplaneConfig
+	)
+	cfg, err := p.routingStore.Update(req.Context(), projectID, pre, func(cfg *routing.DataplaneConfig) error {
+		if !patch.changes(*cfg) {
+			unchanged = *cfg
+			return errPatchUnchanged
+		}
+		if verified && sinkChanged(current, *cfg, routing.DefaultSinkName) {
+			// the verification above relied on the sink that was replaced since
+			return routing.ErrPreconditionFailed
+		}
+		patch.applyTo(cfg)
+		cfg.UpdatedAt, cfg.UpdatedBy = now, userID
+		rejectStatus, rejectErr = validateDataplaneConfig(*cfg)
+		return rejectErr
+	})
+	switch {
+	case errors.Is(err, errPatchUnchanged):
+		if unchanged.Version > 0 {
+			res.Header().Set("ETag", dataplaneETag(unchanged.Version))
+		}
+		ReturnESJSON(res, http.StatusOK, unchanged)
+		return
+	case rejectErr != nil:
+		http.Error(res, rejectErr.Error(), rejectStatus)
+		recordAttempt(rejectStatus, nil)
+		return
+	case errors.Is(err, routing.ErrPreconditionFailed):
+		http.Error(res, "dataplane-config was modified concurrently", http.StatusPreconditionFailed)
+		recordAttempt(http.StatusPreconditionFailed, nil)
+		return
+	case err != nil:
+		logg.Error("dataplane-config PATCH: storage error for project %s: %s", projectID, err)
+		respondwith.ObfuscatedErrorText(res, err)
+		recordAttempt(http.StatusInternalServerError, nil)
+		return
+	}
+
+	logg.Info("dataplane-config PATCH: project=%s enabled=%v updated_by=%s", projectID, cfg.Enabled, userID)
+	recordAttempt(http.StatusOK, cfg)
+	res.Header().Set("ETag", dataplaneETag(cfg.Version))
+	ReturnESJSON(res, http.StatusOK, cfg)
+}
@@ -0,0 +1,128 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapcc/hermes/pkg/routing"
+)
+
+// patchJSON issues a merge patch against dataplaneConfigPath.
+func patchJSON(t *testing.T, handler http.Handler, body string, headers map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPatch, dataplaneConfigPath, strings.NewReader(body))
+	req.Header.Set("X-Auth-Token", "something")
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestDataplaneConfig_Patch(t *testing.T) {
+	handler, routingStore, auditor := setupDataplaneTest(t)
+
+	// without a stored config, the patch applies to the default config
+	rec := patchJSON(t, handler, `{"enabled": true}`, nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "target_bucket is required when enabled is true")
+	rec = patchJSON(t, handler, `{"enabled": true, "target_bucket": "bucket-1", "filter": "event.action != \"read\""}`, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, `"1"`, rec.Header().Get("ETag"))
+
+	// toggling routing off keeps the bucket and the filter
+	rec = patchJSON(t, handler, `{"enabled": false}`, map[string]string{"If-Match": `"1"`})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	cfg := getDataplaneConfig(t, handler)
+	assert.False(t, cfg.Enabled)
+	assert.Equal(t, "bucket-1", cfg.TargetBucket)
+	assert.Equal(t, `event.action != "read"`, cfg.Filter)
+	assert.Equal(t, int64(2), cfg.Version)
+
+	// null removes a field
+	rec = patchJSON(t, handler, `{"filter": null}`, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	stored, err := routingStore.Get(t.Context(), testProjectID)
+	require.NoError(t, err)
+	assert.Empty(t, stored.Filter)
+	assert.Equal(t, "bucket-1", stored.TargetBucket)
+
+	// the result is validated like for PUT
+	require.NoError(t, routingStore.UpsertSink(t.Context(), routing.Sink{ProjectID: testProjectID, Name: "dr", TargetBucket: "dr-bucket"}))
+	auditor.IgnoreEventsUntilNow()
+	for _, tc := range []struct {
+		body   string
+		status int
+	}{
+		{`{"target_bucket": "my--bucket"}`, http.StatusBadRequest},
+		{`{"filter": "event.action"}`, http.StatusBadRequest},
+		{`{"target_bucket": "dr-bucket"}`, http.StatusConflict},
+		{`{"enabled": "yes"}`, http.StatusBadRequest},
+		{`{"sinks": []}`, http.StatusBadRequest},
+		{`{"extra_field": true}`, http.StatusBadRequest},
+		{`[]`, http.StatusBadRequest},
+	} {
+		rec = patchJSON(t, handler, tc.body, nil)
+		assert.Equal(t, tc.status, rec.Code, tc.body)
+	}
+	rec = patchJSON(t, handler, `{"enabled": true}`, map[string]string{"If-Match": `"1"`})
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	req := httptest.NewRequest(http.MethodPatch, dataplaneConfigPath, strings.NewReader(`{"enabled": true}`))
+	req.Header.Set("X-Auth-Token", "something")
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+
+	// nothing was written, and every attempt was audited with the PATCH action
+	stored, err = routingStore.Get(t.Context(), testProjectID)
+	require.NoError(t, err)
+	assert.Equal(t, "bucket-1", stored.TargetBucket)
+	events := auditor.RecordedEvents()
+	require.Len(t, events, 9)
+	for _, event := range events {
+		assert.Equal(t, patchAction, event.Action)
+	}
+	assert.Equal(t, "412", events[7].Reason.ReasonCode)
+}
+
+func TestDataplaneConfig_PatchUnchanged(t *testing.T) {
+	handler, routingStore, auditor := setupDataplaneTest(t)
+	rec := patchJSON(t, handler, `{"enabled": true, "target_bucket": "bucket-1"}`, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	auditor.IgnoreEventsUntilNow()
+	latest, err := routingStore.LatestChange(t.Context())
+	require.NoError(t, err)
+
+	// patches that change nothing return the stored config without writing it
+	for _, body := range []string{`{}`, `{"enabled": true}`, `{"filter": null}`} {
+		rec = patchJSON(t, handler, body, map[string]string{"If-Match": `"1"`})
+		require.Equal(t, http.StatusOK, rec.Code, body)
+		assert.Equal(t, `"1"`, rec.Header().Get("ETag"), body)
+		var cfg routing.DataplaneConfig
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &cfg))
+		assert.Equal(t, "bucket-1", cfg.TargetBucket, body)
+	}
+	_, total, err := routingStore.History(t.Context(), testProjectID, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	changes, err := routingStore.Changes(t.Context(), latest, 10)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+	assert.Empty(t, auditor.RecordedEvents())
+
+	// the precondition is still checked
+	rec = patchJSON(t, handler, `{}`, map[string]string{"If-Match": `"2"`})
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+}
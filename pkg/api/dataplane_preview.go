@@ -0,0 +1,134 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/gorilla/mux"
+	"github.com/sapcc/go-bits/logg"
+	"github.com/sapcc/go-bits/respondwith"
+
+	"github.com/sapcc/hermes/pkg/hermes"
+	"github.com/sapcc/hermes/pkg/routing/filter"
+)
+
+const (
+	// defaultPreviewLimit and maxPreviewLimit bound the number of events that
+	// POST .../dataplane-config/preview evaluates.
+	defaultPreviewLimit = 100
+	maxPreviewLimit     = 1000
+)
+
+// dataplanePreviewRequest is the shape accepted on POST .../dataplane-config/preview.
+type dataplanePreviewRequest struct {
+	// Filter defaults to the stored filter of the project.
+	Filter string `json:"filter"`
+	Limit  int    `json:"limit"`
+}
+
+// dataplanePreview is the response of POST .../dataplane-config/preview.
+type dataplanePreview struct {
+	Filter    string `json:"filter"`
+	Evaluated int    `json:"evaluated"`
+	Matched   int    `json:"matched"`
+	// Errors counts the events for which the filter could not be evaluated.
+	// These count as matched, like in log-router.
+	Errors     int                   `json:"errors"`
+	FirstError string                `json:"first_error,omitempty"`
+	Actions    []dataplanePreviewRow `json:"actions"`
+}
+
+// dataplanePreviewRow breaks down a dataplanePreview by CADF action.
+type dataplanePreviewRow struct {
+	Action    string `json:"action"`
+	Evaluated int    `json:"evaluated"`
+	Matched   int    `json:"matched"`
+}
+
+// PreviewDataplaneFilter handles POST /v1/projects/{project_id}/dataplane-config/preview.
+// It evaluates a filter expression against the latest events of the project
+// and reports how many of them would be routed. Nothing is stored, so no
+// audit event is emitted.
+func (p *v1Provider) PreviewDataplaneFilter(res http.ResponseWriter, req *http.Request) {
+	projectID := mux.Vars(req)["project_id"]
+	token, ok := p.authDataplaneConfig(res, req, projectID)
+	if !ok {
+		return
+	}
+
+	var body dataplanePreviewRequest
+	if status, err := decodeJSONBody(res, req, &body); err != nil {
+		http.Error(res, err.Error(), status)
+		return
+	}
+	maxLimit := min(maxPreviewLimit, int(p.storage.MaxLimit())) //nolint:gosec // MaxLimit is far below MaxInt
+	switch {
+	case body.Limit == 0:
+		body.Limit = min(defaultPreviewLimit, maxLimit)
+	case body.Limit < 0 || body.Limit > maxLimit:
+		http.Error(res, fmt.Sprintf("limit must be between 1 and %d", maxLimit), http.StatusBadRequest)
+		return
+	}
+	if body.Filter == "" {
+		cfg, ok := p.getDataplaneConfigOrDefault(res, req, projectID)
+		if !ok {
+			return
+		}
+		body.Filter = cfg.Filter
+	}
+	if body.Filter == "" {
+		http.Error(res, "filter is required when the project has no stored filter", http.StatusBadRequest)
+		return
+	}
+	program, err := filter.Compile(body.Filter)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// the filter sees the events like the caller does, so that it cannot probe redacted fields
+	events, _, err := hermes.GetEventPayloads(req.Context(), &hermes.EventFilter{
+		Limit: uint(body.Limit),
+		Sort:  []hermes.FieldOrder{{Fieldname: "time", Order: "desc"}},
+	}, projectID, p.storage, p.eventView(token))
+	if respondwith.ErrorText(res, err) {
+		logg.Error("dataplane-config preview: cannot get events for project %s: %s", projectID, err)
+		storageErrorsCounter.Add(1)
+		return
+	}
+
+	preview := dataplanePreview{Filter: body.Filter, Actions: []dataplanePreviewRow{}}
+	rows := make(map[string]*dataplanePreviewRow)
+	for _, event := range events {
+		matched, err := program.Match(event)
+		if err != nil {
+			preview.Errors++
+			if preview.FirstError == "" {
+				preview.FirstError = fmt.Sprintf("event %s: %s", event.ID, err.Error())
+			}
+		}
+		row, ok := rows[string(event.Action)]
+		if !ok {
+			row = &dataplanePreviewRow{Action: string(event.Action)}
+			rows[row.Action] = row
+		}
+		preview.Evaluated++
+		row.Evaluated++
+		if matched {
+			preview.Matched++
+			row.Matched++
+		}
+	}
+	for _, row := range rows {
+		preview.Actions = append(preview.Actions, *row)
+	}
+	slices.SortFunc(preview.Actions, func(a, b dataplanePreviewRow) int {
+		return cmp.Or(cmp.Compare(b.Evaluated, a.Evaluated), cmp.Compare(a.Action, b.Action))
+	})
+	ReturnESJSON(res, http.StatusOK, preview)
+}
@@ -0,0 +1,138 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/sapcc/go-bits/audittools"
+	"github.com/sapcc/go-bits/httpapi"
+	"github.com/sapcc/go-bits/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapcc/hermes/pkg/hermes"
+	"github.com/sapcc/hermes/pkg/routing"
+	"github.com/sapcc/hermes/pkg/storage"
+)
+
+const dataplanePreviewPath = dataplaneConfigPath + "/preview"
+
+func TestDataplaneConfig_Filter(t *testing.T) {
+	handler, routingStore, auditor := setupDataplaneTest(t)
+
+	rec := putJSON(t, handler, map[string]any{"enabled": true, "target_bucket": "my-audit-bucket", "filter": `event.action ==`})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = putJSON(t, handler, map[string]any{"enabled": true, "target_bucket": "my-audit-bucket", "filter": `event.action`})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "filter must evaluate to a bool")
+
+	rec = putJSON(t, handler, map[string]any{"enabled": true, "target_bucket": "my-audit-bucket", "filter": `event.action != "read"`})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	cfg, err := routingStore.Get(t.Context(), testProjectID)
+	require.NoError(t, err)
+	assert.Equal(t, `event.action != "read"`, cfg.Filter)
+
+	// the filter is part of the audited payload
+	events := auditor.RecordedEvents()
+	require.Len(t, events, 3)
+	assert.Equal(t, cfg.Render().Attachments, events[2].Target.Attachments)
+}
+
+func TestPreviewDataplaneFilter(t *testing.T) {
+	events := storage.NewMemory(500)
+	for _, e := range []struct {
+		id, time string
+		action   cadf.Action
+	}{
+		{"a", "2026-01-01T00:00:01Z", "read"},
+		{"b", "2026-01-01T00:00:02Z", "read"},
+		{"c", "2026-01-01T00:00:03Z", "read"},
+		{"d", "2026-01-01T00:00:04Z", "update"},
+		{"e", "2026-01-01T00:00:05Z", "delete"},
+	} {
+		events.Add([]string{testProjectID}, cadf.Event{ID: e.id, EventTime: e.time, Action: e.action,
+			Target: cadf.Resource{TypeURI: "storage/object", ID: "object-" + e.id}})
+	}
+	events.Add([]string{"other-project"}, cadf.Event{ID: "z", EventTime: "2026-01-01T00:00:09Z", Action: "read"})
+	validator := mock.NewValidator(mock.NewEnforcer(), map[string]string{"project_id": testProjectID, "user_id": "user-abc"})
+	routingStore := routing.NewMock()
+	prometheus.DefaultRegisterer = prometheus.NewPedanticRegistry()
+	handler := httpapi.Compose(NewV1API(validator, events, routingStore, audittools.NewNullAuditor()))
+
+	rec := dataplaneRequest(t, handler, http.MethodPost, dataplanePreviewPath, map[string]any{"filter": `event.action != "read"`})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{
+		"filter": "event.action != \"read\"",
+		"evaluated": 5,
+		"matched": 2,
+		"errors": 0,
+		"actions": [
+			{"action": "read", "evaluated": 3, "matched": 0},
+			{"action": "delete", "evaluated": 1, "matched": 1},
+			{"action": "update", "evaluated": 1, "matched": 1}
+		]
+	}`, rec.Body.String())
+
+	// without a filter in the request, the stored one is used; evaluation errors count as matches
+	rec = dataplaneRequest(t, handler, http.MethodPost, dataplanePreviewPath, map[string]any{"limit": 2})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	require.NoError(t, routingStore.Upsert(t.Context(), routing.DataplaneConfig{ProjectID: testProjectID, Filter: `event.target.nmae == ""`}))
+	rec = dataplaneRequest(t, handler, http.MethodPost, dataplanePreviewPath, map[string]any{"limit": 2})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var preview dataplanePreview
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &preview))
+	assert.Equal(t, 2, preview.Evaluated)
+	assert.Equal(t, 2, preview.Matched)
+	assert.Equal(t, 2, preview.Errors)
+	assert.Equal(t, "event e: no such key: nmae", preview.FirstError)
+
+	for _, body := range []map[string]any{
+		{"filter": `event.action ==`},
+		{"filter": `true`, "limit": 1001},
+		{"filter": `true`, "unknown": 1},
+	} {
+		rec = dataplaneRequest(t, handler, http.MethodPost, dataplanePreviewPath, body)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, "%v", body)
+	}
+	rec = dataplaneRequest(t, handler, http.MethodPost, "/v1/projects/other-project/dataplane-config/preview", map[string]any{"filter": `true`})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestPreviewDataplaneFilter_Redaction(t *testing.T) {
+	// the redactor only applies to events caused by cloud operators
+	events := storage.NewMemory(10)
+	events.Add([]string{testProjectID}, cadf.Event{ID: "a", EventTime: "2026-01-01T00:00:01Z", Action: "update",
+		Initiator: cadf.Resource{TypeURI: "service/security/account/user", ID: "operator", ProjectID: "operator-project",
+			Host: &cadf.Host{Address: "10.0.0.1"}}})
+	redactor, err := hermes.NewRedactor(hermes.Operators{ProjectIDs: []string{"operator-project"}}, []hermes.RedactionRule{
+		{Field: "initiator.host.address", Policy: "event:show_initiator_host", Action: hermes.RedactRemove},
+	})
+	require.NoError(t, err)
+
+	preview := func(enforcer *mock.Enforcer) dataplanePreview {
+		t.Helper()
+		validator := mock.NewValidator(enforcer, map[string]string{"project_id": testProjectID, "user_id": "user-abc"})
+		prometheus.DefaultRegisterer = prometheus.NewPedanticRegistry()
+		handler := httpapi.Compose(NewV1API(validator, events, routing.NewMock(), audittools.NewNullAuditor(), WithRedactor(redactor)))
+		rec := dataplaneRequest(t, handler, http.MethodPost, dataplanePreviewPath, map[string]any{"filter": `event.initiator.host.address == "10.0.0.1"`})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var result dataplanePreview
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+		return result
+	}
+
+	assert.Equal(t, 1, preview(mock.NewEnforcer()).Matched)
+
+	// callers that may not see a field cannot probe it with a filter
+	enforcer := mock.NewEnforcer()
+	enforcer.Forbid("event:show_initiator_host")
+	result := preview(enforcer)
+	assert.Equal(t, 1, result.Evaluated)
+	assert.Equal(t, 0, result.Matched)
+}
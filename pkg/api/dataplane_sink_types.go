@@ -0,0 +1,146 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sapcc/hermes/pkg/routing"
+)
+
+const (
+	// maxSwiftContainerNameLength is the limit of Swift for container names, in bytes.
+	maxSwiftContainerNameLength = 256
+	// maxSinkURLLength bounds the URL of webhook sinks.
+	maxSinkURLLength = 2048
+	// maxKafkaBrokers bounds the bootstrap brokers of Kafka sinks.
+	maxKafkaBrokers = 10
+)
+
+// kafkaTopicPattern enforces the topic name rules of Kafka. The names "."
+// and ".." are rejected separately.
+var kafkaTopicPattern = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,249}$`)
+
+// sinkValidators check the type-specific fields of a sink, one validator per
+// routing.SinkType. Each validator may assume that sink.Type is its type.
+var sinkValidators = map[routing.SinkType]func(sink routing.Sink, settings routing.SinkSettings) error{
+	routing.SinkS3:      validateS3Sink,
+	routing.SinkSwift:   validateSwiftSink,
+	routing.SinkWebhook: validateWebhookSink,
+	routing.SinkKafka:   validateKafkaSink,
+}
+
+// validateSinkType checks the type and the type-specific fields of a sink.
+func validateSinkType(sink routing.Sink) error {
+	validate, ok := sinkValidators[sink.Type]
+	if !ok {
+		types := make([]string, len(routing.SinkTypes))
+		for idx, t := range routing.SinkTypes {
+			types[idx] = strconv.Quote(string(t))
+		}
+		return fmt.Errorf("type must be one of %s", strings.Join(types, ", "))
+	}
+	var settings routing.SinkSettings
+	if sink.Settings != nil {
+		settings = *sink.Settings
+	}
+	return validate(sink, settings)
+}
+
+// rejectSinkFields returns an error if any field of the sink other than the
+// allowed ones is set, since it would be ignored for the sink's type.
+func rejectSinkFields(sink routing.Sink, settings routing.SinkSettings, allowed ...string) error {
+	for _, field := range []struct {
+		name string
+		set  bool
+	}{
+		{"target_bucket", sink.TargetBucket != ""},
+		{"settings.container", settings.Container != ""},
+		{"settings.url", settings.URL != ""},
+		{"settings.brokers", len(settings.Brokers) > 0},
+		{"settings.topic", settings.Topic != ""},
+	} {
+		if field.set && !slices.Contains(allowed, field.name) {
+			return fmt.Errorf("%s cannot be set for sinks of type %q", field.name, sink.Type)
+		}
+	}
+	return nil
+}
+
+func validateS3Sink(sink routing.Sink, settings routing.SinkSettings) error {
+	if err := rejectSinkFields(sink, settings, "target_bucket"); err != nil {
+		return err
+	}
+	if sink.TargetBucket == "" {
+		return errors.New("target_bucket is required")
+	}
+	return validateTargetBucket(sink.TargetBucket)
+}
+
+// validateSwiftSink checks the container name against the rules of Swift,
+// which allow more names than s3BucketNamePattern.
+func validateSwiftSink(sink routing.Sink, settings routing.SinkSettings) error {
+	if err := rejectSinkFields(sink, settings, "settings.container"); err != nil {
+		return err
+	}
+	name := settings.Container
+	if name == "" {
+		return errors.New("settings.container is required for sinks of type \"swift\"")
+	}
+	if len(name) > maxSwiftContainerNameLength || !utf8.ValidString(name) ||
+		strings.Contains(name, "/") || strings.ContainsFunc(name, unicode.IsControl) {
+		return fmt.Errorf("settings.container must be at most %d bytes of UTF-8 without slashes or control characters", maxSwiftContainerNameLength)
+	}
+	return nil
+}
+
+func validateWebhookSink(sink routing.Sink, settings routing.SinkSettings) error {
+	if err := rejectSinkFields(sink, settings, "settings.url"); err != nil {
+		return err
+	}
+	u, err := url.Parse(settings.URL)
+	if err != nil || u.Scheme != "https" || u.Host == "" || len(settings.URL) > maxSinkURLLength {
+		return fmt.Errorf("settings.url must be an absolute https URL of at most %d characters", maxSinkURLLength)
+	}
+	// the URL is shown to everyone who can read the config and in audit events
+	if u.User != nil {
+		return errors.New("settings.url must not contain credentials")
+	}
+	return nil
+}
+
+func validateKafkaSink(sink routing.Sink, settings routing.SinkSettings) error {
+	if err := rejectSinkFields(sink, settings, "settings.brokers", "settings.topic"); err != nil {
+		return err
+	}
+	if len(settings.Brokers) == 0 || len(settings.Brokers) > maxKafkaBrokers {
+		return fmt.Errorf("settings.brokers must contain between 1 and %d brokers", maxKafkaBrokers)
+	}
+	for _, broker := range settings.Brokers {
+		host, port, err := net.SplitHostPort(broker)
+		if err == nil {
+			var portNumber uint64
+			portNumber, err = strconv.ParseUint(port, 10, 16)
+			if err == nil && (host == "" || portNumber == 0) {
+				err = errors.New("missing host or port")
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("settings.brokers must contain host:port pairs, got %q", broker)
+		}
+	}
+	if !kafkaTopicPattern.MatchString(settings.Topic) || settings.Topic == "." || settings.Topic == ".." {
+		return errors.New("settings.topic must be 1–249 chars, letters/digits/dots/underscores/hyphens only")
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/sapcc/go-bits/audittools"
+	"github.com/sapcc/go-bits/logg"
+	"github.com/sapcc/go-bits/respondwith"
+
+	"github.com/sapcc/hermes/pkg/routing"
+)
+
+// maxSinksPerProject caps the number of routing sinks of a single project,
+// since log-router writes every event once per enabled sink.
+const maxSinksPerProject = 10
+
+// sinkNamePattern restricts sink names to what fits into URL paths and the
+// dataplane_sinks.name column without escaping.
+var sinkNamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,30}[a-z0-9])?$`)
+
+// dataplaneSinkRequest is the shape accepted on PUT .../sinks/{sink_name}.
+// Without type, the sink is an S3 sink, as before there were other types.
+type dataplaneSinkRequest struct {
+	Enabled      bool                  `json:"enabled"`
+	Type         routing.SinkType      `json:"type"`
+	TargetBucket string                `json:"target_bucket"`
+	Settings     *routing.SinkSettings `json:"settings"`
+}
+
+type dataplaneSinkList struct {
+	Sinks []routing.Sink `json:"sinks"`
+}
+
+// ListDataplaneSinks handles GET /v1/projects/{project_id}/dataplane-config/sinks.
+func (p *v1Provider) ListDataplaneSinks(res http.ResponseWriter, req *http.Request) {
+	projectID := mux.Vars(req)["project_id"]
+	if _, ok := p.authDataplaneConfig(res, req, projectID); !ok {
+		return
+	}
+	cfg, ok := p.getDataplaneConfigOrDefault(res, req, projectID)
+	if !ok {
+		return
+	}
+	ReturnESJSON(res, http.StatusOK, dataplaneSinkList{Sinks: cfg.Sinks})
+}
+
+// GetDataplaneSink handles GET /v1/projects/{project_id}/dataplane-config/sinks/{sink_name}.
+func (p *v1Provider) GetDataplaneSink(res http.ResponseWriter, req *http.Request) {
+	projectID := mux.Vars(req)["project_id"]
+	if _, ok := p.authDataplaneConfig(res, req, projectID); !ok {
+		return
+	}
+	cfg, ok := p.getDataplaneConfigOrDefault(res, req, projectID)
+	if !ok {
+		return
+	}
+	sink := cfg.Sink(mux.Vars(req)["sink_name"])
+	if sink == nil {
+		http.Error(res, "sink not found", http.StatusNotFound)
+		return
+	}
+	ReturnESJSON(res, http.StatusOK, sink)
+}
+
+// PutDataplaneSink handles PUT /v1/projects/{project_id}/dataplane-config/sinks/{sink_name}.
+// Idempotent create-or-replace. Returns 200 with the saved sink.
+// An audit event is emitted for every attempt — successful or not.
+func (p *v1Provider) PutDataplaneSink(res http.ResponseWriter, req *http.Request) {
+	projectID := mux.Vars(req)["project_id"]
+	token, ok := p.authDataplaneConfig(res, req, projectID)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	userID := token.Context.Auth["user_id"]
+	if userID == "" {
+		http.Error(res, "token missing user identity", http.StatusUnauthorized)
+		return
+	}
+	sink := routing.Sink{
+		ProjectID: projectID,
+		Name:      mux.Vars(req)["sink_name"],
+		UpdatedAt: now,
+		UpdatedBy: userID,
+	}
+	cfg, ok := p.getDataplaneConfigOrDefault(res, req, projectID)
+	if !ok {
+		return
+	}
+	action := cadf.UpdateAction
+	if cfg.Sink(sink.Name) == nil {
+		action = cadf.CreateAction
+	}
+	recordAttempt := func(reasonCode int) {
+		p.auditor.Record(audittools.Event{
+			Time:       now,
+			Request:    req,
+			User:       token,
+			ReasonCode: reasonCode,
+			Action:     action,
+			Target:     sink,
+		})
+	}
+
+	var body dataplaneSinkRequest
+	status, err := decodeJSONBody(res, req, &body)
+	if err == nil {
+		sink.Enabled, sink.Type, sink.TargetBucket, sink.Settings = body.Enabled, body.Type, body.TargetBucket, body.Settings
+		if sink.Type == "" {
+			sink.Type = routing.SinkS3
+		}
+		status, err = validateDataplaneSink(cfg, sink)
+	}
+	if err == nil {
+		status, err = p.verifyTargetBucket(req, cfg, sink.Name, sink.Enabled, sink.Bucket())
+	}
+	if err != nil {
+		http.Error(res, err.Error(), status)
+		recordAttempt(status)
+		return
+	}
+
+	if err := p.routingStore.UpsertSink(req.Context(), sink); err != nil {
+		logg.Error("dataplane-config sinks PUT: storage error for project %s: %s", projectID, err)
+		respondwith.ObfuscatedErrorText(res, err)
+		recordAttempt(http.StatusInternalServerError)
+		return
+	}
+
+	logg.Info("dataplane-config sinks PUT: project=%s sink=%s enabled=%v updated_by=%s", projectID, sink.Name, sink.Enabled, userID)
+	recordAttempt(http.StatusOK)
+	ReturnESJSON(res, http.StatusOK, sink)
+}
+
+// DeleteDataplaneSink handles DELETE /v1/projects/{project_id}/dataplane-config/sinks/{sink_name}.
+// Idempotent — deleting a non-existent sink returns 204 without an audit event,
+// like DeleteDataplaneConfig.
+func (p *v1Provider) DeleteDataplaneSink(res http.ResponseWriter, req *http.Request) {
+	projectID := mux.Vars(req)["project_id"]
+	token, ok := p.authDataplaneConfig(res, req, projectID)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	userID := token.Context.Auth["user_id"]
+	if userID == "" {
+		http.Error(res, "token missing user identity", http.StatusUnauthorized)
+		return
+	}
+	sink := routing.Sink{ProjectID: projectID, Name: mux.Vars(req)["sink_name"], UpdatedAt: now, UpdatedBy: userID}
+	recordAttempt := func(reasonCode int) {
+		p.auditor.Record(audittools.Event{
+			Time:       now,
+			Request:    req,
+			User:       token,
+			ReasonCode: reasonCode,
+			Action:     cadf.DeleteAction,
+			Target:     sink,
+		})
+	}
+
+	deleted, err := p.routingStore.DeleteSink(req.Context(), projectID, sink.Name, userID)
+	if err != nil {
+		logg.Error("dataplane-config sinks DELETE: storage error for project %s: %s", projectID, err)
+		respondwith.ObfuscatedErrorText(res, err)
+		recordAttempt(http.StatusInternalServerError)
+		return
+	}
+	if deleted {
+		recordAttempt(http.StatusNoContent)
+	}
+
+	logg.Info("dataplane-config sinks DELETE: project=%s sink=%s updated_by=%s deleted=%v", projectID, sink.Name, userID, deleted)
+	res.WriteHeader(http.StatusNoContent)
+}
+
+// getDataplaneConfigOrDefault reads the config of a project, or the default
+// config if it has none. On error, it writes the error response and returns false.
+func (p *v1Provider) getDataplaneConfigOrDefault(res http.ResponseWriter, req *http.Request, projectID string) (routing.DataplaneConfig, bool) {
+	cfg, err := p.routingStore.Get(req.Context(), projectID)
+	if errors.Is(err, routing.ErrNotFound) {
+		return routing.DefaultDataplaneConfig(projectID), true
+	}
+	if err != nil {
+		logg.Error("dataplane-config: storage error for project %s: %s", projectID, err)
+		respondwith.ObfuscatedErrorText(res, err)
+		return routing.DataplaneConfig{}, false
+	}
+	return *cfg, true
+}
+
+// validateDataplaneSink checks a sink before it is written into cfg.
+// On error, it returns the HTTP status to respond with.
+func validateDataplaneSink(cfg routing.DataplaneConfig, sink routing.Sink) (int, error) {
+	if !sinkNamePattern.MatchString(sink.Name) {
+		return http.StatusBadRequest, errors.New("sink name must be 1–32 chars, lowercase letters/digits/hyphens only, start and end with letter or digit")
+	}
+	if err := validateSinkType(sink); err != nil {
+		return http.StatusBadRequest, err
+	}
+	// the default sink is mirrored into the target_bucket of the config
+	if sink.Name == routing.DefaultSinkName && sink.Type != routing.SinkS3 {
+		return http.StatusBadRequest, fmt.Errorf("the sink %q must have type %q", routing.DefaultSinkName, routing.SinkS3)
+	}
+	if other := sinkUsingDestination(cfg, sink.Destination(), sink.Name); other != "" {
+		if sink.Type == routing.SinkS3 {
+			return http.StatusConflict, fmt.Errorf("target_bucket is already used by sink %q", other)
+		}
+		return http.StatusConflict, fmt.Errorf("the destination of this sink is already used by sink %q", other)
+	}
+	if cfg.Sink(sink.Name) == nil && len(cfg.Sinks) >= maxSinksPerProject {
+		return http.StatusConflict, fmt.Errorf("a project can have at most %d sinks", maxSinksPerProject)
+	}
+	return http.StatusOK, nil
+}
+
+// sinkUsingDestination returns the name of the sink of cfg other than
+// exceptName that routes into destination (see routing.Sink.Destination), or
+// "" if there is none. Routing into the same destination twice would deliver
+// every event twice.
+func sinkUsingDestination(cfg routing.DataplaneConfig, destination, exceptName string) string {
+	for _, s := range cfg.Sinks {
+		if s.Name != exceptName && s.Destination() == destination {
+			return s.Name
+		}
+	}
+	return ""
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"math"
 	"net/http"
+	"net/url"
 	"reflect"
 	"strconv"
 	"strings"
@@ -17,12 +18,14 @@ import (
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 
+	"github.com/sapcc/go-api-declarations/cadf"
 	"github.com/sapcc/go-bits/errext"
 	"github.com/sapcc/go-bits/gopherpolicy"
 	"github.com/sapcc/go-bits/logg"
 	"github.com/sapcc/go-bits/respondwith"
 
 	"github.com/sapcc/hermes/pkg/hermes"
+	"github.com/sapcc/hermes/pkg/ocsf"
 	"github.com/sapcc/hermes/pkg/storage"
 )
 
@@ -42,21 +45,185 @@ func (p *v1Provider) ListEvents(res http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	filter, err := parseEventFilter(req.Form)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	format, err := negotiateFormat(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	columns, err := parseCSVColumns(req.FormValue("columns"))
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	view, err := p.enrichedEventView(req, token)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logg.Debug("api.ListEvents: call hermes.GetEvents()")
+	indexID, err := getIndexID(token, req, res)
+	if err != nil {
+		return
+	}
+	if format == formatOCSF {
+		p.listEventsOCSF(res, req, filter, indexID, view)
+		return
+	}
+	events, total, err := hermes.GetEvents(req.Context(), filter, indexID, p.storage, view)
+	if respondwith.ErrorText(res, err) {
+		logg.Error("api.ListEvents: error calling hermes.GetEvents(): %s", err.Error())
+
+		// Check for UnmarshalTypeError and log it
+		if unmarshalErr, ok := errext.As[*json.UnmarshalTypeError](err); ok {
+			logg.Error("api.ListEvents: JSON unmarshal error: Type=%v, Value=%v, Offset=%v, Struct=%v, Field=%v",
+				unmarshalErr.Type, unmarshalErr.Value, unmarshalErr.Offset, unmarshalErr.Struct, unmarshalErr.Field)
+		}
+		storageErrorsCounter.Add(1)
+		return
+	}
+
+	eventList := EventList{Events: events, Total: total}
+	addPaginationURLs(req, &eventList, filter)
+	if format == formatCSV || format == formatNDJSON {
+		writeEventList(res, format, columns, eventList)
+		return
+	}
+	ReturnESJSON(res, http.StatusOK, eventList)
+}
+
+// enrichedEventView builds the hermes.EventView for the caller, with the
+// enrichments requested in the enrich query parameter (a comma-separated list).
+// Enrichments are opt-in because they cost extra requests to Keystone.
+func (p *v1Provider) enrichedEventView(req *http.Request, token *gopherpolicy.Token) (*hermes.EventView, error) {
+	view := p.eventView(token)
+	for value := range strings.SplitSeq(req.FormValue("enrich"), ",") {
+		switch strings.TrimSpace(value) {
+		case "":
+		case "names":
+			if p.nameResolver == nil {
+				return nil, errors.New("enrich=names is not available without Keystone")
+			}
+			view.Names = p.nameResolver
+		default:
+			return nil, fmt.Errorf("unknown value %q for enrich, expected \"names\"", value)
+		}
+	}
+	return view, nil
+}
+
+// ocsfEventList is the response body of GET /v1/events in the OCSF format.
+type ocsfEventList struct {
+	NextURL string        `json:"next,omitempty"`
+	PrevURL string        `json:"previous,omitempty"`
+	Events  []*ocsf.Event `json:"events"`
+	Total   int           `json:"total"`
+}
+
+// listEventsOCSF responds to GET /v1/events with the matching events rendered
+// as OCSF. Unlike the CADF list, this contains all data of each event.
+func (p *v1Provider) listEventsOCSF(res http.ResponseWriter, req *http.Request, filter *hermes.EventFilter, indexID string, view *hermes.EventView) {
+	payloads, total, err := hermes.GetEventPayloads(req.Context(), filter, indexID, p.storage, view)
+	if respondwith.ErrorText(res, err) {
+		logg.Error("api.ListEvents: error calling hermes.GetEventPayloads(): %s", err.Error())
+		storageErrorsCounter.Add(1)
+		return
+	}
+	events := make([]*ocsf.Event, len(payloads))
+	for i, payload := range payloads {
+		events[i], err = ocsf.FromCADF(payload)
+		if respondwith.ErrorText(res, err) {
+			return
+		}
+	}
+
+	// reuse the pagination of the CADF list
+	eventList := EventList{Total: total}
+	addPaginationURLs(req, &eventList, filter)
+	ReturnESJSON(res, http.StatusOK, ocsfEventList{
+		NextURL: eventList.NextURL,
+		PrevURL: eventList.PrevURL,
+		Events:  events,
+		Total:   total,
+	})
+}
+
+// writeEventList writes an event list as CSV or NDJSON. Since these formats
+// have no envelope, the total and the pagination URLs go into the X-Total-Count
+// and Link headers.
+func writeEventList(res http.ResponseWriter, format responseFormat, columns []csvColumn, eventList EventList) {
+	res.Header().Set("X-Total-Count", strconv.Itoa(eventList.Total))
+	var links []string
+	if eventList.NextURL != "" {
+		links = append(links, fmt.Sprintf("<%s>; rel=\"next\"", eventList.NextURL))
+	}
+	if eventList.PrevURL != "" {
+		links = append(links, fmt.Sprintf("<%s>; rel=\"prev\"", eventList.PrevURL))
+	}
+	if len(links) > 0 {
+		res.Header().Set("Link", strings.Join(links, ", "))
+	}
+	startListResponse(res, format)
+
+	writer, err := newEventWriter(res, format, columns)
+	for _, event := range eventList.Events {
+		if err != nil {
+			break
+		}
+		err = writer.WriteEvent(event)
+	}
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err != nil {
+		logg.Error("api.ListEvents: cannot write %s response: %s", format, err.Error())
+	}
+}
+
+// addPaginationURLs sets the next and previous URLs of an event list. The URLs
+// repeat the request's query parameters with an updated offset.
+func addPaginationURLs(req *http.Request, eventList *EventList, filter *hermes.EventFilter) {
+	// What protocol to use for PrevURL and NextURL?
+	protocol := getProtocol(req)
+
+	if eventList.Total >= 0 && filter.Offset+filter.Limit < uint(eventList.Total) {
+		nextOffset := filter.Offset + filter.Limit
+
+		// Update the offset in the query parameters and construct the NextURL
+		req.Form.Set("offset", strconv.FormatUint(uint64(nextOffset), 10))
+		eventList.NextURL = fmt.Sprintf("%s://%s%s?%s", protocol, req.Host, req.URL.Path, req.Form.Encode())
+	}
+
+	if filter.Offset >= filter.Limit {
+		prevOffset := filter.Offset - filter.Limit
+
+		// Update the offset in the query parameters and construct the PrevURL
+		req.Form.Set("offset", strconv.FormatUint(uint64(prevOffset), 10))
+		eventList.PrevURL = fmt.Sprintf("%s://%s%s?%s", protocol, req.Host, req.URL.Path, req.Form.Encode())
+	}
+}
+
+// parseEventFilter parses the query parameters of GET /v1/events into a hermes.EventFilter.
+// Handlers pass req.Form, which AuthHandler has already populated.
+func parseEventFilter(query url.Values) (*hermes.EventFilter, error) {
 	// QueryParams
-	offsetStr := req.FormValue("offset")
-	limitStr := req.FormValue("limit")
+	offsetStr := query.Get("offset")
+	limitStr := query.Get("limit")
 
 	var offset, limit uint = 0, 10 // Default values
 
 	if offsetStr != "" {
 		parsedOffset, err := strconv.ParseUint(offsetStr, 10, 32)
 		if err != nil {
-			http.Error(res, "Invalid offset value", http.StatusBadRequest)
-			return
+			return nil, errors.New("Invalid offset value") //nolint:staticcheck // existing API error text
 		}
 		if parsedOffset > math.MaxInt32 {
-			http.Error(res, fmt.Sprintf("Offset must be less than or equal to %d", math.MaxInt32), http.StatusBadRequest)
-			return
+			return nil, fmt.Errorf("Offset must be less than or equal to %d", math.MaxInt32) //nolint:staticcheck // existing API error text
 		}
 		offset = uint(parsedOffset)
 	}
@@ -64,12 +231,10 @@ func (p *v1Provider) ListEvents(res http.ResponseWriter, req *http.Request) {
 	if limitStr != "" {
 		parsedLimit, err := strconv.ParseUint(limitStr, 10, 32)
 		if err != nil {
-			http.Error(res, "Invalid limit value", http.StatusBadRequest)
-			return
+			return nil, errors.New("Invalid limit value") //nolint:staticcheck // existing API error text
 		}
 		if parsedLimit > math.MaxInt32 {
-			http.Error(res, fmt.Sprintf("Limit must be less than or equal to %d", math.MaxInt32), http.StatusBadRequest)
-			return
+			return nil, fmt.Errorf("Limit must be less than or equal to %d", math.MaxInt32) //nolint:staticcheck // existing API error text
 		}
 		limit = uint(parsedLimit)
 	}
@@ -101,15 +266,14 @@ func (p *v1Provider) ListEvents(res http.ResponseWriter, req *http.Request) {
 	// Parse the sort query string.
 	// The sort parameter is a comma-separated list of "field:direction" pairs.
 	// Example: "time:desc,initiator_name:asc"
-	sortParam := req.FormValue("sort")
+	sortParam := query.Get("sort")
 
 	for sortElement := range strings.SplitSeq(sortParam, ",") {
 		sortElement = strings.TrimSpace(sortElement)
 
 		if sortElement == "" {
 			if strings.TrimSpace(sortParam) != "" {
-				http.Error(res, "Invalid sort parameter", http.StatusBadRequest)
-				return
+				return nil, errors.New("Invalid sort parameter") //nolint:staticcheck // existing API error text
 			}
 			continue
 		}
@@ -117,29 +281,22 @@ func (p *v1Provider) ListEvents(res http.ResponseWriter, req *http.Request) {
 		sortfield, direction, foundColon := strings.Cut(sortElement, ":")
 
 		if sortfield == "" {
-			http.Error(res, "Invalid sort parameter: field name cannot be empty", http.StatusBadRequest)
-			return
+			return nil, errors.New("Invalid sort parameter: field name cannot be empty") //nolint:staticcheck // existing API error text
 		}
 
 		if !validSortTopics[sortfield] {
-			err := fmt.Errorf("not a valid topic: %s, valid topics: %v", sortfield, reflect.ValueOf(validSortTopics).MapKeys())
-			http.Error(res, err.Error(), http.StatusBadRequest)
-			return
+			return nil, fmt.Errorf("not a valid topic: %s, valid topics: %v", sortfield, reflect.ValueOf(validSortTopics).MapKeys())
 		}
 
 		defsortorder := "asc"
 		if foundColon {
 			sortDirection := strings.TrimSpace(direction)
 			if sortDirection == "" {
-				err := fmt.Errorf("sort direction for field %s cannot be empty", sortfield)
-				http.Error(res, err.Error(), http.StatusBadRequest)
-				return
+				return nil, fmt.Errorf("sort direction for field %s cannot be empty", sortfield)
 			}
 
 			if !validSortDirection[sortDirection] {
-				err := fmt.Errorf("sort direction %s is invalid, must be asc or desc", sortDirection)
-				http.Error(res, err.Error(), http.StatusBadRequest)
-				return
+				return nil, fmt.Errorf("sort direction %s is invalid, must be asc or desc", sortDirection)
 			}
 			defsortorder = sortDirection
 		}
@@ -149,51 +306,69 @@ func (p *v1Provider) ListEvents(res http.ResponseWriter, req *http.Request) {
 	}
 
 	// Next, parse the elements of the time range filter
+	timeRange, err := parseTimeFilter(query.Get("time"))
+	if err != nil {
+		return nil, err
+	}
+
+	details := query.Has("details")
+
+	return &hermes.EventFilter{
+		ObserverType:  query.Get("observer_type") + query.Get("source"),
+		TargetType:    query.Get("target_type") + query.Get("resource_type"),
+		TargetID:      query.Get("target_id"),
+		InitiatorID:   query.Get("initiator_id") + query.Get("user_name"),
+		InitiatorType: query.Get("initiator_type"),
+		InitiatorName: query.Get("initiator_name"),
+		Action:        query.Get("action") + query.Get("event_type"),
+		Outcome:       query.Get("outcome"),
+		Search:        query.Get("search"),
+		RequestPath:   query.Get("request_path"),
+		Time:          timeRange,
+		Offset:        offset,
+		Limit:         limit,
+		Sort:          sortSpec,
+		Details:       details,
+	}, nil
+}
+
+// parseTimeFilter parses the time parameter of GET /v1/events, a comma-separated
+// list of "operator:timestamp" elements, into the map used by hermes.EventFilter.
+func parseTimeFilter(timeParam string) (map[string]string, error) {
 	timeRange := make(map[string]string)
 	validOperators := map[string]bool{"lt": true, "lte": true, "gt": true, "gte": true}
 
-	timeParam := req.FormValue("time")
 	for timeElement := range strings.SplitSeq(timeParam, ",") {
 		timeElement = strings.TrimSpace(timeElement)
 
 		if timeElement == "" {
-			if strings.TrimSpace(req.FormValue("time")) != "" {
-				http.Error(res, "Invalid time parameter: an element is empty", http.StatusBadRequest)
-				return
+			if strings.TrimSpace(timeParam) != "" {
+				return nil, errors.New("Invalid time parameter: an element is empty") //nolint:staticcheck // existing API error text
 			}
 			continue
 		}
 
 		operator, value, foundColon := strings.Cut(timeElement, ":")
 		if operator == "" {
-			http.Error(res, "Invalid time parameter: operator cannot be empty", http.StatusBadRequest)
-			return
+			return nil, errors.New("Invalid time parameter: operator cannot be empty") //nolint:staticcheck // existing API error text
 		}
 
 		if !validOperators[operator] {
-			err := fmt.Errorf("time operator %s is not valid. Must be lt, lte, gt or gte", operator)
-			http.Error(res, err.Error(), http.StatusBadRequest)
-			return
+			return nil, fmt.Errorf("time operator %s is not valid. Must be lt, lte, gt or gte", operator)
 		}
 
 		if !foundColon {
-			err := fmt.Errorf("time operator %s missing :<timestamp>", operator)
-			http.Error(res, err.Error(), http.StatusBadRequest)
-			return
+			return nil, fmt.Errorf("time operator %s missing :<timestamp>", operator)
 		}
 
 		timeStr := strings.TrimSpace(value)
 		if timeStr == "" {
-			err := fmt.Errorf("time operator %s missing :<timestamp>", operator)
-			http.Error(res, err.Error(), http.StatusBadRequest)
-			return
+			return nil, fmt.Errorf("time operator %s missing :<timestamp>", operator)
 		}
 
 		_, exists := timeRange[operator]
 		if exists {
-			err := fmt.Errorf("time operator %s can only occur once", operator)
-			http.Error(res, err.Error(), http.StatusBadRequest)
-			return
+			return nil, fmt.Errorf("time operator %s can only occur once", operator)
 		}
 
 		validTimeFormats := []string{time.RFC3339, "2006-01-02T15:04:05-0700", "2006-01-02T15:04:05"}
@@ -207,74 +382,11 @@ func (p *v1Provider) ListEvents(res http.ResponseWriter, req *http.Request) {
 			}
 		}
 		if !isValidTimeFormat {
-			err := fmt.Errorf("invalid time format: %s", timeStr)
-			http.Error(res, err.Error(), http.StatusBadRequest)
-			return
+			return nil, fmt.Errorf("invalid time format: %s", timeStr)
 		}
 		timeRange[operator] = timeStr
 	}
-
-	details := req.Form.Has("details")
-
-	logg.Debug("api.ListEvents: Create filter")
-	filter := hermes.EventFilter{
-		ObserverType:  req.FormValue("observer_type") + req.FormValue("source"),
-		TargetType:    req.FormValue("target_type") + req.FormValue("resource_type"),
-		TargetID:      req.FormValue("target_id"),
-		InitiatorID:   req.FormValue("initiator_id") + req.FormValue("user_name"),
-		InitiatorType: req.FormValue("initiator_type"),
-		InitiatorName: req.FormValue("initiator_name"),
-		Action:        req.FormValue("action") + req.FormValue("event_type"),
-		Outcome:       req.FormValue("outcome"),
-		Search:        req.FormValue("search"),
-		RequestPath:   req.FormValue("request_path"),
-		Time:          timeRange,
-		Offset:        offset,
-		Limit:         limit,
-		Sort:          sortSpec,
-		Details:       details,
-	}
-
-	logg.Debug("api.ListEvents: call hermes.GetEvents()")
-	indexID, err := getIndexID(token, req, res)
-	if err != nil {
-		return
-	}
-	events, total, err := hermes.GetEvents(req.Context(), &filter, indexID, p.storage)
-	if respondwith.ErrorText(res, err) {
-		logg.Error("api.ListEvents: error calling hermes.GetEvents(): %s", err.Error())
-
-		// Check for UnmarshalTypeError and log it
-		if unmarshalErr, ok := errext.As[*json.UnmarshalTypeError](err); ok {
-			logg.Error("api.ListEvents: JSON unmarshal error: Type=%v, Value=%v, Offset=%v, Struct=%v, Field=%v",
-				unmarshalErr.Type, unmarshalErr.Value, unmarshalErr.Offset, unmarshalErr.Struct, unmarshalErr.Field)
-		}
-		storageErrorsCounter.Add(1)
-		return
-	}
-
-	eventList := EventList{Events: events, Total: total}
-
-	// What protocol to use for PrevURL and NextURL?
-	protocol := getProtocol(req)
-
-	if total >= 0 && filter.Offset+filter.Limit < uint(total) {
-		nextOffset := filter.Offset + filter.Limit
-
-		// Update the offset in the query parameters and construct the NextURL
-		req.Form.Set("offset", strconv.FormatUint(uint64(nextOffset), 10))
-		eventList.NextURL = fmt.Sprintf("%s://%s%s?%s", protocol, req.Host, req.URL.Path, req.Form.Encode())
-	}
-
-	if filter.Offset >= filter.Limit {
-		prevOffset := filter.Offset - filter.Limit
-
-		// Update the offset in the query parameters and construct the PrevURL
-		req.Form.Set("offset", strconv.FormatUint(uint64(prevOffset), 10))
-		eventList.PrevURL = fmt.Sprintf("%s://%s%s?%s", protocol, req.Host, req.URL.Path, req.Form.Encode())
-	}
-
-	ReturnESJSON(res, http.StatusOK, eventList)
+	return timeRange, nil
 }
 
 // GetEvent handles GET /v1/events/:event_id.
@@ -294,13 +406,35 @@ func (p *v1Provider) GetEventDetails(res http.ResponseWriter, req *http.Request)
 		http.Error(res, "Invalid event ID format", http.StatusBadRequest)
 		return
 	}
+	format, err := negotiateEventFormat(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	view, err := p.enrichedEventView(req, token)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	withDiff := false
+	if value := req.FormValue("diff"); value != "" {
+		withDiff, err = strconv.ParseBool(value)
+		if err != nil {
+			http.Error(res, fmt.Sprintf("invalid value %q for diff, expected true or false", value), http.StatusBadRequest)
+			return
+		}
+	}
+	if withDiff && format == formatOCSF {
+		http.Error(res, "diff is not available in the OCSF format", http.StatusBadRequest)
+		return
+	}
 
 	indexID, err := getIndexID(token, req, res)
 	if err != nil {
 		return
 	}
 
-	event, err := hermes.GetEvent(req.Context(), eventID, indexID, p.storage)
+	event, err := hermes.GetEvent(req.Context(), eventID, indexID, p.storage, view)
 
 	if respondwith.ErrorText(res, err) {
 		logg.Error("error getting events from Storage: %s", err)
@@ -312,7 +446,33 @@ func (p *v1Provider) GetEventDetails(res http.ResponseWriter, req *http.Request)
 		http.Error(res, err.Error(), http.StatusNotFound)
 		return
 	}
-	ReturnESJSON(res, http.StatusOK, event)
+	if format == formatOCSF {
+		rendered, err := ocsf.FromCADF(event)
+		if respondwith.ErrorText(res, err) {
+			return
+		}
+		ReturnESJSON(res, http.StatusOK, rendered)
+		return
+	}
+	details := eventDetails{Event: event, Summary: p.summarizer.Summarize(event)}
+	if withDiff {
+		details.Diff, err = hermes.GetEventDiff(req.Context(), event, indexID, p.storage, view)
+		if respondwith.ErrorText(res, err) {
+			logg.Error("could not find previous state of event %s: %s", eventID, err)
+			storageErrorsCounter.Add(1)
+			return
+		}
+	}
+	ReturnESJSON(res, http.StatusOK, details)
+}
+
+// eventDetails is the response body of GET /v1/events/:event_id: the CADF
+// event with the summary that also appears in event lists, and with
+// ?diff=true, the changes compared to the previous state of the target.
+type eventDetails struct {
+	*cadf.Event
+	Summary string            `json:"summary,omitempty"`
+	Diff    *hermes.EventDiff `json:"diff,omitempty"`
 }
 
 // GetAttributes handles GET /v1/attributes/:attribute_name
@@ -330,6 +490,15 @@ func (p *v1Provider) GetAttributes(res http.ResponseWriter, req *http.Request) {
 		logg.Debug("attribute_name empty")
 		return
 	}
+	format, err := negotiateFormat(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if format == formatOCSF {
+		http.Error(res, "format ocsf is only available for events", http.StatusBadRequest)
+		return
+	}
 	maxdepth, _ := strconv.ParseUint(req.FormValue("max_depth"), 10, 32) //nolint:errcheck
 	limit, _ := strconv.ParseUint(req.FormValue("limit"), 10, 32)        //nolint:errcheck
 
@@ -374,6 +543,23 @@ func (p *v1Provider) GetAttributes(res http.ResponseWriter, req *http.Request) {
 		http.Error(res, err.Error(), http.StatusNotFound)
 		return
 	}
+	if format == formatCSV || format == formatNDJSON {
+		startListResponse(res, format)
+		writer, err := newRecordWriter(res, format, []string{"value"})
+		for _, value := range attribute {
+			if err != nil {
+				break
+			}
+			err = writer.WriteValue(value)
+		}
+		if err == nil {
+			err = writer.Flush()
+		}
+		if err != nil {
+			logg.Error("api.GetAttributes: cannot write %s response: %s", format, err.Error())
+		}
+		return
+	}
 	ReturnESJSON(res, http.StatusOK, attribute)
 }
 
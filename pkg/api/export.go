@@ -0,0 +1,202 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/sapcc/go-bits/logg"
+	"github.com/sapcc/go-bits/respondwith"
+
+	"github.com/sapcc/hermes/pkg/export"
+	"github.com/sapcc/hermes/pkg/hermes"
+)
+
+// exportPublicKey is the response body of GET /v1/export/public-key.
+type exportPublicKey struct {
+	Algorithm string `json:"algorithm"`
+	KeyID     string `json:"key_id"`
+	PublicKey string `json:"public_key"`
+}
+
+// exportPageSize is the number of events fetched from storage per request
+// for unsigned CSV and NDJSON exports.
+const exportPageSize = 1000
+
+// ExportEvents handles GET /v1/export.
+// It accepts the filter parameters of GET /v1/events and responds with a signed
+// bundle of all matching events, or with an unsigned CSV or NDJSON stream of
+// them if the client asks for one of those formats.
+func (p *v1Provider) ExportEvents(res http.ResponseWriter, req *http.Request) {
+	token, ok := p.AuthHandler(res, req, "event:export")
+	if !ok {
+		return
+	}
+
+	filter, err := parseEventFilter(req.Form)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	format, err := negotiateFormat(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	columns, err := parseCSVColumns(req.FormValue("columns"))
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if format == formatJSON || format == formatOCSF {
+		http.Error(res, "exports are available as signed bundle, CSV or NDJSON", http.StatusBadRequest)
+		return
+	}
+	if format == formatDefault && p.exporter == nil {
+		http.Error(res, "event export is not enabled on this server", http.StatusNotImplemented)
+		return
+	}
+
+	indexID, err := getIndexID(token, req, res)
+	if err != nil {
+		return
+	}
+	if format != formatDefault {
+		view, err := p.enrichedEventView(req, token)
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusBadRequest)
+			return
+		}
+		p.streamExport(res, req, filter, indexID, view, format, columns)
+		return
+	}
+
+	fileName := fmt.Sprintf("hermes-export-%s-%s.tar.gz", indexID, time.Now().UTC().Format("20060102T150405Z"))
+	bundle := &downloadWriter{res: res, contentType: "application/gzip", fileName: fileName}
+	_, err = p.exporter.Write(req.Context(), bundle, filter, indexID, p.eventView(token))
+	switch {
+	case err == nil:
+		// the bundle was written completely
+	case bundle.started:
+		// the status code was sent already; the client receives a bundle
+		// without manifest, which fails verification
+		logg.Error("could not export events for %s: %s", indexID, err)
+		storageErrorsCounter.Add(1)
+	case errors.Is(err, hermes.ErrTooManyEvents):
+		http.Error(res, err.Error(), http.StatusBadRequest)
+	default:
+		logg.Error("could not export events for %s: %s", indexID, err)
+		storageErrorsCounter.Add(1)
+		respondwith.ObfuscatedErrorText(res, err)
+	}
+}
+
+// downloadWriter sends the headers of a file download along with the first
+// write, so that errors before it can still be answered with an error status.
+type downloadWriter struct {
+	res         http.ResponseWriter
+	contentType string
+	fileName    string
+	started     bool
+}
+
+// Write implements the io.Writer interface.
+func (w *downloadWriter) Write(buf []byte) (int, error) {
+	if !w.started {
+		w.res.Header().Set("Content-Type", w.contentType)
+		w.res.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", w.fileName))
+		w.res.WriteHeader(http.StatusOK)
+		w.started = true
+	}
+	return w.res.Write(buf)
+}
+
+// streamExport writes all events matching the filter as CSV or NDJSON. NDJSON
+// lines carry the full CADF payload, CSV rows the columns of GET /v1/events.
+// The response is written while paging through storage, so the status code
+// is only sent once the first page was read successfully. Errors after that
+// can only be logged, and the client receives an incomplete file.
+func (p *v1Provider) streamExport(res http.ResponseWriter, req *http.Request, filter *hermes.EventFilter, indexID string, view *hermes.EventView, format responseFormat, columns []csvColumn) {
+	if len(filter.Sort) == 0 {
+		filter.Sort = []hermes.FieldOrder{{Fieldname: "time", Order: "asc"}}
+	}
+
+	var writer *recordWriter
+	count, err := hermes.ForEachEvent(req.Context(), filter, indexID, p.storage, view, exportPageSize, func(event *cadf.Event) error {
+		if writer == nil {
+			fileName := fmt.Sprintf("hermes-export-%s-%s.%s", indexID, time.Now().UTC().Format("20060102T150405Z"), format)
+			res.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+			startListResponse(res, format)
+			var err error
+			writer, err = newEventWriter(res, format, columns)
+			if err != nil {
+				return err
+			}
+		}
+		if format == formatNDJSON {
+			return writer.WriteValue(event)
+		}
+		listEvent, err := hermes.NewListEvent(event, false)
+		if err != nil {
+			return err
+		}
+		listEvent.Summary = p.summarizer.Summarize(event)
+		return writer.WriteEvent(listEvent)
+	})
+	if writer == nil {
+		switch {
+		case errors.Is(err, hermes.ErrTooManyEvents):
+			http.Error(res, err.Error(), http.StatusBadRequest)
+		case err != nil:
+			logg.Error("could not export events for %s: %s", indexID, err)
+			storageErrorsCounter.Add(1)
+			respondwith.ObfuscatedErrorText(res, err)
+		default:
+			// no matching events: respond with an empty list
+			startListResponse(res, format)
+			writer, err = newEventWriter(res, format, columns)
+			if err == nil {
+				err = writer.Flush()
+			}
+			if err != nil {
+				logg.Error("could not export events for %s: %s", indexID, err)
+			}
+		}
+		return
+	}
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err != nil {
+		logg.Error("could not export events for %s after %d events: %s", indexID, count, err)
+	}
+}
+
+// GetExportPublicKey handles GET /v1/export/public-key.
+// The key is public, so no token is required. This allows external auditors
+// to obtain it without an account in the cloud.
+func (p *v1Provider) GetExportPublicKey(res http.ResponseWriter, _ *http.Request) {
+	if p.exporter == nil {
+		http.Error(res, "event export is not enabled on this server", http.StatusNotImplemented)
+		return
+	}
+	pub := p.exporter.Signer.PublicKey()
+	pemBytes, err := export.MarshalPublicKeyPEM(pub)
+	if respondwith.ErrorText(res, err) {
+		return
+	}
+	keyID, err := export.KeyID(pub)
+	if respondwith.ErrorText(res, err) {
+		return
+	}
+	ReturnESJSON(res, http.StatusOK, exportPublicKey{
+		Algorithm: "ed25519",
+		KeyID:     keyID,
+		PublicKey: string(pemBytes),
+	})
+}
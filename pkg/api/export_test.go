@@ -0,0 +1,80 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sapcc/go-bits/audittools"
+	"github.com/sapcc/go-bits/httpapi"
+	"github.com/sapcc/go-bits/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapcc/hermes/pkg/export"
+	"github.com/sapcc/hermes/pkg/routing"
+	"github.com/sapcc/hermes/pkg/storage"
+	"github.com/sapcc/hermes/pkg/test"
+)
+
+func TestExport(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	prometheus.DefaultRegisterer = prometheus.NewPedanticRegistry()
+	v1API := NewV1API(mock.NewValidator(mock.NewEnforcer(), nil), storage.Mock{}, routing.NewMock(),
+		audittools.NewNullAuditor(), WithExporter(export.NewExporter(storage.Mock{}, export.NewSigner(priv))))
+	handler := httpapi.Compose(v1API)
+
+	// the public key is available without a token
+	req := httptest.NewRequest(http.MethodGet, "/v1/export/public-key", http.NoBody)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var key exportPublicKey
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &key))
+	assert.Equal(t, "ed25519", key.Algorithm)
+	pub, err := export.ParsePublicKeyPEM([]byte(key.PublicKey))
+	require.NoError(t, err)
+	keyID, err := export.KeyID(pub)
+	require.NoError(t, err)
+	assert.Equal(t, keyID, key.KeyID)
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/export?project_id=b3b70c8271a845709f9a03030e705da7&time=gte:2017-11-01T00:00:00", http.NoBody)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "application/gzip", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), "hermes-export-b3b70c8271a845709f9a03030e705da7-")
+
+	manifest, err := export.Verify(bytes.NewReader(rec.Body.Bytes()), pub)
+	require.NoError(t, err)
+	// the mock storage ignores filters and returns its four static events
+	assert.Equal(t, 4, manifest.EventCount)
+	assert.Equal(t, "b3b70c8271a845709f9a03030e705da7", manifest.TenantID)
+	assert.Equal(t, map[string]string{"gte": "2017-11-01T00:00:00"}, manifest.TimeRange)
+
+	test.APIRequest{
+		Method:           "GET",
+		Path:             "/v1/export?sort=invalidfield",
+		ExpectStatusCode: http.StatusBadRequest,
+	}.Check(t, handler)
+}
+
+func TestExport_NotEnabled(t *testing.T) {
+	router := setupTest(t)
+	for _, path := range []string{"/v1/export", "/v1/export/public-key"} {
+		test.APIRequest{
+			Method:           "GET",
+			Path:             path,
+			ExpectStatusCode: http.StatusNotImplemented,
+		}.Check(t, router)
+	}
+}
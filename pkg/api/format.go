@@ -0,0 +1,250 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sapcc/hermes/pkg/hermes"
+)
+
+// responseFormat is the representation of a list response, selected by
+// negotiateFormat.
+type responseFormat string
+
+const (
+	// formatDefault means that the client did not ask for a specific format.
+	formatDefault responseFormat = ""
+	formatJSON    responseFormat = "json"
+	formatCSV     responseFormat = "csv"
+	formatNDJSON  responseFormat = "ndjson"
+	// formatOCSF renders events in the Open Cybersecurity Schema Framework
+	// instead of CADF. It is only available for events.
+	formatOCSF responseFormat = "ocsf"
+)
+
+// contentType returns the Content-Type header for responses in this format.
+func (f responseFormat) contentType() string {
+	switch f {
+	case formatCSV:
+		return "text/csv; charset=utf-8"
+	case formatNDJSON:
+		return "application/x-ndjson"
+	default:
+		return "application/json"
+	}
+}
+
+// acceptedMediaTypes maps the media types understood in the Accept header to formats.
+var acceptedMediaTypes = map[string]responseFormat{
+	"application/json":     formatJSON,
+	"text/csv":             formatCSV,
+	"application/x-ndjson": formatNDJSON,
+	"application/ndjson":   formatNDJSON,
+	ocsfMediaType:          formatOCSF,
+}
+
+// ocsfMediaType selects OCSF in the Accept header. OCSF does not define a media
+// type, so this is a vendor type. Responses are labeled application/json.
+const ocsfMediaType = "application/vnd.ocsf+json"
+
+// negotiateFormat selects the response format from the format query parameter
+// ("json", "csv", "ndjson" or "ocsf") or, if that is not given, from the Accept header.
+// Unknown media types in the Accept header are ignored, so that browsers and
+// generic clients keep getting the default format.
+func negotiateFormat(req *http.Request) (responseFormat, error) {
+	if param := req.FormValue("format"); param != "" {
+		switch format := responseFormat(param); format {
+		case formatJSON, formatCSV, formatNDJSON, formatOCSF:
+			return format, nil
+		default:
+			return formatDefault, fmt.Errorf("format must be %q, %q, %q or %q", formatJSON, formatCSV, formatNDJSON, formatOCSF)
+		}
+	}
+	return acceptedFormat(req, acceptedMediaTypes), nil
+}
+
+// eventMediaTypes are the media types from acceptedMediaTypes that are
+// available for single events.
+var eventMediaTypes = map[string]responseFormat{
+	"application/json": formatJSON,
+	ocsfMediaType:      formatOCSF,
+}
+
+// negotiateEventFormat is like negotiateFormat, but for single events, which
+// are only available as CADF or OCSF. Other media types in the Accept header
+// are ignored, so that clients that prefer CSV still get the default format.
+func negotiateEventFormat(req *http.Request) (responseFormat, error) {
+	if param := req.FormValue("format"); param != "" {
+		switch format := responseFormat(param); format {
+		case formatJSON, formatOCSF:
+			return format, nil
+		default:
+			return formatDefault, fmt.Errorf("format must be %q or %q for single events", formatJSON, formatOCSF)
+		}
+	}
+	return acceptedFormat(req, eventMediaTypes), nil
+}
+
+// acceptedFormat returns the format of the media type with the highest quality
+// in the Accept header, considering only the given media types.
+func acceptedFormat(req *http.Request, mediaTypes map[string]responseFormat) responseFormat {
+	result := formatDefault
+	bestQuality := 0.0
+	for _, value := range req.Header.Values("Accept") {
+		for element := range strings.SplitSeq(value, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(element))
+			if err != nil {
+				continue
+			}
+			format, ok := mediaTypes[mediaType]
+			if !ok {
+				continue
+			}
+			quality := 1.0
+			if q, err := strconv.ParseFloat(params["q"], 64); err == nil {
+				quality = q
+			}
+			if quality > bestQuality {
+				result, bestQuality = format, quality
+			}
+		}
+	}
+	return result
+}
+
+// csvColumn is a column of the CSV representation of event lists.
+type csvColumn struct {
+	Name  string
+	Value func(*hermes.ListEvent) string
+}
+
+// csvColumns lists all columns in their default order. The names are the JSON
+// field names of hermes.ListEvent, with nested references flattened.
+var csvColumns = []csvColumn{
+	{"id", func(e *hermes.ListEvent) string { return e.ID }},
+	{"eventTime", func(e *hermes.ListEvent) string { return e.Time }},
+	{"action", func(e *hermes.ListEvent) string { return e.Action }},
+	{"outcome", func(e *hermes.ListEvent) string { return e.Outcome }},
+	{"requestPath", func(e *hermes.ListEvent) string { return e.RequestPath }},
+	{"summary", func(e *hermes.ListEvent) string { return e.Summary }},
+	{"initiator.typeURI", func(e *hermes.ListEvent) string { return e.Initiator.TypeURI }},
+	{"initiator.id", func(e *hermes.ListEvent) string { return e.Initiator.ID }},
+	{"initiator.name", func(e *hermes.ListEvent) string { return e.Initiator.Name }},
+	{"target.typeURI", func(e *hermes.ListEvent) string { return e.Target.TypeURI }},
+	{"target.id", func(e *hermes.ListEvent) string { return e.Target.ID }},
+	{"target.name", func(e *hermes.ListEvent) string { return e.Target.Name }},
+	{"observer.typeURI", func(e *hermes.ListEvent) string { return e.Observer.TypeURI }},
+	{"observer.id", func(e *hermes.ListEvent) string { return e.Observer.ID }},
+	{"observer.name", func(e *hermes.ListEvent) string { return e.Observer.Name }},
+}
+
+// parseCSVColumns parses the columns query parameter, a comma-separated list
+// of column names. If it is empty, all columns are returned.
+func parseCSVColumns(param string) ([]csvColumn, error) {
+	if strings.TrimSpace(param) == "" {
+		return csvColumns, nil
+	}
+	var result []csvColumn
+	for name := range strings.SplitSeq(param, ",") {
+		name = strings.TrimSpace(name)
+		idx := slices.IndexFunc(csvColumns, func(c csvColumn) bool { return c.Name == name })
+		if idx < 0 {
+			names := make([]string, len(csvColumns))
+			for i, c := range csvColumns {
+				names[i] = c.Name
+			}
+			return nil, fmt.Errorf("unknown column %q, valid columns: %s", name, strings.Join(names, ", "))
+		}
+		result = append(result, csvColumns[idx])
+	}
+	return result, nil
+}
+
+// csvCell neutralizes values that spreadsheet applications would interpret as
+// formulas, by prefixing them with a single quote. Quoting of separators,
+// quotes and line breaks is done by encoding/csv.
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// recordWriter writes the items of a list response one by one in CSV or
+// NDJSON, so that large responses do not have to be built in memory.
+type recordWriter struct {
+	columns []csvColumn
+	csv     *csv.Writer
+	json    *json.Encoder
+}
+
+// newRecordWriter creates a recordWriter. For CSV, the header row with the
+// given column names is written immediately.
+func newRecordWriter(w io.Writer, format responseFormat, header []string) (*recordWriter, error) {
+	rw := &recordWriter{}
+	if format == formatCSV {
+		rw.csv = csv.NewWriter(w)
+		return rw, rw.csv.Write(header)
+	}
+	rw.json = json.NewEncoder(w)
+	// like ReturnESJSON, keep "&" in URLs readable
+	rw.json.SetEscapeHTML(false)
+	return rw, nil
+}
+
+// newEventWriter creates a recordWriter for hermes.ListEvent items.
+func newEventWriter(w io.Writer, format responseFormat, columns []csvColumn) (*recordWriter, error) {
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = c.Name
+	}
+	rw, err := newRecordWriter(w, format, header)
+	rw.columns = columns
+	return rw, err
+}
+
+// WriteEvent writes one event as a CSV row with the writer's columns, or as a JSON line.
+func (rw *recordWriter) WriteEvent(event *hermes.ListEvent) error {
+	if rw.csv == nil {
+		return rw.json.Encode(event)
+	}
+	row := make([]string, len(rw.columns))
+	for i, c := range rw.columns {
+		row[i] = csvCell(c.Value(event))
+	}
+	return rw.csv.Write(row)
+}
+
+// WriteValue writes an arbitrary value as a JSON line. In CSV, it must be a string.
+func (rw *recordWriter) WriteValue(value any) error {
+	if rw.csv == nil {
+		return rw.json.Encode(value)
+	}
+	return rw.csv.Write([]string{csvCell(fmt.Sprint(value))})
+}
+
+// Flush writes buffered CSV data and reports write errors.
+func (rw *recordWriter) Flush() error {
+	if rw.csv == nil {
+		return nil
+	}
+	rw.csv.Flush()
+	return rw.csv.Error()
+}
+
+// startListResponse writes the response header for a list in CSV or NDJSON.
+func startListResponse(w http.ResponseWriter, format responseFormat) {
+	w.Header().Set("Content-Type", format.contentType())
+	w.Header().Add("Vary", "Accept")
+	w.WriteHeader(http.StatusOK)
+}
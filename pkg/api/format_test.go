@@ -0,0 +1,265 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/sapcc/go-bits/audittools"
+	"github.com/sapcc/go-bits/httpapi"
+	"github.com/sapcc/go-bits/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapcc/hermes/pkg/hermes"
+	"github.com/sapcc/hermes/pkg/routing"
+	"github.com/sapcc/hermes/pkg/storage"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	tt := []struct {
+		query  string
+		accept string
+		expect responseFormat
+	}{
+		{"", "", formatDefault},
+		{"", "*/*", formatDefault},
+		{"", "text/html, application/xhtml+xml", formatDefault},
+		{"", "application/json", formatJSON},
+		{"", "text/csv", formatCSV},
+		{"", "application/x-ndjson", formatNDJSON},
+		{"", "text/csv;q=0.5, application/x-ndjson;q=0.8", formatNDJSON},
+		{"", "text/csv, application/json;q=0.9", formatCSV},
+		{"", "application/vnd.ocsf+json", formatOCSF},
+		{"?format=ndjson", "text/csv", formatNDJSON},
+	}
+	for _, tc := range tt {
+		req := httptest.NewRequest(http.MethodGet, "/v1/events"+tc.query, http.NoBody)
+		if tc.accept != "" {
+			req.Header.Set("Accept", tc.accept)
+		}
+		format, err := negotiateFormat(req)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expect, format, "query %q, Accept %q", tc.query, tc.accept)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/events?format=xml", http.NoBody)
+	_, err := negotiateFormat(req)
+	assert.Error(t, err)
+}
+
+func TestNegotiateEventFormat(t *testing.T) {
+	tt := []struct {
+		query  string
+		accept string
+		expect responseFormat
+	}{
+		{"", "", formatDefault},
+		{"", "text/csv", formatDefault},
+		{"", "application/x-ndjson", formatDefault},
+		{"", "text/csv, application/json;q=0.9", formatJSON},
+		{"", "text/csv, application/vnd.ocsf+json;q=0.5", formatOCSF},
+		{"?format=ocsf", "application/json", formatOCSF},
+	}
+	for _, tc := range tt {
+		req := httptest.NewRequest(http.MethodGet, "/v1/events/a5fe3a49-0d6f-4b1d-8f4f-000000000001"+tc.query, http.NoBody)
+		if tc.accept != "" {
+			req.Header.Set("Accept", tc.accept)
+		}
+		format, err := negotiateEventFormat(req)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expect, format, "query %q, Accept %q", tc.query, tc.accept)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/events/a5fe3a49-0d6f-4b1d-8f4f-000000000001?format=csv", http.NoBody)
+	_, err := negotiateEventFormat(req)
+	assert.Error(t, err)
+}
+
+func TestGetEventDetails_Accept(t *testing.T) {
+	handler := newFormatTest(t)
+	path := "/v1/events/a5fe3a49-0d6f-4b1d-8f4f-000000000001"
+	for _, accept := range []string{"text/csv", "application/x-ndjson", "text/csv, */*;q=0.1"} {
+		rec := getWithAccept(handler, path, accept)
+		require.Equal(t, http.StatusOK, rec.Code, "Accept %q: %s", accept, rec.Body.String())
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		var event cadf.Event
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &event))
+		assert.Equal(t, "a5fe3a49-0d6f-4b1d-8f4f-000000000001", event.ID)
+	}
+
+	rec := getWithAccept(handler, path+"?format=csv", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestCSVCell(t *testing.T) {
+	assert.Equal(t, "alice", csvCell("alice"))
+	assert.Empty(t, csvCell(""))
+	for _, value := range []string{"=1+1", "+1", "-1", "@SUM(A1)", "\tx", "\rx"} {
+		assert.Equal(t, "'"+value, csvCell(value))
+	}
+}
+
+// newFormatTest serves the v1 API with Memory storage holding three events.
+func newFormatTest(t *testing.T, opts ...Option) http.Handler {
+	t.Helper()
+	validator := mock.NewValidator(mock.NewEnforcer(), map[string]string{
+		"project_id": testProjectID,
+		"user_id":    "alice",
+	})
+	prometheus.DefaultRegisterer = prometheus.NewPedanticRegistry()
+	events := storage.NewMemory(100)
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, name := range []string{"alice", "=HYPERLINK(\"http://evil.example\")", "bob, the builder"} {
+		events.Add([]string{testProjectID}, cadf.Event{
+			ID:        "a5fe3a49-0d6f-4b1d-8f4f-00000000000" + string(rune('1'+i)),
+			EventTime: start.Add(time.Duration(i) * time.Minute).Format("2006-01-02T15:04:05.000000+00:00"),
+			Action:    cadf.UpdateAction,
+			Outcome:   cadf.SuccessOutcome,
+			Initiator: cadf.Resource{TypeURI: "service/security/account/user", ID: "user-" + string(rune('1'+i)), Name: name},
+			Target:    cadf.Resource{TypeURI: "compute/server", ID: "server-1"},
+		})
+	}
+	v1API := NewV1API(validator, events, routing.NewMock(), audittools.NewNullAuditor(), opts...)
+	return httpapi.Compose(v1API)
+}
+
+func getWithAccept(handler http.Handler, path, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, http.NoBody)
+	req.Header.Set("X-Auth-Token", "something")
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestListEvents_CSV(t *testing.T) {
+	handler := newFormatTest(t)
+	rec := getWithAccept(handler, "/v1/events?sort=time&limit=1&offset=1&columns=id,initiator.name,target.id", "text/csv")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "3", rec.Header().Get("X-Total-Count"))
+	assert.Contains(t, rec.Header().Get("Link"), `offset=0`)
+	assert.Contains(t, rec.Header().Get("Link"), `rel="prev"`)
+	assert.Contains(t, rec.Header().Get("Link"), `rel="next"`)
+
+	records, err := csv.NewReader(strings.NewReader(rec.Body.String())).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"id", "initiator.name", "target.id"},
+		{"a5fe3a49-0d6f-4b1d-8f4f-000000000002", `'=HYPERLINK("http://evil.example")`, "server-1"},
+	}, records)
+
+	rec = getWithAccept(handler, "/v1/events?format=csv&sort=time&offset=2&columns=initiator.name", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "initiator.name\n\"bob, the builder\"\n", rec.Body.String())
+
+	rec = getWithAccept(handler, "/v1/events?format=csv&columns=id,password", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = getWithAccept(handler, "/v1/events?format=yaml", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestListEvents_NDJSON(t *testing.T) {
+	handler := newFormatTest(t)
+	rec := getWithAccept(handler, "/v1/events?sort=time&limit=2", "application/x-ndjson")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Get("Link"), `rel="next"`)
+
+	lines := strings.Split(strings.TrimSuffix(rec.Body.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+	var event struct {
+		ID        string `json:"id"`
+		Initiator struct {
+			Name string `json:"name"`
+		} `json:"initiator"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &event))
+	assert.Equal(t, "a5fe3a49-0d6f-4b1d-8f4f-000000000001", event.ID)
+	assert.Equal(t, "alice", event.Initiator.Name)
+
+	// JSON stays the default
+	rec = getWithAccept(handler, "/v1/events", "*/*")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"total": 3`)
+}
+
+func TestGetAttributes_Formats(t *testing.T) {
+	handler := newFormatTest(t)
+	rec := getWithAccept(handler, "/v1/attributes/initiator_name?limit=10&format=csv", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	records, err := csv.NewReader(strings.NewReader(rec.Body.String())).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"value"}, {`'=HYPERLINK("http://evil.example")`}, {"alice"}, {"bob, the builder"}}, records)
+
+	rec = getWithAccept(handler, "/v1/attributes/initiator_name?limit=10", "application/x-ndjson")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "\"=HYPERLINK(\\\"http://evil.example\\\")\"\n\"alice\"\n\"bob, the builder\"\n", rec.Body.String())
+}
+
+func TestExport_Formats(t *testing.T) {
+	// CSV and NDJSON exports are unsigned and do not need a signing key
+	handler := newFormatTest(t)
+	rec := getWithAccept(handler, "/v1/export?format=ndjson", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), ".ndjson")
+	lines := strings.Split(strings.TrimSuffix(rec.Body.String(), "\n"), "\n")
+	require.Len(t, lines, 3)
+	var event cadf.Event
+	require.NoError(t, json.Unmarshal([]byte(lines[2]), &event))
+	assert.Equal(t, "a5fe3a49-0d6f-4b1d-8f4f-000000000003", event.ID)
+	assert.Equal(t, "server-1", event.Target.ID)
+
+	rec = getWithAccept(handler, "/v1/export?columns=id,action", "text/csv")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	records, err := csv.NewReader(strings.NewReader(rec.Body.String())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	assert.Equal(t, []string{"a5fe3a49-0d6f-4b1d-8f4f-000000000001", "update"}, records[1])
+
+	// an empty result still has the CSV header
+	rec = getWithAccept(handler, "/v1/export?format=csv&columns=id&action=delete", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "id\n", rec.Body.String())
+
+	rec = getWithAccept(handler, "/v1/export?format=json", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = getWithAccept(handler, "/v1/export", "")
+	assert.Equal(t, http.StatusNotImplemented, rec.Code)
+}
+
+func TestSummaries(t *testing.T) {
+	summarizer, err := hermes.NewSummarizer(nil)
+	require.NoError(t, err)
+	handler := newFormatTest(t, WithSummarizer(summarizer))
+
+	rec := getWithAccept(handler, "/v1/events?sort=time&limit=1", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), `"summary": "alice updated server server-1"`)
+
+	rec = getWithAccept(handler, "/v1/events/a5fe3a49-0d6f-4b1d-8f4f-000000000001", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var details struct {
+		ID      string `json:"id"`
+		Summary string `json:"summary"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &details))
+	assert.Equal(t, "a5fe3a49-0d6f-4b1d-8f4f-000000000001", details.ID)
+	assert.Equal(t, "alice updated server server-1", details.Summary)
+
+	rec = getWithAccept(handler, "/v1/events?sort=time&limit=1&columns=id,summary", "text/csv")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "id,summary\na5fe3a49-0d6f-4b1d-8f4f-000000000001,alice updated server server-1\n", rec.Body.String())
+}
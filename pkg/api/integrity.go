@@ -0,0 +1,102 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sapcc/go-bits/logg"
+	"github.com/sapcc/go-bits/respondwith"
+
+	"github.com/sapcc/hermes/pkg/integrity"
+	"github.com/sapcc/hermes/pkg/storage"
+)
+
+// defaultVerifyRange is the time range checked by GET /v1/integrity/verify
+// when the time parameter does not specify a start.
+const defaultVerifyRange = 24 * time.Hour
+
+// VerifyIntegrity handles GET /v1/integrity/verify.
+// It recomputes the hashes of the caller's events in the requested time range
+// and compares them with the tenant's hash chain.
+func (p *v1Provider) VerifyIntegrity(res http.ResponseWriter, req *http.Request) {
+	token, ok := p.AuthHandler(res, req, "integrity:verify")
+	if !ok {
+		return
+	}
+	if p.integrityStore == nil {
+		http.Error(res, "integrity verification is not enabled on this server", http.StatusNotImplemented)
+		return
+	}
+
+	from, to, err := parseVerifyRange(req.FormValue("time"), time.Now())
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	indexID, err := getIndexID(token, req, res)
+	if err != nil {
+		return
+	}
+
+	report, err := integrity.Verify(req.Context(), p.integrityStore, p.storage, indexID, from, to)
+	if errors.Is(err, integrity.ErrRangeTooLarge) {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if respondwith.ErrorText(res, err) {
+		logg.Error("could not verify integrity for %s: %s", indexID, err)
+		storageErrorsCounter.Add(1)
+		return
+	}
+	ReturnESJSON(res, http.StatusOK, report)
+}
+
+// parseVerifyRange turns the time parameter of GET /v1/integrity/verify into a
+// half-open range [from, to). It accepts the same syntax as GET /v1/events:
+// gt/gte select the start and lt/lte the end. Missing bounds default to the
+// last 24 hours before now.
+func parseVerifyRange(timeParam string, now time.Time) (from, to time.Time, err error) {
+	timeRange, err := parseTimeFilter(timeParam)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if timeRange["gt"] != "" && timeRange["gte"] != "" {
+		return time.Time{}, time.Time{}, errors.New("time operators gt and gte cannot be combined")
+	}
+	if timeRange["lt"] != "" && timeRange["lte"] != "" {
+		return time.Time{}, time.Time{}, errors.New("time operators lt and lte cannot be combined")
+	}
+
+	to = now
+	for _, operator := range []string{"lt", "lte"} {
+		if value := timeRange[operator]; value != "" {
+			if to, err = storage.ParseEventTime(value); err != nil {
+				return time.Time{}, time.Time{}, fmt.Errorf("invalid time format: %s", value)
+			}
+			if operator == "lte" {
+				to = to.Add(time.Nanosecond)
+			}
+		}
+	}
+	from = to.Add(-defaultVerifyRange)
+	for _, operator := range []string{"gt", "gte"} {
+		if value := timeRange[operator]; value != "" {
+			if from, err = storage.ParseEventTime(value); err != nil {
+				return time.Time{}, time.Time{}, fmt.Errorf("invalid time format: %s", value)
+			}
+			if operator == "gt" {
+				from = from.Add(time.Nanosecond)
+			}
+		}
+	}
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, errors.New("time range is empty: start must be before end")
+	}
+	return from, to, nil
+}
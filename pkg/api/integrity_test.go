@@ -0,0 +1,129 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/sapcc/go-bits/audittools"
+	"github.com/sapcc/go-bits/httpapi"
+	"github.com/sapcc/go-bits/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapcc/hermes/pkg/integrity"
+	"github.com/sapcc/hermes/pkg/routing"
+	"github.com/sapcc/hermes/pkg/storage"
+	"github.com/sapcc/hermes/pkg/test"
+)
+
+const integrityProjectID = "b3b70c8271a845709f9a03030e705da7"
+
+var integrityEpoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func integrityEvent(id string, offset time.Duration) cadf.Event {
+	return cadf.Event{
+		ID:        id,
+		EventTime: integrityEpoch.Add(offset).Format("2006-01-02T15:04:05.000000+00:00"),
+		Action:    cadf.UpdateAction,
+		Outcome:   cadf.SuccessOutcome,
+		Target:    cadf.Resource{TypeURI: "compute/server", ID: "server-" + id, ProjectID: integrityProjectID},
+	}
+}
+
+// setupIntegrityTest seals two events into the test project's chain.
+func setupIntegrityTest(t *testing.T) (http.Handler, *storage.Memory) {
+	t.Helper()
+	events := storage.NewMemory(100)
+	store := integrity.NewMock()
+
+	var entries []integrity.Entry
+	for _, event := range []cadf.Event{integrityEvent("e1", time.Minute), integrityEvent("e2", 2*time.Minute)} {
+		events.Add([]string{integrityProjectID}, event)
+		hash, err := integrity.HashEvent(&event)
+		require.NoError(t, err)
+		eventTime, err := storage.ParseEventTime(event.EventTime)
+		require.NoError(t, err)
+		entries = append(entries, integrity.Entry{TenantID: integrityProjectID, EventID: event.ID, EventTime: eventTime, EventHash: hash})
+	}
+	require.NoError(t, store.Seal(t.Context(), time.Time{}, integrityEpoch.Add(time.Hour), entries))
+
+	prometheus.DefaultRegisterer = prometheus.NewPedanticRegistry()
+	v1API := NewV1API(mock.NewValidator(mock.NewEnforcer(), nil), events, routing.NewMock(),
+		audittools.NewNullAuditor(), WithIntegrityStore(store))
+	return httpapi.Compose(v1API), events
+}
+
+func getIntegrityReport(t *testing.T, handler http.Handler, query string) integrity.Report {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/v1/integrity/verify?project_id="+integrityProjectID+"&"+query, http.NoBody)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var report integrity.Report
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	return report
+}
+
+func TestVerifyIntegrity(t *testing.T) {
+	handler, events := setupIntegrityTest(t)
+	query := "time=gte:2026-01-01T00:00:00Z,lt:2026-01-01T01:00:00Z"
+
+	report := getIntegrityReport(t, handler, query)
+	assert.True(t, report.Intact)
+	assert.Equal(t, 2, report.Verified)
+
+	modified := integrityEvent("e1", time.Minute)
+	modified.Outcome = cadf.FailureOutcome
+	require.True(t, events.Replace(modified))
+	require.True(t, events.Remove("e2"))
+
+	report = getIntegrityReport(t, handler, query)
+	assert.False(t, report.Intact)
+	assert.Equal(t, 0, report.Verified)
+	require.Len(t, report.Modified, 1)
+	assert.Equal(t, "e1", report.Modified[0].EventID)
+	require.Len(t, report.Missing, 1)
+	assert.Equal(t, "e2", report.Missing[0].EventID)
+
+	// a range that ends before the second event only sees the modification
+	report = getIntegrityReport(t, handler, "time=gte:2026-01-01T00:00:00Z,lte:2026-01-01T00:01:30Z")
+	assert.Len(t, report.Modified, 1)
+	assert.Empty(t, report.Missing)
+}
+
+func TestVerifyIntegrity_Errors(t *testing.T) {
+	handler, _ := setupIntegrityTest(t)
+	tt := []struct {
+		name       string
+		query      string
+		statusCode int
+	}{
+		{"InvalidOperator", "time=xx:2026-01-01T00:00:00Z", http.StatusBadRequest},
+		{"GtAndGte", "time=gt:2026-01-01T00:00:00Z,gte:2026-01-01T00:00:00Z", http.StatusBadRequest},
+		{"EmptyRange", "time=gte:2026-01-01T01:00:00Z,lt:2026-01-01T00:00:00Z", http.StatusBadRequest},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			test.APIRequest{
+				Method:           "GET",
+				Path:             "/v1/integrity/verify?project_id=" + integrityProjectID + "&" + tc.query,
+				ExpectStatusCode: tc.statusCode,
+			}.Check(t, handler)
+		})
+	}
+
+	// without an integrity store, the endpoint is not available
+	test.APIRequest{
+		Method:           "GET",
+		Path:             "/v1/integrity/verify",
+		ExpectStatusCode: http.StatusNotImplemented,
+	}.Check(t, setupTest(t))
+}
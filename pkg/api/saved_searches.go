@@ -0,0 +1,429 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/sapcc/go-bits/audittools"
+	"github.com/sapcc/go-bits/gopherpolicy"
+	"github.com/sapcc/go-bits/logg"
+	"github.com/sapcc/go-bits/respondwith"
+
+	"github.com/sapcc/hermes/pkg/hermes"
+	"github.com/sapcc/hermes/pkg/searches"
+)
+
+const (
+	maxSavedSearchNameLength        = 255
+	maxSavedSearchDescriptionLength = 1024
+)
+
+// savedSearchRequest is the shape accepted on POST and PUT.
+// We use strict decoding (DisallowUnknownFields) so unknown fields → 400.
+type savedSearchRequest struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Query       map[string]string   `json:"query"`
+	Visibility  searches.Visibility `json:"visibility"`
+}
+
+// savedSearchList is the response body of GET /v1/projects/{project_id}/saved-searches.
+type savedSearchList struct {
+	SavedSearches []searches.SavedSearch `json:"saved_searches"`
+}
+
+// ListSavedSearches handles GET /v1/projects/{project_id}/saved-searches.
+// Returns the saved searches of the project and the domain-shared saved
+// searches of other projects in the same domain that the caller may see.
+func (p *v1Provider) ListSavedSearches(res http.ResponseWriter, req *http.Request) {
+	projectID := mux.Vars(req)["project_id"]
+	token, ok := p.authSavedSearches(res, req, projectID, "saved_search:list")
+	if !ok {
+		return
+	}
+
+	all, err := p.searchStore.List(req.Context(), projectID, token.Context.Auth["project_domain_id"])
+	if err != nil {
+		logg.Error("saved-searches LIST: storage error for project %s: %s", projectID, err)
+		respondwith.ObfuscatedErrorText(res, err)
+		return
+	}
+	visible := []searches.SavedSearch{}
+	for _, s := range all {
+		if s.VisibleTo(projectID, token.Context.Auth["project_domain_id"], token.Context.Auth["user_id"]) {
+			visible = append(visible, s)
+		}
+	}
+	ReturnESJSON(res, http.StatusOK, savedSearchList{SavedSearches: visible})
+}
+
+// CreateSavedSearch handles POST /v1/projects/{project_id}/saved-searches.
+// Returns 201 with the saved document.
+// An audit event is emitted for every attempt — successful or not.
+func (p *v1Provider) CreateSavedSearch(res http.ResponseWriter, req *http.Request) {
+	projectID := mux.Vars(req)["project_id"]
+	token, ok := p.authSavedSearches(res, req, projectID, "saved_search:create")
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	userID := token.Context.Auth["user_id"]
+	if userID == "" {
+		http.Error(res, "token missing user identity", http.StatusUnauthorized)
+		return
+	}
+	search := searches.SavedSearch{
+		ID:        uuid.NewString(),
+		ProjectID: projectID,
+		DomainID:  token.Context.Auth["project_domain_id"],
+		CreatedAt: now,
+		CreatedBy: userID,
+		UpdatedAt: now,
+		UpdatedBy: userID,
+	}
+	recordAttempt := func(reasonCode int) {
+		p.auditor.Record(audittools.Event{
+			Time:       now,
+			Request:    req,
+			User:       token,
+			ReasonCode: reasonCode,
+			Action:     cadf.CreateAction,
+			Target:     search,
+		})
+	}
+
+	var body savedSearchRequest
+	status, err := decodeJSONBody(res, req, &body)
+	if err == nil {
+		status, err = applySavedSearchRequest(token, &search, body, "")
+	}
+	if err != nil {
+		http.Error(res, err.Error(), status)
+		recordAttempt(status)
+		return
+	}
+
+	err = p.searchStore.Create(req.Context(), search)
+	if errors.Is(err, searches.ErrDuplicateName) {
+		http.Error(res, err.Error(), http.StatusConflict)
+		recordAttempt(http.StatusConflict)
+		return
+	}
+	if err != nil {
+		logg.Error("saved-searches POST: storage error for project %s: %s", projectID, err)
+		respondwith.ObfuscatedErrorText(res, err)
+		recordAttempt(http.StatusInternalServerError)
+		return
+	}
+
+	logg.Info("saved-searches POST: project=%s id=%s visibility=%s created_by=%s", projectID, search.ID, search.Visibility, userID)
+	recordAttempt(http.StatusCreated)
+	ReturnESJSON(res, http.StatusCreated, search)
+}
+
+// GetSavedSearch handles GET /v1/projects/{project_id}/saved-searches/{saved_search_id}.
+func (p *v1Provider) GetSavedSearch(res http.ResponseWriter, req *http.Request) {
+	projectID := mux.Vars(req)["project_id"]
+	token, ok := p.authSavedSearches(res, req, projectID, "saved_search:list")
+	if !ok {
+		return
+	}
+	search, ok := p.findSavedSearch(res, req, token, projectID)
+	if !ok {
+		return
+	}
+	ReturnESJSON(res, http.StatusOK, search)
+}
+
+// UpdateSavedSearch handles PUT /v1/projects/{project_id}/saved-searches/{saved_search_id}.
+// Replaces name, description, query and visibility. Returns 200 with the saved document.
+// An audit event is emitted for every attempt on an existing saved search.
+func (p *v1Provider) UpdateSavedSearch(res http.ResponseWriter, req *http.Request) {
+	projectID := mux.Vars(req)["project_id"]
+	token, ok := p.authSavedSearches(res, req, projectID, "saved_search:update")
+	if !ok {
+		return
+	}
+	search, ok := p.findOwnedSavedSearch(res, req, token, projectID)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	userID := token.Context.Auth["user_id"]
+	recordAttempt := func(reasonCode int) {
+		p.auditor.Record(audittools.Event{
+			Time:       now,
+			Request:    req,
+			User:       token,
+			ReasonCode: reasonCode,
+			Action:     cadf.UpdateAction,
+			Target:     *search,
+		})
+	}
+
+	var body savedSearchRequest
+	status, err := decodeJSONBody(res, req, &body)
+	if err == nil {
+		status, err = applySavedSearchRequest(token, search, body, search.Visibility)
+	}
+	if err != nil {
+		http.Error(res, err.Error(), status)
+		recordAttempt(status)
+		return
+	}
+	search.UpdatedAt = now
+	search.UpdatedBy = userID
+
+	err = p.searchStore.Update(req.Context(), *search)
+	switch {
+	case errors.Is(err, searches.ErrDuplicateName):
+		http.Error(res, err.Error(), http.StatusConflict)
+		recordAttempt(http.StatusConflict)
+		return
+	case errors.Is(err, searches.ErrNotFound):
+		// deleted concurrently
+		http.Error(res, "saved search not found", http.StatusNotFound)
+		return
+	case err != nil:
+		logg.Error("saved-searches PUT: storage error for saved search %s: %s", search.ID, err)
+		respondwith.ObfuscatedErrorText(res, err)
+		recordAttempt(http.StatusInternalServerError)
+		return
+	}
+
+	logg.Info("saved-searches PUT: project=%s id=%s visibility=%s updated_by=%s", projectID, search.ID, search.Visibility, userID)
+	recordAttempt(http.StatusOK)
+	ReturnESJSON(res, http.StatusOK, search)
+}
+
+// DeleteSavedSearch handles DELETE /v1/projects/{project_id}/saved-searches/{saved_search_id}.
+// Returns 204. Unlike the dataplane config, a saved search has an ID, so
+// deleting a non-existent one returns 404.
+func (p *v1Provider) DeleteSavedSearch(res http.ResponseWriter, req *http.Request) {
+	projectID := mux.Vars(req)["project_id"]
+	token, ok := p.authSavedSearches(res, req, projectID, "saved_search:delete")
+	if !ok {
+		return
+	}
+	search, ok := p.findOwnedSavedSearch(res, req, token, projectID)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	deleted, err := p.searchStore.Delete(req.Context(), search.ID)
+	if err != nil {
+		logg.Error("saved-searches DELETE: storage error for saved search %s: %s", search.ID, err)
+		respondwith.ObfuscatedErrorText(res, err)
+		p.auditor.Record(audittools.Event{
+			Time:       now,
+			Request:    req,
+			User:       token,
+			ReasonCode: http.StatusInternalServerError,
+			Action:     cadf.DeleteAction,
+			Target:     *search,
+		})
+		return
+	}
+	if !deleted {
+		// deleted concurrently
+		http.Error(res, "saved search not found", http.StatusNotFound)
+		return
+	}
+
+	p.auditor.Record(audittools.Event{
+		Time:       now,
+		Request:    req,
+		User:       token,
+		ReasonCode: http.StatusNoContent,
+		Action:     cadf.DeleteAction,
+		Target:     *search,
+	})
+	logg.Info("saved-searches DELETE: project=%s id=%s deleted_by=%s", projectID, search.ID, token.Context.Auth["user_id"])
+	res.WriteHeader(http.StatusNoContent)
+}
+
+// ExecuteSavedSearch handles GET /v1/projects/{project_id}/saved-searches/{saved_search_id}/events.
+// Runs the saved query against the events of the path project. Query parameters
+// of the request override the saved ones, and offset selects the page.
+// The response has the same shape as GET /v1/events.
+func (p *v1Provider) ExecuteSavedSearch(res http.ResponseWriter, req *http.Request) {
+	projectID := mux.Vars(req)["project_id"]
+	token, ok := p.authSavedSearches(res, req, projectID, "saved_search:list")
+	if !ok {
+		return
+	}
+	if !token.Require(res, "event:list") {
+		return
+	}
+	search, ok := p.findSavedSearch(res, req, token, projectID)
+	if !ok {
+		return
+	}
+
+	if err := req.ParseForm(); err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query := url.Values{}
+	for key, value := range search.Query {
+		query.Set(key, value)
+	}
+	for _, key := range searches.QueryParameters {
+		if req.Form.Has(key) {
+			query.Set(key, req.Form.Get(key))
+		}
+	}
+	if req.Form.Has("offset") {
+		query.Set("offset", req.Form.Get("offset"))
+	}
+	filter, err := parseEventFilter(query)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	events, total, err := hermes.GetEvents(req.Context(), filter, projectID, p.storage, p.eventView(token))
+	if respondwith.ErrorText(res, err) {
+		logg.Error("saved-searches EXECUTE: error calling hermes.GetEvents() for saved search %s: %s", search.ID, err.Error())
+		storageErrorsCounter.Add(1)
+		return
+	}
+	eventList := EventList{Events: events, Total: total}
+	addPaginationURLs(req, &eventList, filter)
+	ReturnESJSON(res, http.StatusOK, eventList)
+}
+
+// authSavedSearches performs the project-scoped auth check for the
+// saved-search endpoints and ensures that a store is configured.
+func (p *v1Provider) authSavedSearches(res http.ResponseWriter, req *http.Request, pathProjectID, rule string) (*gopherpolicy.Token, bool) {
+	token, ok := p.authProjectScoped(res, req, pathProjectID, rule)
+	if !ok {
+		return nil, false
+	}
+	if p.searchStore == nil {
+		http.Error(res, "saved searches are not enabled on this server", http.StatusNotImplemented)
+		return nil, false
+	}
+	return token, true
+}
+
+// findSavedSearch loads the saved search from the path and checks that the
+// caller may see it. Invisible saved searches are reported as not found.
+func (p *v1Provider) findSavedSearch(res http.ResponseWriter, req *http.Request, token *gopherpolicy.Token, projectID string) (*searches.SavedSearch, bool) {
+	id := mux.Vars(req)["saved_search_id"]
+	if _, err := uuid.Parse(id); err != nil {
+		http.Error(res, "Invalid saved search ID format", http.StatusBadRequest)
+		return nil, false
+	}
+	search, err := p.searchStore.Get(req.Context(), id)
+	if errors.Is(err, searches.ErrNotFound) ||
+		(err == nil && !search.VisibleTo(projectID, token.Context.Auth["project_domain_id"], token.Context.Auth["user_id"])) {
+		http.Error(res, "saved search not found", http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		logg.Error("saved-searches GET: storage error for saved search %s: %s", id, err)
+		respondwith.ObfuscatedErrorText(res, err)
+		return nil, false
+	}
+	return search, true
+}
+
+// findOwnedSavedSearch is like findSavedSearch, but additionally checks that
+// the caller may modify the saved search: it must belong to the path project,
+// and the caller must have created it or satisfy "saved_search:manage_all".
+func (p *v1Provider) findOwnedSavedSearch(res http.ResponseWriter, req *http.Request, token *gopherpolicy.Token, projectID string) (*searches.SavedSearch, bool) {
+	search, ok := p.findSavedSearch(res, req, token, projectID)
+	if !ok {
+		return nil, false
+	}
+	if search.ProjectID != projectID {
+		http.Error(res, "saved search belongs to another project", http.StatusForbidden)
+		return nil, false
+	}
+	if search.CreatedBy != token.Context.Auth["user_id"] && !token.Require(res, "saved_search:manage_all") {
+		return nil, false
+	}
+	return search, true
+}
+
+// decodeJSONBody strictly decodes the JSON request body of POST and PUT into target.
+// On error, it returns the HTTP status to respond with.
+func decodeJSONBody(res http.ResponseWriter, req *http.Request, target any) (int, error) {
+	if ct := req.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		return http.StatusUnsupportedMediaType, errors.New("Content-Type must be application/json") //nolint:staticcheck // matches dataplane-config
+	}
+	// Body size cap: 64 KiB
+	req.Body = http.MaxBytesReader(res, req.Body, 64*1024)
+	decoder := json.NewDecoder(req.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(target); err != nil {
+		return http.StatusBadRequest, errors.New("invalid request body: " + err.Error())
+	}
+	return http.StatusOK, nil
+}
+
+// applySavedSearchRequest validates the request body and copies it into search.
+// Sharing a saved search requires the policy rule for the requested visibility,
+// unless the visibility stays the same (previousVisibility).
+// On error, it returns the HTTP status to respond with.
+func applySavedSearchRequest(token *gopherpolicy.Token, search *searches.SavedSearch, body savedSearchRequest, previousVisibility searches.Visibility) (int, error) {
+	name := strings.TrimSpace(body.Name)
+	if name == "" || len(name) > maxSavedSearchNameLength {
+		return http.StatusBadRequest, fmt.Errorf("name must be between 1 and %d characters", maxSavedSearchNameLength)
+	}
+	if len(body.Description) > maxSavedSearchDescriptionLength {
+		return http.StatusBadRequest, fmt.Errorf("description must be at most %d characters", maxSavedSearchDescriptionLength)
+	}
+
+	visibility := body.Visibility
+	if visibility == "" {
+		visibility = searches.VisibilityPrivate
+	}
+	if !visibility.IsValid() {
+		return http.StatusBadRequest, fmt.Errorf("visibility must be %q, %q or %q",
+			searches.VisibilityPrivate, searches.VisibilityProject, searches.VisibilityDomain)
+	}
+	if visibility != previousVisibility && visibility != searches.VisibilityPrivate {
+		rule := "saved_search:share_" + string(visibility)
+		if !token.Check(rule) {
+			return http.StatusForbidden, fmt.Errorf("sharing with the %s requires the %s permission", visibility, rule)
+		}
+	}
+
+	query := url.Values{}
+	for key, value := range body.Query {
+		if !slices.Contains(searches.QueryParameters, key) {
+			return http.StatusBadRequest, fmt.Errorf("query parameter %q cannot be saved, valid parameters: %s",
+				key, strings.Join(searches.QueryParameters, ", "))
+		}
+		query.Set(key, value)
+	}
+	if _, err := parseEventFilter(query); err != nil {
+		return http.StatusBadRequest, fmt.Errorf("invalid query: %w", err)
+	}
+
+	search.Name = name
+	search.Description = body.Description
+	search.Query = body.Query
+	if search.Query == nil {
+		search.Query = map[string]string{}
+	}
+	search.Visibility = visibility
+	return http.StatusOK, nil
+}
@@ -0,0 +1,275 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/sapcc/go-bits/audittools"
+	"github.com/sapcc/go-bits/httpapi"
+	"github.com/sapcc/go-bits/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapcc/hermes/pkg/routing"
+	"github.com/sapcc/hermes/pkg/searches"
+	"github.com/sapcc/hermes/pkg/storage"
+)
+
+const savedSearchesPath = "/v1/projects/" + testProjectID + "/saved-searches"
+
+// testUser is a handler whose mock token belongs to one user.
+type testUser struct {
+	t       *testing.T
+	handler http.Handler
+}
+
+func newTestUser(t *testing.T, auditor audittools.Auditor, projectID, userID string, forbiddenRules []string, opts ...Option) testUser {
+	t.Helper()
+	enforcer := mock.NewEnforcer()
+	for _, rule := range forbiddenRules {
+		enforcer.Forbid(rule)
+	}
+	validator := mock.NewValidator(enforcer, map[string]string{
+		"project_id":        projectID,
+		"project_domain_id": "test-domain",
+		"user_id":           userID,
+	})
+	prometheus.DefaultRegisterer = prometheus.NewPedanticRegistry()
+	v1API := NewV1API(validator, storage.Mock{}, routing.NewMock(), auditor, opts...)
+	return testUser{t: t, handler: httpapi.Compose(v1API)}
+}
+
+// newSavedSearchUser builds a testUser with a saved-search store.
+// All users created with the same store and auditor share their saved searches.
+func newSavedSearchUser(t *testing.T, store searches.Store, auditor audittools.Auditor, projectID, userID string, forbiddenRules ...string) testUser {
+	t.Helper()
+	return newTestUser(t, auditor, projectID, userID, forbiddenRules, WithSavedSearchStore(store))
+}
+
+func (u testUser) do(method, path string, body any) *httptest.ResponseRecorder {
+	u.t.Helper()
+	var reqBody *bytes.Reader
+	if body == nil {
+		reqBody = bytes.NewReader(nil)
+	} else {
+		buf, err := json.Marshal(body)
+		require.NoError(u.t, err)
+		reqBody = bytes.NewReader(buf)
+	}
+	req := httptest.NewRequest(method, path, reqBody)
+	req.Header.Set("X-Auth-Token", "something")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	u.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func (u testUser) create(body map[string]any) searches.SavedSearch {
+	u.t.Helper()
+	rec := u.do(http.MethodPost, savedSearchesPath, body)
+	require.Equal(u.t, http.StatusCreated, rec.Code, rec.Body.String())
+	var search searches.SavedSearch
+	require.NoError(u.t, json.Unmarshal(rec.Body.Bytes(), &search))
+	return search
+}
+
+func (u testUser) listNames(projectID string) []string {
+	u.t.Helper()
+	rec := u.do(http.MethodGet, "/v1/projects/"+projectID+"/saved-searches", nil)
+	require.Equal(u.t, http.StatusOK, rec.Code, rec.Body.String())
+	var list savedSearchList
+	require.NoError(u.t, json.Unmarshal(rec.Body.Bytes(), &list))
+	names := []string{}
+	for _, s := range list.SavedSearches {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+func TestSavedSearches_CRUD(t *testing.T) {
+	store := searches.NewMock()
+	auditor := audittools.NewMockAuditor()
+	alice := newSavedSearchUser(t, store, auditor, testProjectID, "alice")
+
+	search := alice.create(map[string]any{
+		"name":  "failed deletes",
+		"query": map[string]string{"action": "delete", "outcome": "failure", "sort": "time:desc"},
+	})
+	assert.Equal(t, testProjectID, search.ProjectID)
+	assert.Equal(t, "test-domain", search.DomainID)
+	assert.Equal(t, searches.VisibilityPrivate, search.Visibility)
+	assert.Equal(t, "alice", search.CreatedBy)
+	auditor.ExpectEvents(t, cadf.Event{
+		Action:      cadf.CreateAction,
+		Outcome:     cadf.SuccessOutcome,
+		Reason:      cadf.Reason{ReasonType: "HTTP", ReasonCode: "201"},
+		Target:      search.Render(),
+		RequestPath: savedSearchesPath,
+	})
+
+	// names are unique per project
+	rec := alice.do(http.MethodPost, savedSearchesPath, map[string]any{"name": "failed deletes"})
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	itemPath := savedSearchesPath + "/" + search.ID
+	rec = alice.do(http.MethodGet, itemPath, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = alice.do(http.MethodPut, itemPath, map[string]any{
+		"name":        "failed deletes",
+		"description": "for incident reviews",
+		"query":       map[string]string{"action": "delete"},
+		"visibility":  "project",
+	})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var updated searches.SavedSearch
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &updated))
+	assert.Equal(t, "for incident reviews", updated.Description)
+	assert.Equal(t, map[string]string{"action": "delete"}, updated.Query)
+	assert.Equal(t, searches.VisibilityProject, updated.Visibility)
+
+	rec = alice.do(http.MethodDelete, itemPath, nil)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	rec = alice.do(http.MethodGet, itemPath, nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = alice.do(http.MethodDelete, itemPath, nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestSavedSearches_Validation(t *testing.T) {
+	alice := newSavedSearchUser(t, searches.NewMock(), audittools.NewNullAuditor(), testProjectID, "alice")
+	tt := []struct {
+		name       string
+		body       map[string]any
+		statusCode int
+	}{
+		{"MissingName", map[string]any{"query": map[string]string{}}, http.StatusBadRequest},
+		{"UnknownField", map[string]any{"name": "x", "owner": "bob"}, http.StatusBadRequest},
+		{"UnknownVisibility", map[string]any{"name": "x", "visibility": "public"}, http.StatusBadRequest},
+		{"ScopeParameter", map[string]any{"name": "x", "query": map[string]string{"project_id": "other"}}, http.StatusBadRequest},
+		{"InvalidSort", map[string]any{"name": "x", "query": map[string]string{"sort": "nonsense"}}, http.StatusBadRequest},
+		{"InvalidTime", map[string]any{"name": "x", "query": map[string]string{"time": "lt:yesterday"}}, http.StatusBadRequest},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := alice.do(http.MethodPost, savedSearchesPath, tc.body)
+			assert.Equal(t, tc.statusCode, rec.Code, rec.Body.String())
+		})
+	}
+
+	rec := alice.do(http.MethodGet, savedSearchesPath+"/not-a-uuid", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = alice.do(http.MethodGet, "/v1/projects/other-project/saved-searches", nil)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestSavedSearches_Sharing(t *testing.T) {
+	store := searches.NewMock()
+	auditor := audittools.NewNullAuditor()
+	alice := newSavedSearchUser(t, store, auditor, testProjectID, "alice", "saved_search:share_domain", "saved_search:manage_all")
+	bob := newSavedSearchUser(t, store, auditor, testProjectID, "bob", "saved_search:share_domain", "saved_search:manage_all")
+	admin := newSavedSearchUser(t, store, auditor, testProjectID, "admin")
+	neighbor := newSavedSearchUser(t, store, auditor, "test-project-2", "carol")
+
+	private := alice.create(map[string]any{"name": "mine"})
+	alice.create(map[string]any{"name": "team", "visibility": "project"})
+	domainWide := admin.create(map[string]any{"name": "domain", "visibility": "domain"})
+
+	// sharing with the domain requires saved_search:share_domain
+	rec := alice.do(http.MethodPost, savedSearchesPath, map[string]any{"name": "x", "visibility": "domain"})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	assert.Equal(t, []string{"domain", "mine", "team"}, alice.listNames(testProjectID))
+	assert.Equal(t, []string{"domain", "team"}, bob.listNames(testProjectID))
+	assert.Equal(t, []string{"domain"}, neighbor.listNames("test-project-2"))
+
+	// private searches are invisible to other users
+	rec = bob.do(http.MethodGet, savedSearchesPath+"/"+private.ID, nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// domain-shared searches can be executed from other projects, but only changed in their own project
+	rec = neighbor.do(http.MethodGet, "/v1/projects/test-project-2/saved-searches/"+domainWide.ID+"/events", nil)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	rec = neighbor.do(http.MethodDelete, "/v1/projects/test-project-2/saved-searches/"+domainWide.ID, nil)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	// other users' searches can only be changed with saved_search:manage_all,
+	// and keeping the visibility does not require the sharing permission again
+	rec = bob.do(http.MethodPut, savedSearchesPath+"/"+domainWide.ID, map[string]any{"name": "renamed", "visibility": "domain"})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	rec = admin.do(http.MethodPut, savedSearchesPath+"/"+domainWide.ID, map[string]any{"name": "renamed", "visibility": "domain"})
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+}
+
+func TestSavedSearches_MutationRules(t *testing.T) {
+	store := searches.NewMock()
+	auditor := audittools.NewNullAuditor()
+	alice := newSavedSearchUser(t, store, auditor, testProjectID, "alice")
+	readOnly := newSavedSearchUser(t, store, auditor, testProjectID, "alice", "saved_search:update", "saved_search:delete")
+
+	// changing and deleting require their own rules, even for the creator
+	search := alice.create(map[string]any{"name": "mine"})
+	itemPath := savedSearchesPath + "/" + search.ID
+	rec := readOnly.do(http.MethodPut, itemPath, map[string]any{"name": "renamed"})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	rec = readOnly.do(http.MethodDelete, itemPath, nil)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	rec = readOnly.do(http.MethodGet, itemPath, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = alice.do(http.MethodPut, itemPath, map[string]any{"name": "renamed"})
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	rec = alice.do(http.MethodDelete, itemPath, nil)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
+
+func TestSavedSearches_Execute(t *testing.T) {
+	alice := newSavedSearchUser(t, searches.NewMock(), audittools.NewNullAuditor(), testProjectID, "alice")
+	search := alice.create(map[string]any{"name": "recent", "query": map[string]string{"limit": "2", "sort": "time:desc"}})
+	eventsPath := savedSearchesPath + "/" + search.ID + "/events"
+
+	rec := alice.do(http.MethodGet, eventsPath, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var list EventList
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	// the mock storage ignores the filter, but pagination uses the saved limit
+	assert.Equal(t, 4, list.Total)
+	assert.Equal(t, "http://example.com"+eventsPath+"?offset=2", list.NextURL)
+
+	// request parameters override the saved ones
+	rec = alice.do(http.MethodGet, eventsPath+"?limit=1&offset=1", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var overridden EventList
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &overridden))
+	assert.Equal(t, "http://example.com"+eventsPath+"?limit=1&offset=2", overridden.NextURL)
+	assert.Equal(t, "http://example.com"+eventsPath+"?limit=1&offset=0", overridden.PrevURL)
+
+	rec = alice.do(http.MethodGet, eventsPath+"?sort=nonsense", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestSavedSearches_NotEnabled(t *testing.T) {
+	router := setupTest(t)
+	req := httptest.NewRequest(http.MethodGet, "/v1/projects/"+testProjectID+"/saved-searches", http.NoBody)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	// the default test token has no project scope, so the scope check fails before the store is consulted
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	prometheus.DefaultRegisterer = prometheus.NewPedanticRegistry()
+	validator := mock.NewValidator(mock.NewEnforcer(), map[string]string{"project_id": testProjectID})
+	handler := httpapi.Compose(NewV1API(validator, storage.Mock{}, routing.NewMock(), audittools.NewNullAuditor()))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/projects/"+testProjectID+"/saved-searches", http.NoBody))
+	assert.Equal(t, http.StatusNotImplemented, rec.Code)
+}
@@ -20,11 +20,11 @@ import (
 )
 
 // Server Set up and start the API server using httpapi patterns
-func Server(ctx context.Context, validator gopherpolicy.Validator, storageInterface storage.Storage, routingStore routing.Store, auditor audittools.Auditor) error {
+func Server(ctx context.Context, validator gopherpolicy.Validator, storageInterface storage.Storage, routingStore routing.Store, auditor audittools.Auditor, opts ...Option) error {
 	logg.Info("Starting Hermes API server")
 
 	// Create API compositions
-	v1API := NewV1API(validator, storageInterface, routingStore, auditor)
+	v1API := NewV1API(validator, storageInterface, routingStore, auditor, opts...)
 	versionAPI := NewVersionAPI(v1API.VersionData())
 	metricsAPI := NewMetricsAPI()
 
@@ -38,10 +38,13 @@ func Server(ctx context.Context, validator gopherpolicy.Validator, storageInterf
 	// Apply middleware
 	handler = InstrumentInflight(handler)
 
-	// Enable CORS support — PUT and DELETE are required for the dataplane-config endpoints.
+	// Enable CORS support — PUT, PATCH and DELETE are required for the dataplane-config endpoints,
+	// POST for creating saved searches.
 	c := cors.New(cors.Options{
-		AllowedHeaders: []string{"X-Auth-Token", "Content-Type", "Accept"},
-		AllowedMethods: []string{"GET", "HEAD", "PUT", "DELETE"},
+		AllowedHeaders: []string{"X-Auth-Token", "Content-Type", "Accept", "Last-Event-ID", "If-Match", "If-None-Match"},
+		AllowedMethods: []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE"},
+		// pagination of CSV and NDJSON event lists, and versions of dataplane-config
+		ExposedHeaders: []string{"Link", "X-Total-Count", "ETag"},
 		MaxAge:         600,
 	})
 	handler = c.Handler(handler)
@@ -0,0 +1,311 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sapcc/go-bits/logg"
+
+	"github.com/sapcc/hermes/pkg/hermes"
+	"github.com/sapcc/hermes/pkg/storage"
+)
+
+// streamPageSize is the number of events requested from storage per query
+// while polling for new events.
+const streamPageSize = 100
+
+// EventStreamConfig tunes GET /v1/events/stream.
+type EventStreamConfig struct {
+	// PollInterval is the time between two queries for new events.
+	PollInterval time.Duration
+	// HeartbeatInterval is the time between two comment lines that keep idle
+	// connections open through proxies.
+	HeartbeatInterval time.Duration
+	// Lookback is how long after its eventTime an event may arrive in storage
+	// and still be streamed. Events that arrive later are missed.
+	Lookback time.Duration
+	// MaxResume caps how far back a stream may resume via Last-Event-ID.
+	MaxResume time.Duration
+	// MaxConnectionsPerTenant limits the concurrent streams per project or
+	// domain. Zero means no limit.
+	MaxConnectionsPerTenant int
+}
+
+// DefaultEventStreamConfig returns the settings used unless WithEventStream is given.
+func DefaultEventStreamConfig() EventStreamConfig {
+	return EventStreamConfig{
+		PollInterval:            2 * time.Second,
+		HeartbeatInterval:       15 * time.Second,
+		Lookback:                time.Minute,
+		MaxResume:               time.Hour,
+		MaxConnectionsPerTenant: 10,
+	}
+}
+
+// StreamEvents handles GET /v1/events/stream.
+// It serves Server-Sent Events with every newly stored event that matches the
+// filter parameters of GET /v1/events, until the client disconnects.
+// There is no ingest path in Hermes, so new events are found by polling the
+// storage with a moving time watermark.
+func (p *v1Provider) StreamEvents(res http.ResponseWriter, req *http.Request) {
+	token, ok := p.AuthHandler(res, req, "event:list")
+	if !ok {
+		return
+	}
+
+	filter, err := parseEventFilter(req.Form)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(filter.Time) > 0 || len(filter.Sort) > 0 {
+		http.Error(res, "time and sort cannot be used with the event stream", http.StatusBadRequest)
+		return
+	}
+	indexID, err := getIndexID(token, req, res)
+	if err != nil {
+		return
+	}
+
+	now := time.Now().UTC()
+	stream := &eventStream{
+		filter:  filter,
+		tenant:  indexID,
+		storage: p.storage,
+		view:    p.eventView(token),
+		since:   now.Add(-p.streamConfig.Lookback),
+		sent:    make(map[string]time.Time),
+		config:  p.streamConfig,
+	}
+	resuming := false
+	if lastEventID := req.Header.Get("Last-Event-ID"); lastEventID != "" {
+		eventTime, eventID, err := parseStreamEventID(lastEventID)
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusBadRequest)
+			return
+		}
+		stream.since = maxTime(eventTime, now.Add(-p.streamConfig.MaxResume))
+		stream.sent[eventID] = eventTime
+		resuming = true
+	}
+
+	release, ok := p.streamConnections.acquire(indexID, p.streamConfig.MaxConnectionsPerTenant)
+	if !ok {
+		http.Error(res, "too many concurrent event streams for this project or domain", http.StatusTooManyRequests)
+		return
+	}
+	defer release()
+
+	ctx := req.Context()
+	if !resuming {
+		// everything that is already stored counts as sent
+		if _, err := stream.poll(ctx, now); err != nil {
+			logg.Error("api.StreamEvents: cannot query storage for tenant %s: %s", indexID, err.Error())
+			storageErrorsCounter.Add(1)
+			http.Error(res, "cannot query storage", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// the server's write timeout is meant for regular requests, not for streams
+	rc := http.NewResponseController(res)
+	_ = rc.SetWriteDeadline(time.Time{}) //nolint:errcheck // not every ResponseWriter supports deadlines
+	res.Header().Set("Content-Type", "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	if _, err := fmt.Fprintf(res, "retry: %d\n\n", p.streamConfig.PollInterval.Milliseconds()); err != nil || rc.Flush() != nil {
+		return
+	}
+	logg.Debug("api.StreamEvents: stream opened for tenant %s (resuming: %t)", indexID, resuming)
+
+	poll := time.NewTicker(p.streamConfig.PollInterval)
+	defer poll.Stop()
+	heartbeat := time.NewTicker(p.streamConfig.HeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(res, ": heartbeat\n\n"); err != nil || rc.Flush() != nil {
+				return
+			}
+		case <-poll.C:
+			events, err := stream.poll(ctx, time.Now().UTC())
+			if errors.Is(err, context.Canceled) {
+				return
+			}
+			if err != nil {
+				// keep the stream open; the next poll continues from the same watermark
+				logg.Error("api.StreamEvents: cannot query storage for tenant %s: %s", indexID, err.Error())
+				storageErrorsCounter.Add(1)
+				continue
+			}
+			for _, event := range events {
+				if err := writeStreamEvent(res, event); err != nil {
+					return
+				}
+			}
+			if len(events) > 0 && rc.Flush() != nil {
+				return
+			}
+		}
+	}
+}
+
+// eventStream tracks what one GET /v1/events/stream connection has sent.
+type eventStream struct {
+	filter  *hermes.EventFilter
+	tenant  string
+	storage storage.Storage
+	view    *hermes.EventView
+	// since is the lower bound (inclusive) of the eventTime of the next query.
+	since time.Time
+	// sent holds the IDs and times of the sent events with eventTime >= since,
+	// so that overlapping queries do not send them twice.
+	sent   map[string]time.Time
+	config EventStreamConfig
+}
+
+// streamEvent is a hermes.ListEvent together with its parsed eventTime.
+type streamEvent struct {
+	*hermes.ListEvent
+	time time.Time
+}
+
+// poll returns the matching events since the watermark that were not sent yet,
+// in time order, and moves the watermark to now minus Lookback. If there are
+// more new events than storage can page through, the rest is returned by the
+// next poll.
+func (s *eventStream) poll(ctx context.Context, now time.Time) ([]streamEvent, error) {
+	filter := *s.filter
+	filter.Sort = []hermes.FieldOrder{{Fieldname: "time", Order: "asc"}}
+	filter.Offset = 0
+	filter.Limit = min(streamPageSize, s.storage.MaxLimit())
+
+	var (
+		result    []streamEvent
+		lower     = s.since
+		truncated = false
+	)
+	for {
+		filter.Time = map[string]string{"gte": lower.Format(time.RFC3339Nano)}
+		events, total, err := hermes.GetEvents(ctx, &filter, s.tenant, s.storage, s.view)
+		if err != nil {
+			return nil, err
+		}
+		var last time.Time
+		for _, event := range events {
+			eventTime, err := storage.ParseEventTime(event.Time)
+			if err != nil {
+				logg.Error("api.StreamEvents: skipping event %s with unparseable eventTime %q", event.ID, event.Time)
+				continue
+			}
+			last = eventTime.UTC()
+			if _, seen := s.sent[event.ID]; seen {
+				continue
+			}
+			s.sent[event.ID] = last
+			result = append(result, streamEvent{event, last})
+		}
+		if len(events) < int(filter.Limit) || int(filter.Offset)+len(events) >= total { //nolint:gosec // offsets are capped by MaxLimit
+			break
+		}
+		// continue after the last event; page by offset only while many events share one timestamp
+		if last.After(lower) {
+			lower = last
+			filter.Offset = 0
+		} else {
+			filter.Offset += uint(len(events))
+		}
+		if filter.Offset+filter.Limit > s.storage.MaxLimit() {
+			truncated = true
+			break
+		}
+	}
+
+	if truncated {
+		s.since = lower
+	} else {
+		s.since = maxTime(s.since, now.Add(-s.config.Lookback))
+	}
+	for id, eventTime := range s.sent {
+		if eventTime.Before(s.since) {
+			delete(s.sent, id)
+		}
+	}
+	return result, nil
+}
+
+// writeStreamEvent writes one event in the Server-Sent Events format.
+func writeStreamEvent(w http.ResponseWriter, event streamEvent) error {
+	data, err := json.Marshal(event.ListEvent)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: event\ndata: %s\n\n", formatStreamEventID(event.time, event.ID), data)
+	return err
+}
+
+// formatStreamEventID builds the SSE event ID "<eventTime in Unix nanoseconds>-<event ID>",
+// which clients send back as Last-Event-ID to resume.
+func formatStreamEventID(eventTime time.Time, eventID string) string {
+	return strconv.FormatInt(eventTime.UnixNano(), 10) + "-" + eventID
+}
+
+func parseStreamEventID(value string) (time.Time, string, error) {
+	nanos, eventID, ok := strings.Cut(value, "-")
+	unixNanos, err := strconv.ParseInt(nanos, 10, 64)
+	if !ok || err != nil || eventID == "" {
+		return time.Time{}, "", errors.New("malformed Last-Event-ID")
+	}
+	return time.Unix(0, unixNanos).UTC(), eventID, nil
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// connectionLimiter counts open connections per key.
+type connectionLimiter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func newConnectionLimiter() *connectionLimiter {
+	return &connectionLimiter{counts: make(map[string]int)}
+}
+
+// acquire registers a connection for key unless limit connections are open
+// already. A limit of zero means no limit. If ok is true, the caller must
+// call release when the connection is closed.
+func (l *connectionLimiter) acquire(key string, limit int) (release func(), ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if limit > 0 && l.counts[key] >= limit {
+		return nil, false
+	}
+	l.counts[key]++
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.counts[key]--
+		if l.counts[key] == 0 {
+			delete(l.counts, key)
+		}
+	}, true
+}
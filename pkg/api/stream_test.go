@@ -0,0 +1,209 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/sapcc/go-bits/audittools"
+	"github.com/sapcc/go-bits/httpapi"
+	"github.com/sapcc/go-bits/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapcc/hermes/pkg/hermes"
+	"github.com/sapcc/hermes/pkg/routing"
+	"github.com/sapcc/hermes/pkg/storage"
+)
+
+// streamTest serves the v1 API with a Memory storage on a real HTTP server,
+// because streamed responses cannot be read from an httptest.ResponseRecorder.
+type streamTest struct {
+	events *storage.Memory
+	server *httptest.Server
+}
+
+func newStreamTest(t *testing.T, config EventStreamConfig) *streamTest {
+	t.Helper()
+	validator := mock.NewValidator(mock.NewEnforcer(), map[string]string{
+		"project_id": testProjectID,
+		"user_id":    "alice",
+	})
+	prometheus.DefaultRegisterer = prometheus.NewPedanticRegistry()
+	events := storage.NewMemory(100)
+	v1API := NewV1API(validator, events, routing.NewMock(), audittools.NewNullAuditor(), WithEventStream(config))
+	server := httptest.NewServer(httpapi.Compose(v1API))
+	t.Cleanup(server.Close)
+	return &streamTest{events: events, server: server}
+}
+
+func fastStreamConfig() EventStreamConfig {
+	config := DefaultEventStreamConfig()
+	config.PollInterval = 10 * time.Millisecond
+	return config
+}
+
+func (st *streamTest) addEvent(tenantID, id, action string) {
+	st.events.Add([]string{tenantID}, cadf.Event{
+		ID:        id,
+		EventTime: time.Now().UTC().Format("2006-01-02T15:04:05.000000+00:00"),
+		Action:    cadf.Action(action),
+		Outcome:   cadf.SuccessOutcome,
+	})
+}
+
+// sseMessage is one message of an event stream. Comment lines are
+// reported as messages with only Comment set.
+type sseMessage struct {
+	ID      string
+	Event   string
+	Data    string
+	Comment string
+}
+
+// open starts a stream and returns its messages until the test ends.
+func (st *streamTest) open(t *testing.T, query, lastEventID string) (*http.Response, <-chan sseMessage) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(t.Context())
+	t.Cleanup(cancel)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, st.server.URL+"/v1/events/stream"+query, http.NoBody)
+	require.NoError(t, err)
+	req.Header.Set("X-Auth-Token", "something")
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp, readSSE(resp.Body)
+}
+
+// readSSE parses an event stream into messages until body is closed.
+func readSSE(body io.Reader) <-chan sseMessage {
+	messages := make(chan sseMessage, 100)
+	go func() {
+		defer close(messages)
+		scanner := bufio.NewScanner(body)
+		var msg sseMessage
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				if msg != (sseMessage{}) {
+					messages <- msg
+				}
+				msg = sseMessage{}
+			case strings.HasPrefix(line, ":"):
+				msg.Comment = strings.TrimSpace(strings.TrimPrefix(line, ":"))
+			case strings.HasPrefix(line, "id: "):
+				msg.ID = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "event: "):
+				msg.Event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				msg.Data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}()
+	return messages
+}
+
+// nextEvent returns the next message that carries an event, skipping heartbeats.
+func nextEvent(t *testing.T, messages <-chan sseMessage) (sseMessage, hermes.ListEvent) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case msg, ok := <-messages:
+			require.True(t, ok, "stream closed unexpectedly")
+			if msg.Event == "" {
+				continue
+			}
+			var event hermes.ListEvent
+			require.NoError(t, json.Unmarshal([]byte(msg.Data), &event))
+			return msg, event
+		case <-timeout:
+			t.Fatal("timed out waiting for an event")
+		}
+	}
+}
+
+func TestStreamEvents(t *testing.T) {
+	st := newStreamTest(t, fastStreamConfig())
+	st.addEvent(testProjectID, "a5fe3a49-0d6f-4b1d-8f4f-000000000001", "create")
+
+	resp, messages := st.open(t, "?action=delete", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// events that were stored before the stream was opened, that do not match the
+	// filter or that belong to other tenants are not sent
+	st.addEvent(testProjectID, "a5fe3a49-0d6f-4b1d-8f4f-000000000002", "create")
+	st.addEvent("other-project", "a5fe3a49-0d6f-4b1d-8f4f-000000000003", "delete")
+	st.addEvent(testProjectID, "a5fe3a49-0d6f-4b1d-8f4f-000000000004", "delete")
+	msg, event := nextEvent(t, messages)
+	assert.Equal(t, "event", msg.Event)
+	assert.Equal(t, "a5fe3a49-0d6f-4b1d-8f4f-000000000004", event.ID)
+	assert.True(t, strings.HasSuffix(msg.ID, "-"+event.ID), msg.ID)
+
+	st.addEvent(testProjectID, "a5fe3a49-0d6f-4b1d-8f4f-000000000005", "delete")
+	_, event = nextEvent(t, messages)
+	assert.Equal(t, "a5fe3a49-0d6f-4b1d-8f4f-000000000005", event.ID)
+}
+
+func TestStreamEvents_Resume(t *testing.T) {
+	st := newStreamTest(t, fastStreamConfig())
+	_, messages := st.open(t, "", "")
+	st.addEvent(testProjectID, "a5fe3a49-0d6f-4b1d-8f4f-000000000001", "delete")
+	first, _ := nextEvent(t, messages)
+
+	// events stored while the client was disconnected are sent right after reconnecting
+	st.addEvent(testProjectID, "a5fe3a49-0d6f-4b1d-8f4f-000000000002", "delete")
+	_, messages = st.open(t, "", first.ID)
+	_, event := nextEvent(t, messages)
+	assert.Equal(t, "a5fe3a49-0d6f-4b1d-8f4f-000000000002", event.ID)
+
+	resp, _ := st.open(t, "", "not-a-valid-id")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestStreamEvents_Heartbeat(t *testing.T) {
+	config := fastStreamConfig()
+	config.HeartbeatInterval = 10 * time.Millisecond
+	st := newStreamTest(t, config)
+	_, messages := st.open(t, "", "")
+	select {
+	case msg := <-messages:
+		assert.Equal(t, sseMessage{Comment: "heartbeat"}, msg)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a heartbeat")
+	}
+}
+
+func TestStreamEvents_ConnectionLimit(t *testing.T) {
+	config := fastStreamConfig()
+	config.MaxConnectionsPerTenant = 1
+	st := newStreamTest(t, config)
+	resp, _ := st.open(t, "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp, _ = st.open(t, "", "")
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+}
+
+func TestStreamEvents_InvalidParameters(t *testing.T) {
+	st := newStreamTest(t, fastStreamConfig())
+	for _, query := range []string{"?time=gte:2026-01-01T00:00:00Z", "?sort=time", "?limit=abc"} {
+		resp, _ := st.open(t, query, "")
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+	}
+}
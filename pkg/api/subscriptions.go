@@ -0,0 +1,360 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/sapcc/go-bits/audittools"
+	"github.com/sapcc/go-bits/gopherpolicy"
+	"github.com/sapcc/go-bits/logg"
+	"github.com/sapcc/go-bits/respondwith"
+
+	"github.com/sapcc/hermes/pkg/hermes"
+	"github.com/sapcc/hermes/pkg/subscriptions"
+	"github.com/sapcc/hermes/pkg/webhook"
+)
+
+const (
+	minSubscriptionSecretLength = 16
+	maxSubscriptionSecretLength = 256
+)
+
+// subscriptionRequest is the shape accepted on POST and PUT.
+// We use strict decoding (DisallowUnknownFields) so unknown fields → 400.
+type subscriptionRequest struct {
+	Name   string             `json:"name"`
+	Filter hermes.FieldFilter `json:"filter"`
+	URL    string             `json:"url"`
+	// Secret is generated on POST if empty, and kept unchanged on PUT if empty.
+	Secret string `json:"secret"`
+	// Enabled defaults to true.
+	Enabled *bool `json:"enabled"`
+}
+
+// subscriptionList is the response body of GET /v1/projects/{project_id}/subscriptions.
+type subscriptionList struct {
+	Subscriptions []subscriptions.Subscription `json:"subscriptions"`
+}
+
+// deadLetterList is the response body of GET /v1/projects/{project_id}/subscriptions/{subscription_id}/dead-letters.
+type deadLetterList struct {
+	DeadLetters []subscriptions.Delivery `json:"dead_letters"`
+}
+
+// ListSubscriptions handles GET /v1/projects/{project_id}/subscriptions.
+// Secrets are never included.
+func (p *v1Provider) ListSubscriptions(res http.ResponseWriter, req *http.Request) {
+	projectID := mux.Vars(req)["project_id"]
+	if _, ok := p.authSubscriptions(res, req, projectID, "subscription:list"); !ok {
+		return
+	}
+
+	subs, err := p.subscriptionStore.ListSubscriptions(req.Context(), projectID)
+	if err != nil {
+		logg.Error("subscriptions LIST: storage error for project %s: %s", projectID, err)
+		respondwith.ObfuscatedErrorText(res, err)
+		return
+	}
+	if subs == nil {
+		subs = []subscriptions.Subscription{}
+	}
+	for i := range subs {
+		subs[i].Secret = ""
+	}
+	ReturnESJSON(res, http.StatusOK, subscriptionList{Subscriptions: subs})
+}
+
+// CreateSubscription handles POST /v1/projects/{project_id}/subscriptions.
+// Returns 201 with the saved document. This is the only response that
+// includes the secret.
+// An audit event is emitted for every attempt — successful or not.
+func (p *v1Provider) CreateSubscription(res http.ResponseWriter, req *http.Request) {
+	projectID := mux.Vars(req)["project_id"]
+	token, ok := p.authSubscriptions(res, req, projectID, "subscription:manage")
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	userID := token.Context.Auth["user_id"]
+	if userID == "" {
+		http.Error(res, "token missing user identity", http.StatusUnauthorized)
+		return
+	}
+	sub := subscriptions.Subscription{
+		ID:        uuid.NewString(),
+		ProjectID: projectID,
+		// events are delivered from the moment of creation onwards
+		QueuedUntil: now,
+		CreatedAt:   now,
+		CreatedBy:   userID,
+		UpdatedAt:   now,
+		UpdatedBy:   userID,
+	}
+	recordAttempt := func(reasonCode int) {
+		p.auditor.Record(audittools.Event{
+			Time:       now,
+			Request:    req,
+			User:       token,
+			ReasonCode: reasonCode,
+			Action:     cadf.CreateAction,
+			Target:     sub,
+		})
+	}
+
+	var body subscriptionRequest
+	status, err := decodeJSONBody(res, req, &body)
+	if err == nil {
+		status, err = applySubscriptionRequest(&sub, body, p.webhookGuard)
+	}
+	if err != nil {
+		http.Error(res, err.Error(), status)
+		recordAttempt(status)
+		return
+	}
+	if sub.Secret == "" {
+		sub.Secret = generateSubscriptionSecret()
+	}
+
+	err = p.subscriptionStore.CreateSubscription(req.Context(), sub)
+	if errors.Is(err, subscriptions.ErrDuplicateName) {
+		http.Error(res, err.Error(), http.StatusConflict)
+		recordAttempt(http.StatusConflict)
+		return
+	}
+	if err != nil {
+		logg.Error("subscriptions POST: storage error for project %s: %s", projectID, err)
+		respondwith.ObfuscatedErrorText(res, err)
+		recordAttempt(http.StatusInternalServerError)
+		return
+	}
+
+	logg.Info("subscriptions POST: project=%s id=%s created_by=%s", projectID, sub.ID, userID)
+	recordAttempt(http.StatusCreated)
+	ReturnESJSON(res, http.StatusCreated, sub)
+}
+
+// GetSubscription handles GET /v1/projects/{project_id}/subscriptions/{subscription_id}.
+func (p *v1Provider) GetSubscription(res http.ResponseWriter, req *http.Request) {
+	projectID := mux.Vars(req)["project_id"]
+	if _, ok := p.authSubscriptions(res, req, projectID, "subscription:list"); !ok {
+		return
+	}
+	sub, ok := p.findSubscription(res, req, projectID)
+	if !ok {
+		return
+	}
+	sub.Secret = ""
+	ReturnESJSON(res, http.StatusOK, sub)
+}
+
+// UpdateSubscription handles PUT /v1/projects/{project_id}/subscriptions/{subscription_id}.
+// Replaces all user-settable fields; an empty secret keeps the existing one.
+// Returns 200 with the saved document (without the secret).
+// An audit event is emitted for every attempt on an existing subscription.
+func (p *v1Provider) UpdateSubscription(res http.ResponseWriter, req *http.Request) {
+	projectID := mux.Vars(req)["project_id"]
+	token, ok := p.authSubscriptions(res, req, projectID, "subscription:manage")
+	if !ok {
+		return
+	}
+	sub, ok := p.findSubscription(res, req, projectID)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	userID := token.Context.Auth["user_id"]
+	recordAttempt := func(reasonCode int) {
+		p.auditor.Record(audittools.Event{
+			Time:       now,
+			Request:    req,
+			User:       token,
+			ReasonCode: reasonCode,
+			Action:     cadf.UpdateAction,
+			Target:     *sub,
+		})
+	}
+
+	var body subscriptionRequest
+	status, err := decodeJSONBody(res, req, &body)
+	if err == nil {
+		status, err = applySubscriptionRequest(sub, body, p.webhookGuard)
+	}
+	if err != nil {
+		http.Error(res, err.Error(), status)
+		recordAttempt(status)
+		return
+	}
+	sub.UpdatedAt = now
+	sub.UpdatedBy = userID
+
+	err = p.subscriptionStore.UpdateSubscription(req.Context(), *sub)
+	switch {
+	case errors.Is(err, subscriptions.ErrDuplicateName):
+		http.Error(res, err.Error(), http.StatusConflict)
+		recordAttempt(http.StatusConflict)
+		return
+	case errors.Is(err, subscriptions.ErrNotFound):
+		// deleted concurrently
+		http.Error(res, "subscription not found", http.StatusNotFound)
+		return
+	case err != nil:
+		logg.Error("subscriptions PUT: storage error for subscription %s: %s", sub.ID, err)
+		respondwith.ObfuscatedErrorText(res, err)
+		recordAttempt(http.StatusInternalServerError)
+		return
+	}
+
+	logg.Info("subscriptions PUT: project=%s id=%s enabled=%t updated_by=%s", projectID, sub.ID, sub.Enabled, userID)
+	recordAttempt(http.StatusOK)
+	sub.Secret = ""
+	ReturnESJSON(res, http.StatusOK, sub)
+}
+
+// DeleteSubscription handles DELETE /v1/projects/{project_id}/subscriptions/{subscription_id}.
+// Returns 204. Pending deliveries and dead letters are deleted as well.
+func (p *v1Provider) DeleteSubscription(res http.ResponseWriter, req *http.Request) {
+	projectID := mux.Vars(req)["project_id"]
+	token, ok := p.authSubscriptions(res, req, projectID, "subscription:manage")
+	if !ok {
+		return
+	}
+	sub, ok := p.findSubscription(res, req, projectID)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	recordAttempt := func(reasonCode int) {
+		p.auditor.Record(audittools.Event{
+			Time:       now,
+			Request:    req,
+			User:       token,
+			ReasonCode: reasonCode,
+			Action:     cadf.DeleteAction,
+			Target:     *sub,
+		})
+	}
+	deleted, err := p.subscriptionStore.DeleteSubscription(req.Context(), sub.ID)
+	if err != nil {
+		logg.Error("subscriptions DELETE: storage error for subscription %s: %s", sub.ID, err)
+		respondwith.ObfuscatedErrorText(res, err)
+		recordAttempt(http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		// deleted concurrently
+		http.Error(res, "subscription not found", http.StatusNotFound)
+		return
+	}
+
+	recordAttempt(http.StatusNoContent)
+	logg.Info("subscriptions DELETE: project=%s id=%s deleted_by=%s", projectID, sub.ID, token.Context.Auth["user_id"])
+	res.WriteHeader(http.StatusNoContent)
+}
+
+// ListDeadLetters handles GET /v1/projects/{project_id}/subscriptions/{subscription_id}/dead-letters.
+// Returns the deliveries that failed permanently, including their payload and last error.
+func (p *v1Provider) ListDeadLetters(res http.ResponseWriter, req *http.Request) {
+	projectID := mux.Vars(req)["project_id"]
+	if _, ok := p.authSubscriptions(res, req, projectID, "subscription:list"); !ok {
+		return
+	}
+	sub, ok := p.findSubscription(res, req, projectID)
+	if !ok {
+		return
+	}
+
+	result, err := p.subscriptionStore.DeadLetters(req.Context(), sub.ID)
+	if err != nil {
+		logg.Error("subscriptions DEAD-LETTERS: storage error for subscription %s: %s", sub.ID, err)
+		respondwith.ObfuscatedErrorText(res, err)
+		return
+	}
+	if result == nil {
+		result = []subscriptions.Delivery{}
+	}
+	ReturnESJSON(res, http.StatusOK, deadLetterList{DeadLetters: result})
+}
+
+// authSubscriptions performs the project-scoped auth check for the
+// subscription endpoints and ensures that a store is configured.
+func (p *v1Provider) authSubscriptions(res http.ResponseWriter, req *http.Request, pathProjectID, rule string) (*gopherpolicy.Token, bool) {
+	token, ok := p.authProjectScoped(res, req, pathProjectID, rule)
+	if !ok {
+		return nil, false
+	}
+	if p.subscriptionStore == nil {
+		http.Error(res, "subscriptions are not enabled on this server", http.StatusNotImplemented)
+		return nil, false
+	}
+	return token, true
+}
+
+// findSubscription loads the subscription from the path. Subscriptions of
+// other projects are reported as not found.
+func (p *v1Provider) findSubscription(res http.ResponseWriter, req *http.Request, projectID string) (*subscriptions.Subscription, bool) {
+	id := mux.Vars(req)["subscription_id"]
+	if _, err := uuid.Parse(id); err != nil {
+		http.Error(res, "Invalid subscription ID format", http.StatusBadRequest)
+		return nil, false
+	}
+	sub, err := p.subscriptionStore.GetSubscription(req.Context(), id)
+	if errors.Is(err, subscriptions.ErrNotFound) || (err == nil && sub.ProjectID != projectID) {
+		http.Error(res, "subscription not found", http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		logg.Error("subscriptions GET: storage error for subscription %s: %s", id, err)
+		respondwith.ObfuscatedErrorText(res, err)
+		return nil, false
+	}
+	return sub, true
+}
+
+// applySubscriptionRequest validates the request body and copies it into sub.
+// On error, it returns the HTTP status to respond with.
+func applySubscriptionRequest(sub *subscriptions.Subscription, body subscriptionRequest, guard webhook.Guard) (int, error) {
+	name := strings.TrimSpace(body.Name)
+	if name == "" || len(name) > maxSavedSearchNameLength {
+		return http.StatusBadRequest, fmt.Errorf("name must be between 1 and %d characters", maxSavedSearchNameLength)
+	}
+	if !isWebhookURL(body.URL) {
+		return http.StatusBadRequest, errors.New("url must be an absolute http or https URL")
+	}
+	if err := guard.CheckURL(body.URL); err != nil {
+		return http.StatusUnprocessableEntity, fmt.Errorf("url is not allowed: %w", err)
+	}
+	if body.Secret != "" && (len(body.Secret) < minSubscriptionSecretLength || len(body.Secret) > maxSubscriptionSecretLength) {
+		return http.StatusBadRequest, fmt.Errorf("secret must be between %d and %d characters",
+			minSubscriptionSecretLength, maxSubscriptionSecretLength)
+	}
+
+	sub.Name = name
+	sub.Filter = body.Filter
+	sub.URL = body.URL
+	if body.Secret != "" {
+		sub.Secret = body.Secret
+	}
+	sub.Enabled = body.Enabled == nil || *body.Enabled
+	return http.StatusOK, nil
+}
+
+// generateSubscriptionSecret returns 32 random bytes, hex-encoded.
+func generateSubscriptionSecret() string {
+	buf := make([]byte, 32)
+	_, _ = rand.Read(buf) //nolint:errcheck // crypto/rand.Read never returns an error
+	return hex.EncodeToString(buf)
+}
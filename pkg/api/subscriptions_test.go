@@ -0,0 +1,206 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/sapcc/go-bits/audittools"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapcc/hermes/pkg/hermes"
+	"github.com/sapcc/hermes/pkg/subscriptions"
+	"github.com/sapcc/hermes/pkg/webhook"
+)
+
+const subscriptionsPath = "/v1/projects/" + testProjectID + "/subscriptions"
+
+func validSubscriptionBody() map[string]any {
+	return map[string]any{
+		"name":   "role changes",
+		"filter": map[string]string{"target_type": "data/security/project"},
+		"url":    "https://siem.example.com/hermes",
+	}
+}
+
+func TestSubscriptions_CRUD(t *testing.T) {
+	store := subscriptions.NewMock()
+	auditor := audittools.NewMockAuditor()
+	admin := newTestUser(t, auditor, testProjectID, "admin", nil, WithSubscriptionStore(store))
+	viewer := newTestUser(t, auditor, testProjectID, "viewer", []string{"subscription:manage"}, WithSubscriptionStore(store))
+	neighbor := newTestUser(t, auditor, "test-project-2", "carol", nil, WithSubscriptionStore(store))
+
+	rec := viewer.do(http.MethodPost, subscriptionsPath, validSubscriptionBody())
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	// the generated secret is only returned on create
+	rec = admin.do(http.MethodPost, subscriptionsPath, validSubscriptionBody())
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var sub subscriptions.Subscription
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &sub))
+	assert.Equal(t, testProjectID, sub.ProjectID)
+	assert.Equal(t, hermes.FieldFilter{TargetType: "data/security/project"}, sub.Filter)
+	assert.Len(t, sub.Secret, 64)
+	assert.True(t, sub.Enabled, "subscriptions are enabled by default")
+	assert.Equal(t, sub.CreatedAt, sub.QueuedUntil)
+	auditor.ExpectEvents(t, cadf.Event{
+		Action:      cadf.CreateAction,
+		Outcome:     cadf.SuccessOutcome,
+		Reason:      cadf.Reason{ReasonType: "HTTP", ReasonCode: "201"},
+		Target:      sub.Render(),
+		RequestPath: subscriptionsPath,
+	})
+
+	rec = admin.do(http.MethodPost, subscriptionsPath, validSubscriptionBody())
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = viewer.do(http.MethodGet, subscriptionsPath, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), sub.Secret)
+	var list subscriptionList
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list.Subscriptions, 1)
+	assert.Equal(t, sub.ID, list.Subscriptions[0].ID)
+
+	subPath := subscriptionsPath + "/" + sub.ID
+	rec = viewer.do(http.MethodGet, subPath, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), sub.Secret)
+
+	// subscriptions of other projects are not visible
+	rec = neighbor.do(http.MethodGet, "/v1/projects/test-project-2/subscriptions/"+sub.ID, nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// an empty secret on PUT keeps the existing one
+	body := validSubscriptionBody()
+	body["enabled"] = false
+	rec = admin.do(http.MethodPut, subPath, body)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var updated subscriptions.Subscription
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &updated))
+	assert.False(t, updated.Enabled)
+	assert.Empty(t, updated.Secret)
+	stored, err := store.GetSubscription(t.Context(), sub.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sub.Secret, stored.Secret)
+
+	body["secret"] = strings.Repeat("s", 20)
+	rec = admin.do(http.MethodPut, subPath, body)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	stored, err = store.GetSubscription(t.Context(), sub.ID)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("s", 20), stored.Secret)
+
+	rec = admin.do(http.MethodDelete, subPath, nil)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	rec = admin.do(http.MethodGet, subPath, nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestSubscriptions_Validation(t *testing.T) {
+	admin := newTestUser(t, audittools.NewNullAuditor(), testProjectID, "admin", nil, WithSubscriptionStore(subscriptions.NewMock()))
+	tt := []struct {
+		name   string
+		modify func(body map[string]any)
+	}{
+		{"MissingName", func(body map[string]any) { delete(body, "name") }},
+		{"MissingURL", func(body map[string]any) { delete(body, "url") }},
+		{"RelativeURL", func(body map[string]any) { body["url"] = "/hook" }},
+		{"URLScheme", func(body map[string]any) { body["url"] = "ftp://example.com/hook" }},
+		{"ShortSecret", func(body map[string]any) { body["secret"] = "hunter2" }},
+		{"UnknownFilter", func(body map[string]any) { body["filter"] = map[string]string{"project_id": "other"} }},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			body := validSubscriptionBody()
+			tc.modify(body)
+			rec := admin.do(http.MethodPost, subscriptionsPath, body)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestSubscriptions_WebhookGuard(t *testing.T) {
+	admin := newTestUser(t, audittools.NewNullAuditor(), testProjectID, "admin", nil, WithSubscriptionStore(subscriptions.NewMock()),
+		WithWebhookGuard(webhook.Guard{AllowedNetworks: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}}))
+	rec := admin.do(http.MethodPost, subscriptionsPath, validSubscriptionBody())
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var sub subscriptions.Subscription
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &sub))
+
+	for _, callbackURL := range []string{
+		"http://siem.example.com/hermes",
+		"https://localhost/hermes",
+		"https://127.0.0.1:8080/hermes",
+		"https://[::1]/hermes",
+		"https://0.0.0.0/hermes",
+		"https://172.16.0.1/hermes",
+		"https://169.254.169.254/latest/meta-data",
+	} {
+		body := validSubscriptionBody()
+		body["name"] = callbackURL
+		body["url"] = callbackURL
+		rec = admin.do(http.MethodPost, subscriptionsPath, body)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, "POST %s: %s", callbackURL, rec.Body.String())
+		rec = admin.do(http.MethodPut, subscriptionsPath+"/"+sub.ID, body)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, "PUT %s: %s", callbackURL, rec.Body.String())
+	}
+
+	// internal networks allowed by the operator are accepted
+	body := validSubscriptionBody()
+	body["url"] = "https://10.1.2.3/hermes"
+	rec = admin.do(http.MethodPut, subscriptionsPath+"/"+sub.ID, body)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+}
+
+func TestSubscriptions_DeadLetters(t *testing.T) {
+	store := subscriptions.NewMock()
+	admin := newTestUser(t, audittools.NewNullAuditor(), testProjectID, "admin", nil, WithSubscriptionStore(store))
+	rec := admin.do(http.MethodPost, subscriptionsPath, validSubscriptionBody())
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var sub subscriptions.Subscription
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &sub))
+
+	deadLettersPath := subscriptionsPath + "/" + sub.ID + "/dead-letters"
+	rec = admin.do(http.MethodGet, deadLettersPath, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"dead_letters":[]}`, rec.Body.String())
+
+	eventTime := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	delivery := subscriptions.Delivery{
+		ID:             "7d0c2a6e-3f5c-4b8e-9c1a-2f4e6a8b0c1d",
+		SubscriptionID: sub.ID,
+		EventID:        "event-1",
+		EventTime:      eventTime,
+		Payload:        json.RawMessage(`{"id":"event-1"}`),
+		Status:         subscriptions.DeliveryDead,
+		Attempts:       8,
+		NextAttemptAt:  eventTime,
+		LastError:      "webhook responded with 503 Service Unavailable",
+		CreatedAt:      eventTime,
+	}
+	require.NoError(t, store.Enqueue(t.Context(), sub.ID, sub.QueuedUntil, sub.QueuedUntil.Add(time.Minute), []subscriptions.Delivery{delivery}))
+
+	rec = admin.do(http.MethodGet, deadLettersPath, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list deadLetterList
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list.DeadLetters, 1)
+	assert.Equal(t, "event-1", list.DeadLetters[0].EventID)
+	assert.Equal(t, 8, list.DeadLetters[0].Attempts)
+	assert.JSONEq(t, `{"id":"event-1"}`, string(list.DeadLetters[0].Payload))
+}
+
+func TestSubscriptions_NotEnabled(t *testing.T) {
+	admin := newTestUser(t, audittools.NewNullAuditor(), testProjectID, "admin", nil)
+	rec := admin.do(http.MethodGet, subscriptionsPath, nil)
+	assert.Equal(t, http.StatusNotImplemented, rec.Code)
+}
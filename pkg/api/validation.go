@@ -0,0 +1,66 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/sapcc/hermes/pkg/hermes"
+)
+
+const (
+	// maxValidateBodySize caps the request body of POST /v1/validate.
+	maxValidateBodySize = 1 << 20
+	// maxValidateEvents caps the number of events in one POST /v1/validate request.
+	maxValidateEvents = 100
+)
+
+// ValidateEvents handles POST /v1/validate.
+// It checks one event, or a JSON array of events, with hermes.ValidateEventJSON
+// and responds with one result per event. Invalid events do not make the
+// request fail; only bodies that are neither an object nor an array do.
+func (p *v1Provider) ValidateEvents(res http.ResponseWriter, req *http.Request) {
+	if _, ok := p.AuthHandler(res, req, "event:validate"); !ok {
+		return
+	}
+	if ct := req.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		http.Error(res, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(res, req.Body, maxValidateBodySize))
+	if err != nil {
+		http.Error(res, "invalid request body: "+err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	body = bytes.TrimSpace(body)
+	if !bytes.HasPrefix(body, []byte("[")) {
+		if !bytes.HasPrefix(body, []byte("{")) {
+			http.Error(res, "request body must be an event or an array of events", http.StatusBadRequest)
+			return
+		}
+		ReturnESJSON(res, http.StatusOK, hermes.ValidateEventJSON(body))
+		return
+	}
+
+	var events []json.RawMessage
+	if err := json.Unmarshal(body, &events); err != nil {
+		http.Error(res, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(events) > maxValidateEvents {
+		http.Error(res, fmt.Sprintf("at most %d events can be validated at once", maxValidateEvents), http.StatusBadRequest)
+		return
+	}
+	results := make([]hermes.ValidationResult, len(events))
+	for idx, event := range events {
+		results[idx] = hermes.ValidateEventJSON(event)
+	}
+	ReturnESJSON(res, http.StatusOK, results)
+}
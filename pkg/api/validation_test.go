@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sapcc/go-bits/audittools"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapcc/hermes/pkg/hermes"
+)
+
+const validTestEvent = `{
+	"typeURI": "http://schemas.dmtf.org/cloud/audit/1.0/event",
+	"id": "7189ce80-6e73-5ad9-bdc5-dcc47f176378",
+	"eventTime": "2026-01-02T15:04:05.000000+00:00",
+	"eventType": "activity",
+	"action": "create",
+	"outcome": "success",
+	"requestPath": "/v2/servers",
+	"initiator": {"typeURI": "service/security/account/user", "id": "u1", "name": "alice", "project_id": "a759dcc2a2384a76b0386bb985952373"},
+	"target": {"typeURI": "compute/server", "id": "server-1", "project_id": "a759dcc2a2384a76b0386bb985952373"},
+	"observer": {"typeURI": "service/compute", "id": "nova", "name": "nova"}
+}`
+
+func TestValidateEvents(t *testing.T) {
+	user := newTestUser(t, audittools.NewNullAuditor(), testProjectID, "u1", nil)
+
+	// a single event gets a single result
+	rec := user.do(http.MethodPost, "/v1/validate", json.RawMessage(validTestEvent))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var result hermes.ValidationResult
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.True(t, result.Valid)
+	assert.Equal(t, []string{"a759dcc2a2384a76b0386bb985952373"}, result.TenantIDs)
+	assert.Empty(t, result.Errors)
+	assert.Empty(t, result.Warnings)
+
+	// an array gets one result per event, including events that are not even objects
+	invalid := strings.Replace(validTestEvent, `"observer": {"typeURI": "service/compute", "id": "nova", "name": "nova"}`, `"obsrver": {}`, 1)
+	rec = user.do(http.MethodPost, "/v1/validate", json.RawMessage("["+validTestEvent+","+invalid+`,"foo"]`))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var results []hermes.ValidationResult
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &results))
+	require.Len(t, results, 3)
+	assert.True(t, results[0].Valid)
+	assert.False(t, results[1].Valid)
+	assert.Contains(t, results[1].Errors, hermes.Finding{Field: "observer.typeURI", Message: "is required"})
+	assert.Contains(t, results[1].Warnings, hermes.Finding{Field: "obsrver", Message: "is not a field of the event schema and will not be shown"})
+	assert.False(t, results[2].Valid)
+
+	// bodies that are neither events nor arrays are rejected
+	rec = user.do(http.MethodPost, "/v1/validate", "foo")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = user.do(http.MethodPost, "/v1/validate", make([]struct{}, maxValidateEvents+1))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = user.do(http.MethodPost, "/v1/validate", nil)
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+
+	forbidden := newTestUser(t, audittools.NewNullAuditor(), testProjectID, "u1", []string{"event:validate"})
+	rec = forbidden.do(http.MethodPost, "/v1/validate", json.RawMessage(validTestEvent))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
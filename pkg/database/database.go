@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+// Package database connects to the hermez database. The stores of the hermez
+// subsystems (routing, integrity, searches, alerts, subscriptions, forwarding)
+// share one database, and each of them brings its own migrations. Since the
+// schema version is tracked once per database, the versions of all migrations
+// are unique across the packages.
+package database
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/sapcc/go-api-declarations/bininfo"
+	"github.com/sapcc/go-bits/logg"
+	"github.com/sapcc/go-bits/osext"
+	"go.xyrillian.de/gg/gsql"
+	"go.xyrillian.de/gg/pgruntime"
+
+	// load DB driver
+	_ "github.com/lib/pq"
+)
+
+// Connect connects to postgres using env-var based connection params and
+// runs the pending migrations of the given packages. The env vars are:
+//
+//	HERMES_PG_HOSTNAME (default: localhost)
+//	HERMES_PG_PORT     (default: 5432)
+//	HERMES_PG_USERNAME (default: hermes)
+//	HERMES_PG_PASSWORD
+//	HERMES_PG_DBNAME   (default: hermes)
+//	HERMES_PG_CONNECTION_OPTIONS
+//
+// The returned target allows stores to open additional connections, e.g. for
+// LISTEN.
+func Connect(ctx context.Context, migrations ...map[int64]string) (*gsql.DB, pgruntime.ConnectionTarget, error) {
+	target := pgruntime.ConnectionTarget{
+		HostName:          osext.GetenvOrDefault("HERMES_PG_HOSTNAME", "localhost"),
+		Port:              osext.GetenvOrDefault("HERMES_PG_PORT", "5432"),
+		UserName:          osext.GetenvOrDefault("HERMES_PG_USERNAME", "hermes"),
+		Password:          osext.GetenvOrDefault("HERMES_PG_PASSWORD", ""),
+		ConnectionOptions: osext.GetenvOrDefault("HERMES_PG_CONNECTION_OPTIONS", ""),
+		DatabaseName:      osext.GetenvOrDefault("HERMES_PG_DBNAME", "hermes"),
+		ApplicationName:   bininfo.Component(),
+	}
+	merged, err := MergeMigrations(migrations...)
+	if err != nil {
+		return nil, target, err
+	}
+	db, err := pgruntime.StdConnector("postgres").Connect(ctx, target, pgruntime.ConnectionBehavior{
+		Migrations: merged,
+	})
+	if err != nil {
+		return nil, target, fmt.Errorf("database: cannot connect to postgres: %w", err)
+	}
+	db.SetMaxOpenConns(16)
+	db.SetMaxIdleConns(4)
+	logg.Info("database: postgres connected and migrations applied")
+	return db, target, nil
+}
+
+// MergeMigrations combines the migrations of several packages into one set.
+// It fails if two packages use the same version.
+func MergeMigrations(migrations ...map[int64]string) (map[int64]string, error) {
+	merged := make(map[int64]string)
+	for _, m := range migrations {
+		for _, version := range slices.Sorted(maps.Keys(m)) {
+			if _, exists := merged[version]; exists {
+				return nil, fmt.Errorf("database: duplicate migration version %d", version)
+			}
+			merged[version] = m[version]
+		}
+	}
+	return merged, nil
+}
@@ -0,0 +1,41 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapcc/hermes/pkg/alerts"
+	"github.com/sapcc/hermes/pkg/forwarding"
+	"github.com/sapcc/hermes/pkg/integrity"
+	"github.com/sapcc/hermes/pkg/routing"
+	"github.com/sapcc/hermes/pkg/searches"
+	"github.com/sapcc/hermes/pkg/subscriptions"
+)
+
+func TestMergeMigrations(t *testing.T) {
+	merged, err := MergeMigrations(map[int64]string{1: "a", 3: "c"}, map[int64]string{2: "b"})
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]string{1: "a", 2: "b", 3: "c"}, merged)
+
+	_, err = MergeMigrations(map[int64]string{1: "a"}, map[int64]string{1: "b"})
+	assert.ErrorContains(t, err, "duplicate migration version 1")
+
+	// the packages that share the hermez database use distinct versions
+	merged, err = MergeMigrations(
+		routing.DBMigrations,
+		integrity.DBMigrations,
+		searches.DBMigrations,
+		alerts.DBMigrations,
+		subscriptions.DBMigrations,
+		forwarding.DBMigrations,
+	)
+	require.NoError(t, err)
+	for version := int64(1); version <= 16; version++ {
+		assert.Contains(t, merged, version)
+	}
+}
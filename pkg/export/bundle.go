@@ -0,0 +1,405 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+// Package export writes and verifies signed audit export bundles.
+//
+// A bundle is a gzip-compressed tar archive with these files, in this order:
+//
+//   - events-000001.ndjson, events-000002.ndjson etc. contain the exported
+//     CADF events, one JSON document per line. Each file holds up to
+//     Exporter.ChunkSize events, so that bundles can be written and verified
+//     without holding all events in memory.
+//   - manifest.json describes the export (tenant, filter, time range, event
+//     count) and lists the SHA-256 digest of every events file.
+//   - manifest.json.sig is the base64-encoded Ed25519 signature of manifest.json.
+//
+// Because the manifest covers the digests of all other files, verifying the
+// signature of the manifest and the digests is enough to prove that the bundle
+// was produced by the holder of the signing key and not altered since. Since
+// the manifest comes last, a bundle whose writing was aborted fails verification.
+package export
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+
+	"github.com/sapcc/hermes/pkg/hermes"
+	"github.com/sapcc/hermes/pkg/storage"
+)
+
+const (
+	// FormatVersion identifies the bundle layout in Manifest.Format.
+	FormatVersion = "hermes-export/v1"
+
+	manifestFileName  = "manifest.json"
+	signatureFileName = "manifest.json.sig"
+	// eventsFileNameFormat is the name of the events files, numbered from 1.
+	eventsFileNameFormat = "events-%06d.ndjson"
+)
+
+// ErrInvalidSignature is returned by Verify when the manifest signature does
+// not match the given public key.
+var ErrInvalidSignature = errors.New("manifest signature is not valid for this public key")
+
+// Manifest describes the contents of a bundle.
+type Manifest struct {
+	Format    string    `json:"format"`
+	TenantID  string    `json:"tenant_id"`
+	CreatedAt time.Time `json:"created_at"`
+	Filter    Filter    `json:"filter"`
+	// TimeRange contains the time operators of the filter (gt, gte, lt, lte) as given by the caller.
+	TimeRange map[string]string `json:"time_range"`
+	// EventCount is the number of lines in all events files.
+	EventCount int `json:"event_count"`
+	// FirstEventTime and LastEventTime are the earliest and latest eventTime in the bundle.
+	FirstEventTime string       `json:"first_event_time,omitempty"`
+	LastEventTime  string       `json:"last_event_time,omitempty"`
+	Files          []FileDigest `json:"files"`
+	// KeyID is the fingerprint of the public key that verifies manifest.json.sig.
+	KeyID string `json:"key_id"`
+}
+
+// Filter records the event selection of an export, using the query parameter
+// names of GET /v1/events.
+type Filter struct {
+	ObserverType  string   `json:"observer_type,omitempty"`
+	TargetType    string   `json:"target_type,omitempty"`
+	TargetID      string   `json:"target_id,omitempty"`
+	InitiatorID   string   `json:"initiator_id,omitempty"`
+	InitiatorType string   `json:"initiator_type,omitempty"`
+	InitiatorName string   `json:"initiator_name,omitempty"`
+	Action        string   `json:"action,omitempty"`
+	Outcome       string   `json:"outcome,omitempty"`
+	Search        string   `json:"search,omitempty"`
+	RequestPath   string   `json:"request_path,omitempty"`
+	Sort          []string `json:"sort,omitempty"`
+}
+
+// FileDigest is the size and SHA-256 digest of one file in a bundle.
+type FileDigest struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+func newFilter(f *hermes.EventFilter) Filter {
+	result := Filter{
+		ObserverType:  f.ObserverType,
+		TargetType:    f.TargetType,
+		TargetID:      f.TargetID,
+		InitiatorID:   f.InitiatorID,
+		InitiatorType: f.InitiatorType,
+		InitiatorName: f.InitiatorName,
+		Action:        f.Action,
+		Outcome:       f.Outcome,
+		Search:        f.Search,
+		RequestPath:   f.RequestPath,
+	}
+	for _, order := range f.Sort {
+		result.Sort = append(result.Sort, order.Fieldname+":"+order.Order)
+	}
+	return result
+}
+
+// Exporter writes signed bundles of the events in storage.
+type Exporter struct {
+	Storage storage.Storage
+	Signer  *Signer
+	// PageSize is the number of events fetched from storage per request.
+	PageSize uint
+	// ChunkSize is the maximum number of events per events file. One file is
+	// held in memory while the bundle is written.
+	ChunkSize int
+	// Now returns the current time. Tests replace it with a mock clock.
+	Now func() time.Time
+}
+
+// NewExporter builds an Exporter with the default page and chunk sizes.
+func NewExporter(eventStore storage.Storage, signer *Signer) *Exporter {
+	return &Exporter{
+		Storage:   eventStore,
+		Signer:    signer,
+		PageSize:  1000,
+		ChunkSize: 10000,
+		Now:       time.Now,
+	}
+}
+
+// Write selects the tenant's events with the same semantics as hermes.GetEvents
+// and writes them as a signed bundle to w. Offset and Limit of the filter are
+// ignored: all matching events are exported, sorted by time unless the filter
+// specifies a different order. If more events match than storage can return,
+// hermes.ErrTooManyEvents is returned.
+//
+// The bundle is written while paging through storage. Nothing is written to w
+// before the first events file is complete, so errors from the first pages
+// (including hermes.ErrTooManyEvents) are reported before the first byte of
+// the bundle. Later errors leave an incomplete bundle without a manifest.
+func (e *Exporter) Write(ctx context.Context, w io.Writer, filter *hermes.EventFilter, tenantID string, view *hermes.EventView) (*Manifest, error) {
+	selection := *filter
+	if len(selection.Sort) == 0 {
+		selection.Sort = []hermes.FieldOrder{{Fieldname: "time", Order: "asc"}}
+	}
+	keyID, err := e.Signer.KeyID()
+	if err != nil {
+		return nil, err
+	}
+	manifest := Manifest{
+		Format:    FormatVersion,
+		TenantID:  tenantID,
+		CreatedAt: e.Now().UTC(),
+		Filter:    newFilter(&selection),
+		TimeRange: selection.Time,
+		Files:     []FileDigest{},
+		KeyID:     keyID,
+	}
+	if manifest.TimeRange == nil {
+		manifest.TimeRange = map[string]string{}
+	}
+
+	bundle := &bundleWriter{w: w, modTime: manifest.CreatedAt}
+	var (
+		chunk       bytes.Buffer
+		chunkEvents int
+	)
+	encoder := json.NewEncoder(&chunk)
+	writeChunk := func() error {
+		if chunkEvents == 0 {
+			return nil
+		}
+		name := fmt.Sprintf(eventsFileNameFormat, len(manifest.Files)+1)
+		manifest.Files = append(manifest.Files, digest(name, chunk.Bytes()))
+		err := bundle.writeFile(name, chunk.Bytes())
+		chunk.Reset()
+		chunkEvents = 0
+		return err
+	}
+	count, err := hermes.ForEachEvent(ctx, &selection, tenantID, e.Storage, view, e.PageSize, func(event *cadf.Event) error {
+		if manifest.FirstEventTime == "" || event.EventTime < manifest.FirstEventTime {
+			manifest.FirstEventTime = event.EventTime
+		}
+		if event.EventTime > manifest.LastEventTime {
+			manifest.LastEventTime = event.EventTime
+		}
+		if err := encoder.Encode(event); err != nil {
+			return err
+		}
+		chunkEvents++
+		if chunkEvents >= e.ChunkSize {
+			return writeChunk()
+		}
+		return nil
+	})
+	if err == nil {
+		err = writeChunk()
+	}
+	if err != nil {
+		return nil, err
+	}
+	manifest.EventCount = count
+
+	manifestBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	signature, err := e.Signer.Sign(manifestBytes)
+	if err != nil {
+		return nil, err
+	}
+	if err := bundle.writeFile(manifestFileName, manifestBytes); err != nil {
+		return nil, err
+	}
+	if err := bundle.writeFile(signatureFileName, []byte(base64.StdEncoding.EncodeToString(signature)+"\n")); err != nil {
+		return nil, err
+	}
+	if err := bundle.close(); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// bundleWriter writes the files of a bundle into a gzip-compressed tar
+// archive. Nothing is written to w before the first file.
+type bundleWriter struct {
+	w       io.Writer
+	modTime time.Time
+	gz      *gzip.Writer
+	tw      *tar.Writer
+}
+
+func (b *bundleWriter) writeFile(name string, content []byte) error {
+	if b.tw == nil {
+		b.gz = gzip.NewWriter(b.w)
+		b.tw = tar.NewWriter(b.gz)
+	}
+	err := b.tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    int64(len(content)),
+		ModTime: b.modTime,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = b.tw.Write(content)
+	return err
+}
+
+func (b *bundleWriter) close() error {
+	if err := b.tw.Close(); err != nil {
+		return err
+	}
+	return b.gz.Close()
+}
+
+func digest(name string, content []byte) FileDigest {
+	sum := sha256.Sum256(content)
+	return FileDigest{Name: name, Size: int64(len(content)), SHA256: hex.EncodeToString(sum[:])}
+}
+
+// Verify checks a bundle read from r against the given public key: the
+// manifest signature, the digest of every file, that no unlisted files are
+// present and that the event count matches. Returns the verified manifest.
+// The events files are checked while reading, so that they need not fit into
+// memory.
+func Verify(r io.Reader, pub ed25519.PublicKey) (*Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("bundle is not gzip-compressed: %w", err)
+	}
+	var (
+		manifestBytes    []byte
+		encodedSignature []byte
+		// the digest, event count and parse error of every events file
+		digests     = make(map[string]FileDigest)
+		eventCounts = make(map[string]int)
+		parseErrors = make(map[string]error)
+	)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot read bundle: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("bundle contains %s, which is not a regular file", header.Name)
+		}
+		_, exists := digests[header.Name]
+		if exists || (header.Name == manifestFileName && manifestBytes != nil) || (header.Name == signatureFileName && encodedSignature != nil) {
+			return nil, fmt.Errorf("bundle contains %s more than once", header.Name)
+		}
+
+		switch header.Name {
+		case manifestFileName:
+			manifestBytes, err = io.ReadAll(tr)
+		case signatureFileName:
+			encodedSignature, err = io.ReadAll(tr)
+		default:
+			hash := sha256.New()
+			content := &countingReader{r: io.TeeReader(tr, hash)}
+			eventCounts[header.Name], parseErrors[header.Name] = countEvents(content)
+			// read the rest in case of a parse error, so that the digest is complete
+			_, err = io.Copy(io.Discard, content)
+			digests[header.Name] = FileDigest{Name: header.Name, Size: content.n, SHA256: hex.EncodeToString(hash.Sum(nil))}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot read %s from bundle: %w", header.Name, err)
+		}
+	}
+
+	if manifestBytes == nil {
+		return nil, fmt.Errorf("bundle does not contain %s", manifestFileName)
+	}
+	if encodedSignature == nil {
+		return nil, fmt.Errorf("bundle does not contain %s", signatureFileName)
+	}
+	signature, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(encodedSignature)))
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode %s: %w", signatureFileName, err)
+	}
+	if !ed25519.Verify(pub, manifestBytes, signature) {
+		return nil, ErrInvalidSignature
+	}
+
+	// from here on, the manifest is known to be authentic
+	var manifest Manifest
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %w", manifestFileName, err)
+	}
+	if manifest.Format != FormatVersion {
+		return nil, fmt.Errorf("unsupported bundle format %q", manifest.Format)
+	}
+
+	listed := make(map[string]bool)
+	count := 0
+	for _, expected := range manifest.Files {
+		actual, ok := digests[expected.Name]
+		if !ok {
+			return nil, fmt.Errorf("bundle does not contain %s", expected.Name)
+		}
+		if actual != expected {
+			return nil, fmt.Errorf("%s was modified: expected sha256 %s and size %d, got sha256 %s and size %d",
+				expected.Name, expected.SHA256, expected.Size, actual.SHA256, actual.Size)
+		}
+		if err := parseErrors[expected.Name]; err != nil {
+			return nil, fmt.Errorf("cannot parse event %d in %s: %w", eventCounts[expected.Name]+1, expected.Name, err)
+		}
+		listed[expected.Name] = true
+		count += eventCounts[expected.Name]
+	}
+	for name := range digests {
+		if !listed[name] {
+			return nil, fmt.Errorf("bundle contains %s, which is not listed in the manifest", name)
+		}
+	}
+	if count != manifest.EventCount {
+		return nil, fmt.Errorf("the events files contain %d events, but the manifest lists %d", count, manifest.EventCount)
+	}
+	return &manifest, nil
+}
+
+// countEvents returns the number of CADF events in an events file, and the
+// error for the first event that cannot be parsed.
+func countEvents(r io.Reader) (int, error) {
+	count := 0
+	decoder := json.NewDecoder(r)
+	for {
+		var event cadf.Event
+		err := decoder.Decode(&event)
+		if errors.Is(err, io.EOF) {
+			return count, nil
+		}
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+}
+
+// countingReader counts the bytes that are read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(buf []byte) (int, error) {
+	n, err := c.r.Read(buf)
+	c.n += int64(n)
+	return n, err
+}
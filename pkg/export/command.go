@@ -0,0 +1,62 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package export
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// VerifyCommand implements `hermes verify-bundle`. It verifies an export
+// bundle against a public key, reports the result on stdout and stderr and
+// returns the process exit code.
+// It does not need a config file, so that it can be run by external auditors.
+func VerifyCommand(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("verify-bundle", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	keyPath := fs.String("key", "", "path to the PEM-encoded Ed25519 public key (from GET /v1/export/public-key)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s verify-bundle -key <public-key.pem> <bundle.tar.gz>\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *keyPath == "" || fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	keyBytes, err := os.ReadFile(*keyPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err.Error())
+		return 1
+	}
+	pub, err := ParsePublicKeyPEM(keyBytes)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %s\n", *keyPath, err.Error())
+		return 1
+	}
+
+	bundle, err := os.Open(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(stderr, err.Error())
+		return 1
+	}
+	defer bundle.Close()
+
+	manifest, err := Verify(bundle, pub)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: verification FAILED: %s\n", fs.Arg(0), err.Error())
+		return 1
+	}
+	fmt.Fprintf(stdout, "%s: OK\n", fs.Arg(0))
+	fmt.Fprintf(stdout, "  tenant:     %s\n", manifest.TenantID)
+	fmt.Fprintf(stdout, "  created at: %s\n", manifest.CreatedAt.Format("2006-01-02T15:04:05Z07:00"))
+	fmt.Fprintf(stdout, "  events:     %d (%s to %s)\n", manifest.EventCount, manifest.FirstEventTime, manifest.LastEventTime)
+	fmt.Fprintf(stdout, "  key id:     %s\n", manifest.KeyID)
+	return 0
+}
@@ -0,0 +1,268 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package export
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/sapcc/go-bits/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapcc/hermes/pkg/hermes"
+	"github.com/sapcc/hermes/pkg/storage"
+)
+
+func newTestExporter(t *testing.T, events int) (*Exporter, ed25519.PublicKey) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	eventStore := storage.NewMemory(100)
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range events {
+		outcome := cadf.SuccessOutcome
+		if i%2 == 1 {
+			outcome = cadf.FailureOutcome
+		}
+		eventStore.Add([]string{"project-a"}, cadf.Event{
+			ID:        string(rune('a' + i)),
+			EventTime: base.Add(time.Duration(i) * time.Minute).Format("2006-01-02T15:04:05.000000+00:00"),
+			Action:    cadf.CreateAction,
+			Outcome:   outcome,
+		})
+	}
+	eventStore.Add([]string{"project-b"}, cadf.Event{ID: "other", EventTime: base.Format(time.RFC3339)})
+
+	exporter := NewExporter(eventStore, NewSigner(priv))
+	exporter.PageSize = 2
+	exporter.Now = mock.NewClock().Now
+	return exporter, pub
+}
+
+// rewriteBundle applies modify to the files of a bundle and packs them again.
+func rewriteBundle(t *testing.T, bundle []byte, modify func(files map[string][]byte)) []byte {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(bundle))
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+	files := make(map[string][]byte)
+	var names []string
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		files[header.Name], err = io.ReadAll(tr)
+		require.NoError(t, err)
+		names = append(names, header.Name)
+	}
+	modify(files)
+
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+	for name := range files {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	for _, name := range names {
+		content, ok := files[name]
+		if !ok {
+			continue
+		}
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content))}))
+		_, err := tw.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gzw.Close())
+	return buf.Bytes()
+}
+
+func TestWriteAndVerify(t *testing.T) {
+	exporter, pub := newTestExporter(t, 5)
+
+	var bundle bytes.Buffer
+	filter := hermes.EventFilter{Outcome: "success", Time: map[string]string{"gte": "2026-01-01T00:00:00"}}
+	manifest, err := exporter.Write(t.Context(), &bundle, &filter, "project-a", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, manifest.EventCount)
+	assert.Equal(t, "2026-01-01T00:00:00.000000+00:00", manifest.FirstEventTime)
+	assert.Equal(t, "2026-01-01T00:04:00.000000+00:00", manifest.LastEventTime)
+	assert.Equal(t, "success", manifest.Filter.Outcome)
+	assert.Equal(t, []string{"time:asc"}, manifest.Filter.Sort)
+	assert.Equal(t, map[string]string{"gte": "2026-01-01T00:00:00"}, manifest.TimeRange)
+	keyID, err := KeyID(pub)
+	require.NoError(t, err)
+	assert.Equal(t, keyID, manifest.KeyID)
+
+	verified, err := Verify(bytes.NewReader(bundle.Bytes()), pub)
+	require.NoError(t, err)
+	assert.Equal(t, manifest, verified)
+
+	otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, err = Verify(bytes.NewReader(bundle.Bytes()), otherPub)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	exporter, pub := newTestExporter(t, 3)
+	exporter.ChunkSize = 2
+	var bundle bytes.Buffer
+	_, err := exporter.Write(t.Context(), &bundle, &hermes.EventFilter{}, "project-a", nil)
+	require.NoError(t, err)
+
+	tt := []struct {
+		name    string
+		modify  func(files map[string][]byte)
+		message string
+	}{
+		{"ModifiedEvents", func(files map[string][]byte) {
+			files["events-000001.ndjson"] = bytes.Replace(files["events-000001.ndjson"], []byte("failure"), []byte("success"), 1)
+		}, "events-000001.ndjson was modified"},
+		{"RemovedEvent", func(files map[string][]byte) {
+			lines := bytes.SplitAfter(files["events-000001.ndjson"], []byte("\n"))
+			files["events-000001.ndjson"] = bytes.Join(lines[1:], nil)
+		}, "events-000001.ndjson was modified"},
+		{"RemovedEventsFile", func(files map[string][]byte) {
+			delete(files, "events-000002.ndjson")
+		}, "bundle does not contain events-000002.ndjson"},
+		{"ModifiedManifest", func(files map[string][]byte) {
+			files[manifestFileName] = bytes.Replace(files[manifestFileName], []byte("project-a"), []byte("project-b"), 1)
+		}, ErrInvalidSignature.Error()},
+		{"MissingSignature", func(files map[string][]byte) {
+			delete(files, signatureFileName)
+		}, "bundle does not contain manifest.json.sig"},
+		{"ExtraFile", func(files map[string][]byte) {
+			files["README"] = []byte("trust me")
+		}, "bundle contains README, which is not listed in the manifest"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Verify(bytes.NewReader(rewriteBundle(t, bundle.Bytes(), tc.modify)), pub)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.message)
+		})
+	}
+}
+
+func TestWriteChunks(t *testing.T) {
+	exporter, pub := newTestExporter(t, 5)
+	exporter.ChunkSize = 2
+
+	var bundle bytes.Buffer
+	manifest, err := exporter.Write(t.Context(), &bundle, &hermes.EventFilter{}, "project-a", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 5, manifest.EventCount)
+	var names []string
+	for _, file := range manifest.Files {
+		names = append(names, file.Name)
+	}
+	assert.Equal(t, []string{"events-000001.ndjson", "events-000002.ndjson", "events-000003.ndjson"}, names)
+
+	verified, err := Verify(bytes.NewReader(bundle.Bytes()), pub)
+	require.NoError(t, err)
+	assert.Equal(t, manifest, verified)
+
+	// the manifest comes last, so a bundle that was cut off does not verify
+	var truncated bytes.Buffer
+	gz, err := gzip.NewReader(bytes.NewReader(bundle.Bytes()))
+	require.NoError(t, err)
+	gzw := gzip.NewWriter(&truncated)
+	tr := tar.NewReader(gz)
+	tw := tar.NewWriter(gzw)
+	for {
+		header, err := tr.Next()
+		require.NoError(t, err)
+		if header.Name == manifestFileName {
+			break
+		}
+		require.NoError(t, tw.WriteHeader(header))
+		_, err = io.Copy(tw, tr)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gzw.Close())
+	_, err = Verify(&truncated, pub)
+	assert.EqualError(t, err, "bundle does not contain manifest.json")
+}
+
+func TestWriteTooManyEvents(t *testing.T) {
+	exporter, _ := newTestExporter(t, 3)
+	eventStore := storage.NewMemory(2)
+	eventStore.Add([]string{"project-a"}, cadf.Event{ID: "1"}, cadf.Event{ID: "2"}, cadf.Event{ID: "3"})
+	exporter.Storage = eventStore
+
+	var bundle bytes.Buffer
+	_, err := exporter.Write(t.Context(), &bundle, &hermes.EventFilter{}, "project-a", nil)
+	assert.ErrorIs(t, err, hermes.ErrTooManyEvents)
+	assert.Zero(t, bundle.Len(), "nothing may be written on error")
+}
+
+func TestVerifyCommand(t *testing.T) {
+	exporter, pub := newTestExporter(t, 3)
+	dir := t.TempDir()
+	bundlePath := filepath.Join(dir, "bundle.tar.gz")
+	keyPath := filepath.Join(dir, "key.pem")
+	var bundle bytes.Buffer
+	_, err := exporter.Write(t.Context(), &bundle, &hermes.EventFilter{}, "project-a", nil)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(bundlePath, bundle.Bytes(), 0o600))
+	pemBytes, err := MarshalPublicKeyPEM(pub)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(keyPath, pemBytes, 0o600))
+
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, 0, VerifyCommand([]string{"-key", keyPath, bundlePath}, &stdout, &stderr), stderr.String())
+	assert.Contains(t, stdout.String(), bundlePath+": OK")
+	assert.Contains(t, stdout.String(), "events:     3")
+
+	otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	pemBytes, err = MarshalPublicKeyPEM(otherPub)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(keyPath, pemBytes, 0o600))
+	stdout.Reset()
+	assert.Equal(t, 1, VerifyCommand([]string{"-key", keyPath, bundlePath}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "verification FAILED")
+
+	assert.Equal(t, 2, VerifyCommand([]string{bundlePath}, &stdout, &stderr))
+}
+
+func TestLoadSigner(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+
+	signer, err := LoadSigner(path)
+	require.NoError(t, err)
+	assert.Equal(t, pub, signer.PublicKey())
+
+	pemBytes, err := MarshalPublicKeyPEM(signer.PublicKey())
+	require.NoError(t, err)
+	parsed, err := ParsePublicKeyPEM(pemBytes)
+	require.NoError(t, err)
+	assert.Equal(t, pub, parsed)
+}
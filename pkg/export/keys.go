@@ -0,0 +1,101 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package export
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Signer signs bundle manifests with an Ed25519 private key.
+type Signer struct {
+	key ed25519.PrivateKey
+}
+
+// NewSigner wraps an Ed25519 private key.
+func NewSigner(key ed25519.PrivateKey) *Signer {
+	return &Signer{key: key}
+}
+
+// LoadSigner reads a PEM-encoded PKCS #8 Ed25519 private key, as generated by
+// `openssl genpkey -algorithm ed25519`.
+func LoadSigner(path string) (*Signer, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(buf)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("%s: expected a PEM block of type PRIVATE KEY", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: expected an Ed25519 key, got %T", path, key)
+	}
+	return NewSigner(edKey), nil
+}
+
+// PublicKey returns the public half of the signing key.
+func (s *Signer) PublicKey() ed25519.PublicKey {
+	return s.key.Public().(ed25519.PublicKey) //nolint:errcheck // always an ed25519.PublicKey
+}
+
+// KeyID returns the fingerprint of the public key that is recorded in manifests.
+func (s *Signer) KeyID() (string, error) {
+	return KeyID(s.PublicKey())
+}
+
+// Sign returns the Ed25519 signature of message.
+func (s *Signer) Sign(message []byte) ([]byte, error) {
+	return s.key.Sign(nil, message, crypto.Hash(0))
+}
+
+// KeyID returns the fingerprint of an Ed25519 public key: the first 16 bytes of
+// the SHA-256 digest of its PKIX encoding, hex-encoded.
+func KeyID(pub ed25519.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:16]), nil
+}
+
+// MarshalPublicKeyPEM encodes an Ed25519 public key as a PEM block of type PUBLIC KEY.
+func MarshalPublicKeyPEM(pub ed25519.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// ParsePublicKeyPEM decodes a PEM-encoded Ed25519 public key, e.g. as returned
+// by GET /v1/export/public-key.
+func ParsePublicKeyPEM(buf []byte) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode(buf)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, errors.New("expected a PEM block of type PUBLIC KEY")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	edKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("expected an Ed25519 key, got %T", key)
+	}
+	return edKey, nil
+}
@@ -0,0 +1,250 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package forwarding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/sapcc/go-bits/logg"
+
+	"github.com/sapcc/hermes/pkg/hermes"
+	"github.com/sapcc/hermes/pkg/storage"
+)
+
+// minWindow is the smallest window that the Forwarder will shrink to when a
+// window contains more events than storage can return in one query.
+const minWindow = time.Second
+
+// Forwarder periodically reads the newly stored events of every target's
+// tenant and sends them to the target's collector.
+//
+// Each target has a cursor in the Store up to which events were sent. The
+// events of the next window are rendered into an in-memory buffer, which is
+// kept across runs until the collector has accepted all of it; only then does
+// the cursor move on. A restart therefore resends at most one window.
+type Forwarder struct {
+	Store   Store
+	Storage storage.Storage
+	// Redactor is applied to every event before it is sent, as for a caller
+	// without any exemptions. May be nil.
+	Redactor *hermes.Redactor
+	// Hostname is the HOSTNAME field of the syslog messages.
+	Hostname string
+	// Interval is the time between two runs.
+	Interval time.Duration
+	// SettleDelay is how long events are given to arrive in storage before
+	// they are forwarded. Events stored later than that are not forwarded.
+	SettleDelay time.Duration
+	// MaxWindow caps the time span read from storage in one step.
+	MaxWindow time.Duration
+	// Timeout applies to connecting to a collector and to each write.
+	Timeout time.Duration
+	// InitialBackoff is the delay before reconnecting after the first failure.
+	// It doubles with every further failure, up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Now returns the current time. Tests replace it with a mock clock.
+	Now func() time.Time
+
+	targets []*targetState
+}
+
+// targetState is the connection and the unsent buffer of one target.
+type targetState struct {
+	Target
+	sender *sender
+	// pending holds the messages of the window [pendingFrom, pendingUntil) that were not sent yet.
+	pending      [][]byte
+	pendingFrom  time.Time
+	pendingUntil time.Time
+}
+
+// NewForwarder builds a Forwarder for validated targets (see ValidateTargets)
+// with the default timings.
+func NewForwarder(store Store, eventStore storage.Storage, targets []Target) (*Forwarder, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = ""
+	}
+	f := &Forwarder{
+		Store:          store,
+		Storage:        eventStore,
+		Hostname:       hostname,
+		Interval:       10 * time.Second,
+		SettleDelay:    time.Minute,
+		MaxWindow:      time.Hour,
+		Timeout:        10 * time.Second,
+		InitialBackoff: 5 * time.Second,
+		MaxBackoff:     5 * time.Minute,
+		Now:            time.Now,
+	}
+	for _, target := range targets {
+		tlsConfig, err := target.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		f.targets = append(f.targets, &targetState{
+			Target: target,
+			sender: &sender{target: target.Name, address: target.Address, tlsConfig: tlsConfig},
+		})
+	}
+	return f, nil
+}
+
+// Run forwards events every Interval until ctx is cancelled.
+func (f *Forwarder) Run(ctx context.Context) {
+	defer f.Close()
+	ticker := time.NewTicker(f.Interval)
+	defer ticker.Stop()
+	for {
+		err := f.RunOnce(ctx)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			logg.Error("forwarding: run failed: %s", err.Error())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Close closes all connections to collectors.
+func (f *Forwarder) Close() {
+	for _, ts := range f.targets {
+		ts.sender.close()
+	}
+}
+
+// RunOnce forwards the new events of all targets once. If another replica
+// holds the forwarder lock, nothing is done. Errors of individual targets are
+// logged and do not stop the other targets.
+func (f *Forwarder) RunOnce(ctx context.Context) error {
+	release, ok, err := f.Store.TryLock(ctx)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		logg.Debug("forwarding: another process is running the forwarder")
+		// the other process now owns the collectors
+		f.Close()
+		return nil
+	}
+	defer release()
+
+	horizon := f.Now().UTC().Add(-f.SettleDelay).Truncate(time.Second)
+	failed := 0
+	for _, ts := range f.targets {
+		ts.sender.timeout = f.Timeout
+		ts.sender.initialBackoff = f.InitialBackoff
+		ts.sender.maxBackoff = f.MaxBackoff
+		ts.sender.now = f.Now
+
+		err := f.forward(ctx, ts, horizon)
+		if errors.Is(err, context.Canceled) {
+			return err
+		}
+		var backingOff errBackingOff
+		if errors.As(err, &backingOff) {
+			logg.Debug("forwarding: target %s: %s", ts.Name, err.Error())
+			continue
+		}
+		if err != nil {
+			logg.Error("forwarding: cannot forward events of %s to target %s: %s", ts.TenantID, ts.Name, err.Error())
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("events could not be forwarded to %d of %d targets", failed, len(f.targets))
+	}
+	return nil
+}
+
+// forward sends the target's events up to horizon, window by window.
+func (f *Forwarder) forward(ctx context.Context, ts *targetState, horizon time.Time) error {
+	cursor, err := f.Store.Cursor(ctx, ts.Name)
+	if err != nil {
+		return err
+	}
+	if cursor.IsZero() {
+		// new target: start at the current time
+		err := f.Store.MoveCursor(ctx, ts.Name, time.Time{}, horizon)
+		if err != nil && !errors.Is(err, ErrCursorMoved) {
+			return err
+		}
+		return nil
+	}
+	if len(ts.pending) > 0 && !ts.pendingFrom.Equal(cursor) {
+		// another replica has forwarded this window in the meantime
+		ts.pending = nil
+	}
+
+	for {
+		if len(ts.pending) == 0 {
+			if !cursor.Before(horizon) {
+				return nil
+			}
+			until := cursor.Add(f.MaxWindow)
+			if until.After(horizon) {
+				until = horizon
+			}
+			messages, err := f.fetchWindow(ctx, ts.Target, cursor, until)
+			for errors.Is(err, hermes.ErrTooManyEvents) && until.Sub(cursor) > minWindow {
+				until = cursor.Add(until.Sub(cursor) / 2)
+				messages, err = f.fetchWindow(ctx, ts.Target, cursor, until)
+			}
+			if err != nil {
+				return err
+			}
+			ts.pending, ts.pendingFrom, ts.pendingUntil = messages, cursor, until
+		}
+
+		sent, err := ts.sender.send(ctx, ts.pending)
+		ts.pending = ts.pending[sent:]
+		if err != nil {
+			return err
+		}
+		err = f.Store.MoveCursor(ctx, ts.Name, ts.pendingFrom, ts.pendingUntil)
+		if errors.Is(err, ErrCursorMoved) {
+			// another replica is forwarding as well; pick up its cursor on the next run
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if sent > 0 {
+			logg.Debug("forwarding: sent %d events to target %s in [%s, %s)", sent, ts.Name,
+				ts.pendingFrom.Format(time.RFC3339), ts.pendingUntil.Format(time.RFC3339))
+		}
+		cursor = ts.pendingUntil
+	}
+}
+
+// fetchWindow renders the messages for the target's events with eventTime in [from, until).
+func (f *Forwarder) fetchWindow(ctx context.Context, target Target, from, until time.Time) ([][]byte, error) {
+	filter := hermes.FieldFilter{}.Between(from, until)
+	filter.Sort = []hermes.FieldOrder{{Fieldname: "time", Order: "asc"}}
+	view := &hermes.EventView{Redactor: f.Redactor}
+
+	var messages [][]byte
+	_, err := hermes.ForEachEvent(ctx, filter, target.TenantID, f.Storage, view, 1000, func(event *cadf.Event) error {
+		eventTime, err := storage.ParseEventTime(event.EventTime)
+		if err != nil {
+			logg.Error("forwarding: skipping event %s with unparseable eventTime %q", event.ID, event.EventTime)
+			return nil
+		}
+		msg, err := renderMessage(target, f.Hostname, event, eventTime)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, msg)
+		return nil
+	})
+	return messages, err
+}
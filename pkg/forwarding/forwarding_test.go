@@ -0,0 +1,280 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package forwarding
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/sapcc/go-bits/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapcc/hermes/pkg/storage"
+)
+
+// testCollector is a syslog collector that records all messages received
+// with octet-counting framing.
+type testCollector struct {
+	t        *testing.T
+	address  string
+	mu       sync.Mutex
+	listener net.Listener
+	messages []string
+	received chan struct{}
+}
+
+func newTestCollector(t *testing.T) *testCollector {
+	t.Helper()
+	c := &testCollector{t: t, received: make(chan struct{}, 1000)}
+	c.start("127.0.0.1:0")
+	t.Cleanup(c.stop)
+	return c
+}
+
+func (c *testCollector) start(address string) {
+	c.t.Helper()
+	listener, err := net.Listen("tcp", address)
+	require.NoError(c.t, err)
+	c.mu.Lock()
+	c.listener = listener
+	c.address = listener.Addr().String()
+	c.mu.Unlock()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go c.read(conn)
+		}
+	}()
+}
+
+// stop closes the listener, so that connection attempts fail.
+func (c *testCollector) stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.listener != nil {
+		c.listener.Close()
+		c.listener = nil
+	}
+}
+
+func (c *testCollector) read(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		length, err := r.ReadString(' ')
+		if err != nil {
+			return
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(length, " "))
+		if !assert.NoError(c.t, err, "invalid frame length") {
+			return
+		}
+		buf := make([]byte, n)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return
+		}
+		c.mu.Lock()
+		c.messages = append(c.messages, string(buf))
+		c.mu.Unlock()
+		c.received <- struct{}{}
+	}
+}
+
+// take waits for count messages, then returns and forgets all recorded messages.
+func (c *testCollector) take(count int) []string {
+	c.t.Helper()
+	for range count {
+		select {
+		case <-c.received:
+		case <-time.After(5 * time.Second):
+			c.t.Fatalf("timeout while waiting for %d messages", count)
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result := c.messages
+	c.messages = nil
+	return result
+}
+
+type forwarderTest struct {
+	events    *storage.Memory
+	clock     *mock.Clock
+	forwarder *Forwarder
+	eventSeq  int
+}
+
+func newForwarderTest(t *testing.T, targets ...Target) *forwarderTest {
+	t.Helper()
+	require.NoError(t, ValidateTargets(targets))
+	ft := &forwarderTest{
+		events: storage.NewMemory(100),
+		clock:  mock.NewClock(),
+	}
+	ft.clock.StepBy(24 * time.Hour)
+	var err error
+	ft.forwarder, err = NewForwarder(NewMock(), ft.events, targets)
+	require.NoError(t, err)
+	ft.forwarder.Hostname = "hermes-test"
+	ft.forwarder.Now = ft.clock.Now
+	t.Cleanup(ft.forwarder.Close)
+	return ft
+}
+
+// addEvent stores an event that happens now.
+func (ft *forwarderTest) addEvent(tenantID string, outcome cadf.Outcome) string {
+	ft.eventSeq++
+	id := fmt.Sprintf("event-%d", ft.eventSeq)
+	ft.events.Add([]string{tenantID}, cadf.Event{
+		ID:          id,
+		EventTime:   ft.clock.Now().UTC().Format("2006-01-02T15:04:05.000000+00:00"),
+		Action:      "delete",
+		Outcome:     outcome,
+		RequestPath: "/v3/users/" + id,
+		Initiator:   cadf.Resource{Name: "alice", ID: "user-1", ProjectID: tenantID},
+		Target:      cadf.Resource{TypeURI: "data/security/account/user", ID: id},
+		Observer:    cadf.Resource{Name: "keystone"},
+	})
+	return id
+}
+
+// runAfter advances the clock and runs the forwarder.
+func (ft *forwarderTest) runAfter(t *testing.T, d time.Duration) error {
+	t.Helper()
+	ft.clock.StepBy(d)
+	return ft.forwarder.RunOnce(t.Context())
+}
+
+func TestForwardRFC5424(t *testing.T) {
+	collector := newTestCollector(t)
+	ft := newForwarderTest(t, Target{Name: "siem", TenantID: "project-a", Address: collector.address})
+
+	// the first run starts the cursor at the current time; older events are not forwarded
+	ft.addEvent("project-a", cadf.SuccessOutcome)
+	require.NoError(t, ft.runAfter(t, 2*ft.forwarder.SettleDelay))
+
+	ft.clock.StepBy(time.Second)
+	successID := ft.addEvent("project-a", cadf.SuccessOutcome)
+	failureID := ft.addEvent("project-a", cadf.FailureOutcome)
+	ft.addEvent("project-b", cadf.SuccessOutcome)
+
+	// events are not forwarded before the settle delay has passed
+	require.NoError(t, ft.runAfter(t, 10*time.Second))
+	require.NoError(t, ft.runAfter(t, ft.forwarder.SettleDelay))
+	messages := collector.take(2)
+	require.Len(t, messages, 2)
+
+	eventTime := ft.clock.Now().Add(-ft.forwarder.SettleDelay - 10*time.Second).UTC().Format("2006-01-02T15:04:05.000000Z")
+	for i, expected := range []struct {
+		id  string
+		pri string
+	}{{successID, "<109>"}, {failureID, "<108>"}} {
+		prefix := expected.pri + "1 " + eventTime + " hermes-test hermes - cadf - "
+		require.True(t, strings.HasPrefix(messages[i], prefix), "message %q should start with %q", messages[i], prefix)
+		var event cadf.Event
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(messages[i], prefix)), &event))
+		assert.Equal(t, expected.id, event.ID)
+	}
+
+	// forwarded events are not sent again
+	require.NoError(t, ft.runAfter(t, time.Hour))
+	assert.Empty(t, collector.take(0))
+}
+
+func TestForwardCEF(t *testing.T) {
+	collector := newTestCollector(t)
+	facility := 10
+	ft := newForwarderTest(t, Target{Name: "siem", TenantID: "project-a", Address: collector.address, Format: FormatCEF, Facility: &facility})
+	require.NoError(t, ft.runAfter(t, 0))
+
+	ft.clock.StepBy(time.Second)
+	id := ft.addEvent("project-a", cadf.FailureOutcome)
+	rt := ft.clock.Now().UnixMilli()
+	require.NoError(t, ft.runAfter(t, ft.forwarder.SettleDelay+time.Second))
+
+	messages := collector.take(1)
+	require.Len(t, messages, 1)
+	_, cef, found := strings.Cut(messages[0], " hermes - cef - ")
+	require.True(t, found, "unexpected message: %q", messages[0])
+	assert.True(t, strings.HasPrefix(messages[0], "<84>1 "), "unexpected PRI in %q", messages[0])
+	assert.Equal(t, "CEF:0|SAP|Hermes|unknown|delete|delete data/security/account/user|6|"+
+		fmt.Sprintf("rt=%d externalId=%s act=delete outcome=failure suser=alice suid=user-1 request=/v3/users/%s ", rt, id, id)+
+		"cs1Label=targetTypeURI cs1=data/security/account/user cs2Label=targetID cs2="+id+" "+
+		"cs3Label=projectID cs3=project-a cs5Label=observer cs5=keystone", cef)
+}
+
+func TestRenderCEFEscaping(t *testing.T) {
+	event := &cadf.Event{
+		ID:        "event-1",
+		Action:    "update|x",
+		Outcome:   cadf.SuccessOutcome,
+		Initiator: cadf.Resource{Name: "a=b\\c\nd"},
+	}
+	assert.Equal(t, `CEF:0|SAP|Hermes|unknown|update\|x|update\|x|3|rt=0 externalId=event-1 act=update|x outcome=success suser=a\=b\\c\nd`,
+		RenderCEF(event, time.UnixMilli(0)))
+}
+
+func TestReconnectAfterCollectorOutage(t *testing.T) {
+	collector := newTestCollector(t)
+	ft := newForwarderTest(t, Target{Name: "siem", TenantID: "project-a", Address: collector.address})
+	require.NoError(t, ft.runAfter(t, 0))
+
+	// while the collector is down, events are kept and the forwarder backs off
+	collector.stop()
+	ft.clock.StepBy(time.Second)
+	firstID := ft.addEvent("project-a", cadf.SuccessOutcome)
+	require.Error(t, ft.runAfter(t, ft.forwarder.SettleDelay+time.Second))
+	ft.clock.StepBy(time.Second)
+	secondID := ft.addEvent("project-a", cadf.SuccessOutcome)
+
+	collector.start(collector.address)
+	require.NoError(t, ft.runAfter(t, ft.forwarder.InitialBackoff/2))
+	assert.Empty(t, collector.take(0), "no reconnect during backoff")
+
+	require.NoError(t, ft.runAfter(t, ft.forwarder.InitialBackoff+ft.forwarder.SettleDelay))
+	var ids []string
+	for _, msg := range collector.take(2) {
+		var event cadf.Event
+		require.NoError(t, json.Unmarshal([]byte(msg[strings.Index(msg, "{"):]), &event))
+		ids = append(ids, event.ID)
+	}
+	assert.Equal(t, []string{firstID, secondID}, ids)
+}
+
+func TestValidateTargets(t *testing.T) {
+	targets := []Target{{Name: "a", TenantID: "project-a", Address: "syslog.example.com:6514", TLS: true}}
+	require.NoError(t, ValidateTargets(targets))
+	assert.Equal(t, FormatRFC5424, targets[0].Format)
+	assert.Equal(t, defaultFacility, *targets[0].Facility)
+
+	facility := 24
+	err := ValidateTargets([]Target{
+		{Name: "a", TenantID: "project-a", Address: "syslog.example.com", CAFile: "ca.pem"},
+		{Name: "a", Address: "syslog.example.com:514", Format: "json", Facility: &facility},
+	})
+	require.Error(t, err)
+	for _, msg := range []string{
+		`"a": address must be host:port`,
+		`"a": ca_file requires tls = true`,
+		`"a": name is used twice`,
+		`"a": tenant_id is missing`,
+		`"a": format must be "cef" or "rfc5424"`,
+		`"a": facility must be between 0 and 23`,
+	} {
+		assert.Contains(t, err.Error(), msg)
+	}
+}
@@ -0,0 +1,33 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package forwarding
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrCursorMoved is returned by Store.MoveCursor when the target's cursor is
+// no longer the expected value.
+var ErrCursorMoved = errors.New("forwarding: cursor was moved concurrently")
+
+// Store is the persistence interface for the forwarding progress.
+// The Postgres implementation is the production backend;
+// the Mock implementation is used in unit tests.
+type Store interface {
+	// Cursor returns the end of the last forwarded time window of a target,
+	// or the zero time if nothing has been forwarded to it yet.
+	Cursor(ctx context.Context, target string) (time.Time, error)
+
+	// MoveCursor moves the cursor of a target from `from` to `until`. A zero
+	// `from` means that the target has no cursor yet.
+	// Returns ErrCursorMoved if the current cursor is not `from`.
+	MoveCursor(ctx context.Context, target string, from, until time.Time) error
+
+	// TryLock acquires the forwarder lock without waiting, so that only one
+	// hermez replica forwards at a time. If ok is true, the caller must call
+	// release when done.
+	TryLock(ctx context.Context) (release func(), ok bool, err error)
+}
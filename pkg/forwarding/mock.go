@@ -0,0 +1,50 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package forwarding
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Mock implements Store with in-memory storage for use in unit tests.
+type Mock struct {
+	mu      sync.RWMutex
+	cursors map[string]time.Time
+	locked  sync.Mutex
+}
+
+// NewMock creates an empty Mock store.
+func NewMock() *Mock {
+	return &Mock{cursors: make(map[string]time.Time)}
+}
+
+// Cursor implements the Store interface.
+func (m *Mock) Cursor(_ context.Context, target string) (time.Time, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.cursors[target], nil
+}
+
+// MoveCursor implements the Store interface.
+func (m *Mock) MoveCursor(_ context.Context, target string, from, until time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.cursors[target].Equal(from) {
+		return ErrCursorMoved
+	}
+	m.cursors[target] = until
+	return nil
+}
+
+// TryLock implements the Store interface.
+func (m *Mock) TryLock(_ context.Context) (release func(), ok bool, err error) {
+	if !m.locked.TryLock() {
+		return nil, false, nil
+	}
+	return m.locked.Unlock, true, nil
+}
+
+var _ Store = (*Mock)(nil)
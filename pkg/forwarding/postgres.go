@@ -0,0 +1,110 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package forwarding
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sapcc/go-bits/logg"
+	"go.xyrillian.de/gg/gsql"
+)
+
+// forwarderLockID is the key of the Postgres advisory lock taken by TryLock
+// ("hermes" in ASCII, followed by a number per lock).
+const forwarderLockID int64 = 0x6865726d65730003
+
+// DBMigrations contains the SQL migrations for the tables of this package in
+// the hermez database (see package database).
+var DBMigrations = map[int64]string{
+	6: `
+		-- Progress of the syslog forwarding targets from the config file.
+		CREATE TABLE IF NOT EXISTS forwarding_cursors (
+			target          VARCHAR(255) PRIMARY KEY,
+			forwarded_until TIMESTAMPTZ  NOT NULL
+		);
+	`,
+}
+
+// Postgres implements Store using the hermez PostgreSQL database.
+type Postgres struct {
+	db *gsql.DB
+}
+
+// NewPostgres wraps an already connected and migrated database.
+func NewPostgres(db *gsql.DB) *Postgres {
+	return &Postgres{db: db}
+}
+
+// Cursor implements the Store interface.
+func (p *Postgres) Cursor(ctx context.Context, target string) (time.Time, error) {
+	var until time.Time
+	err := p.db.QueryRowContext(ctx,
+		`SELECT forwarded_until FROM forwarding_cursors WHERE target = $1`, target,
+	).Scan(&until)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("forwarding: cannot read cursor of target %s: %w", target, err)
+	}
+	return until.UTC(), nil
+}
+
+// MoveCursor implements the Store interface.
+func (p *Postgres) MoveCursor(ctx context.Context, target string, from, until time.Time) error {
+	var result sql.Result
+	var err error
+	if from.IsZero() {
+		result, err = p.db.ExecContext(ctx,
+			`INSERT INTO forwarding_cursors (target, forwarded_until) VALUES ($1, $2) ON CONFLICT (target) DO NOTHING`,
+			target, until)
+	} else {
+		result, err = p.db.ExecContext(ctx,
+			`UPDATE forwarding_cursors SET forwarded_until = $3 WHERE target = $1 AND forwarded_until = $2`,
+			target, from, until)
+	}
+	if err != nil {
+		return fmt.Errorf("forwarding: cannot move cursor of target %s: %w", target, err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("forwarding: cannot get rows affected after moving cursor of target %s: %w", target, err)
+	}
+	if n == 0 {
+		return ErrCursorMoved
+	}
+	return nil
+}
+
+// TryLock implements the Store interface using a session-level advisory lock.
+// The lock is held on a dedicated connection, which is returned to the pool on release.
+func (p *Postgres) TryLock(ctx context.Context) (release func(), ok bool, err error) {
+	conn, err := p.db.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("forwarding: cannot get connection for forwarder lock: %w", err)
+	}
+	err = conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, forwarderLockID).Scan(&ok)
+	if err != nil || !ok {
+		conn.Close()
+		if err != nil {
+			return nil, false, fmt.Errorf("forwarding: cannot take forwarder lock: %w", err)
+		}
+		return nil, false, nil
+	}
+	release = func() {
+		// use a fresh context: the lock must be released even if ctx was cancelled
+		_, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, forwarderLockID)
+		if err != nil {
+			logg.Error("forwarding: cannot release forwarder lock: %s", err.Error())
+		}
+		conn.Close()
+	}
+	return release, true, nil
+}
+
+var _ Store = (*Postgres)(nil)
@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package forwarding
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/bininfo"
+	"github.com/sapcc/go-api-declarations/cadf"
+)
+
+// appName is the APP-NAME of all syslog messages.
+const appName = "hermes"
+
+// RenderCEF renders an event as a line in the ArcSight Common Event Format.
+func RenderCEF(event *cadf.Event, eventTime time.Time) string {
+	var severity int
+	switch event.Outcome {
+	case cadf.SuccessOutcome:
+		severity = 3
+	case cadf.FailureOutcome:
+		severity = 6
+	default:
+		severity = 5
+	}
+	action := string(event.Action)
+	header := []string{
+		"CEF:0",
+		cefHeader("SAP"),
+		cefHeader("Hermes"),
+		cefHeader(bininfo.VersionOr("unknown")),
+		cefHeader(action),
+		cefHeader(strings.TrimSpace(action + " " + event.Target.TypeURI)),
+		strconv.Itoa(severity),
+	}
+
+	var ext cefExtension
+	ext.add("rt", strconv.FormatInt(eventTime.UnixMilli(), 10))
+	ext.add("externalId", event.ID)
+	ext.add("act", action)
+	ext.add("outcome", string(event.Outcome))
+	ext.add("reason", event.Reason.ReasonCode)
+	ext.add("suser", event.Initiator.Name)
+	ext.add("suid", event.Initiator.ID)
+	if host := event.Initiator.Host; host != nil {
+		ext.add("src", host.Address)
+		ext.add("requestClientApplication", host.Agent)
+	}
+	ext.add("request", event.RequestPath)
+	ext.addCustom(1, "targetTypeURI", event.Target.TypeURI)
+	ext.addCustom(2, "targetID", event.Target.ID)
+	ext.addCustom(3, "projectID", event.Initiator.ProjectID)
+	ext.addCustom(4, "domainID", event.Initiator.DomainID)
+	ext.addCustom(5, "observer", event.Observer.Name)
+	return strings.Join(header, "|") + "|" + strings.Join(ext, " ")
+}
+
+// cefHeader escapes a CEF header field.
+func cefHeader(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\r", " ", "\n", " ").Replace(value)
+}
+
+// cefExtension collects the key=value pairs of a CEF extension. Empty values are left out.
+type cefExtension []string
+
+func (e *cefExtension) add(key, value string) {
+	if value == "" {
+		return
+	}
+	value = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r", `\r`, "\n", `\n`).Replace(value)
+	*e = append(*e, key+"="+value)
+}
+
+// addCustom adds a custom string field csN together with its label.
+func (e *cefExtension) addCustom(n int, label, value string) {
+	if value == "" {
+		return
+	}
+	e.add(fmt.Sprintf("cs%dLabel", n), label)
+	e.add(fmt.Sprintf("cs%d", n), value)
+}
+
+// renderMessage renders an event as an RFC 5424 syslog message in the
+// target's format, framed by octet counting (RFC 6587) as required for TLS
+// by RFC 5425.
+func renderMessage(target Target, hostname string, event *cadf.Event, eventTime time.Time) ([]byte, error) {
+	var msgID, msg string
+	switch target.Format {
+	case FormatCEF:
+		msgID, msg = "cef", RenderCEF(event, eventTime)
+	default:
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return nil, fmt.Errorf("cannot serialize event %s: %w", event.ID, err)
+		}
+		msgID, msg = "cadf", string(payload)
+	}
+
+	// "warning" for failed events, "notice" for all others
+	severity := 5
+	if event.Outcome == cadf.FailureOutcome {
+		severity = 4
+	}
+	if hostname == "" {
+		hostname = "-"
+	}
+	line := fmt.Sprintf("<%d>1 %s %s %s - %s - %s",
+		*target.Facility*8+severity,
+		eventTime.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		hostname, appName, msgID, msg)
+	return []byte(strconv.Itoa(len(line)) + " " + line), nil
+}
@@ -0,0 +1,109 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package forwarding
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/sapcc/go-bits/logg"
+)
+
+// sender keeps a TCP or TLS connection to a collector open across runs and
+// reconnects with exponential backoff after failures.
+type sender struct {
+	target    string
+	address   string
+	tlsConfig *tls.Config
+	// timeout applies to dialing and to each write.
+	timeout        time.Duration
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	now            func() time.Time
+
+	conn     net.Conn
+	failures int
+	// retryAt is the earliest time for the next connection attempt after a failure.
+	retryAt time.Time
+}
+
+// errBackingOff is returned by send while waiting to reconnect.
+type errBackingOff struct {
+	until time.Time
+}
+
+func (e errBackingOff) Error() string {
+	return "waiting until " + e.until.Format(time.RFC3339) + " before reconnecting"
+}
+
+// send writes the messages in order and returns how many were written
+// completely. After an error, the connection is closed and the remaining
+// messages must be passed to the next call.
+func (s *sender) send(ctx context.Context, messages [][]byte) (int, error) {
+	if len(messages) == 0 {
+		return 0, nil
+	}
+	if s.conn == nil {
+		if err := s.connect(ctx); err != nil {
+			return 0, err
+		}
+	}
+	for i, msg := range messages {
+		if err := ctx.Err(); err != nil {
+			return i, err
+		}
+		// deadlines refer to the wall clock, not to s.now
+		_ = s.conn.SetWriteDeadline(time.Now().Add(s.timeout)) //nolint:errcheck // a missing deadline only delays the error
+		if _, err := s.conn.Write(msg); err != nil {
+			s.fail()
+			return i, fmt.Errorf("cannot write to %s: %w", s.address, err)
+		}
+	}
+	s.failures = 0
+	return len(messages), nil
+}
+
+func (s *sender) connect(ctx context.Context) error {
+	if now := s.now(); now.Before(s.retryAt) {
+		return errBackingOff{s.retryAt}
+	}
+	dialer := &net.Dialer{Timeout: s.timeout}
+	var (
+		conn net.Conn
+		err  error
+	)
+	if s.tlsConfig != nil {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: s.tlsConfig}).DialContext(ctx, "tcp", s.address)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", s.address)
+	}
+	if err != nil {
+		s.fail()
+		return fmt.Errorf("cannot connect to %s: %w", s.address, err)
+	}
+	logg.Info("forwarding: connected to %s for target %s", s.address, s.target)
+	s.conn = conn
+	return nil
+}
+
+// fail closes the connection and schedules the next connection attempt.
+func (s *sender) fail() {
+	s.close()
+	backoff := s.initialBackoff << min(s.failures, 16)
+	if backoff > s.maxBackoff || backoff <= 0 {
+		backoff = s.maxBackoff
+	}
+	s.failures++
+	s.retryAt = s.now().Add(backoff)
+}
+
+func (s *sender) close() {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+}
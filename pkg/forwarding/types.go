@@ -0,0 +1,119 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+// Package forwarding ships the audit events of selected projects or domains to
+// syslog collectors as CEF or RFC 5424 messages over TCP or TLS.
+package forwarding
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/spf13/viper"
+)
+
+// Format is the message format of a Target.
+type Format string
+
+const (
+	// FormatCEF sends ArcSight Common Event Format lines as syslog messages.
+	FormatCEF Format = "cef"
+	// FormatRFC5424 sends the CADF event as JSON in RFC 5424 syslog messages.
+	FormatRFC5424 Format = "rfc5424"
+)
+
+// defaultFacility is the syslog facility "log audit".
+const defaultFacility = 13
+
+// Target is a syslog collector that receives the events of one project or
+// domain. Targets are configured in the [[forwarding.targets]] config section.
+type Target struct {
+	// Name identifies the target in logs and in the stored cursor. Renaming a
+	// target makes it start over at the current time.
+	Name string `mapstructure:"name"`
+	// TenantID is the project or domain whose events are forwarded.
+	TenantID string `mapstructure:"tenant_id"`
+	// Address is the host:port of the collector.
+	Address string `mapstructure:"address"`
+	// TLS enables TLS (RFC 5425). Without CAFile, the system roots are used.
+	TLS    bool   `mapstructure:"tls"`
+	CAFile string `mapstructure:"ca_file"`
+	Format Format `mapstructure:"format"`
+	// Facility is the syslog facility, 13 ("log audit") by default.
+	Facility *int `mapstructure:"facility"`
+}
+
+// NewTargetsFromConfig reads and validates the [[forwarding.targets]] config section.
+// Returns no targets when the section is absent.
+func NewTargetsFromConfig() ([]Target, error) {
+	var targets []Target
+	if err := viper.UnmarshalKey("forwarding.targets", &targets); err != nil {
+		return nil, fmt.Errorf("cannot parse forwarding.targets: %w", err)
+	}
+	return targets, ValidateTargets(targets)
+}
+
+// ValidateTargets checks the targets and fills in defaults.
+func ValidateTargets(targets []Target) error {
+	names := make(map[string]bool, len(targets))
+	var errs []error
+	for i := range targets {
+		t := &targets[i]
+		if t.Name == "" {
+			errs = append(errs, fmt.Errorf("forwarding target #%d: name is missing", i+1))
+		} else if names[t.Name] {
+			errs = append(errs, fmt.Errorf("forwarding target %q: name is used twice", t.Name))
+		}
+		names[t.Name] = true
+		if t.TenantID == "" {
+			errs = append(errs, fmt.Errorf("forwarding target %q: tenant_id is missing", t.Name))
+		}
+		if _, _, err := net.SplitHostPort(t.Address); err != nil {
+			errs = append(errs, fmt.Errorf("forwarding target %q: address must be host:port: %w", t.Name, err))
+		}
+		switch t.Format {
+		case "":
+			t.Format = FormatRFC5424
+		case FormatCEF, FormatRFC5424:
+		default:
+			errs = append(errs, fmt.Errorf("forwarding target %q: format must be %q or %q", t.Name, FormatCEF, FormatRFC5424))
+		}
+		if t.Facility == nil {
+			facility := defaultFacility
+			t.Facility = &facility
+		} else if *t.Facility < 0 || *t.Facility > 23 {
+			errs = append(errs, fmt.Errorf("forwarding target %q: facility must be between 0 and 23", t.Name))
+		}
+		if t.CAFile != "" && !t.TLS {
+			errs = append(errs, fmt.Errorf("forwarding target %q: ca_file requires tls = true", t.Name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// tlsConfig returns the TLS client config of the target, or nil if TLS is disabled.
+func (t Target) tlsConfig() (*tls.Config, error) {
+	if !t.TLS {
+		return nil, nil
+	}
+	host, _, err := net.SplitHostPort(t.Address)
+	if err != nil {
+		return nil, err
+	}
+	config := &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+	if t.CAFile != "" {
+		pemBytes, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read CA file of forwarding target %q: %w", t.Name, err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pemBytes) {
+			return nil, fmt.Errorf("CA file of forwarding target %q contains no certificates", t.Name)
+		}
+	}
+	return config, nil
+}
@@ -0,0 +1,193 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package hermes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+
+	"github.com/sapcc/hermes/pkg/storage"
+)
+
+// diffCandidates is how many earlier events of the same target are searched
+// for one with comparable attachments.
+const diffCandidates = 10
+
+// ChangeOp is the kind of a Change, named as in JSON Patch (RFC 6902).
+type ChangeOp string
+
+const (
+	// ChangeAdd is a value that the previous state did not have.
+	ChangeAdd ChangeOp = "add"
+	// ChangeRemove is a value that the new state does not have anymore.
+	ChangeRemove ChangeOp = "remove"
+	// ChangeReplace is a value that differs between the states.
+	ChangeReplace ChangeOp = "replace"
+)
+
+// Change is one difference between the attachments of two events.
+type Change struct {
+	// Attachment is the name of the attachment (or its typeURI if it has no name).
+	Attachment string `json:"attachment"`
+	// Path is a JSON Pointer (RFC 6901) into the attachment's content. Arrays
+	// are compared as a whole. The empty path refers to the whole content.
+	Path string   `json:"path"`
+	Op   ChangeOp `json:"op"`
+	Old  any      `json:"old,omitempty"`
+	New  any      `json:"new,omitempty"`
+}
+
+// EventDiff describes what an event changed about its target, compared to the
+// latest earlier event of the same target that carries the same attachments.
+type EventDiff struct {
+	// PreviousEventID and PreviousEventTime identify the earlier event. Both
+	// are empty if there is none, and then all attachments count as added.
+	PreviousEventID   string   `json:"previous_event_id,omitempty"`
+	PreviousEventTime string   `json:"previous_event_time,omitempty"`
+	Changes           []Change `json:"changes"`
+}
+
+// GetEventDiff compares the attachments of the event with those of the latest
+// earlier event with the same target.id that has at least one attachment of
+// the same name. The event must have been read with the same view, so that
+// both sides are redacted alike.
+func GetEventDiff(ctx context.Context, event *cadf.Event, tenantID string, eventStore storage.Storage, view *EventView) (*EventDiff, error) {
+	diff := &EventDiff{Changes: []Change{}}
+	previous, err := findPreviousState(ctx, event, tenantID, eventStore, view)
+	if err != nil {
+		return nil, err
+	}
+	var previousAttachments []cadf.Attachment
+	if previous != nil {
+		diff.PreviousEventID = previous.ID
+		diff.PreviousEventTime = previous.EventTime
+		previousAttachments = previous.Attachments
+	}
+
+	oldByName := attachmentsByName(previousAttachments)
+	newByName := attachmentsByName(event.Attachments)
+	names := make([]string, 0, len(oldByName)+len(newByName))
+	for name := range oldByName {
+		names = append(names, name)
+	}
+	for name := range newByName {
+		if _, ok := oldByName[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		oldValue, hasOld := oldByName[name]
+		newValue, hasNew := newByName[name]
+		switch {
+		case !hasOld:
+			diff.Changes = append(diff.Changes, Change{Attachment: name, Op: ChangeAdd, New: newValue})
+		case !hasNew:
+			diff.Changes = append(diff.Changes, Change{Attachment: name, Op: ChangeRemove, Old: oldValue})
+		default:
+			diffValues(name, "", oldValue, newValue, &diff.Changes)
+		}
+	}
+	return diff, nil
+}
+
+// findPreviousState returns the latest event of the same target before the
+// given event that shares an attachment name with it, or nil.
+func findPreviousState(ctx context.Context, event *cadf.Event, tenantID string, eventStore storage.Storage, view *EventView) (*cadf.Event, error) {
+	if event.Target.ID == "" || len(event.Attachments) == 0 {
+		return nil, nil
+	}
+	eventTime, err := storage.ParseEventTime(event.EventTime)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse eventTime of event %s: %w", event.ID, err)
+	}
+	filter := &EventFilter{
+		TargetID: event.Target.ID,
+		Time:     map[string]string{"lt": eventTime.UTC().Format(time.RFC3339Nano)},
+		Sort:     []FieldOrder{{Fieldname: "time", Order: "desc"}},
+		Limit:    diffCandidates,
+	}
+	candidates, _, err := GetEventPayloads(ctx, filter, tenantID, eventStore, view)
+	if err != nil {
+		return nil, err
+	}
+	names := attachmentsByName(event.Attachments)
+	for _, candidate := range candidates {
+		if candidate.ID == event.ID {
+			continue
+		}
+		for name := range attachmentsByName(candidate.Attachments) {
+			if _, ok := names[name]; ok {
+				return candidate, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// attachmentsByName returns the contents of the attachments by name. Contents
+// that are strings with JSON objects or arrays are decoded, since several
+// services store request bodies in that form.
+func attachmentsByName(attachments []cadf.Attachment) map[string]any {
+	result := make(map[string]any, len(attachments))
+	for _, a := range attachments {
+		name := a.Name
+		if name == "" {
+			name = a.TypeURI
+		}
+		content := a.Content
+		if s, ok := content.(string); ok && (strings.HasPrefix(s, "{") || strings.HasPrefix(s, "[")) {
+			var decoded any
+			if json.Unmarshal([]byte(s), &decoded) == nil {
+				content = decoded
+			}
+		}
+		result[name] = content
+	}
+	return result
+}
+
+// diffValues appends the changes between two decoded JSON values. Objects are
+// compared key by key; all other values, including arrays, as a whole.
+func diffValues(attachment, path string, oldValue, newValue any, changes *[]Change) {
+	oldObject, oldIsObject := oldValue.(map[string]any)
+	newObject, newIsObject := newValue.(map[string]any)
+	if !oldIsObject || !newIsObject {
+		if !reflect.DeepEqual(oldValue, newValue) {
+			*changes = append(*changes, Change{Attachment: attachment, Path: path, Op: ChangeReplace, Old: oldValue, New: newValue})
+		}
+		return
+	}
+
+	keys := make([]string, 0, len(oldObject)+len(newObject))
+	for key := range oldObject {
+		keys = append(keys, key)
+	}
+	for key := range newObject {
+		if _, ok := oldObject[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		keyPath := path + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+		o, hasOld := oldObject[key]
+		n, hasNew := newObject[key]
+		switch {
+		case !hasOld:
+			*changes = append(*changes, Change{Attachment: attachment, Path: keyPath, Op: ChangeAdd, New: n})
+		case !hasNew:
+			*changes = append(*changes, Change{Attachment: attachment, Path: keyPath, Op: ChangeRemove, Old: o})
+		default:
+			diffValues(attachment, keyPath, o, n, changes)
+		}
+	}
+}
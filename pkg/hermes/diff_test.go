@@ -0,0 +1,66 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package hermes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapcc/hermes/pkg/storage"
+)
+
+func Test_GetEventDiff(t *testing.T) {
+	eventStore := storage.NewMemory(100)
+	target := cadf.Resource{TypeURI: "compute/server", ID: "server-1"}
+	payload := func(content any) []cadf.Attachment {
+		return []cadf.Attachment{{Name: "payload", TypeURI: "mime:application/json", Content: content}}
+	}
+	eventStore.Add([]string{"p1"},
+		cadf.Event{ID: "create", EventTime: "2026-01-01T00:00:01Z", Action: "create", Target: target,
+			Attachments: payload(`{"name": "a", "size": 1, "tags": ["x"], "meta": {"a/b": 1}}`)},
+		// events without matching attachments are skipped
+		cadf.Event{ID: "read", EventTime: "2026-01-01T00:00:02Z", Action: "read", Target: target},
+		// as are events of other targets
+		cadf.Event{ID: "other", EventTime: "2026-01-01T00:00:03Z", Action: "update",
+			Target: cadf.Resource{TypeURI: "compute/server", ID: "server-2"}, Attachments: payload(`{"name": "z"}`)},
+		cadf.Event{ID: "update", EventTime: "2026-01-01T00:00:04Z", Action: "update", Target: target,
+			Attachments: payload(map[string]any{"name": "b", "size": float64(1), "meta": map[string]any{"a/b": float64(2)}, "extra": true})},
+	)
+
+	event, err := GetEvent(context.Background(), "update", "p1", eventStore, nil)
+	require.NoError(t, err)
+	diff, err := GetEventDiff(context.Background(), event, "p1", eventStore, nil)
+	require.NoError(t, err)
+	assert.Equal(t, &EventDiff{
+		PreviousEventID:   "create",
+		PreviousEventTime: "2026-01-01T00:00:01Z",
+		Changes: []Change{
+			{Attachment: "payload", Path: "/extra", Op: ChangeAdd, New: true},
+			{Attachment: "payload", Path: "/meta/a~1b", Op: ChangeReplace, Old: float64(1), New: float64(2)},
+			{Attachment: "payload", Path: "/name", Op: ChangeReplace, Old: "a", New: "b"},
+			{Attachment: "payload", Path: "/tags", Op: ChangeRemove, Old: []any{"x"}},
+		},
+	}, diff)
+
+	// without a previous state, everything is new
+	event, err = GetEvent(context.Background(), "create", "p1", eventStore, nil)
+	require.NoError(t, err)
+	diff, err = GetEventDiff(context.Background(), event, "p1", eventStore, nil)
+	require.NoError(t, err)
+	assert.Empty(t, diff.PreviousEventID)
+	require.Len(t, diff.Changes, 1)
+	assert.Equal(t, ChangeAdd, diff.Changes[0].Op)
+	assert.Equal(t, "payload", diff.Changes[0].Attachment)
+
+	// events without attachments have no changes
+	event, err = GetEvent(context.Background(), "read", "p1", eventStore, nil)
+	require.NoError(t, err)
+	diff, err = GetEventDiff(context.Background(), event, "p1", eventStore, nil)
+	require.NoError(t, err)
+	assert.Equal(t, &EventDiff{Changes: []Change{}}, diff)
+}
@@ -5,7 +5,9 @@ package hermes
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jinzhu/copier"
 	"github.com/sapcc/go-api-declarations/cadf"
@@ -23,6 +25,7 @@ type ListEvent struct {
 	Action      string            `json:"action"`
 	Outcome     string            `json:"outcome"`
 	RequestPath string            `json:"requestPath"`
+	Summary     string            `json:"summary,omitempty"`
 	Initiator   ResourceRef       `json:"initiator"`
 	Target      ResourceRef       `json:"target"`
 	Observer    ResourceRef       `json:"observer"`
@@ -55,6 +58,72 @@ type EventFilter struct {
 	Details       bool // Additional Detail for eventsList func which includes attachments.
 }
 
+// FieldFilter contains the field filters of EventFilter, with the query
+// parameter names of GET /v1/events as JSON names. Subsystems that persist a
+// filter (alert rules, subscriptions) store it in this form. Empty fields match
+// all events.
+type FieldFilter struct {
+	ObserverType  string `json:"observer_type,omitempty"`
+	TargetType    string `json:"target_type,omitempty"`
+	TargetID      string `json:"target_id,omitempty"`
+	InitiatorID   string `json:"initiator_id,omitempty"`
+	InitiatorType string `json:"initiator_type,omitempty"`
+	InitiatorName string `json:"initiator_name,omitempty"`
+	Action        string `json:"action,omitempty"`
+	Outcome       string `json:"outcome,omitempty"`
+	Search        string `json:"search,omitempty"`
+	RequestPath   string `json:"request_path,omitempty"`
+}
+
+// Between returns an EventFilter with these field filters that selects the
+// events with eventTime in [from, until).
+func (f FieldFilter) Between(from, until time.Time) *EventFilter {
+	return &EventFilter{
+		ObserverType:  f.ObserverType,
+		TargetType:    f.TargetType,
+		TargetID:      f.TargetID,
+		InitiatorID:   f.InitiatorID,
+		InitiatorType: f.InitiatorType,
+		InitiatorName: f.InitiatorName,
+		Action:        f.Action,
+		Outcome:       f.Outcome,
+		Search:        f.Search,
+		RequestPath:   f.RequestPath,
+		Time: map[string]string{
+			"gte": from.UTC().Format(time.RFC3339Nano),
+			"lt":  until.UTC().Format(time.RFC3339Nano),
+		},
+	}
+}
+
+// EventView controls the per-caller post-processing that GetEvents and GetEvent
+// apply to stored events before returning them. A nil *EventView returns the
+// events as stored.
+type EventView struct {
+	// Redactor hides fields that Caller is not allowed to see.
+	Redactor *Redactor
+	// Caller is the token of the requesting user, used to evaluate redaction policies.
+	Caller PolicyChecker
+	// Names fills in missing names of users, projects and domains. May be nil.
+	Names NameResolver
+	// Summarizer renders the Summary of list items. May be nil.
+	Summarizer *Summarizer
+}
+
+// apply runs the view's post-processing on a batch of stored events. Names
+// are filled in before redaction, so that redacted names stay hidden.
+func (v *EventView) apply(ctx context.Context, events []*cadf.Event) {
+	if v == nil {
+		return
+	}
+	if v.Names != nil {
+		enrichNames(ctx, v.Names, events)
+	}
+	for _, event := range events {
+		v.Redactor.Redact(event, v.Caller)
+	}
+}
+
 // FieldOrder is an embedded struct for Event Filtering
 type FieldOrder struct {
 	Fieldname string
@@ -68,8 +137,23 @@ type AttributeFilter struct {
 	Limit     uint
 }
 
+// GetEventPayloads is like GetEvents, but returns the full CADF payload of
+// the matching events instead of list items. filter.Details is ignored.
+func GetEventPayloads(ctx context.Context, filter *EventFilter, tenantID string, eventStore storage.Storage, view *EventView) ([]*cadf.Event, int, error) {
+	storageFilter, err := storageFilter(filter, eventStore)
+	if err != nil {
+		return nil, 0, err
+	}
+	events, total, err := eventStore.GetEvents(ctx, storageFilter, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	view.apply(ctx, events)
+	return events, total, nil
+}
+
 // GetEvents returns a list of matching events (with filtering)
-func GetEvents(ctx context.Context, filter *EventFilter, tenantID string, eventStore storage.Storage) ([]*ListEvent, int, error) {
+func GetEvents(ctx context.Context, filter *EventFilter, tenantID string, eventStore storage.Storage, view *EventView) ([]*ListEvent, int, error) {
 	storageFilter, err := storageFilter(filter, eventStore)
 	if err != nil {
 		return nil, 0, err
@@ -81,13 +165,60 @@ func GetEvents(ctx context.Context, filter *EventFilter, tenantID string, eventS
 		return nil, 0, err
 	}
 
+	view.apply(ctx, eventDetails)
 	events, err := eventsList(eventDetails, filter.Details)
 	if err != nil {
 		return nil, 0, err
 	}
+	if view != nil {
+		for i, event := range events {
+			event.Summary = view.Summarizer.Summarize(eventDetails[i])
+		}
+	}
 	return events, total, err
 }
 
+// ErrTooManyEvents is returned by ForEachEvent when more events match the filter
+// than storage can page through.
+var ErrTooManyEvents = errors.New("too many matching events, please narrow the filter (e.g. the time range)")
+
+// ForEachEvent calls fn with the full CADF payload of every event that matches
+// the filter, in the filter's sort order. Offset and Limit of the filter are
+// ignored; instead all matching events are visited in pages of pageSize.
+// Returns the number of visited events.
+func ForEachEvent(ctx context.Context, filter *EventFilter, tenantID string, eventStore storage.Storage, view *EventView, pageSize uint, fn func(*cadf.Event) error) (int, error) {
+	pageFilter := *filter
+	pageFilter.Offset = 0
+	pageFilter.Limit = min(pageSize, eventStore.MaxLimit())
+
+	visited := 0
+	for {
+		storageFilter, err := storageFilter(&pageFilter, eventStore)
+		if err != nil {
+			return visited, err
+		}
+		events, total, err := eventStore.GetEvents(ctx, storageFilter, tenantID)
+		if err != nil {
+			return visited, err
+		}
+		if uint(total) > eventStore.MaxLimit() { //nolint:gosec // total is never negative
+			return visited, ErrTooManyEvents
+		}
+		view.apply(ctx, events)
+		for _, event := range events {
+			if err := fn(event); err != nil {
+				return visited, err
+			}
+			visited++
+		}
+		pageFilter.Offset += uint(len(events))
+		if len(events) == 0 || pageFilter.Offset >= uint(total) { //nolint:gosec // total is never negative
+			return visited, nil
+		}
+		pageFilter.Limit = min(pageFilter.Limit, eventStore.MaxLimit()-pageFilter.Offset)
+	}
+}
+
 func storageFilter(filter *EventFilter, eventStore storage.Storage) (*storage.EventFilter, error) {
 	// As per the documentation, the default limit is 10
 	if filter.Limit == 0 {
@@ -127,46 +258,61 @@ func storageFilter(filter *EventFilter, eventStore storage.Storage) (*storage.Ev
 func eventsList(eventDetails []*cadf.Event, details bool) ([]*ListEvent, error) {
 	var events []*ListEvent
 	for _, storageEvent := range eventDetails {
-		event := ListEvent{
-			Initiator: ResourceRef{
-				TypeURI: storageEvent.Initiator.TypeURI,
-				ID:      storageEvent.Initiator.ID,
-				Name:    storageEvent.Initiator.Name,
-			},
-			Target: ResourceRef{
-				TypeURI: storageEvent.Target.TypeURI,
-				ID:      storageEvent.Target.ID,
-			},
-			ID:          storageEvent.ID,
-			Action:      string(storageEvent.Action),
-			Outcome:     string(storageEvent.Outcome),
-			RequestPath: storageEvent.RequestPath,
-			Time:        storageEvent.EventTime,
-			Observer: ResourceRef{
-				TypeURI: storageEvent.Observer.TypeURI,
-				ID:      storageEvent.Observer.ID,
-				Name:    storageEvent.Observer.Name,
-			},
-		}
-		if details {
-			event.Attachments = storageEvent.Attachments
-		}
-		copiedInitiator := storageEvent.Initiator              // Create a copy of the Initiator
-		err := copier.Copy(&event.Initiator, &copiedInitiator) // Use the copy as the source for the copy
+		event, err := NewListEvent(storageEvent, details)
 		if err != nil {
 			return nil, err
 		}
-
-		events = append(events, &event)
+		events = append(events, event)
 	}
 	return events, nil
 }
 
+// NewListEvent builds the list item for a full CADF event. Attachments are
+// only included if details is set.
+func NewListEvent(storageEvent *cadf.Event, details bool) (*ListEvent, error) {
+	event := ListEvent{
+		Initiator: ResourceRef{
+			TypeURI: storageEvent.Initiator.TypeURI,
+			ID:      storageEvent.Initiator.ID,
+			Name:    storageEvent.Initiator.Name,
+		},
+		Target: ResourceRef{
+			TypeURI: storageEvent.Target.TypeURI,
+			ID:      storageEvent.Target.ID,
+			Name:    storageEvent.Target.Name,
+		},
+		ID:          storageEvent.ID,
+		Action:      string(storageEvent.Action),
+		Outcome:     string(storageEvent.Outcome),
+		RequestPath: storageEvent.RequestPath,
+		Time:        storageEvent.EventTime,
+		Observer: ResourceRef{
+			TypeURI: storageEvent.Observer.TypeURI,
+			ID:      storageEvent.Observer.ID,
+			Name:    storageEvent.Observer.Name,
+		},
+	}
+	if details {
+		event.Attachments = storageEvent.Attachments
+	}
+	copiedInitiator := storageEvent.Initiator              // Create a copy of the Initiator
+	err := copier.Copy(&event.Initiator, &copiedInitiator) // Use the copy as the source for the copy
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
 // GetEvent returns the CADF detail for event with the specified ID
-func GetEvent(ctx context.Context, eventID, tenantID string, eventStore storage.Storage) (*cadf.Event, error) {
+func GetEvent(ctx context.Context, eventID, tenantID string, eventStore storage.Storage, view *EventView) (*cadf.Event, error) {
 	event, err := eventStore.GetEvent(ctx, eventID, tenantID)
-
-	return event, err
+	if err != nil {
+		return nil, err
+	}
+	if event != nil {
+		view.apply(ctx, []*cadf.Event{event})
+	}
+	return event, nil
 }
 
 // GetAttributes No Logic here, but handles mock implementation for eventStore
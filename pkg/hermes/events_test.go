@@ -7,6 +7,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/sapcc/go-api-declarations/cadf"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -15,7 +16,7 @@ import (
 
 func Test_GetEvent(t *testing.T) {
 	eventID := "7be6c4ff-b761-5f1f-b234-f5d41616c2cd"
-	event, err := GetEvent(context.Background(), eventID, "", storage.Mock{})
+	event, err := GetEvent(context.Background(), eventID, "", storage.Mock{}, nil)
 	require.Nil(t, err)
 	require.NotNil(t, event)
 	assert.Equal(t, "7be6c4ff-b761-5f1f-b234-f5d41616c2cd", event.ID)
@@ -25,7 +26,7 @@ func Test_GetEvent(t *testing.T) {
 }
 
 func Test_GetEvents(t *testing.T) {
-	events, total, err := GetEvents(context.Background(), &EventFilter{}, "", storage.Mock{})
+	events, total, err := GetEvents(context.Background(), &EventFilter{}, "", storage.Mock{}, nil)
 	require.Nil(t, err)
 	require.NotNil(t, events)
 	assert.Equal(t, len(events), 4)
@@ -42,6 +43,28 @@ func Test_GetEvents(t *testing.T) {
 	assert.NotEqual(t, events[0].ID, events[2].ID)
 }
 
+func Test_ForEachEvent(t *testing.T) {
+	eventStore := storage.NewMemory(5)
+	for _, id := range []string{"e1", "e2", "e3", "e4"} {
+		eventStore.Add([]string{"project-a"}, cadf.Event{ID: id, EventTime: "2026-01-01T00:00:0" + id[1:] + "Z", Outcome: cadf.SuccessOutcome})
+	}
+	eventStore.Add([]string{"project-a"}, cadf.Event{ID: "failed", EventTime: "2026-01-01T00:00:00Z", Outcome: cadf.FailureOutcome})
+
+	var ids []string
+	filter := EventFilter{Outcome: "success", Sort: []FieldOrder{{Fieldname: "time", Order: "asc"}}, Offset: 3, Limit: 1}
+	count, err := ForEachEvent(context.Background(), &filter, "project-a", eventStore, nil, 3, func(event *cadf.Event) error {
+		ids = append(ids, event.ID)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 4, count)
+	assert.Equal(t, []string{"e1", "e2", "e3", "e4"}, ids, "offset and limit of the filter must be ignored")
+
+	eventStore.Add([]string{"project-a"}, cadf.Event{ID: "e5", Outcome: cadf.SuccessOutcome}, cadf.Event{ID: "e6", Outcome: cadf.SuccessOutcome})
+	_, err = ForEachEvent(context.Background(), &EventFilter{}, "project-a", eventStore, nil, 3, func(*cadf.Event) error { return nil })
+	assert.ErrorIs(t, err, ErrTooManyEvents)
+}
+
 func Test_GetAttributes(t *testing.T) {
 	attributes, err := GetAttributes(context.Background(), &AttributeFilter{QueryName: "action"}, "", storage.Mock{})
 	require.Nil(t, err)
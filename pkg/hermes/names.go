@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package hermes
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/sapcc/go-bits/logg"
+)
+
+// NameKind is the kind of Keystone entity whose names a NameResolver looks up.
+type NameKind string
+
+const (
+	// UserNames are the names of Keystone users.
+	UserNames NameKind = "user"
+	// ProjectNames are the names of Keystone projects.
+	ProjectNames NameKind = "project"
+	// DomainNames are the names of Keystone domains.
+	DomainNames NameKind = "domain"
+)
+
+// NameResolver looks up the names of Keystone users, projects and domains by ID.
+type NameResolver interface {
+	// ResolveNames returns the names of the entities with the given IDs.
+	// IDs that do not exist are missing from the result. On error, the names
+	// that could be resolved are returned as well.
+	ResolveNames(ctx context.Context, kind NameKind, ids []string) (map[string]string, error)
+}
+
+// targetNameKind returns which kind of name belongs to a target of the given
+// typeURI, e.g. "data/security/account/user", or "" if there is none.
+func targetNameKind(typeURI string) NameKind {
+	switch {
+	case strings.HasSuffix(typeURI, "/account/user"):
+		return UserNames
+	case strings.HasSuffix(typeURI, "/project"):
+		return ProjectNames
+	case strings.HasSuffix(typeURI, "/domain"):
+		return DomainNames
+	default:
+		return ""
+	}
+}
+
+// nameField is a name in an event that can be filled in from an ID.
+type nameField struct {
+	kind NameKind
+	id   string
+	name *string
+}
+
+// nameFields returns the empty names in the event that have an ID.
+func nameFields(event *cadf.Event) []nameField {
+	var fields []nameField
+	add := func(kind NameKind, id string, name *string) {
+		if kind != "" && id != "" && *name == "" {
+			fields = append(fields, nameField{kind, id, name})
+		}
+	}
+	// the initiator is the user who sent the request
+	add(UserNames, event.Initiator.ID, &event.Initiator.Name)
+	add(ProjectNames, event.Initiator.ProjectID, &event.Initiator.ProjectName)
+	add(DomainNames, event.Initiator.DomainID, &event.Initiator.DomainName)
+	add(targetNameKind(event.Target.TypeURI), event.Target.ID, &event.Target.Name)
+	return fields
+}
+
+// enrichNames fills in the missing names of users, projects and domains in
+// the events, with one lookup per kind for the whole batch. Lookup errors are
+// logged and leave the names empty, so that reading events does not depend
+// on Keystone.
+func enrichNames(ctx context.Context, resolver NameResolver, events []*cadf.Event) {
+	var fields []nameField
+	for _, event := range events {
+		fields = append(fields, nameFields(event)...)
+	}
+	idsByKind := make(map[NameKind][]string)
+	for _, field := range fields {
+		if !slices.Contains(idsByKind[field.kind], field.id) {
+			idsByKind[field.kind] = append(idsByKind[field.kind], field.id)
+		}
+	}
+
+	namesByKind := make(map[NameKind]map[string]string, len(idsByKind))
+	for _, kind := range []NameKind{UserNames, ProjectNames, DomainNames} {
+		if len(idsByKind[kind]) == 0 {
+			continue
+		}
+		names, err := resolver.ResolveNames(ctx, kind, idsByKind[kind])
+		if err != nil {
+			logg.Error("hermes: cannot resolve %s names: %s", kind, err.Error())
+		}
+		namesByKind[kind] = names
+	}
+	for _, field := range fields {
+		*field.name = namesByKind[field.kind][field.id]
+	}
+}
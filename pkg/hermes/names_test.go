@@ -0,0 +1,132 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package hermes
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapcc/hermes/pkg/storage"
+)
+
+// fakeNames is a NameResolver that records its calls.
+type fakeNames struct {
+	names map[NameKind]map[string]string
+	fail  NameKind
+	calls map[NameKind][][]string
+}
+
+func (f *fakeNames) ResolveNames(_ context.Context, kind NameKind, ids []string) (map[string]string, error) {
+	if f.calls == nil {
+		f.calls = make(map[NameKind][][]string)
+	}
+	f.calls[kind] = append(f.calls[kind], slices.Clone(ids))
+	if kind == f.fail {
+		return nil, errors.New("keystone is down")
+	}
+	return f.names[kind], nil
+}
+
+func Test_GetEvents_NameEnrichment(t *testing.T) {
+	eventStore := storage.NewMemory(10)
+	initiator := cadf.Resource{TypeURI: "service/security/account/user", ID: "u1", ProjectID: "p1", DomainID: "d1"}
+	eventStore.Add([]string{"p1"}, cadf.Event{
+		ID: "e1", EventTime: "2026-01-01T00:00:01Z", Initiator: initiator,
+		Target: cadf.Resource{TypeURI: "data/security/account/user", ID: "u2"},
+	})
+	eventStore.Add([]string{"p1"}, cadf.Event{
+		ID: "e2", EventTime: "2026-01-01T00:00:02Z", Initiator: initiator,
+		Target: cadf.Resource{TypeURI: "data/security/project", ID: "p2"},
+	})
+	eventStore.Add([]string{"p1"}, cadf.Event{
+		ID: "e3", EventTime: "2026-01-01T00:00:03Z",
+		Initiator: cadf.Resource{TypeURI: "service/security/account/user", ID: "u3", Name: "stored name"},
+		Target:    cadf.Resource{TypeURI: "compute/server", ID: "server-1"},
+	})
+	names := &fakeNames{names: map[NameKind]map[string]string{
+		UserNames:    {"u1": "alice", "u2": "bob"},
+		ProjectNames: {"p1": "project one", "p2": "project two"},
+		DomainNames:  {"d1": "domain one"},
+	}}
+
+	filter := EventFilter{Sort: []FieldOrder{{Fieldname: "time", Order: "asc"}}}
+	payloads, _, err := GetEventPayloads(context.Background(), &filter, "p1", eventStore, &EventView{Names: names})
+	require.NoError(t, err)
+	require.Len(t, payloads, 3)
+	assert.Equal(t, "alice", payloads[0].Initiator.Name)
+	assert.Equal(t, "project one", payloads[0].Initiator.ProjectName)
+	assert.Equal(t, "domain one", payloads[0].Initiator.DomainName)
+	assert.Equal(t, "bob", payloads[0].Target.Name)
+	assert.Equal(t, "project two", payloads[1].Target.Name)
+	// stored names are kept, and targets without a Keystone type are not looked up
+	assert.Equal(t, "stored name", payloads[2].Initiator.Name)
+	assert.Empty(t, payloads[2].Target.Name)
+
+	// one lookup per kind for the whole page, without duplicate IDs
+	assert.Equal(t, map[NameKind][][]string{
+		UserNames:    {{"u1", "u2"}},
+		ProjectNames: {{"p1", "p2"}},
+		DomainNames:  {{"d1"}},
+	}, names.calls)
+
+	// names are filled in before redaction
+	redactor, err := NewRedactor(Operators{ProjectIDs: []string{"p1"}}, []RedactionRule{
+		{Field: "initiator.name", Policy: "event:show_initiator_name", Action: RedactMask},
+	})
+	require.NoError(t, err)
+	events, _, err := GetEvents(context.Background(), &filter, "p1", eventStore, &EventView{Redactor: redactor, Caller: fakeCaller{}, Names: names})
+	require.NoError(t, err)
+	assert.Equal(t, MaskedValue, events[0].Initiator.Name)
+	assert.Equal(t, "bob", events[0].Target.Name)
+
+	// lookup errors leave the names empty instead of failing the request
+	names.fail = UserNames
+	event, err := GetEvent(context.Background(), "e1", "p1", eventStore, &EventView{Names: names})
+	require.NoError(t, err)
+	assert.Empty(t, event.Initiator.Name)
+	assert.Equal(t, "project one", event.Initiator.ProjectName)
+}
+
+func Test_GetEvents_NamesOnlyOnRequest(t *testing.T) {
+	eventStore := storage.NewMemory(10)
+	eventStore.Add([]string{"p1"}, cadf.Event{
+		ID: "e1", EventTime: "2026-01-01T00:00:01Z",
+		Initiator: cadf.Resource{TypeURI: "service/security/account/user", ID: "u1", ProjectID: "p1"},
+		Target:    cadf.Resource{TypeURI: "data/security/account/user", ID: "u2"},
+	})
+	names := &fakeNames{names: map[NameKind]map[string]string{
+		UserNames:    {"u1": "alice", "u2": "bob"},
+		ProjectNames: {"p1": "project one"},
+	}}
+
+	// without a resolver in the view, nothing is looked up
+	filter := EventFilter{}
+	events, _, err := GetEvents(context.Background(), &filter, "p1", eventStore, &EventView{})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Empty(t, events[0].Initiator.Name)
+	assert.Empty(t, events[0].Target.Name)
+	event, err := GetEvent(context.Background(), "e1", "p1", eventStore, &EventView{})
+	require.NoError(t, err)
+	assert.Empty(t, event.Initiator.Name)
+	assert.Empty(t, event.Initiator.ProjectName)
+
+	// list entries and details carry the same names
+	events, _, err = GetEvents(context.Background(), &filter, "p1", eventStore, &EventView{Names: names})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "alice", events[0].Initiator.Name)
+	assert.Equal(t, "bob", events[0].Target.Name)
+	event, err = GetEvent(context.Background(), "e1", "p1", eventStore, &EventView{Names: names})
+	require.NoError(t, err)
+	assert.Equal(t, "alice", event.Initiator.Name)
+	assert.Equal(t, "project one", event.Initiator.ProjectName)
+	assert.Equal(t, "bob", event.Target.Name)
+}
@@ -0,0 +1,257 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package hermes
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/spf13/viper"
+)
+
+// MaskedValue is the value that replaces masked string fields.
+const MaskedValue = "***"
+
+// RedactionAction selects what happens to a field when a RedactionRule applies.
+type RedactionAction string
+
+const (
+	// RedactRemove removes the field from the event entirely.
+	RedactRemove RedactionAction = "remove"
+	// RedactMask replaces the field's value with MaskedValue.
+	// Structured fields (hosts, addresses, attachments) keep their shape,
+	// but every string value inside them is masked.
+	RedactMask RedactionAction = "mask"
+)
+
+// RedactionRule hides a single CADF field of events caused by cloud operators
+// from callers that do not satisfy the given oslo policy rule.
+//
+// The mapstructure tags allow loading rules from the [[redaction.rules]]
+// config section, e.g.:
+//
+//	[[redaction.rules]]
+//	field  = "initiator.host.address"
+//	policy = "event:show_initiator_host"
+//	action = "mask"
+type RedactionRule struct {
+	Field  string          `mapstructure:"field"`
+	Policy string          `mapstructure:"policy"`
+	Action RedactionAction `mapstructure:"action"`
+}
+
+// PolicyChecker is the part of *gopherpolicy.Token that redaction needs.
+// It is an interface so that the hermes package does not depend on how
+// the caller was authenticated.
+type PolicyChecker interface {
+	Check(rule string) bool
+}
+
+// redactableField knows how to mask and remove one CADF field path.
+type redactableField struct {
+	mask   func(e *cadf.Event)
+	remove func(e *cadf.Event)
+}
+
+// redactableFields lists the CADF field paths that can be named in a RedactionRule.
+// Paths use the JSON names of the fields as they appear in the API response.
+var redactableFields = map[string]redactableField{
+	"requestPath": {
+		mask:   func(e *cadf.Event) { maskString(&e.RequestPath) },
+		remove: func(e *cadf.Event) { e.RequestPath = "" },
+	},
+	"attachments": {
+		mask:   func(e *cadf.Event) { maskAttachments(e.Attachments) },
+		remove: func(e *cadf.Event) { e.Attachments = nil },
+	},
+	"initiator.name": {
+		mask:   func(e *cadf.Event) { maskString(&e.Initiator.Name) },
+		remove: func(e *cadf.Event) { e.Initiator.Name = "" },
+	},
+	"initiator.domain": {
+		mask:   func(e *cadf.Event) { maskString(&e.Initiator.Domain) },
+		remove: func(e *cadf.Event) { e.Initiator.Domain = "" },
+	},
+	"initiator.host": {
+		mask: func(e *cadf.Event) {
+			if e.Initiator.Host != nil {
+				maskString(&e.Initiator.Host.ID)
+				maskString(&e.Initiator.Host.Address)
+				maskString(&e.Initiator.Host.Agent)
+				maskString(&e.Initiator.Host.Platform)
+			}
+		},
+		remove: func(e *cadf.Event) { e.Initiator.Host = nil },
+	},
+	"initiator.host.address": {
+		mask: func(e *cadf.Event) {
+			if e.Initiator.Host != nil {
+				maskString(&e.Initiator.Host.Address)
+			}
+		},
+		remove: func(e *cadf.Event) {
+			if e.Initiator.Host != nil {
+				e.Initiator.Host.Address = ""
+			}
+		},
+	},
+	"initiator.host.agent": {
+		mask: func(e *cadf.Event) {
+			if e.Initiator.Host != nil {
+				maskString(&e.Initiator.Host.Agent)
+			}
+		},
+		remove: func(e *cadf.Event) {
+			if e.Initiator.Host != nil {
+				e.Initiator.Host.Agent = ""
+			}
+		},
+	},
+	"initiator.attachments": {
+		mask:   func(e *cadf.Event) { maskAttachments(e.Initiator.Attachments) },
+		remove: func(e *cadf.Event) { e.Initiator.Attachments = nil },
+	},
+	"target.addresses": {
+		mask: func(e *cadf.Event) {
+			for i := range e.Target.Addresses {
+				maskString(&e.Target.Addresses[i].URL)
+				maskString(&e.Target.Addresses[i].Name)
+			}
+		},
+		remove: func(e *cadf.Event) { e.Target.Addresses = nil },
+	},
+	"target.attachments": {
+		mask:   func(e *cadf.Event) { maskAttachments(e.Target.Attachments) },
+		remove: func(e *cadf.Event) { e.Target.Attachments = nil },
+	},
+}
+
+func maskString(s *string) {
+	if *s != "" {
+		*s = MaskedValue
+	}
+}
+
+func maskAttachments(attachments []cadf.Attachment) {
+	for i := range attachments {
+		attachments[i].Content = MaskedValue
+	}
+}
+
+// Operators identifies cloud operators by the project or domain that their
+// token was scoped to, as recorded in the initiator of their events.
+//
+// The mapstructure tags allow loading them from the [redaction] config
+// section, e.g.:
+//
+//	[redaction]
+//	operator_project_ids = ["c4c4ad2f8bbc4b19a1ec4cb6a7a4ea3c"]
+//	operator_domain_ids  = ["f2a2d7a47d4a4c2e9e5a19d2ab44b6e1"]
+type Operators struct {
+	ProjectIDs []string `mapstructure:"operator_project_ids"`
+	DomainIDs  []string `mapstructure:"operator_domain_ids"`
+}
+
+// Initiated returns whether the event was caused by a cloud operator.
+func (o Operators) Initiated(event *cadf.Event) bool {
+	initiator := event.Initiator
+	return (initiator.ProjectID != "" && slices.Contains(o.ProjectIDs, initiator.ProjectID)) ||
+		(initiator.DomainID != "" && slices.Contains(o.DomainIDs, initiator.DomainID))
+}
+
+// Redactor masks or removes CADF fields of events caused by cloud operators
+// according to a list of RedactionRules. Events of other initiators are
+// returned in full. A nil *Redactor redacts nothing.
+type Redactor struct {
+	operators Operators
+	rules     []RedactionRule
+}
+
+// NewRedactor validates the given rules and builds a Redactor from them.
+// An empty Action defaults to RedactRemove. Rules require at least one
+// operator project or domain, since they would never apply otherwise.
+func NewRedactor(operators Operators, rules []RedactionRule) (*Redactor, error) {
+	var errs []error
+	if len(rules) > 0 && len(operators.ProjectIDs) == 0 && len(operators.DomainIDs) == 0 {
+		errs = append(errs, errors.New("redaction rules require operator_project_ids or operator_domain_ids"))
+	}
+	validated := make([]RedactionRule, 0, len(rules))
+	for idx, rule := range rules {
+		if _, ok := redactableFields[rule.Field]; !ok {
+			errs = append(errs, fmt.Errorf("redaction rule %d: unsupported field %q (supported: %s)",
+				idx, rule.Field, strings.Join(RedactableFields(), ", ")))
+		}
+		if strings.TrimSpace(rule.Policy) == "" {
+			errs = append(errs, fmt.Errorf("redaction rule %d: policy must not be empty", idx))
+		}
+		switch rule.Action {
+		case "":
+			rule.Action = RedactRemove
+		case RedactRemove, RedactMask:
+		default:
+			errs = append(errs, fmt.Errorf("redaction rule %d: action must be %q or %q, got %q",
+				idx, RedactRemove, RedactMask, rule.Action))
+		}
+		validated = append(validated, rule)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return &Redactor{operators: operators, rules: validated}, nil
+}
+
+// NewRedactorFromConfig builds a Redactor from the [redaction] config section
+// and its [[redaction.rules]]. Returns a Redactor without rules when the
+// section is absent.
+func NewRedactorFromConfig() (*Redactor, error) {
+	var rules []RedactionRule
+	if err := viper.UnmarshalKey("redaction.rules", &rules); err != nil {
+		return nil, fmt.Errorf("cannot parse redaction.rules: %w", err)
+	}
+	operators := Operators{
+		ProjectIDs: viper.GetStringSlice("redaction.operator_project_ids"),
+		DomainIDs:  viper.GetStringSlice("redaction.operator_domain_ids"),
+	}
+	return NewRedactor(operators, rules)
+}
+
+// RedactableFields returns the sorted list of field paths accepted in RedactionRule.Field.
+func RedactableFields() []string {
+	fields := make([]string, 0, len(redactableFields))
+	for field := range redactableFields {
+		fields = append(fields, field)
+	}
+	slices.Sort(fields)
+	return fields
+}
+
+// Redact applies every rule whose policy the caller does not satisfy, if the
+// event was caused by a cloud operator. The event is modified in place.
+// A nil caller is treated as satisfying no rules.
+func (r *Redactor) Redact(event *cadf.Event, caller PolicyChecker) {
+	if r == nil || event == nil || !r.operators.Initiated(event) {
+		return
+	}
+	// Policy checks are cached per call because the same rule often guards several fields.
+	allowed := make(map[string]bool, len(r.rules))
+	for _, rule := range r.rules {
+		ok, checked := allowed[rule.Policy]
+		if !checked {
+			ok = caller != nil && caller.Check(rule.Policy)
+			allowed[rule.Policy] = ok
+		}
+		if ok {
+			continue
+		}
+		field := redactableFields[rule.Field]
+		if rule.Action == RedactMask {
+			field.mask(event)
+		} else {
+			field.remove(event)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package hermes
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapcc/hermes/pkg/storage"
+)
+
+// fakeCaller satisfies PolicyChecker with a fixed set of granted rules.
+type fakeCaller map[string]bool
+
+func (c fakeCaller) Check(rule string) bool { return c[rule] }
+
+// testOperators contains the project of the initiator of the storage.Mock event.
+var testOperators = Operators{ProjectIDs: []string{"a759dcc2a2384a76b0386bb985952373"}}
+
+func Test_NewRedactor_Validation(t *testing.T) {
+	_, err := NewRedactor(testOperators, []RedactionRule{{Field: "initiator.password", Policy: "event:show_secret"}})
+	assert.ErrorContains(t, err, `unsupported field "initiator.password"`)
+
+	_, err = NewRedactor(testOperators, []RedactionRule{{Field: "initiator.host", Policy: ""}})
+	assert.ErrorContains(t, err, "policy must not be empty")
+
+	_, err = NewRedactor(testOperators, []RedactionRule{{Field: "initiator.host", Policy: "event:show_initiator_host", Action: "shred"}})
+	assert.ErrorContains(t, err, `action must be "remove" or "mask"`)
+
+	_, err = NewRedactor(Operators{}, []RedactionRule{{Field: "initiator.host", Policy: "event:show_initiator_host"}})
+	assert.ErrorContains(t, err, "require operator_project_ids or operator_domain_ids")
+
+	r, err := NewRedactor(testOperators, []RedactionRule{{Field: "initiator.host", Policy: "event:show_initiator_host"}})
+	require.NoError(t, err)
+	assert.Equal(t, RedactRemove, r.rules[0].Action)
+	_, err = NewRedactor(Operators{}, nil)
+	require.NoError(t, err)
+}
+
+func Test_GetEvent_Redaction(t *testing.T) {
+	redactor, err := NewRedactor(testOperators, []RedactionRule{
+		{Field: "initiator.host.address", Policy: "event:show_initiator_host", Action: RedactMask},
+		{Field: "initiator.host.agent", Policy: "event:show_initiator_host"},
+		{Field: "attachments", Policy: "event:show_attachments", Action: RedactMask},
+	})
+	require.NoError(t, err)
+	eventID := "7be6c4ff-b761-5f1f-b234-f5d41616c2cd"
+
+	// caller without any of the rules sees masked/removed fields
+	view := &EventView{Redactor: redactor, Caller: fakeCaller{}}
+	event, err := GetEvent(context.Background(), eventID, "", storage.Mock{}, view)
+	require.NoError(t, err)
+	require.NotNil(t, event.Initiator.Host)
+	assert.Equal(t, MaskedValue, event.Initiator.Host.Address)
+	assert.Empty(t, event.Initiator.Host.Agent)
+	require.Len(t, event.Attachments, 1)
+	assert.Equal(t, "role_id", event.Attachments[0].Name)
+	assert.Equal(t, MaskedValue, event.Attachments[0].Content)
+	// fields without rules are untouched
+	assert.Equal(t, "test_admin", event.Initiator.Name)
+
+	// caller with the host rule sees the host but not the attachments
+	view.Caller = fakeCaller{"event:show_initiator_host": true}
+	event, err = GetEvent(context.Background(), eventID, "", storage.Mock{}, view)
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", event.Initiator.Host.Address)
+	assert.NotEmpty(t, event.Initiator.Host.Agent)
+	assert.Equal(t, MaskedValue, event.Attachments[0].Content)
+
+	// a nil caller is denied everything
+	view.Caller = nil
+	event, err = GetEvent(context.Background(), eventID, "", storage.Mock{}, view)
+	require.NoError(t, err)
+	assert.Equal(t, MaskedValue, event.Initiator.Host.Address)
+}
+
+func Test_GetEvents_Redaction(t *testing.T) {
+	redactor, err := NewRedactor(Operators{ProjectIDs: []string{"admin-project"}, DomainIDs: []string{"admin-domain"}}, []RedactionRule{
+		{Field: "initiator.name", Policy: "event:show_initiator_name", Action: RedactMask},
+	})
+	require.NoError(t, err)
+	eventStore := storage.NewMemory(10)
+	for idx, initiator := range []cadf.Resource{
+		{ID: "u1", Name: "operator", ProjectID: "admin-project"},
+		{ID: "u2", Name: "domain operator", DomainID: "admin-domain"},
+		{ID: "u3", Name: "tenant", ProjectID: "p1", DomainID: "d1"},
+	} {
+		eventStore.Add([]string{"p1"}, cadf.Event{ID: fmt.Sprintf("e%d", idx+1), EventTime: fmt.Sprintf("2026-01-01T00:00:0%dZ", idx+1), Initiator: initiator})
+	}
+
+	// only events caused by cloud operators are redacted
+	filter := EventFilter{Sort: []FieldOrder{{Fieldname: "time", Order: "asc"}}}
+	view := &EventView{Redactor: redactor, Caller: fakeCaller{}}
+	events, _, err := GetEvents(context.Background(), &filter, "p1", eventStore, view)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Equal(t, MaskedValue, events[0].Initiator.Name)
+	assert.Equal(t, MaskedValue, events[1].Initiator.Name)
+	assert.Equal(t, "tenant", events[2].Initiator.Name)
+	for _, event := range events {
+		assert.NotEmpty(t, event.Initiator.ID)
+	}
+}
@@ -0,0 +1,238 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package hermes
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/spf13/viper"
+)
+
+// SummaryTemplate renders the one-line summary of the events that match its
+// observer type, target type and action. Each of these is either empty or "*"
+// (matches everything), a prefix ending in "/*" (e.g. "data/security/*"), or
+// an exact value. When several templates match an event, the most specific one
+// wins: exact values count more than prefixes, and prefixes more than "*".
+// Among equally specific templates, the first one wins.
+//
+// The mapstructure tags allow loading templates from the [[summaries.templates]]
+// config section, e.g.:
+//
+//	[[summaries.templates]]
+//	target_type = "data/security/project"
+//	action      = "update"
+//	template    = "{{.Initiator}} changed the settings of project {{.Target}}"
+//
+// Templates use Go's text/template syntax with the fields of SummaryData.
+type SummaryTemplate struct {
+	ObserverType string `mapstructure:"observer_type"`
+	TargetType   string `mapstructure:"target_type"`
+	Action       string `mapstructure:"action"`
+	Template     string `mapstructure:"template"`
+}
+
+// SummaryData is the data that summary templates are executed with.
+type SummaryData struct {
+	// Initiator is the name of the initiator, or its ID if the name is unknown.
+	Initiator string
+	// Target is the name of the target, or its ID if the name is unknown.
+	Target string
+	// TargetType is the last segment of the target's typeURI, e.g. "project".
+	TargetType string
+	// Observer is the name of the observing service, or the last segment of its typeURI.
+	Observer string
+	// Action is the CADF action, e.g. "update/add".
+	Action string
+	// Verb is the first segment of Action, e.g. "update".
+	Verb string
+	// Past is Verb in the past tense, e.g. "updated".
+	Past string
+	// Failed is true for events with the outcome "failure".
+	Failed bool
+	// Event is the full event.
+	Event *cadf.Event
+}
+
+// DefaultSummaryTemplates is the built-in catalog. Configured templates take
+// precedence over these when they are equally specific. The last entry
+// matches all events and is used when nothing else matches.
+var DefaultSummaryTemplates = []SummaryTemplate{
+	{Action: "authenticate", Template: `{{.Initiator}} {{if .Failed}}failed to log in{{else}}logged in{{end}}`},
+	{Action: "authenticate/*", Template: `{{.Initiator}} {{if .Failed}}failed to log in{{else}}logged in{{end}}`},
+	{Action: "authenticate/logout", Template: `{{.Initiator}} logged out`},
+	{Action: "create/role_assignment", Template: `{{.Initiator}} {{if .Failed}}failed to assign{{else}}assigned{{end}} a role to {{.TargetType}} {{.Target}}`},
+	{Action: "delete/role_assignment", Template: `{{.Initiator}} {{if .Failed}}failed to remove{{else}}removed{{end}} a role from {{.TargetType}} {{.Target}}`},
+	{Template: fallbackSummaryTemplate},
+}
+
+// fallbackSummaryTemplate is also used when a matching template fails to execute.
+const fallbackSummaryTemplate = `{{.Initiator}} {{if .Failed}}failed to {{.Verb}}{{else}}{{.Past}}{{end}} {{.TargetType}} {{.Target}}`
+
+// pastTense maps the CADF action verbs to their past tense.
+var pastTense = map[string]string{
+	"allow":        "allowed",
+	"authenticate": "authenticated",
+	"backup":       "backed up",
+	"capture":      "captured",
+	"configure":    "configured",
+	"create":       "created",
+	"delete":       "deleted",
+	"deny":         "denied",
+	"disable":      "disabled",
+	"enable":       "enabled",
+	"evaluate":     "evaluated",
+	"monitor":      "monitored",
+	"notify":       "notified",
+	"read":         "read",
+	"receive":      "received",
+	"restore":      "restored",
+	"send":         "sent",
+	"start":        "started",
+	"stop":         "stopped",
+	"undelete":     "restored",
+	"update":       "updated",
+}
+
+type summaryTemplate struct {
+	SummaryTemplate
+	tmpl *template.Template
+}
+
+// Summarizer renders one-line summaries of events from a template catalog.
+// A nil *Summarizer renders empty summaries.
+type Summarizer struct {
+	templates []summaryTemplate
+	fallback  *template.Template
+}
+
+// NewSummarizer validates the given templates and builds a Summarizer from
+// them, followed by DefaultSummaryTemplates.
+func NewSummarizer(templates []SummaryTemplate) (*Summarizer, error) {
+	s := &Summarizer{fallback: template.Must(template.New("fallback").Parse(fallbackSummaryTemplate))}
+	sample := summaryData(&cadf.Event{})
+	var errs []error
+	for idx, t := range slices.Concat(templates, DefaultSummaryTemplates) {
+		tmpl, err := template.New(fmt.Sprintf("summary template %d", idx)).Parse(t.Template)
+		if err == nil {
+			// catches references to unknown fields
+			err = tmpl.Execute(&strings.Builder{}, sample)
+		}
+		switch {
+		case strings.TrimSpace(t.Template) == "":
+			errs = append(errs, fmt.Errorf("summary template %d: template must not be empty", idx))
+		case err != nil:
+			errs = append(errs, fmt.Errorf("summary template %d: %w", idx, err))
+		}
+		s.templates = append(s.templates, summaryTemplate{t, tmpl})
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return s, nil
+}
+
+// NewSummarizerFromConfig builds a Summarizer from the [[summaries.templates]]
+// config section. Only the built-in templates are used when the section is absent.
+func NewSummarizerFromConfig() (*Summarizer, error) {
+	var templates []SummaryTemplate
+	if err := viper.UnmarshalKey("summaries.templates", &templates); err != nil {
+		return nil, fmt.Errorf("cannot parse summaries.templates: %w", err)
+	}
+	return NewSummarizer(templates)
+}
+
+// Summarize renders the summary of the event with the most specific matching template.
+func (s *Summarizer) Summarize(event *cadf.Event) string {
+	if s == nil || event == nil {
+		return ""
+	}
+	best, bestScore := -1, -1
+	for idx, t := range s.templates {
+		score, ok := t.match(event)
+		if ok && score > bestScore {
+			best, bestScore = idx, score
+		}
+	}
+	data := summaryData(event)
+	var buf strings.Builder
+	if best < 0 || s.templates[best].tmpl.Execute(&buf, data) != nil {
+		buf.Reset()
+		s.fallback.Execute(&buf, data) //nolint:errcheck // cannot fail, see NewSummarizer
+	}
+	// collapse the gaps left by empty fields
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
+
+// match reports whether the template applies to the event, and how specific it is.
+func (t summaryTemplate) match(event *cadf.Event) (int, bool) {
+	total := 0
+	for _, m := range []struct{ pattern, value string }{
+		{t.ObserverType, event.Observer.TypeURI},
+		{t.TargetType, event.Target.TypeURI},
+		{t.Action, string(event.Action)},
+	} {
+		score, ok := matchSummaryPattern(m.pattern, m.value)
+		if !ok {
+			return 0, false
+		}
+		total += score
+	}
+	return total, true
+}
+
+// matchSummaryPattern matches one field of a SummaryTemplate. Exact patterns
+// score 2, prefixes 1 and wildcards 0.
+func matchSummaryPattern(pattern, value string) (int, bool) {
+	switch {
+	case pattern == "" || pattern == "*":
+		return 0, true
+	case strings.HasSuffix(pattern, "/*"):
+		return 1, strings.HasPrefix(value, strings.TrimSuffix(pattern, "*"))
+	default:
+		return 2, pattern == value
+	}
+}
+
+func summaryData(event *cadf.Event) SummaryData {
+	action := string(event.Action)
+	verb, _, _ := strings.Cut(action, "/")
+	past, ok := pastTense[verb]
+	if !ok {
+		verb, past = "perform "+action+" on", "performed "+action+" on"
+	}
+	observer := event.Observer.Name
+	if observer == "" {
+		observer = lastSegment(event.Observer.TypeURI)
+	}
+	return SummaryData{
+		Initiator:  firstNonEmpty(event.Initiator.Name, event.Initiator.ID, "someone"),
+		Target:     firstNonEmpty(event.Target.Name, event.Target.ID),
+		TargetType: lastSegment(event.Target.TypeURI),
+		Observer:   observer,
+		Action:     action,
+		Verb:       verb,
+		Past:       past,
+		Failed:     event.Outcome == cadf.FailureOutcome,
+		Event:      event,
+	}
+}
+
+// lastSegment returns the part of a typeURI after the last slash.
+func lastSegment(typeURI string) string {
+	return typeURI[strings.LastIndex(typeURI, "/")+1:]
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
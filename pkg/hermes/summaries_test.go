@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package hermes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapcc/hermes/pkg/storage"
+)
+
+func Test_NewSummarizer_Validation(t *testing.T) {
+	_, err := NewSummarizer([]SummaryTemplate{{Action: "update"}})
+	assert.ErrorContains(t, err, "summary template 0: template must not be empty")
+
+	_, err = NewSummarizer([]SummaryTemplate{{Template: "{{.Initiator"}})
+	assert.ErrorContains(t, err, "summary template 0:")
+
+	_, err = NewSummarizer([]SummaryTemplate{{Template: "{{.Project}}"}})
+	assert.ErrorContains(t, err, `can't evaluate field Project`)
+}
+
+func Test_Summarize(t *testing.T) {
+	s, err := NewSummarizer([]SummaryTemplate{
+		{TargetType: "data/security/project", Action: "update", Template: "{{.Initiator}} changed project {{.Target}}"},
+		{ObserverType: "service/compute", Action: "*", Template: "{{.Observer}}: {{.Initiator}} {{.Past}} server {{.Target}}"},
+		{ObserverType: "service/storage/*", Template: "{{.Event.Initiator.Name}} used object storage"},
+	})
+	require.NoError(t, err)
+
+	alice := cadf.Resource{TypeURI: "service/security/account/user", ID: "u1", Name: "alice"}
+	tt := []struct {
+		event  cadf.Event
+		expect string
+	}{
+		// configured templates
+		{cadf.Event{Action: "update", Outcome: cadf.SuccessOutcome, Initiator: alice,
+			Target: cadf.Resource{TypeURI: "data/security/project", ID: "p1", Name: "foo"}},
+			"alice changed project foo"},
+		{cadf.Event{Action: "create", Initiator: alice, Observer: cadf.Resource{TypeURI: "service/compute", Name: "nova"},
+			Target: cadf.Resource{TypeURI: "compute/server", ID: "server-1"}},
+			"nova: alice created server server-1"},
+		{cadf.Event{Action: "read", Initiator: alice, Observer: cadf.Resource{TypeURI: "service/storage/object"}},
+			"alice used object storage"},
+		// built-in templates
+		{cadf.Event{Action: "authenticate", Outcome: cadf.FailureOutcome, Initiator: alice},
+			"alice failed to log in"},
+		{cadf.Event{Action: "authenticate/logout", Initiator: alice},
+			"alice logged out"},
+		{cadf.Event{Action: "create/role_assignment", Initiator: alice,
+			Target: cadf.Resource{TypeURI: "data/security/account/user", ID: "u2", Name: "bob"}},
+			"alice assigned a role to user bob"},
+		// fallbacks for unknown combinations
+		{cadf.Event{Action: "update/set", Outcome: cadf.SuccessOutcome, Initiator: alice,
+			Target: cadf.Resource{TypeURI: "network/port", ID: "port-1"}},
+			"alice updated port port-1"},
+		{cadf.Event{Action: "delete", Outcome: cadf.FailureOutcome, Initiator: cadf.Resource{ID: "u1"},
+			Target: cadf.Resource{TypeURI: "dns/zone", ID: "zone-1"}},
+			"u1 failed to delete zone zone-1"},
+		{cadf.Event{Action: "rotate", Target: cadf.Resource{TypeURI: "keymanager/secret"}},
+			"someone performed rotate on secret"},
+	}
+	for _, tc := range tt {
+		assert.Equal(t, tc.expect, s.Summarize(&tc.event), "action %s", tc.event.Action)
+	}
+
+	var nilSummarizer *Summarizer
+	assert.Empty(t, nilSummarizer.Summarize(&tt[0].event))
+}
+
+func Test_GetEvents_Summary(t *testing.T) {
+	s, err := NewSummarizer(nil)
+	require.NoError(t, err)
+	events, _, err := GetEvents(context.Background(), &EventFilter{}, "", storage.Mock{}, &EventView{Summarizer: s})
+	require.NoError(t, err)
+	require.NotEmpty(t, events)
+	for _, event := range events {
+		assert.NotEmpty(t, event.Summary)
+	}
+
+	// without a Summarizer, the summary stays empty
+	events, _, err = GetEvents(context.Background(), &EventFilter{}, "", storage.Mock{}, nil)
+	require.NoError(t, err)
+	assert.Empty(t, events[0].Summary)
+}
@@ -0,0 +1,31 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package hermes
+
+import (
+	"slices"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+)
+
+// TenantIDs returns the tenants (project or domain IDs) that an event belongs to,
+// mirroring how the ingestion pipeline fills the tenant_ids field of the storage
+// backend: the project and domain of both the target and the initiator, in that
+// order, without duplicates. The placeholder "unavailable" is not a tenant.
+func TenantIDs(event *cadf.Event) []string {
+	candidates := []string{
+		event.Target.ProjectID,
+		event.Target.DomainID,
+		event.Initiator.ProjectID,
+		event.Initiator.DomainID,
+	}
+	var result []string
+	for _, id := range candidates {
+		if id == "" || id == "unavailable" || slices.Contains(result, id) {
+			continue
+		}
+		result = append(result, id)
+	}
+	return result
+}
@@ -0,0 +1,239 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package hermes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/sapcc/go-api-declarations/cadf"
+
+	"github.com/sapcc/hermes/pkg/storage"
+)
+
+// CADFEventTypeURI is the typeURI of all CADF events.
+const CADFEventTypeURI = "http://schemas.dmtf.org/cloud/audit/1.0/event"
+
+// Finding is a problem found by ValidateEvent.
+type Finding struct {
+	// Field is the JSON path of the offending field, e.g. "observer.typeURI",
+	// or empty if the finding concerns the whole event.
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// ValidationResult is the outcome of ValidateEvent. Errors make an event
+// unusable for Hermes (it is not stored, cannot be found, or cannot be
+// shown); warnings make it display badly or violate our conventions.
+type ValidationResult struct {
+	Valid bool `json:"valid"`
+	// TenantIDs are the projects and domains that will see the event, see TenantIDs.
+	TenantIDs []string  `json:"tenant_ids"`
+	Errors    []Finding `json:"errors"`
+	Warnings  []Finding `json:"warnings"`
+}
+
+func (r *ValidationResult) addError(field, format string, args ...any) {
+	r.Errors = append(r.Errors, Finding{field, fmt.Sprintf(format, args...)})
+}
+
+func (r *ValidationResult) addWarning(field, format string, args ...any) {
+	r.Warnings = append(r.Warnings, Finding{field, fmt.Sprintf(format, args...)})
+}
+
+var (
+	// typeURIPattern matches the slash-separated lowercase paths of the CADF
+	// resource taxonomy, e.g. "compute/server" or "data/security/account/user".
+	typeURIPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]*(/[a-z0-9][a-z0-9_.-]*)*$`)
+
+	eventTypes = []string{"activity", "monitor", "control"}
+	outcomes   = []cadf.Outcome{cadf.SuccessOutcome, cadf.FailureOutcome, cadf.PendingOutcome, "unknown"}
+	// actionVerbs are the first segments of the CADF action taxonomy.
+	actionVerbs = []cadf.Action{
+		cadf.BackupAction, cadf.CaptureAction, cadf.CreateAction, cadf.ConfigureAction, cadf.ReadAction,
+		cadf.ListAction, cadf.UpdateAction, cadf.DeleteAction, cadf.MonitorAction, cadf.StartAction,
+		cadf.StopAction, cadf.DeployAction, cadf.UndeployAction, cadf.EnableAction, cadf.DisableAction,
+		cadf.SendAction, cadf.ReceiveAction, cadf.AuthenticateAction, cadf.RevokeAction, cadf.RenewAction,
+		cadf.RestoreAction, cadf.EvaluateAction, cadf.AllowAction, cadf.DenyAction, cadf.NotifyAction,
+		cadf.UnknownAction,
+	}
+)
+
+// ValidateEvent checks an event against the required fields of CADF, the
+// taxonomy conventions of Hermes and the rules by which Hermes derives the
+// tenants of an event. It does not need a storage backend, so emitters can
+// run it in their tests.
+func ValidateEvent(event *cadf.Event) ValidationResult {
+	r := ValidationResult{Errors: []Finding{}, Warnings: []Finding{}}
+
+	if event.TypeURI != CADFEventTypeURI {
+		r.addError("typeURI", "must be %q", CADFEventTypeURI)
+	}
+	switch {
+	case event.ID == "":
+		r.addError("id", "is required")
+	case uuid.Validate(event.ID) != nil:
+		r.addError("id", "must be a UUID, otherwise the event cannot be shown by GET /v1/events/:event_id")
+	}
+	if event.EventTime == "" {
+		r.addError("eventTime", "is required")
+	} else if _, err := storage.ParseEventTime(event.EventTime); err != nil {
+		r.addError("eventTime", "must be an ISO 8601 timestamp with time zone, e.g. \"2026-01-02T15:04:05.000000+00:00\"")
+	}
+	if !slices.Contains(eventTypes, event.EventType) {
+		r.addError("eventType", "must be one of %s", strings.Join(eventTypes, ", "))
+	}
+	verb, _, _ := strings.Cut(string(event.Action), "/")
+	switch {
+	case event.Action == "":
+		r.addError("action", "is required")
+	case !slices.Contains(actionVerbs, cadf.Action(verb)):
+		r.addWarning("action", "%q is not an action of the CADF taxonomy (e.g. create, read, update, delete)", verb)
+	}
+	if !slices.Contains(outcomes, event.Outcome) {
+		r.addError("outcome", "must be one of success, failure, pending, unknown")
+	}
+	if event.Reason.ReasonType == "HTTP" {
+		if code, err := strconv.Atoi(event.Reason.ReasonCode); err != nil || code < 100 || code > 599 {
+			r.addWarning("reason.reasonCode", "must be an HTTP status code when reasonType is HTTP")
+		}
+	}
+
+	validateResource(&r, "initiator", event.Initiator)
+	validateResource(&r, "target", event.Target)
+	validateResource(&r, "observer", event.Observer)
+	if event.Initiator.Name == "" {
+		r.addWarning("initiator.name", "is empty, so users are shown by ID")
+	}
+	if event.Observer.Name == "" {
+		r.addWarning("observer.name", "is empty, so the service is shown by typeURI")
+	}
+	if event.Observer.TypeURI != "" && !strings.HasPrefix(event.Observer.TypeURI, "service/") {
+		r.addWarning("observer.typeURI", "should start with \"service/\", e.g. \"service/compute\"")
+	}
+	if event.RequestPath == "" {
+		r.addWarning("requestPath", "is empty")
+	}
+	for i, a := range event.Attachments {
+		field := fmt.Sprintf("attachments[%d]", i)
+		if a.Name == "" {
+			r.addWarning(field+".name", "is empty, so the attachment cannot be told apart from others")
+		}
+		if a.TypeURI == "" {
+			r.addWarning(field+".typeURI", "is empty")
+		}
+		if a.Content == nil {
+			r.addWarning(field+".content", "is empty")
+		}
+	}
+
+	r.TenantIDs = TenantIDs(event)
+	if r.TenantIDs == nil {
+		r.TenantIDs = []string{}
+	}
+	if len(r.TenantIDs) == 0 {
+		r.addError("", "neither target nor initiator has a project_id or domain_id, so no project or domain can see the event")
+	} else if event.Target.ProjectID == "" && event.Target.DomainID == "" {
+		r.addWarning("target.project_id", "is empty, so the event is only visible to the initiator's project or domain, not to the owner of the target")
+	}
+	for _, field := range []struct{ name, value string }{
+		{"target.project_id", event.Target.ProjectID},
+		{"target.domain_id", event.Target.DomainID},
+		{"initiator.project_id", event.Initiator.ProjectID},
+		{"initiator.domain_id", event.Initiator.DomainID},
+	} {
+		if field.value != "" && field.value != "unavailable" && !isKeystoneID(field.value) {
+			r.addWarning(field.name, "%q does not look like a Keystone ID", field.value)
+		}
+	}
+
+	r.Valid = len(r.Errors) == 0
+	return r
+}
+
+func validateResource(r *ValidationResult, name string, resource cadf.Resource) {
+	switch {
+	case resource.TypeURI == "":
+		r.addError(name+".typeURI", "is required")
+	case !typeURIPattern.MatchString(resource.TypeURI):
+		r.addWarning(name+".typeURI", "should be a lowercase path of the CADF resource taxonomy, e.g. \"compute/server\"")
+	}
+	if resource.ID == "" {
+		r.addError(name+".id", "is required")
+	}
+}
+
+// isKeystoneID reports whether id looks like a Keystone project or domain ID:
+// a UUID with or without dashes, or the ID of the default domain.
+func isKeystoneID(id string) bool {
+	return id == "default" || uuid.Validate(id) == nil
+}
+
+// ValidateEventJSON is like ValidateEvent, but takes the JSON representation
+// of the event. Besides the checks of ValidateEvent, it reports malformed
+// JSON and fields that are not part of the event schema. Field names are
+// case-sensitive: Go would accept "eventtime" for "eventTime", but OpenSearch
+// would not.
+func ValidateEventJSON(data []byte) ValidationResult {
+	var event cadf.Event
+	if err := json.Unmarshal(data, &event); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		r := ValidationResult{TenantIDs: []string{}, Errors: []Finding{}, Warnings: []Finding{}}
+		if errors.As(err, &typeErr) {
+			r.addError(typeErr.Field, "has the wrong type: expected %s, got %s", typeErr.Type, typeErr.Value)
+		} else {
+			r.addError("", "is not a valid JSON object: %s", err.Error())
+		}
+		return r
+	}
+
+	r := ValidateEvent(&event)
+	var raw any
+	if json.Unmarshal(data, &raw) == nil {
+		validateFieldNames(&r, "", raw, reflect.TypeFor[cadf.Event]())
+	}
+	r.Valid = len(r.Errors) == 0
+	return r
+}
+
+// validateFieldNames warns about the keys of a decoded JSON object that do
+// not exactly match a JSON field name of the struct type t.
+func validateFieldNames(r *ValidationResult, prefix string, value any, t reflect.Type) {
+	object, ok := value.(map[string]any)
+	if !ok {
+		return
+	}
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		fields[name] = t.Field(i).Type
+	}
+
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		fieldType, ok := fields[key]
+		switch {
+		case !ok:
+			r.addWarning(prefix+key, "is not a field of the event schema and will not be shown")
+		case fieldType.Kind() == reflect.Struct:
+			validateFieldNames(r, prefix+key+".", object[key], fieldType)
+		case fieldType.Kind() == reflect.Slice && fieldType.Elem().Kind() == reflect.Struct:
+			list, _ := object[key].([]any)
+			for idx, item := range list {
+				validateFieldNames(r, fmt.Sprintf("%s%s[%d].", prefix, key, idx), item, fieldType.Elem())
+			}
+		}
+	}
+}
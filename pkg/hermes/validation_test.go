@@ -0,0 +1,99 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package hermes
+
+import (
+	"testing"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/stretchr/testify/assert"
+)
+
+func validTestEvent() cadf.Event {
+	return cadf.Event{
+		TypeURI:     CADFEventTypeURI,
+		ID:          "7189ce80-6e73-5ad9-bdc5-dcc47f176378",
+		EventTime:   "2026-01-02T15:04:05.000000+00:00",
+		EventType:   "activity",
+		Action:      cadf.CreateAction,
+		Outcome:     cadf.SuccessOutcome,
+		Reason:      cadf.Reason{ReasonType: "HTTP", ReasonCode: "201"},
+		RequestPath: "/v2/servers",
+		Initiator: cadf.Resource{TypeURI: "service/security/account/user", ID: "u1", Name: "alice",
+			ProjectID: "a759dcc2a2384a76b0386bb985952373"},
+		Target: cadf.Resource{TypeURI: "compute/server", ID: "server-1",
+			ProjectID: "b759dcc2a2384a76b0386bb985952373"},
+		Observer: cadf.Resource{TypeURI: "service/compute", ID: "nova", Name: "nova"},
+	}
+}
+
+func Test_ValidateEvent(t *testing.T) {
+	event := validTestEvent()
+	result := ValidateEvent(&event)
+	assert.Equal(t, ValidationResult{
+		Valid:     true,
+		TenantIDs: []string{"b759dcc2a2384a76b0386bb985952373", "a759dcc2a2384a76b0386bb985952373"},
+		Errors:    []Finding{},
+		Warnings:  []Finding{},
+	}, result)
+
+	tt := []struct {
+		modify  func(*cadf.Event)
+		finding Finding
+		isError bool
+	}{
+		{func(e *cadf.Event) { e.TypeURI = "" }, Finding{"typeURI", `must be "` + CADFEventTypeURI + `"`}, true},
+		{func(e *cadf.Event) { e.ID = "" }, Finding{"id", "is required"}, true},
+		{func(e *cadf.Event) { e.ID = "event-1" }, Finding{"id", "must be a UUID, otherwise the event cannot be shown by GET /v1/events/:event_id"}, true},
+		{func(e *cadf.Event) { e.EventTime = "yesterday" }, Finding{"eventTime", `must be an ISO 8601 timestamp with time zone, e.g. "2026-01-02T15:04:05.000000+00:00"`}, true},
+		{func(e *cadf.Event) { e.EventType = "" }, Finding{"eventType", "must be one of activity, monitor, control"}, true},
+		{func(e *cadf.Event) { e.Action = "" }, Finding{"action", "is required"}, true},
+		{func(e *cadf.Event) { e.Outcome = "ok" }, Finding{"outcome", "must be one of success, failure, pending, unknown"}, true},
+		{func(e *cadf.Event) { e.Observer = cadf.Resource{} }, Finding{"observer.typeURI", "is required"}, true},
+		{func(e *cadf.Event) { e.Target.ID = "" }, Finding{"target.id", "is required"}, true},
+		{func(e *cadf.Event) { e.Initiator.ProjectID, e.Target.ProjectID = "", "" },
+			Finding{"", "neither target nor initiator has a project_id or domain_id, so no project or domain can see the event"}, true},
+		{func(e *cadf.Event) { e.Action = "rotate" }, Finding{"action", `"rotate" is not an action of the CADF taxonomy (e.g. create, read, update, delete)`}, false},
+		{func(e *cadf.Event) { e.Reason.ReasonCode = "OK" }, Finding{"reason.reasonCode", "must be an HTTP status code when reasonType is HTTP"}, false},
+		{func(e *cadf.Event) { e.Target.TypeURI = "Compute/Server" }, Finding{"target.typeURI", `should be a lowercase path of the CADF resource taxonomy, e.g. "compute/server"`}, false},
+		{func(e *cadf.Event) { e.Observer.TypeURI = "compute" }, Finding{"observer.typeURI", `should start with "service/", e.g. "service/compute"`}, false},
+		{func(e *cadf.Event) { e.Initiator.Name = "" }, Finding{"initiator.name", "is empty, so users are shown by ID"}, false},
+		{func(e *cadf.Event) { e.RequestPath = "" }, Finding{"requestPath", "is empty"}, false},
+		{func(e *cadf.Event) { e.Attachments = []cadf.Attachment{{TypeURI: "mime:application/json"}} },
+			Finding{"attachments[0].name", "is empty, so the attachment cannot be told apart from others"}, false},
+		{func(e *cadf.Event) { e.Target.ProjectID = "" },
+			Finding{"target.project_id", "is empty, so the event is only visible to the initiator's project or domain, not to the owner of the target"}, false},
+		{func(e *cadf.Event) { e.Target.ProjectID = "my-project" }, Finding{"target.project_id", `"my-project" does not look like a Keystone ID`}, false},
+	}
+	for _, tc := range tt {
+		event := validTestEvent()
+		tc.modify(&event)
+		result := ValidateEvent(&event)
+		if tc.isError {
+			assert.False(t, result.Valid, tc.finding.Field)
+			assert.Contains(t, result.Errors, tc.finding)
+		} else {
+			assert.True(t, result.Valid, tc.finding.Field)
+			assert.Contains(t, result.Warnings, tc.finding)
+		}
+	}
+}
+
+func Test_ValidateEventJSON(t *testing.T) {
+	result := ValidateEventJSON([]byte(`{"id": 42}`))
+	assert.False(t, result.Valid)
+	assert.Equal(t, []Finding{{"id", "has the wrong type: expected string, got number"}}, result.Errors)
+
+	result = ValidateEventJSON([]byte(`{"id": `))
+	assert.False(t, result.Valid)
+	assert.Len(t, result.Errors, 1)
+
+	// field names are case-sensitive for OpenSearch, but not for encoding/json
+	result = ValidateEventJSON([]byte(`{"eventtime": "2026-01-02T15:04:05Z", "target": {"projectID": "p1"}, "attachments": [{"contentType": "json"}]}`))
+	assert.Equal(t, []Finding{
+		{"attachments[0].contentType", "is not a field of the event schema and will not be shown"},
+		{"eventtime", "is not a field of the event schema and will not be shown"},
+		{"target.projectID", "is not a field of the event schema and will not be shown"},
+	}, result.Warnings[len(result.Warnings)-3:])
+}
@@ -0,0 +1,214 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package identity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gophercloud/gophercloud/v2"
+	"github.com/gophercloud/gophercloud/v2/openstack/identity/v3/domains"
+	"github.com/gophercloud/gophercloud/v2/openstack/identity/v3/projects"
+	"github.com/gophercloud/gophercloud/v2/openstack/identity/v3/users"
+	"github.com/sapcc/go-bits/logg"
+	"github.com/spf13/viper"
+
+	"github.com/sapcc/hermes/pkg/hermes"
+)
+
+// lookupConcurrency is the number of concurrent Keystone requests per ResolveNames call.
+const lookupConcurrency = 8
+
+// NameResolver implements hermes.NameResolver by looking up names in Keystone.
+// Names are cached for TTL, including the absence of deleted entities.
+type NameResolver struct {
+	client *gophercloud.ServiceClient
+	// TTL is how long a looked up name is cached.
+	TTL time.Duration
+	// MaxEntries bounds the number of cached names.
+	MaxEntries int
+	// MaxLookups bounds the number of users or projects that one ResolveNames
+	// call looks up in Keystone, since Keystone cannot list them by ID. The
+	// names of further IDs stay empty until a later call looks them up.
+	MaxLookups int
+	// Now returns the current time. Tests replace it with a mock clock.
+	Now func() time.Time
+
+	mu    sync.Mutex
+	cache map[nameKey]cachedName
+}
+
+type nameKey struct {
+	kind hermes.NameKind
+	id   string
+}
+
+type cachedName struct {
+	name    string
+	expires time.Time
+}
+
+// NewNameResolver builds a NameResolver that uses the given Keystone client,
+// e.g. the IdentityV3 client of the TokenValidator. The cache is configured by
+// keystone.name_cache_time (in seconds) and keystone.name_cache_size, the
+// lookups per call by keystone.name_max_lookups.
+func NewNameResolver(client *gophercloud.ServiceClient) *NameResolver {
+	return &NameResolver{
+		client:     client,
+		TTL:        time.Duration(viper.GetInt("keystone.name_cache_time")) * time.Second,
+		MaxEntries: viper.GetInt("keystone.name_cache_size"),
+		MaxLookups: viper.GetInt("keystone.name_max_lookups"),
+		Now:        time.Now,
+		cache:      make(map[nameKey]cachedName),
+	}
+}
+
+// ResolveNames implements the hermes.NameResolver interface. Uncached domains
+// are looked up with a single request that lists all domains. Uncached users
+// and projects are looked up concurrently, one Keystone request per ID, for
+// at most MaxLookups IDs.
+func (r *NameResolver) ResolveNames(ctx context.Context, kind hermes.NameKind, ids []string) (map[string]string, error) {
+	result := make(map[string]string, len(ids))
+	var missing []string
+	r.mu.Lock()
+	now := r.Now()
+	for _, id := range ids {
+		entry, ok := r.cache[nameKey{kind, id}]
+		switch {
+		case !ok || now.After(entry.expires):
+			if !slices.Contains(missing, id) {
+				missing = append(missing, id)
+			}
+		case entry.name != "":
+			result[id] = entry.name
+		}
+	}
+	r.mu.Unlock()
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	if kind == hermes.DomainNames {
+		return result, r.lookupDomains(ctx, missing, result)
+	}
+	if len(missing) > r.MaxLookups {
+		logg.Debug("identity: looking up only %d of %d %s names", r.MaxLookups, len(missing), kind)
+		missing = missing[:r.MaxLookups]
+	}
+
+	var (
+		wg        sync.WaitGroup
+		resultMu  sync.Mutex
+		errs      []error
+		semaphore = make(chan struct{}, lookupConcurrency)
+	)
+	for _, id := range missing {
+		wg.Go(func() {
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			name, err := r.lookup(ctx, kind, id)
+			resultMu.Lock()
+			defer resultMu.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+			r.store(nameKey{kind, id}, name)
+			if name != "" {
+				result[id] = name
+			}
+		})
+	}
+	wg.Wait()
+	return result, errors.Join(errs...)
+}
+
+// lookup returns the name of one entity, or "" if it does not exist.
+func (r *NameResolver) lookup(ctx context.Context, kind hermes.NameKind, id string) (string, error) {
+	var (
+		name string
+		err  error
+	)
+	switch kind {
+	case hermes.UserNames:
+		var user *users.User
+		user, err = users.Get(ctx, r.client, id).Extract()
+		if err == nil {
+			name = user.Name
+		}
+	case hermes.ProjectNames:
+		var project *projects.Project
+		project, err = projects.Get(ctx, r.client, id).Extract()
+		if err == nil {
+			name = project.Name
+		}
+	default:
+		return "", fmt.Errorf("unknown name kind %q", kind)
+	}
+	if gophercloud.ResponseCodeIs(err, http.StatusNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("cannot get %s %s from Keystone: %w", kind, id, err)
+	}
+	return name, nil
+}
+
+// lookupDomains looks up the names of the given domains by listing all
+// domains, and adds them to result. Since there are few domains, all of them
+// are cached.
+func (r *NameResolver) lookupDomains(ctx context.Context, ids []string, result map[string]string) error {
+	page, err := domains.List(r.client, domains.ListOpts{}).AllPages(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot list domains in Keystone: %w", err)
+	}
+	all, err := domains.ExtractDomains(page)
+	if err != nil {
+		return fmt.Errorf("cannot list domains in Keystone: %w", err)
+	}
+	for _, domain := range all {
+		r.store(nameKey{hermes.DomainNames, domain.ID}, domain.Name)
+		if slices.Contains(ids, domain.ID) {
+			result[domain.ID] = domain.Name
+		}
+	}
+	// remember the absence of deleted domains
+	for _, id := range ids {
+		if _, ok := result[id]; !ok {
+			r.store(nameKey{hermes.DomainNames, id}, "")
+		}
+	}
+	return nil
+}
+
+// store caches a name. When the cache is full, expired entries are removed
+// first, then arbitrary ones.
+func (r *NameResolver) store(key nameKey, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.Now()
+	if len(r.cache) >= r.MaxEntries {
+		for k, entry := range r.cache {
+			if now.After(entry.expires) {
+				delete(r.cache, k)
+			}
+		}
+	}
+	for k := range r.cache {
+		if len(r.cache) < r.MaxEntries {
+			break
+		}
+		delete(r.cache, k)
+	}
+	if r.MaxEntries > 0 {
+		r.cache[key] = cachedName{name, now.Add(r.TTL)}
+	}
+}
+
+var _ hermes.NameResolver = (*NameResolver)(nil)
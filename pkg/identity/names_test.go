@@ -0,0 +1,112 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package identity
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gophercloud/gophercloud/v2"
+	"github.com/sapcc/go-bits/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapcc/hermes/pkg/hermes"
+)
+
+func TestNameResolver(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests = make(map[string]int)
+	)
+	keystone := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/users/u1":
+			w.Write([]byte(`{"user":{"id":"u1","name":"alice"}}`)) //nolint:errcheck
+		case "/projects/p1":
+			w.Write([]byte(`{"project":{"id":"p1","name":"project one"}}`)) //nolint:errcheck
+		case "/domains":
+			w.Write([]byte(`{"domains":[{"id":"d1","name":"domain one"},{"id":"d2","name":"domain two"}],"links":{}}`)) //nolint:errcheck
+		case "/users/broken":
+			http.Error(w, "oops", http.StatusInternalServerError)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(keystone.Close)
+
+	clock := mock.NewClock()
+	resolver := NewNameResolver(&gophercloud.ServiceClient{
+		ProviderClient: &gophercloud.ProviderClient{},
+		Endpoint:       keystone.URL + "/",
+	})
+	resolver.TTL = time.Hour
+	resolver.MaxEntries = 100
+	resolver.MaxLookups = 10
+	resolver.Now = clock.Now
+
+	names, err := resolver.ResolveNames(t.Context(), hermes.UserNames, []string{"u1", "deleted"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"u1": "alice"}, names)
+	names, err = resolver.ResolveNames(t.Context(), hermes.ProjectNames, []string{"p1"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"p1": "project one"}, names)
+	names, err = resolver.ResolveNames(t.Context(), hermes.DomainNames, []string{"d1", "deleted"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"d1": "domain one"}, names)
+
+	// all domains are cached from one list request
+	names, err = resolver.ResolveNames(t.Context(), hermes.DomainNames, []string{"d2", "deleted"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"d2": "domain two"}, names)
+
+	// errors are returned together with the names that could be resolved, and are not cached
+	names, err = resolver.ResolveNames(t.Context(), hermes.UserNames, []string{"u1", "broken"})
+	assert.ErrorContains(t, err, "cannot get user broken from Keystone")
+	assert.Equal(t, map[string]string{"u1": "alice"}, names)
+	_, err = resolver.ResolveNames(t.Context(), hermes.UserNames, []string{"broken"})
+	assert.Error(t, err)
+
+	// names and missing entities are cached until the TTL expires
+	mu.Lock()
+	assert.Equal(t, map[string]int{"/users/u1": 1, "/users/deleted": 1, "/projects/p1": 1, "/domains": 1, "/users/broken": 2}, requests)
+	mu.Unlock()
+	clock.StepBy(2 * time.Hour)
+	_, err = resolver.ResolveNames(t.Context(), hermes.UserNames, []string{"u1", "deleted"})
+	require.NoError(t, err)
+	mu.Lock()
+	assert.Equal(t, 2, requests["/users/u1"])
+	assert.Equal(t, 2, requests["/users/deleted"])
+	mu.Unlock()
+
+	// duplicate IDs are looked up once, and at most MaxLookups IDs per call
+	resolver.MaxLookups = 2
+	names, err = resolver.ResolveNames(t.Context(), hermes.ProjectNames, []string{"x1", "x1", "x2", "p1"})
+	require.NoError(t, err)
+	assert.Empty(t, names)
+	mu.Lock()
+	assert.Equal(t, 1, requests["/projects/x1"])
+	assert.Equal(t, 1, requests["/projects/x2"])
+	assert.Equal(t, 1, requests["/projects/p1"])
+	mu.Unlock()
+	names, err = resolver.ResolveNames(t.Context(), hermes.ProjectNames, []string{"x1", "x2", "p1"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"p1": "project one"}, names)
+	mu.Lock()
+	assert.Equal(t, 2, requests["/projects/p1"])
+	mu.Unlock()
+
+	// the cache is bounded
+	resolver.MaxEntries = 2
+	_, err = resolver.ResolveNames(t.Context(), hermes.ProjectNames, []string{"p1", "p2", "p3"})
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(resolver.cache), 2)
+}
@@ -0,0 +1,44 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package identity
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gophercloud/gophercloud/v2"
+	"github.com/gophercloud/gophercloud/v2/openstack/identity/v3/projects"
+
+	"github.com/sapcc/hermes/pkg/routing"
+)
+
+// ProjectLister implements routing.ProjectLister by listing projects in Keystone.
+type ProjectLister struct {
+	client *gophercloud.ServiceClient
+}
+
+// NewProjectLister builds a ProjectLister that uses the given Keystone
+// client, e.g. the IdentityV3 client of the TokenValidator.
+func NewProjectLister(client *gophercloud.ServiceClient) *ProjectLister {
+	return &ProjectLister{client: client}
+}
+
+// ListProjects implements the routing.ProjectLister interface.
+func (l *ProjectLister) ListProjects(ctx context.Context, domainID string) ([]string, error) {
+	pages, err := projects.List(l.client, projects.ListOpts{DomainID: domainID}).AllPages(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list projects of domain %s in Keystone: %w", domainID, err)
+	}
+	list, err := projects.ExtractProjects(pages)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list projects of domain %s in Keystone: %w", domainID, err)
+	}
+	ids := make([]string, 0, len(list))
+	for _, project := range list {
+		ids = append(ids, project.ID)
+	}
+	return ids, nil
+}
+
+var _ routing.ProjectLister = (*ProjectLister)(nil)
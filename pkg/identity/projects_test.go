@@ -0,0 +1,41 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package identity
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectLister(t *testing.T) {
+	var keystone *httptest.Server
+	keystone = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path != "/projects" || r.URL.Query().Get("domain_id") != "d1":
+			http.Error(w, "not found", http.StatusNotFound)
+		case r.URL.Query().Get("marker") == "":
+			w.Write([]byte(`{"projects":[{"id":"p1"},{"id":"p2"}],"links":{"next":"` + keystone.URL + `/projects?domain_id=d1&marker=p2"}}`)) //nolint:errcheck
+		default:
+			w.Write([]byte(`{"projects":[{"id":"p3"}],"links":{"next":null}}`)) //nolint:errcheck
+		}
+	}))
+	t.Cleanup(keystone.Close)
+
+	lister := NewProjectLister(&gophercloud.ServiceClient{
+		ProviderClient: &gophercloud.ProviderClient{},
+		Endpoint:       keystone.URL + "/",
+	})
+	ids, err := lister.ListProjects(t.Context(), "d1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"p1", "p2", "p3"}, ids)
+
+	_, err = lister.ListProjects(t.Context(), "unknown")
+	assert.Error(t, err)
+}
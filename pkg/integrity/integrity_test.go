@@ -0,0 +1,172 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package integrity
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/sapcc/go-bits/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapcc/hermes/pkg/storage"
+)
+
+const (
+	projectA = "project-a"
+	projectB = "project-b"
+)
+
+func testEvent(id, projectID string, eventTime time.Time) cadf.Event {
+	return cadf.Event{
+		ID:        id,
+		EventTime: eventTime.Format("2006-01-02T15:04:05.000000+00:00"),
+		Action:    cadf.UpdateAction,
+		Outcome:   cadf.SuccessOutcome,
+		Initiator: cadf.Resource{TypeURI: "service/security/account/user", ID: "user-1"},
+		Target:    cadf.Resource{TypeURI: "compute/server", ID: "server-" + id, ProjectID: projectID},
+		Observer:  cadf.Resource{TypeURI: "service/compute", ID: "nova"},
+	}
+}
+
+// setupSealer returns a sealer whose chains start at the mock clock's epoch.
+func setupSealer(t *testing.T) (*Sealer, *Mock, *storage.Memory, *mock.Clock) {
+	t.Helper()
+	clock := mock.NewClock()
+	store := NewMock()
+	events := storage.NewMemory(100)
+	sealer := NewSealer(store, events)
+	sealer.Now = clock.Now
+	sealer.SettleDelay = time.Minute
+	sealer.MaxWindow = 10 * time.Minute
+
+	// first run only initializes the watermark
+	clock.StepBy(time.Minute)
+	require.NoError(t, sealer.SealPending(t.Context()))
+	watermark, err := store.Watermark(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, time.Unix(0, 0).UTC(), watermark)
+	return sealer, store, events, clock
+}
+
+func TestSealAndVerify(t *testing.T) {
+	ctx := context.Background()
+	sealer, store, events, clock := setupSealer(t)
+
+	base := clock.Now()
+	events.Add([]string{projectA}, testEvent("e1", projectA, base.Add(10*time.Second)))
+	events.Add([]string{projectB}, testEvent("e2", projectB, base.Add(20*time.Second)))
+	events.Add([]string{projectA}, testEvent("e3", projectA, base.Add(30*time.Second)))
+
+	// events are not sealed until the settle delay has passed
+	require.NoError(t, sealer.SealPending(ctx))
+	links, err := store.Links(ctx, projectA, time.Time{}, base.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, links)
+
+	clock.StepBy(2 * time.Minute)
+	require.NoError(t, sealer.SealPending(ctx))
+	links, err = store.Links(ctx, projectA, time.Time{}, base.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, links, 2)
+	assert.Equal(t, "e1", links[0].EventID)
+	assert.Equal(t, int64(1), links[0].Sequence)
+	assert.Equal(t, "e3", links[1].EventID)
+	assert.Equal(t, int64(2), links[1].Sequence)
+
+	report, err := Verify(ctx, store, events, projectA, base, clock.Now())
+	require.NoError(t, err)
+	assert.True(t, report.Intact)
+	assert.Equal(t, 2, report.Verified)
+	assert.Empty(t, report.Unsealed)
+
+	// tampering with an event is reported as modification
+	tampered := testEvent("e3", projectA, base.Add(30*time.Second))
+	tampered.Outcome = cadf.FailureOutcome
+	require.True(t, events.Replace(tampered))
+	// removing an event is reported as gap
+	require.True(t, events.Remove("e1"))
+	// an event that arrives after its window was sealed is reported as unsealed
+	events.Add([]string{projectA}, testEvent("e4", projectA, base.Add(40*time.Second)))
+
+	report, err = Verify(ctx, store, events, projectA, base, clock.Now())
+	require.NoError(t, err)
+	assert.False(t, report.Intact)
+	assert.Equal(t, 0, report.Verified)
+	require.Len(t, report.Modified, 1)
+	assert.Equal(t, "e3", report.Modified[0].EventID)
+	assert.NotEqual(t, report.Modified[0].Expected, report.Modified[0].Actual)
+	require.Len(t, report.Missing, 1)
+	assert.Equal(t, "e1", report.Missing[0].EventID)
+	require.Len(t, report.Unsealed, 1)
+	assert.Equal(t, "e4", report.Unsealed[0].EventID)
+
+	// the other tenant's chain is unaffected
+	report, err = Verify(ctx, store, events, projectB, base, clock.Now())
+	require.NoError(t, err)
+	assert.True(t, report.Intact)
+	assert.Equal(t, 1, report.Verified)
+}
+
+func TestVerifyDetectsChainTampering(t *testing.T) {
+	ctx := context.Background()
+	sealer, store, events, clock := setupSealer(t)
+
+	base := clock.Now()
+	for i, id := range []string{"e1", "e2", "e3"} {
+		events.Add([]string{projectA}, testEvent(id, projectA, base.Add(time.Duration(i+1)*time.Second)))
+	}
+	clock.StepBy(2 * time.Minute)
+	require.NoError(t, sealer.SealPending(ctx))
+
+	// rewrite the middle link as if its event had different content; a careful forger
+	// also recomputes the link's own chain hash, but cannot fix all later links
+	links, err := store.Links(ctx, projectA, time.Time{}, clock.Now())
+	require.NoError(t, err)
+	require.Len(t, links, 3)
+	forged := NextLink(&links[0], Entry{
+		TenantID:  projectA,
+		EventID:   links[1].EventID,
+		EventTime: links[1].EventTime,
+		EventHash: "0000000000000000000000000000000000000000000000000000000000000000",
+	}, links[1].SealedAt)
+	store.Tamper(forged)
+
+	// verifying only the last event checks its link against the forged predecessor
+	report, err := Verify(ctx, store, events, projectA, links[2].EventTime, clock.Now())
+	require.NoError(t, err)
+	assert.False(t, report.Intact)
+	require.Len(t, report.ChainBreaks, 1)
+	assert.Equal(t, int64(3), report.ChainBreaks[0].Sequence)
+
+	// the full range additionally shows the forged link as modified event
+	report, err = Verify(ctx, store, events, projectA, base, clock.Now())
+	require.NoError(t, err)
+	assert.False(t, report.Intact)
+	require.Len(t, report.ChainBreaks, 1)
+	require.Len(t, report.Modified, 1)
+	assert.Equal(t, "e2", report.Modified[0].EventID)
+}
+
+func TestSealerShrinksLargeWindows(t *testing.T) {
+	ctx := context.Background()
+	sealer, store, _, clock := setupSealer(t)
+	events := storage.NewMemory(4)
+	sealer.Storage = events
+
+	base := clock.Now()
+	for i := range 10 {
+		id := string(rune('a' + i))
+		events.Add([]string{projectA}, testEvent(id, projectA, base.Add(time.Duration(i)*30*time.Second)))
+	}
+	clock.StepBy(10 * time.Minute)
+	require.NoError(t, sealer.SealPending(ctx))
+
+	links, err := store.Links(ctx, projectA, time.Time{}, clock.Now())
+	require.NoError(t, err)
+	assert.Len(t, links, 10)
+}
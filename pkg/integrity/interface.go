@@ -0,0 +1,36 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package integrity
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrWatermarkMoved is returned by Store.Seal when another process has sealed
+// the window in the meantime. The caller should re-read the watermark and retry.
+var ErrWatermarkMoved = errors.New("integrity: watermark was moved concurrently")
+
+// Store is the persistence interface for the hash chains.
+// The Postgres implementation is the production backend;
+// the Mock implementation is used in unit tests.
+type Store interface {
+	// Watermark returns the end of the last sealed window,
+	// or the zero time if nothing has been sealed yet.
+	Watermark(ctx context.Context) (time.Time, error)
+
+	// Seal appends the entries (in order) to their tenants' chains and moves
+	// the watermark from `from` to `until`, atomically. Entries whose event is
+	// already part of the tenant's chain are skipped.
+	// Returns ErrWatermarkMoved if the current watermark is not `from`.
+	Seal(ctx context.Context, from, until time.Time, entries []Entry) error
+
+	// Links returns the links of a tenant's chain whose EventTime is in [from, to),
+	// ordered by sequence.
+	Links(ctx context.Context, tenantID string, from, to time.Time) ([]Link, error)
+
+	// Link returns the link with the given sequence number, or nil if there is none.
+	Link(ctx context.Context, tenantID string, sequence int64) (*Link, error)
+}
@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package integrity
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Mock implements Store with in-memory storage for use in unit tests.
+type Mock struct {
+	mu        sync.RWMutex
+	watermark time.Time
+	chains    map[string][]Link
+}
+
+// NewMock creates an empty Mock store.
+func NewMock() *Mock {
+	return &Mock{chains: make(map[string][]Link)}
+}
+
+// Watermark returns the end of the last sealed window.
+func (m *Mock) Watermark(_ context.Context) (time.Time, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.watermark, nil
+}
+
+// Seal appends the entries to their tenants' chains and moves the watermark.
+func (m *Mock) Seal(_ context.Context, from, until time.Time, entries []Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.watermark.Equal(from) {
+		return ErrWatermarkMoved
+	}
+	now := time.Now().UTC()
+	for _, e := range entries {
+		chain := m.chains[e.TenantID]
+		if containsEvent(chain, e.EventID) {
+			continue
+		}
+		var prev *Link
+		if len(chain) > 0 {
+			prev = &chain[len(chain)-1]
+		}
+		m.chains[e.TenantID] = append(chain, NextLink(prev, e, now))
+	}
+	m.watermark = until
+	return nil
+}
+
+// Links returns the links of a tenant's chain whose EventTime is in [from, to).
+func (m *Mock) Links(_ context.Context, tenantID string, from, to time.Time) ([]Link, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []Link
+	for _, link := range m.chains[tenantID] {
+		if !link.EventTime.Before(from) && link.EventTime.Before(to) {
+			result = append(result, link)
+		}
+	}
+	return result, nil
+}
+
+// Link returns the link with the given sequence number, or nil if there is none.
+func (m *Mock) Link(_ context.Context, tenantID string, sequence int64) (*Link, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, link := range m.chains[tenantID] {
+		if link.Sequence == sequence {
+			l := link
+			return &l, nil
+		}
+	}
+	return nil, nil
+}
+
+// Tamper replaces a stored link. It exists so that tests can simulate
+// modifications of the chain itself.
+func (m *Mock) Tamper(link Link) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	chain := m.chains[link.TenantID]
+	for idx := range chain {
+		if chain[idx].Sequence == link.Sequence {
+			chain[idx] = link
+		}
+	}
+}
+
+func containsEvent(chain []Link, eventID string) bool {
+	for _, link := range chain {
+		if link.EventID == eventID {
+			return true
+		}
+	}
+	return false
+}
+
+// Ensure Mock implements Store.
+var _ Store = (*Mock)(nil)
@@ -0,0 +1,197 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package integrity
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.xyrillian.de/gg/gsql"
+)
+
+// DBMigrations contains the SQL migrations for the tables of this package in
+// the hermez database (see package database).
+var DBMigrations = map[int64]string{
+	2: `
+		-- Per-tenant hash chains over stored audit events.
+		CREATE TABLE IF NOT EXISTS integrity_links (
+			tenant_id  VARCHAR(64) NOT NULL,
+			sequence   BIGINT      NOT NULL,
+			event_id   VARCHAR(64) NOT NULL,
+			event_time TIMESTAMPTZ NOT NULL,
+			event_hash CHAR(64)    NOT NULL,
+			chain_hash CHAR(64)    NOT NULL,
+			sealed_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (tenant_id, sequence),
+			UNIQUE (tenant_id, event_id)
+		);
+		CREATE INDEX IF NOT EXISTS integrity_links_tenant_time ON integrity_links (tenant_id, event_time);
+
+		-- Single-row table holding the end of the last sealed time window.
+		CREATE TABLE IF NOT EXISTS integrity_state (
+			id           INTEGER     PRIMARY KEY CHECK (id = 1),
+			sealed_until TIMESTAMPTZ NOT NULL
+		);
+	`,
+}
+
+// Postgres implements Store using the hermez PostgreSQL database.
+type Postgres struct {
+	db *gsql.DB
+}
+
+// NewPostgres wraps an already connected and migrated database.
+func NewPostgres(db *gsql.DB) *Postgres {
+	return &Postgres{db: db}
+}
+
+// Watermark returns the end of the last sealed window.
+func (p *Postgres) Watermark(ctx context.Context) (time.Time, error) {
+	var until time.Time
+	err := p.db.QueryRowContext(ctx, `SELECT sealed_until FROM integrity_state WHERE id = 1`).Scan(&until)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("integrity: cannot get watermark: %w", err)
+	}
+	return until.UTC(), nil
+}
+
+// Seal appends the entries to their tenants' chains and moves the watermark, in one transaction.
+func (p *Postgres) Seal(ctx context.Context, from, until time.Time, entries []Entry) error {
+	return p.db.WithinTransaction(ctx, func(tx *gsql.Tx) error {
+		// Lock the state row so that concurrent sealers (one per hermez replica) serialize here.
+		var current time.Time
+		err := tx.QueryRowContext(ctx, `SELECT sealed_until FROM integrity_state WHERE id = 1 FOR UPDATE`).Scan(&current)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			if !from.IsZero() {
+				return ErrWatermarkMoved
+			}
+		case err != nil:
+			return fmt.Errorf("integrity: cannot lock watermark: %w", err)
+		case !current.Equal(from):
+			return ErrWatermarkMoved
+		}
+
+		now := time.Now().UTC()
+		heads := make(map[string]*Link)
+		for _, e := range entries {
+			prev, ok := heads[e.TenantID]
+			if !ok {
+				prev, err = queryHead(ctx, tx, e.TenantID)
+				if err != nil {
+					return err
+				}
+			}
+			var exists bool
+			err = tx.QueryRowContext(ctx,
+				`SELECT EXISTS(SELECT 1 FROM integrity_links WHERE tenant_id = $1 AND event_id = $2)`,
+				e.TenantID, e.EventID,
+			).Scan(&exists)
+			if err != nil {
+				return fmt.Errorf("integrity: cannot check link for event %s: %w", e.EventID, err)
+			}
+			if exists {
+				heads[e.TenantID] = prev
+				continue
+			}
+
+			link := NextLink(prev, e, now)
+			_, err = tx.ExecContext(ctx,
+				`INSERT INTO integrity_links (tenant_id, sequence, event_id, event_time, event_hash, chain_hash, sealed_at)
+				 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+				link.TenantID, link.Sequence, link.EventID, link.EventTime, link.EventHash, link.ChainHash, link.SealedAt,
+			)
+			if err != nil {
+				return fmt.Errorf("integrity: cannot append event %s to chain of tenant %s: %w", e.EventID, e.TenantID, err)
+			}
+			heads[e.TenantID] = &link
+		}
+
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO integrity_state (id, sealed_until) VALUES (1, $1)
+			 ON CONFLICT (id) DO UPDATE SET sealed_until = EXCLUDED.sealed_until`,
+			until,
+		)
+		if err != nil {
+			return fmt.Errorf("integrity: cannot move watermark: %w", err)
+		}
+		return nil
+	})
+}
+
+func queryHead(ctx context.Context, tx *gsql.Tx, tenantID string) (*Link, error) {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT tenant_id, sequence, event_id, event_time, event_hash, chain_hash, sealed_at
+		   FROM integrity_links WHERE tenant_id = $1 ORDER BY sequence DESC LIMIT 1`,
+		tenantID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("integrity: cannot get chain head of tenant %s: %w", tenantID, err)
+	}
+	links, err := scanLinks(rows)
+	if err != nil || len(links) == 0 {
+		return nil, err
+	}
+	return &links[0], nil
+}
+
+// Links returns the links of a tenant's chain whose EventTime is in [from, to).
+func (p *Postgres) Links(ctx context.Context, tenantID string, from, to time.Time) ([]Link, error) {
+	rows, err := p.db.QueryContext(ctx,
+		`SELECT tenant_id, sequence, event_id, event_time, event_hash, chain_hash, sealed_at
+		   FROM integrity_links
+		  WHERE tenant_id = $1 AND event_time >= $2 AND event_time < $3
+		  ORDER BY sequence`,
+		tenantID, from, to,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("integrity: cannot list links of tenant %s: %w", tenantID, err)
+	}
+	return scanLinks(rows)
+}
+
+// Link returns the link with the given sequence number, or nil if there is none.
+func (p *Postgres) Link(ctx context.Context, tenantID string, sequence int64) (*Link, error) {
+	rows, err := p.db.QueryContext(ctx,
+		`SELECT tenant_id, sequence, event_id, event_time, event_hash, chain_hash, sealed_at
+		   FROM integrity_links WHERE tenant_id = $1 AND sequence = $2`,
+		tenantID, sequence,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("integrity: cannot get link %d of tenant %s: %w", sequence, tenantID, err)
+	}
+	links, err := scanLinks(rows)
+	if err != nil || len(links) == 0 {
+		return nil, err
+	}
+	return &links[0], nil
+}
+
+func scanLinks(rows *sql.Rows) ([]Link, error) {
+	defer rows.Close()
+	var links []Link
+	for rows.Next() {
+		var l Link
+		err := rows.Scan(&l.TenantID, &l.Sequence, &l.EventID, &l.EventTime, &l.EventHash, &l.ChainHash, &l.SealedAt)
+		if err != nil {
+			return nil, fmt.Errorf("integrity: cannot scan link: %w", err)
+		}
+		l.EventTime = l.EventTime.UTC()
+		l.SealedAt = l.SealedAt.UTC()
+		links = append(links, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("integrity: cannot iterate links: %w", err)
+	}
+	return links, nil
+}
+
+// Ensure Postgres implements Store.
+var _ Store = (*Postgres)(nil)
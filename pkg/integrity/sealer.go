@@ -0,0 +1,195 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package integrity
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/sapcc/go-bits/logg"
+
+	"github.com/sapcc/hermes/pkg/hermes"
+	"github.com/sapcc/hermes/pkg/storage"
+)
+
+// minWindow is the smallest window that the Sealer will shrink to when a
+// window contains more events than storage can return in one query.
+const minWindow = time.Second
+
+// Sealer periodically appends newly stored events to the tenants' hash chains.
+type Sealer struct {
+	Store   Store
+	Storage storage.Storage
+	// Interval is the time between two sealing runs.
+	Interval time.Duration
+	// SettleDelay is how long events are given to arrive in storage before
+	// their time window is sealed. Events stored later are reported as unsealed.
+	SettleDelay time.Duration
+	// MaxWindow caps the time span covered by one Seal call.
+	MaxWindow time.Duration
+	// Now returns the current time. Tests replace it with a mock clock.
+	Now func() time.Time
+}
+
+// NewSealer builds a Sealer with the default timings.
+func NewSealer(store Store, eventStore storage.Storage) *Sealer {
+	return &Sealer{
+		Store:       store,
+		Storage:     eventStore,
+		Interval:    time.Minute,
+		SettleDelay: 5 * time.Minute,
+		MaxWindow:   time.Hour,
+		Now:         time.Now,
+	}
+}
+
+// Run seals new events every Interval until ctx is cancelled.
+func (s *Sealer) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		err := s.SealPending(ctx)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			logg.Error("integrity: sealing failed: %s", err.Error())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SealPending seals all windows between the watermark and now minus SettleDelay.
+// On the very first run, the watermark is initialized without sealing anything:
+// chains start when the feature is enabled, not at the beginning of storage.
+func (s *Sealer) SealPending(ctx context.Context) error {
+	horizon := s.Now().UTC().Add(-s.SettleDelay).Truncate(time.Second)
+	watermark, err := s.Store.Watermark(ctx)
+	if err != nil {
+		return err
+	}
+	if watermark.IsZero() {
+		logg.Info("integrity: starting hash chains at %s", horizon.Format(time.RFC3339))
+		return s.Store.Seal(ctx, time.Time{}, horizon, nil)
+	}
+
+	for watermark.Before(horizon) {
+		until := watermark.Add(s.MaxWindow)
+		if until.After(horizon) {
+			until = horizon
+		}
+		events, err := s.fetchWindow(ctx, watermark, until)
+		var tooMany errTooManyEvents
+		for errors.As(err, &tooMany) && until.Sub(watermark) > minWindow {
+			until = watermark.Add(until.Sub(watermark) / 2)
+			events, err = s.fetchWindow(ctx, watermark, until)
+		}
+		if err != nil {
+			return err
+		}
+
+		entries, err := buildEntries(events)
+		if err != nil {
+			return err
+		}
+		err = s.Store.Seal(ctx, watermark, until, entries)
+		if errors.Is(err, ErrWatermarkMoved) {
+			// another replica was faster; continue from wherever it got to
+			watermark, err = s.Store.Watermark(ctx)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		logg.Debug("integrity: sealed %d chain entries for [%s, %s)", len(entries),
+			watermark.Format(time.RFC3339), until.Format(time.RFC3339))
+		watermark = until
+	}
+	return nil
+}
+
+type errTooManyEvents struct {
+	total int
+	max   uint
+}
+
+func (e errTooManyEvents) Error() string {
+	return fmt.Sprintf("time window contains %d events, more than the storage maximum of %d", e.total, e.max)
+}
+
+// fetchWindow returns all events of all tenants with eventTime in [from, until).
+func (s *Sealer) fetchWindow(ctx context.Context, from, until time.Time) ([]*cadf.Event, error) {
+	return fetchEvents(ctx, s.Storage, storage.AllTenants, from, until)
+}
+
+// fetchEvents pages through all events of a tenant with eventTime in [from, until).
+// Returns errTooManyEvents if they do not fit into the storage's MaxLimit.
+func fetchEvents(ctx context.Context, eventStore storage.Storage, tenantID string, from, until time.Time) ([]*cadf.Event, error) {
+	maxLimit := eventStore.MaxLimit()
+	pageSize := min(maxLimit, 1000)
+	filter := storage.EventFilter{
+		Time: map[string]string{
+			"gte": from.UTC().Format(time.RFC3339Nano),
+			"lt":  until.UTC().Format(time.RFC3339Nano),
+		},
+		Sort:  []storage.FieldOrder{{Fieldname: "time", Order: "asc"}},
+		Limit: pageSize,
+	}
+
+	var result []*cadf.Event
+	for {
+		events, total, err := eventStore.GetEvents(ctx, &filter, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		if total > int(maxLimit) { //nolint:gosec // MaxLimit is far below MaxInt
+			return nil, errTooManyEvents{total, maxLimit}
+		}
+		result = append(result, events...)
+		filter.Offset += pageSize
+		if len(events) == 0 || filter.Offset >= uint(total) {
+			break
+		}
+		filter.Limit = min(pageSize, maxLimit-filter.Offset)
+	}
+	return storage.DeduplicateEvents(result), nil
+}
+
+// buildEntries hashes the events and orders them deterministically by (eventTime, id)
+// so that every replica builds the same chains from the same window.
+func buildEntries(events []*cadf.Event) ([]Entry, error) {
+	var entries []Entry
+	for _, event := range events {
+		eventTime, err := storage.ParseEventTime(event.EventTime)
+		if err != nil {
+			logg.Error("integrity: skipping event %s with unparseable eventTime %q", event.ID, event.EventTime)
+			continue
+		}
+		eventHash, err := HashEvent(event)
+		if err != nil {
+			return nil, fmt.Errorf("integrity: cannot hash event %s: %w", event.ID, err)
+		}
+		for _, tenantID := range hermes.TenantIDs(event) {
+			entries = append(entries, Entry{
+				TenantID:  tenantID,
+				EventID:   event.ID,
+				EventTime: eventTime.UTC(),
+				EventHash: eventHash,
+			})
+		}
+	}
+	slices.SortStableFunc(entries, func(lhs, rhs Entry) int {
+		return cmp.Or(lhs.EventTime.Compare(rhs.EventTime), cmp.Compare(lhs.EventID, rhs.EventID))
+	})
+	return entries, nil
+}
@@ -0,0 +1,82 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+// Package integrity makes modifications of stored audit events detectable.
+//
+// A background Sealer reads newly stored events from storage.Storage in
+// time windows and appends them to a per-tenant hash chain in Postgres.
+// Verify later recomputes the hashes from what storage returns and reports
+// events that were modified, removed or never sealed, as well as breaks in
+// the chain itself.
+package integrity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+)
+
+// Entry is an event that is about to be appended to a tenant's chain.
+type Entry struct {
+	TenantID  string
+	EventID   string
+	EventTime time.Time
+	EventHash string
+}
+
+// Link is one element of a tenant's hash chain.
+// ChainHash covers the previous link's ChainHash and this link's EventHash,
+// so altering or removing any link changes every ChainHash after it.
+type Link struct {
+	TenantID  string    `json:"tenant_id"`
+	Sequence  int64     `json:"sequence"`
+	EventID   string    `json:"event_id"`
+	EventTime time.Time `json:"event_time"`
+	EventHash string    `json:"event_hash"`
+	ChainHash string    `json:"chain_hash"`
+	SealedAt  time.Time `json:"sealed_at"`
+}
+
+// genesisHash is the ChainHash that the first link of every chain refers to.
+var genesisHash = hex.EncodeToString(make([]byte, sha256.Size))
+
+// HashEvent computes the SHA-256 of the event's JSON representation as returned by storage.Storage.
+// Fields that are not part of cadf.Event are not covered.
+func HashEvent(event *cadf.Event) (string, error) {
+	buf, err := json.Marshal(event)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// chainHash computes the ChainHash of a link from its predecessor's ChainHash.
+func chainHash(prevChainHash, eventHash string) string {
+	sum := sha256.Sum256([]byte(prevChainHash + eventHash))
+	return hex.EncodeToString(sum[:])
+}
+
+// NextLink builds the link that appends e to a chain whose last link is prev.
+// prev is nil for the first link of a chain. Store implementations use this
+// so that all of them compute identical chains.
+func NextLink(prev *Link, e Entry, sealedAt time.Time) Link {
+	prevHash := genesisHash
+	var seq int64 = 1
+	if prev != nil {
+		prevHash = prev.ChainHash
+		seq = prev.Sequence + 1
+	}
+	return Link{
+		TenantID:  e.TenantID,
+		Sequence:  seq,
+		EventID:   e.EventID,
+		EventTime: e.EventTime,
+		EventHash: e.EventHash,
+		ChainHash: chainHash(prevHash, e.EventHash),
+		SealedAt:  sealedAt,
+	}
+}
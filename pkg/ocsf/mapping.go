@@ -0,0 +1,217 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package ocsf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+
+	"github.com/sapcc/hermes/pkg/storage"
+)
+
+// class is an OCSF event class together with its category.
+type class struct {
+	UID          int
+	Name         string
+	CategoryUID  int
+	CategoryName string
+}
+
+var (
+	classAccountChange  = class{3001, "Account Change", 3, "Identity & Access Management"}
+	classAuthentication = class{3002, "Authentication", 3, "Identity & Access Management"}
+	classAPIActivity    = class{6003, "API Activity", 6, "Application Activity"}
+)
+
+// activityOther is the activity_id for activities without a specific ID in all classes.
+const activityOther = 99
+
+var activityNames = map[class]map[int]string{
+	classAccountChange: {
+		1: "Create", 2: "Enable", 3: "Password Change", 4: "Password Reset", 5: "Disable", 6: "Delete",
+		7: "Attach Policy", 8: "Detach Policy", 99: "Other",
+	},
+	classAuthentication: {1: "Logon", 2: "Logoff", 99: "Other"},
+	classAPIActivity:    {1: "Create", 2: "Read", 3: "Update", 4: "Delete", 99: "Other"},
+}
+
+// FromCADF renders a CADF event as an OCSF event. The class is chosen as follows:
+// actions starting with "authenticate" are Authentication, events whose target
+// is a user account are Account Change, and everything else is API Activity.
+func FromCADF(event *cadf.Event) (*Event, error) {
+	eventTime, err := storage.ParseEventTime(event.EventTime)
+	if err != nil {
+		return nil, fmt.Errorf("cannot render event %s as OCSF: %w", event.ID, err)
+	}
+
+	action := string(event.Action)
+	eventClass, activityID := classify(event)
+	activityName := activityNames[eventClass][activityID]
+	statusID, status := mapOutcome(event.Outcome)
+	result := &Event{
+		ClassUID:     eventClass.UID,
+		ClassName:    eventClass.Name,
+		CategoryUID:  eventClass.CategoryUID,
+		CategoryName: eventClass.CategoryName,
+		ActivityID:   activityID,
+		ActivityName: activityName,
+		TypeUID:      eventClass.UID*100 + activityID,
+		TypeName:     eventClass.Name + ": " + activityName,
+		Time:         eventTime.UnixMilli(),
+		// CADF has no notion of severity
+		SeverityID:   1,
+		Severity:     "Informational",
+		StatusID:     statusID,
+		Status:       status,
+		StatusCode:   event.Reason.ReasonCode,
+		StatusDetail: event.Reason.ReasonType,
+		Metadata: Metadata{
+			Version:      Version,
+			Product:      Product{Name: "Hermes", VendorName: "SAP"},
+			UID:          event.ID,
+			OriginalTime: event.EventTime,
+			EventCode:    action,
+		},
+		Actor: &Actor{User: mapUser(event.Initiator)},
+		Cloud: mapCloud(event.Initiator),
+	}
+
+	initiator := event.Initiator
+	if initiator.Host != nil && initiator.Host.Address != "" {
+		result.SrcEndpoint = &Endpoint{IP: initiator.Host.Address}
+	}
+	if event.RequestPath != "" || initiator.RequestID != "" || (initiator.Host != nil && initiator.Host.Agent != "") {
+		result.HTTPRequest = &HTTPRequest{UID: initiator.RequestID}
+		if event.RequestPath != "" {
+			result.HTTPRequest.URL = &URL{Path: event.RequestPath}
+		}
+		if initiator.Host != nil {
+			result.HTTPRequest.UserAgent = initiator.Host.Agent
+		}
+	}
+	if event.Reason.ReasonType == "HTTP" {
+		if code, err := strconv.Atoi(event.Reason.ReasonCode); err == nil {
+			result.HTTPResponse = &HTTPResponse{Code: code}
+		}
+	}
+
+	observer := mapService(event.Observer)
+	unmapped := Unmapped{Attachments: event.Attachments}
+	switch eventClass {
+	case classAPIActivity:
+		result.API = &API{Operation: action, Service: observer}
+		result.Resources = []Resource{{
+			UID:  event.Target.ID,
+			Name: event.Target.Name,
+			Type: event.Target.TypeURI,
+		}}
+	case classAuthentication:
+		result.User = mapUser(event.Initiator)
+		result.Service = observer
+	case classAccountChange:
+		result.User = mapUser(event.Target)
+		unmapped.Observer = observer
+	}
+	if unmapped.Observer != nil || len(unmapped.Attachments) > 0 {
+		result.Unmapped = &unmapped
+	}
+	return result, nil
+}
+
+// classify returns the OCSF class and activity_id for a CADF event.
+func classify(event *cadf.Event) (class, int) {
+	action := string(event.Action)
+	verb, _, _ := strings.Cut(action, "/")
+
+	switch {
+	case verb == "authenticate":
+		if action == "authenticate/logout" {
+			return classAuthentication, 2
+		}
+		return classAuthentication, 1
+
+	case strings.HasSuffix(event.Target.TypeURI, "/account/user"):
+		roleAssignment := strings.Contains(action, "role_assignment")
+		switch {
+		case verb == "create" && roleAssignment:
+			return classAccountChange, 7
+		case verb == "delete" && roleAssignment:
+			return classAccountChange, 8
+		case verb == "create":
+			return classAccountChange, 1
+		case verb == "delete":
+			return classAccountChange, 6
+		default:
+			return classAccountChange, activityOther
+		}
+
+	default:
+		switch verb {
+		case "create":
+			return classAPIActivity, 1
+		case "read":
+			return classAPIActivity, 2
+		case "update":
+			return classAPIActivity, 3
+		case "delete":
+			return classAPIActivity, 4
+		default:
+			return classAPIActivity, activityOther
+		}
+	}
+}
+
+// mapOutcome returns the OCSF status_id and status for a CADF outcome.
+func mapOutcome(outcome cadf.Outcome) (int, string) {
+	switch outcome {
+	case cadf.SuccessOutcome:
+		return 1, "Success"
+	case cadf.FailureOutcome:
+		return 2, "Failure"
+	case "", "unknown", cadf.PendingOutcome:
+		return 0, "Unknown"
+	default:
+		return 99, "Other"
+	}
+}
+
+func mapUser(resource cadf.Resource) *User {
+	user := &User{
+		UID:           resource.ID,
+		Name:          resource.Name,
+		Domain:        resource.Domain,
+		CredentialUID: resource.AppCredentialID,
+	}
+	switch {
+	case strings.HasSuffix(resource.TypeURI, "/account/user"):
+		user.TypeID, user.Type = 1, "User"
+	case resource.TypeURI == "":
+		user.TypeID, user.Type = 0, "Unknown"
+	default:
+		user.TypeID, user.Type = 99, "Other"
+	}
+	return user
+}
+
+func mapService(resource cadf.Resource) *Service {
+	if resource.ID == "" && resource.Name == "" {
+		return nil
+	}
+	return &Service{UID: resource.ID, Name: resource.Name}
+}
+
+// mapCloud describes the project and domain of the initiator's token.
+func mapCloud(initiator cadf.Resource) *Cloud {
+	cloud := &Cloud{Provider: "OpenStack", ProjectUID: initiator.ProjectID}
+	if initiator.DomainID != "" || initiator.DomainName != "" {
+		cloud.Account = &Account{UID: initiator.DomainID, Name: initiator.DomainName}
+	}
+	if cloud.ProjectUID == "" && cloud.Account == nil {
+		return nil
+	}
+	return cloud
+}
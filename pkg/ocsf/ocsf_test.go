@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package ocsf
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFromCADF renders every fixtures/<name>.cadf.json and compares the
+// result with fixtures/<name>.ocsf.json.
+func TestFromCADF(t *testing.T) {
+	inputs, err := filepath.Glob("fixtures/*.cadf.json")
+	require.NoError(t, err)
+	require.NotEmpty(t, inputs)
+	for _, input := range inputs {
+		name := strings.TrimSuffix(filepath.Base(input), ".cadf.json")
+		t.Run(name, func(t *testing.T) {
+			buf, err := os.ReadFile(input)
+			require.NoError(t, err)
+			var event cadf.Event
+			require.NoError(t, json.Unmarshal(buf, &event))
+
+			rendered, err := FromCADF(&event)
+			require.NoError(t, err)
+			actual, err := json.Marshal(rendered)
+			require.NoError(t, err)
+			expected, err := os.ReadFile(filepath.Join("fixtures", name+".ocsf.json"))
+			require.NoError(t, err)
+			assert.JSONEq(t, string(expected), string(actual))
+		})
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tt := []struct {
+		action     string
+		targetType string
+		expectType int
+	}{
+		{"authenticate", "service/security/account/user", 300201},
+		{"authenticate/logout", "service/security/account/user", 300202},
+		{"create", "service/security/account/user", 300101},
+		{"delete", "data/security/account/user", 300106},
+		{"create/role_assignment", "service/security/account/user", 300107},
+		{"delete/role_assignment", "service/security/account/user", 300108},
+		{"update", "service/security/account/user", 300199},
+		{"create", "compute/server", 600301},
+		{"read/list", "compute/server", 600302},
+		{"update/add/floatingip", "network/port", 600303},
+		{"delete", "network/port", 600304},
+		{"start", "compute/server", 600399},
+	}
+	for _, tc := range tt {
+		event := cadf.Event{
+			ID:        "e2b3f1c0-0000-4000-8000-000000000001",
+			EventTime: "2026-03-02T08:00:00.000000+00:00",
+			Action:    cadf.Action(tc.action),
+			Outcome:   cadf.SuccessOutcome,
+			Target:    cadf.Resource{TypeURI: tc.targetType},
+		}
+		rendered, err := FromCADF(&event)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expectType, rendered.TypeUID, "%s on %s", tc.action, tc.targetType)
+		assert.NotEmpty(t, rendered.ActivityName)
+	}
+
+	_, err := FromCADF(&cadf.Event{ID: "broken", EventTime: "yesterday"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,140 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package ocsf
+
+import "github.com/sapcc/go-api-declarations/cadf"
+
+// Version is the OCSF schema version of the rendered events.
+const Version = "1.1.0"
+
+// Event is an OCSF event of one of the classes API Activity, Account Change or
+// Authentication. It only contains the attributes that can be filled from a
+// CADF event. Class-specific attributes are empty for the other classes.
+type Event struct {
+	ClassUID     int    `json:"class_uid"`
+	ClassName    string `json:"class_name"`
+	CategoryUID  int    `json:"category_uid"`
+	CategoryName string `json:"category_name"`
+	ActivityID   int    `json:"activity_id"`
+	ActivityName string `json:"activity_name"`
+	TypeUID      int    `json:"type_uid"`
+	TypeName     string `json:"type_name"`
+	// Time is the eventTime in milliseconds since the Unix epoch.
+	Time         int64         `json:"time"`
+	SeverityID   int           `json:"severity_id"`
+	Severity     string        `json:"severity"`
+	StatusID     int           `json:"status_id"`
+	Status       string        `json:"status"`
+	StatusCode   string        `json:"status_code,omitempty"`
+	StatusDetail string        `json:"status_detail,omitempty"`
+	Metadata     Metadata      `json:"metadata"`
+	Actor        *Actor        `json:"actor,omitempty"`
+	SrcEndpoint  *Endpoint     `json:"src_endpoint,omitempty"`
+	HTTPRequest  *HTTPRequest  `json:"http_request,omitempty"`
+	HTTPResponse *HTTPResponse `json:"http_response,omitempty"`
+	Cloud        *Cloud        `json:"cloud,omitempty"`
+
+	// API Activity
+	API       *API       `json:"api,omitempty"`
+	Resources []Resource `json:"resources,omitempty"`
+
+	// Account Change and Authentication
+	User *User `json:"user,omitempty"`
+
+	// Authentication
+	Service *Service `json:"service,omitempty"`
+
+	// Unmapped holds CADF data without an OCSF counterpart.
+	Unmapped *Unmapped `json:"unmapped,omitempty"`
+}
+
+// Metadata is the OCSF metadata object.
+type Metadata struct {
+	Version      string  `json:"version"`
+	Product      Product `json:"product"`
+	UID          string  `json:"uid"`
+	OriginalTime string  `json:"original_time"`
+	EventCode    string  `json:"event_code,omitempty"`
+}
+
+// Product is the OCSF product object.
+type Product struct {
+	Name       string `json:"name"`
+	VendorName string `json:"vendor_name"`
+}
+
+// Actor is the OCSF actor object.
+type Actor struct {
+	User *User `json:"user,omitempty"`
+}
+
+// User is the OCSF user object.
+type User struct {
+	UID           string `json:"uid,omitempty"`
+	Name          string `json:"name,omitempty"`
+	Domain        string `json:"domain,omitempty"`
+	TypeID        int    `json:"type_id"`
+	Type          string `json:"type"`
+	CredentialUID string `json:"credential_uid,omitempty"`
+}
+
+// Endpoint is the OCSF network endpoint object.
+type Endpoint struct {
+	IP string `json:"ip,omitempty"`
+}
+
+// HTTPRequest is the OCSF HTTP request object.
+type HTTPRequest struct {
+	UID       string `json:"uid,omitempty"`
+	URL       *URL   `json:"url,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+}
+
+// URL is the OCSF uniform resource locator object.
+type URL struct {
+	Path string `json:"path"`
+}
+
+// HTTPResponse is the OCSF HTTP response object.
+type HTTPResponse struct {
+	Code int `json:"code"`
+}
+
+// Cloud is the OCSF cloud object.
+type Cloud struct {
+	Provider   string   `json:"provider"`
+	ProjectUID string   `json:"project_uid,omitempty"`
+	Account    *Account `json:"account,omitempty"`
+}
+
+// Account is the OCSF account object. For OpenStack, it is the domain.
+type Account struct {
+	UID  string `json:"uid,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+// API is the OCSF API object.
+type API struct {
+	Operation string   `json:"operation"`
+	Service   *Service `json:"service,omitempty"`
+}
+
+// Service is the OCSF service object.
+type Service struct {
+	UID  string `json:"uid,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+// Resource is the OCSF resource details object.
+type Resource struct {
+	UID  string `json:"uid,omitempty"`
+	Name string `json:"name,omitempty"`
+	Type string `json:"type,omitempty"`
+}
+
+// Unmapped holds the parts of a CADF event that OCSF has no attribute for.
+type Unmapped struct {
+	Observer    *Service          `json:"observer,omitempty"`
+	Attachments []cadf.Attachment `json:"attachments,omitempty"`
+}
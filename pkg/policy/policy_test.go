@@ -100,6 +100,26 @@ func Test_Policy_ProjectNoProject(t *testing.T) {
 	assert.False(t, enforcer.Enforce("event:show", c))
 }
 
+func Test_Policy_DataplaneConfigListNeedsAdmin(t *testing.T) {
+	enforcer := GetEnforcer()
+	c := policy.Context{
+		Roles: []string{
+			"audit_viewer",
+		},
+		Auth: map[string]string{
+			"project_id":          "7a09c05926ec452ca7992af4aa03c31d",
+			"project_name":        "cloud_admin_project",
+			"project_domain_name": "cloud_domain",
+		},
+		Request: map[string]string{},
+		Logger:  logg.Debug,
+	}
+	assert.True(t, enforcer.Enforce("event:show_initiator_host", c))
+	assert.False(t, enforcer.Enforce("dataplane_config:list", c))
+	c.Roles = append(c.Roles, "audit_admin")
+	assert.True(t, enforcer.Enforce("dataplane_config:list", c))
+}
+
 func TestPolicy(t *testing.T) {
 	var keystonePolicy map[string]string
 
@@ -148,3 +168,21 @@ func TestPolicy(t *testing.T) {
 	//	t.Error("service_admin_or_owner should pass for non owning user")
 	//}
 }
+
+func Test_Policy_ProjectViewerCannotSeeInitiatorHost(t *testing.T) {
+	enforcer := GetEnforcer()
+	c := policy.Context{
+		Roles: []string{
+			"audit_viewer",
+		},
+		Auth: map[string]string{
+			"project_id": "7a09c05926ec452ca7992af4aa03c31d",
+		},
+		Request: map[string]string{
+			"project_id": "7a09c05926ec452ca7992af4aa03c31d",
+		},
+		Logger: logg.Debug,
+	}
+	assert.True(t, enforcer.Enforce("event:show", c))
+	assert.False(t, enforcer.Enforce("event:show_initiator_host", c))
+}
@@ -0,0 +1,164 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/sapcc/go-bits/logg"
+
+	"github.com/sapcc/hermes/pkg/routing"
+)
+
+const (
+	// EventsPrefix is the key prefix below which log-router writes the routed
+	// events into a bucket. Other objects in the bucket are never deleted.
+	EventsPrefix = "events/"
+	// configPageSize is the number of configs loaded at once.
+	configPageSize = 1000
+)
+
+// Enforcer periodically deletes the routed events that are older than the
+// RetentionDays and GraceMinutes of the dataplane config of their project from
+// the project's enabled sinks. Projects that inherit the config of their
+// domain are skipped, since domain configs have no retention.
+type Enforcer struct {
+	Store   Store
+	Configs routing.Store
+	Objects ObjectStore
+	// Interval is the time between two enforcement runs.
+	Interval time.Duration
+	// Now returns the current time. Tests replace it with a mock clock.
+	Now func() time.Time
+}
+
+// NewEnforcer builds an Enforcer with the default interval.
+func NewEnforcer(store Store, configs routing.Store, objectStore ObjectStore) *Enforcer {
+	return &Enforcer{
+		Store:    store,
+		Configs:  configs,
+		Objects:  objectStore,
+		Interval: time.Hour,
+		Now:      time.Now,
+	}
+}
+
+// Run enforces retention every Interval until ctx is cancelled.
+func (e *Enforcer) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.Interval)
+	defer ticker.Stop()
+	for {
+		err := e.EnforceAll(ctx)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			logg.Error("retention: enforcement failed: %s", err.Error())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// EnforceAll enforces the retention of every project once. If another replica
+// holds the retention lock, nothing is done. Errors of individual projects
+// are logged and do not stop the other projects.
+func (e *Enforcer) EnforceAll(ctx context.Context) error {
+	release, ok, err := e.Store.TryLock(ctx)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		logg.Debug("retention: another process is enforcing retention")
+		return nil
+	}
+	defer release()
+
+	var configs []routing.DataplaneConfig
+	for {
+		page, total, err := e.Configs.List(ctx, routing.ListFilter{}, len(configs), configPageSize)
+		if err != nil {
+			return err
+		}
+		configs = append(configs, page...)
+		if len(page) == 0 || len(configs) >= total {
+			break
+		}
+	}
+
+	failed := 0
+	for _, cfg := range configs {
+		if cfg.RetentionDays == 0 {
+			continue
+		}
+		deleted, err := e.Enforce(ctx, cfg)
+		if errors.Is(err, context.Canceled) {
+			return err
+		}
+		if err != nil {
+			logg.Error("retention: cannot enforce retention of project %s: %s", cfg.ProjectID, err.Error())
+			failed++
+		}
+		if deleted > 0 {
+			logg.Info("retention: deleted %d objects older than %s of project %s", deleted, Retention(cfg), cfg.ProjectID)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("retention could not be enforced for %d projects", failed)
+	}
+	return nil
+}
+
+// Retention returns how long the routed events of a project are kept, or 0
+// if they are kept forever.
+func Retention(cfg routing.DataplaneConfig) time.Duration {
+	if cfg.RetentionDays == 0 {
+		return 0
+	}
+	return time.Duration(cfg.RetentionDays)*24*time.Hour + time.Duration(cfg.GraceMinutes)*time.Minute
+}
+
+// Enforce deletes the objects below EventsPrefix that are older than the
+// Retention of cfg from the buckets and containers of the enabled sinks of
+// cfg. Disabled sinks are left alone, since the project may have stopped
+// routing into them to keep their contents. Sinks outside of the object
+// storage are skipped. Returns the number of deleted objects.
+func (e *Enforcer) Enforce(ctx context.Context, cfg routing.DataplaneConfig) (int, error) {
+	retention := Retention(cfg)
+	if retention == 0 {
+		return 0, nil
+	}
+	cutoff := e.Now().Add(-retention)
+
+	var buckets []string
+	for _, sink := range cfg.Sinks {
+		if bucket := sink.Bucket(); sink.Enabled && bucket != "" && !slices.Contains(buckets, bucket) {
+			buckets = append(buckets, bucket)
+		}
+	}
+	deleted := 0
+	var errs []error
+	for _, bucket := range buckets {
+		objects, err := e.Objects.List(ctx, cfg.ProjectID, bucket, EventsPrefix)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, obj := range objects {
+			if !obj.LastModified.Before(cutoff) {
+				continue
+			}
+			if err := e.Objects.Delete(ctx, cfg.ProjectID, bucket, obj.Key); err != nil {
+				errs = append(errs, err)
+				break
+			}
+			deleted++
+		}
+	}
+	return deleted, errors.Join(errs...)
+}
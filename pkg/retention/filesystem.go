@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Filesystem implements ObjectStore on a local directory, for unit tests and
+// local setups. The object "a/b" in bucket "x" of project "p" is the file
+// "<Root>/p/x/a/b", and its LastModified is the modification time of the file.
+type Filesystem struct {
+	Root string
+}
+
+// NewFilesystem builds a Filesystem below the given directory.
+func NewFilesystem(root string) *Filesystem {
+	return &Filesystem{Root: root}
+}
+
+// List implements the ObjectStore interface.
+func (f *Filesystem) List(_ context.Context, projectID, bucket, prefix string) ([]Object, error) {
+	bucketDir, err := f.bucketDir(projectID, bucket)
+	if err != nil {
+		return nil, err
+	}
+	var objects []Object
+	err = filepath.WalkDir(bucketDir, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		key, err := filepath.Rel(bucketDir, name)
+		if err != nil {
+			return err
+		}
+		key = filepath.ToSlash(key)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, Object{Key: key, LastModified: info.ModTime()})
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("retention: cannot list bucket %s of project %s: %w", bucket, projectID, err)
+	}
+	return objects, nil
+}
+
+// Delete implements the ObjectStore interface.
+func (f *Filesystem) Delete(_ context.Context, projectID, bucket, key string) error {
+	bucketDir, err := f.bucketDir(projectID, bucket)
+	if err != nil {
+		return err
+	}
+	cleanKey := path.Clean("/" + key)[1:]
+	if cleanKey == "" || cleanKey != key {
+		return fmt.Errorf("retention: invalid object key %q", key)
+	}
+	err = os.Remove(filepath.Join(bucketDir, filepath.FromSlash(cleanKey)))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("retention: cannot delete %s from bucket %s of project %s: %w", key, bucket, projectID, err)
+	}
+	return nil
+}
+
+// bucketDir returns the directory of a bucket, rejecting names that would
+// escape from Root.
+func (f *Filesystem) bucketDir(projectID, bucket string) (string, error) {
+	for _, name := range []string{projectID, bucket} {
+		if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+			return "", fmt.Errorf("retention: invalid name %q", name)
+		}
+	}
+	return filepath.Join(f.Root, projectID, bucket), nil
+}
+
+var _ ObjectStore = (*Filesystem)(nil)
@@ -0,0 +1,41 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+// Package retention deletes routed dataplane events from the sinks of a
+// project once they are older than the RetentionDays and GraceMinutes of its
+// dataplane config.
+package retention
+
+import (
+	"context"
+	"time"
+)
+
+// Object is one object in a bucket.
+type Object struct {
+	Key          string
+	LastModified time.Time
+}
+
+// ObjectStore is the interface to the object storage that holds the buckets
+// of the projects. The Swift implementation is the production backend;
+// the Filesystem implementation is used in unit tests and local setups.
+type ObjectStore interface {
+	// List returns all objects in a bucket of a project whose keys start with
+	// prefix. A bucket that does not exist has no objects.
+	List(ctx context.Context, projectID, bucket, prefix string) ([]Object, error)
+
+	// Delete removes an object. Deleting an object that does not exist is
+	// not an error.
+	Delete(ctx context.Context, projectID, bucket, key string) error
+}
+
+// Store is the persistence interface for the retention job.
+// The Postgres implementation is the production backend;
+// the Mock implementation is used in unit tests.
+type Store interface {
+	// TryLock acquires the retention lock without waiting, so that only one
+	// hermez replica enforces retention at a time. If ok is true, the caller
+	// must call release when done.
+	TryLock(ctx context.Context) (release func(), ok bool, err error)
+}
@@ -0,0 +1,29 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package retention
+
+import (
+	"context"
+	"sync"
+)
+
+// Mock implements Store in memory for use in unit tests.
+type Mock struct {
+	locked sync.Mutex
+}
+
+// NewMock creates a Mock store.
+func NewMock() *Mock {
+	return &Mock{}
+}
+
+// TryLock implements the Store interface.
+func (m *Mock) TryLock(_ context.Context) (release func(), ok bool, err error) {
+	if !m.locked.TryLock() {
+		return nil, false, nil
+	}
+	return m.locked.Unlock, true, nil
+}
+
+var _ Store = (*Mock)(nil)
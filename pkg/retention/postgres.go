@@ -0,0 +1,54 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package retention
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sapcc/go-bits/logg"
+	"go.xyrillian.de/gg/gsql"
+)
+
+// retentionLockID is the key of the Postgres advisory lock taken by TryLock
+// ("hermes" in ASCII, followed by a number per lock).
+const retentionLockID int64 = 0x6865726d65730005
+
+// Postgres implements Store using the hermez PostgreSQL database.
+type Postgres struct {
+	db *gsql.DB
+}
+
+// NewPostgres wraps an already connected and migrated database.
+func NewPostgres(db *gsql.DB) *Postgres {
+	return &Postgres{db: db}
+}
+
+// TryLock implements the Store interface using a session-level advisory lock.
+// The lock is held on a dedicated connection, which is returned to the pool on release.
+func (p *Postgres) TryLock(ctx context.Context) (release func(), ok bool, err error) {
+	conn, err := p.db.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("retention: cannot get connection for retention lock: %w", err)
+	}
+	err = conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, retentionLockID).Scan(&ok)
+	if err != nil || !ok {
+		conn.Close()
+		if err != nil {
+			return nil, false, fmt.Errorf("retention: cannot take retention lock: %w", err)
+		}
+		return nil, false, nil
+	}
+	release = func() {
+		// use a fresh context: the lock must be released even if ctx was cancelled
+		_, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, retentionLockID)
+		if err != nil {
+			logg.Error("retention: cannot release retention lock: %s", err.Error())
+		}
+		conn.Close()
+	}
+	return release, true, nil
+}
+
+var _ Store = (*Postgres)(nil)
@@ -0,0 +1,144 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package retention
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gophercloud/gophercloud/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapcc/hermes/pkg/routing"
+)
+
+// writeObject creates an object in a Filesystem with the given age.
+func writeObject(t *testing.T, root, projectID, bucket, key string, modified time.Time) {
+	t.Helper()
+	name := filepath.Join(root, projectID, bucket, filepath.FromSlash(key))
+	require.NoError(t, os.MkdirAll(filepath.Dir(name), 0o755))
+	require.NoError(t, os.WriteFile(name, []byte("{}\n"), 0o644))
+	require.NoError(t, os.Chtimes(name, modified, modified))
+}
+
+func listKeys(t *testing.T, objectStore ObjectStore, projectID, bucket string) []string {
+	t.Helper()
+	objects, err := objectStore.List(t.Context(), projectID, bucket, "")
+	require.NoError(t, err)
+	keys := []string{}
+	for _, obj := range objects {
+		keys = append(keys, obj.Key)
+	}
+	return keys
+}
+
+func TestEnforcer(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	root := t.TempDir()
+	old, recent := now.Add(-31*24*time.Hour), now.Add(-29*24*time.Hour)
+	for _, bucket := range []string{"bucket-a", "dr-bucket", "Audit Archive", "paused-bucket"} {
+		writeObject(t, root, "project-a", bucket, "events/_Default/identity/region/2026/09/17/10:00_10:59/S0.json", old)
+		writeObject(t, root, "project-a", bucket, "events/_Default/identity/region/2026/09/19/10:00_10:59/S0.json", recent)
+	}
+	// objects that log-router did not write are kept
+	writeObject(t, root, "project-a", "bucket-a", "backups/old.tar", old)
+	// projects without retention are not touched
+	writeObject(t, root, "project-b", "bucket-b", "events/_Default/old/S0.json", old)
+	// the grace period extends the retention
+	writeObject(t, root, "project-c", "bucket-c", "events/_Default/within-grace/S0.json", now.Add(-30*24*time.Hour-time.Hour))
+	writeObject(t, root, "project-c", "bucket-c", "events/_Default/after-grace/S0.json", now.Add(-30*24*time.Hour-3*time.Hour))
+
+	configs := routing.NewMock()
+	require.NoError(t, configs.Upsert(t.Context(), routing.DataplaneConfig{ProjectID: "project-a", Enabled: true, TargetBucket: "bucket-a", RetentionDays: 30}))
+	require.NoError(t, configs.UpsertSink(t.Context(), routing.Sink{ProjectID: "project-a", Name: "dr", Enabled: true, TargetBucket: "dr-bucket"}))
+	require.NoError(t, configs.UpsertSink(t.Context(), routing.Sink{ProjectID: "project-a", Name: "archive", Enabled: true, Type: routing.SinkSwift,
+		Settings: &routing.SinkSettings{Container: "Audit Archive"}}))
+	// disabled sinks are not touched
+	require.NoError(t, configs.UpsertSink(t.Context(), routing.Sink{ProjectID: "project-a", Name: "paused", TargetBucket: "paused-bucket"}))
+	// sinks outside of the object storage are skipped
+	require.NoError(t, configs.UpsertSink(t.Context(), routing.Sink{ProjectID: "project-a", Name: "siem", Type: routing.SinkWebhook,
+		Settings: &routing.SinkSettings{URL: "https://siem.example.com/ingest"}}))
+	require.NoError(t, configs.Upsert(t.Context(), routing.DataplaneConfig{ProjectID: "project-b", Enabled: true, TargetBucket: "bucket-b"}))
+	require.NoError(t, configs.Upsert(t.Context(), routing.DataplaneConfig{ProjectID: "project-c", Enabled: true, TargetBucket: "bucket-c", RetentionDays: 30, GraceMinutes: 120}))
+
+	objectStore := NewFilesystem(root)
+	store := NewMock()
+	enforcer := NewEnforcer(store, configs, objectStore)
+	enforcer.Now = func() time.Time { return now }
+	require.NoError(t, enforcer.EnforceAll(t.Context()))
+
+	assert.Equal(t, []string{"backups/old.tar", "events/_Default/identity/region/2026/09/19/10:00_10:59/S0.json"}, listKeys(t, objectStore, "project-a", "bucket-a"))
+	assert.Equal(t, []string{"events/_Default/identity/region/2026/09/19/10:00_10:59/S0.json"}, listKeys(t, objectStore, "project-a", "dr-bucket"))
+	assert.Equal(t, []string{"events/_Default/identity/region/2026/09/19/10:00_10:59/S0.json"}, listKeys(t, objectStore, "project-a", "Audit Archive"))
+	assert.Len(t, listKeys(t, objectStore, "project-a", "paused-bucket"), 2)
+	assert.Equal(t, []string{"events/_Default/old/S0.json"}, listKeys(t, objectStore, "project-b", "bucket-b"))
+	assert.Equal(t, []string{"events/_Default/within-grace/S0.json"}, listKeys(t, objectStore, "project-c", "bucket-c"))
+
+	// nothing happens while another replica holds the lock
+	release, ok, err := store.TryLock(t.Context())
+	require.NoError(t, err)
+	require.True(t, ok)
+	enforcer.Now = func() time.Time { return now.Add(2 * 24 * time.Hour) }
+	require.NoError(t, enforcer.EnforceAll(t.Context()))
+	assert.Len(t, listKeys(t, objectStore, "project-a", "dr-bucket"), 1)
+	release()
+	require.NoError(t, enforcer.EnforceAll(t.Context()))
+	assert.Empty(t, listKeys(t, objectStore, "project-a", "dr-bucket"))
+}
+
+func TestFilesystem(t *testing.T) {
+	objectStore := NewFilesystem(t.TempDir())
+	objects, err := objectStore.List(t.Context(), "project-a", "missing", "")
+	require.NoError(t, err)
+	assert.Empty(t, objects)
+	require.NoError(t, objectStore.Delete(t.Context(), "project-a", "missing", "events/S0.json"))
+
+	for _, name := range []string{"", "..", "a/b"} {
+		_, err = objectStore.List(t.Context(), "project-a", name, "")
+		assert.Error(t, err, name)
+	}
+	assert.Error(t, objectStore.Delete(t.Context(), "project-a", "bucket", "events/../../other-bucket/S0.json"))
+}
+
+func TestSwift(t *testing.T) {
+	var deleted []string
+	swift := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/AUTH_project-a/bucket-a":
+			assert.Equal(t, "events/", r.URL.Query().Get("prefix"))
+			w.Header().Set("Content-Type", "application/json")
+			if r.URL.Query().Get("marker") == "" {
+				w.Write([]byte(`[{"name":"events/a/S0.json","last_modified":"2026-09-17T10:59:59.123456","bytes":3}]`)) //nolint:errcheck
+			} else {
+				w.Write([]byte(`[]`)) //nolint:errcheck
+			}
+		case r.Method == http.MethodDelete && r.URL.Path == "/v1/AUTH_project-a/bucket-a/events/a/S0.json":
+			deleted = append(deleted, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(swift.Close)
+
+	objectStore := NewSwift(&gophercloud.ProviderClient{}, swift.URL+"/v1/AUTH_%(project_id)s")
+	objects, err := objectStore.List(t.Context(), "project-a", "bucket-a", EventsPrefix)
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	assert.Equal(t, "events/a/S0.json", objects[0].Key)
+	assert.Equal(t, time.Date(2026, 9, 17, 10, 59, 59, 123456000, time.UTC), objects[0].LastModified.UTC())
+
+	objects, err = objectStore.List(t.Context(), "project-a", "missing", EventsPrefix)
+	require.NoError(t, err)
+	assert.Empty(t, objects)
+
+	require.NoError(t, objectStore.Delete(t.Context(), "project-a", "bucket-a", "events/a/S0.json"))
+	require.NoError(t, objectStore.Delete(t.Context(), "project-a", "bucket-a", "events/gone/S0.json"))
+	assert.Equal(t, []string{"/v1/AUTH_project-a/bucket-a/events/a/S0.json"}, deleted)
+}
@@ -0,0 +1,77 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package retention
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gophercloud/gophercloud/v2"
+	"github.com/gophercloud/gophercloud/v2/openstack/objectstorage/v1/objects"
+	"github.com/gophercloud/gophercloud/v2/pagination"
+)
+
+// Swift implements ObjectStore with the Swift API. Buckets are containers in
+// the Swift account of the project. The hermez service user needs access to
+// the accounts of all projects, e.g. through the ResellerAdmin role.
+type Swift struct {
+	provider *gophercloud.ProviderClient
+	// Endpoint is the URL of the Swift account of a project. The placeholder
+	// %(project_id)s is replaced with the project ID, e.g.
+	// "https://objectstore.example.com/v1/AUTH_%(project_id)s".
+	Endpoint string
+}
+
+// NewSwift builds a Swift client that authenticates with the given provider
+// client, e.g. the one of the IdentityV3 client of the TokenValidator.
+func NewSwift(provider *gophercloud.ProviderClient, endpoint string) *Swift {
+	return &Swift{provider: provider, Endpoint: endpoint}
+}
+
+// List implements the ObjectStore interface.
+func (s *Swift) List(ctx context.Context, projectID, bucket, prefix string) ([]Object, error) {
+	var result []Object
+	err := objects.List(s.client(projectID), bucket, objects.ListOpts{Prefix: prefix}).EachPage(ctx,
+		func(_ context.Context, page pagination.Page) (bool, error) {
+			infos, err := objects.ExtractInfo(page)
+			if err != nil {
+				return false, err
+			}
+			for _, info := range infos {
+				result = append(result, Object{Key: info.Name, LastModified: info.LastModified})
+			}
+			return true, nil
+		})
+	if gophercloud.ResponseCodeIs(err, http.StatusNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("retention: cannot list bucket %s of project %s: %w", bucket, projectID, err)
+	}
+	return result, nil
+}
+
+// Delete implements the ObjectStore interface.
+func (s *Swift) Delete(ctx context.Context, projectID, bucket, key string) error {
+	_, err := objects.Delete(ctx, s.client(projectID), bucket, key, nil).Extract()
+	if err != nil && !gophercloud.ResponseCodeIs(err, http.StatusNotFound) {
+		return fmt.Errorf("retention: cannot delete %s from bucket %s of project %s: %w", key, bucket, projectID, err)
+	}
+	return nil
+}
+
+// client returns a service client for the Swift account of a project.
+func (s *Swift) client(projectID string) *gophercloud.ServiceClient {
+	endpoint := strings.ReplaceAll(s.Endpoint, "%(project_id)s", url.PathEscape(projectID))
+	return &gophercloud.ServiceClient{
+		ProviderClient: s.provider,
+		Endpoint:       strings.TrimSuffix(endpoint, "/") + "/",
+		Type:           "object-store",
+	}
+}
+
+var _ ObjectStore = (*Swift)(nil)
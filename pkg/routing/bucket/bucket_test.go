@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package bucket
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPVerifier(t *testing.T) {
+	store := NewFakeObjectStore()
+	store.AddToken("token", "project-1")
+	store.AddToken("other-token", "project-2")
+	store.AddBucket("project-1", "mine", false)
+	store.AddBucket("project-2", "theirs", false)
+	store.AddBucket("project-1", "read-only", true)
+	server := httptest.NewServer(store)
+	t.Cleanup(server.Close)
+	verifier, err := NewHTTPVerifier(server.URL + "/AUTH_%(project_id)s")
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		bucket, token string
+		err           error
+	}{
+		{"mine", "token", nil},
+		{"missing", "token", ErrNotFound},
+		// buckets are looked up in the account of the project only
+		{"theirs", "token", ErrNotFound},
+		{"theirs", "other-token", ErrNotOwned},
+		{"read-only", "token", ErrNotWritable},
+		{"mine", "", ErrNotOwned},
+	} {
+		err := verifier.Verify(t.Context(), Request{ProjectID: "project-1", Bucket: tc.bucket, Token: tc.token})
+		if tc.err == nil {
+			assert.NoError(t, err, tc.bucket)
+		} else {
+			assert.ErrorIs(t, err, tc.err, tc.bucket)
+		}
+	}
+	// the write probe cleans up after itself
+	assert.Empty(t, store.Objects("project-1", "mine"))
+
+	// unexpected responses are errors, but do not blame the bucket
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/AUTH_project-1/mine", r.URL.Path)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	err = verifier.Verify(t.Context(), Request{ProjectID: "project-1", Bucket: "mine", Token: "token"})
+	require.Error(t, err)
+	for _, target := range []error{ErrNotFound, ErrNotOwned, ErrNotWritable} {
+		assert.NotErrorIs(t, err, target)
+	}
+}
+
+func TestNewHTTPVerifier(t *testing.T) {
+	for endpoint, valid := range map[string]bool{
+		"https://objectstore.example.com/swift/v1/AUTH_%(project_id)s": true,
+		"https://objectstore.example.com/v1/AUTH_%(project_id)s/":      true,
+		// without the account, ownership cannot be derived from the URL
+		"https://objectstore.example.com":                       false,
+		"https://objectstore.example.com/?owner=%(project_id)s": false,
+		"/v1/AUTH_%(project_id)s":                               false,
+	} {
+		_, err := NewHTTPVerifier(endpoint)
+		assert.Equal(t, valid, err == nil, endpoint)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for value, expected := range map[string]Mode{"": ModeOff, "off": ModeOff, "warn": ModeWarn, "enforce": ModeEnforce} {
+		mode, err := ParseMode(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, expected, mode, value)
+	}
+	_, err := ParseMode("strict")
+	assert.Error(t, err)
+}
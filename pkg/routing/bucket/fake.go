@@ -0,0 +1,122 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package bucket
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// FakeObjectStore is an in-process stand-in for the Swift API of Ceph RGW with
+// Keystone integration for use in unit tests, e.g. behind httptest.NewServer.
+// Like RGW with rgw_swift_account_in_url and rgw_keystone_implicit_tenants,
+// it addresses buckets as /AUTH_<project_id>/<bucket>, so that every project
+// has buckets of its own, and only accepts tokens of the project for its
+// account. It understands just what HTTPVerifier needs: HEAD on buckets, and
+// PUT and DELETE on objects.
+type FakeObjectStore struct {
+	mu sync.Mutex
+	// buckets holds the buckets of each project by name.
+	buckets map[string]map[string]*fakeBucket
+	// tokens holds the project of each known token.
+	tokens map[string]string
+}
+
+type fakeBucket struct {
+	readOnly bool
+	objects  map[string]bool
+}
+
+// NewFakeObjectStore creates a FakeObjectStore without buckets or tokens.
+func NewFakeObjectStore() *FakeObjectStore {
+	return &FakeObjectStore{
+		buckets: make(map[string]map[string]*fakeBucket),
+		tokens:  make(map[string]string),
+	}
+}
+
+// AddToken makes the fake accept a token that is scoped to the given project.
+func (f *FakeObjectStore) AddToken(token, projectID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tokens[token] = projectID
+}
+
+// AddBucket creates a bucket in the account of the given project. If readOnly
+// is true, writes into the bucket are rejected.
+func (f *FakeObjectStore) AddBucket(projectID, name string, readOnly bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.buckets[projectID] == nil {
+		f.buckets[projectID] = make(map[string]*fakeBucket)
+	}
+	f.buckets[projectID][name] = &fakeBucket{readOnly: readOnly, objects: make(map[string]bool)}
+}
+
+// Objects returns the names of the objects in a bucket of a project, sorted.
+func (f *FakeObjectStore) Objects(projectID, bucketName string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	b, ok := f.buckets[projectID][bucketName]
+	if !ok {
+		return nil
+	}
+	names := make([]string, 0, len(b.objects))
+	for name := range b.objects {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (f *FakeObjectStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	account, path, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	bucketName, key, _ := strings.Cut(path, "/")
+	projectID, ok := strings.CutPrefix(account, "AUTH_")
+	if !ok || bucketName == "" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tokenProjectID, ok := f.tokens[r.Header.Get("X-Auth-Token")]
+	switch {
+	case !ok:
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	case tokenProjectID != projectID:
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	b, ok := f.buckets[projectID][bucketName]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodHead && key == "":
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut && key != "":
+		if b.readOnly {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		b.objects[key] = true
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodDelete && key != "":
+		if !b.objects[key] {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(b.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
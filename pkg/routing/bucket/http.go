@@ -0,0 +1,118 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package bucket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sapcc/go-api-declarations/bininfo"
+	"github.com/sapcc/go-bits/logg"
+)
+
+const (
+	// probePrefix is the name prefix of the objects written by the write probe.
+	probePrefix = ".hermez-write-probe-"
+	// projectPlaceholder is replaced with the project ID in HTTPVerifier.Endpoint.
+	projectPlaceholder = "%(project_id)s"
+)
+
+// HTTPVerifier verifies buckets through the Swift API of the object storage:
+// HEAD on the container, and PUT and DELETE on an object in it. Requests carry
+// the Keystone token of the caller.
+//
+// Ownership is not reported by the object storage, but follows from the URL:
+// buckets are addressed within the account of the project (AUTH_<project_id>),
+// so a bucket that exists there belongs to the project. This holds for Swift
+// with the Keystone reseller prefix "AUTH_", and for the Swift API of Ceph RGW
+// with rgw_swift_account_in_url and rgw_keystone_implicit_tenants, where
+// containers of the Swift API and buckets of the S3 API share the tenant of
+// the project. Both answer 404 for buckets that only exist in other accounts,
+// and 401 or 403 for tokens that cannot access the account.
+type HTTPVerifier struct {
+	// Endpoint is the URL of the account of a project, with the placeholder
+	// %(project_id)s in place of the project ID, e.g.
+	// "https://objectstore.example.com/swift/v1/AUTH_%(project_id)s".
+	Endpoint string
+	Client   *http.Client
+}
+
+// NewHTTPVerifier builds an HTTPVerifier with a 10-second timeout per request.
+// The endpoint must address the account of the project, see HTTPVerifier.
+func NewHTTPVerifier(endpoint string) (*HTTPVerifier, error) {
+	u, err := url.Parse(strings.ReplaceAll(endpoint, projectPlaceholder, "project"))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("bucket endpoint must be an absolute URL, got %q", endpoint)
+	}
+	if !strings.Contains(strings.SplitN(endpoint, "?", 2)[0], projectPlaceholder) {
+		return nil, errors.New("bucket endpoint must address the account of the project with " + projectPlaceholder)
+	}
+	return &HTTPVerifier{Endpoint: endpoint, Client: &http.Client{Timeout: 10 * time.Second}}, nil
+}
+
+// Verify implements the Verifier interface.
+func (v *HTTPVerifier) Verify(ctx context.Context, req Request) error {
+	bucketURL := strings.TrimSuffix(strings.ReplaceAll(v.Endpoint, projectPlaceholder, url.PathEscape(req.ProjectID)), "/") +
+		"/" + url.PathEscape(req.Bucket)
+
+	status, err := v.do(ctx, http.MethodHead, bucketURL, req)
+	if err != nil {
+		return err
+	}
+	switch {
+	case status == http.StatusNotFound:
+		// also if the bucket exists in the account of another project
+		return fmt.Errorf("%w: %s", ErrNotFound, req.Bucket)
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		// the token cannot access the account of the project
+		return fmt.Errorf("%w: %s", ErrNotOwned, req.Bucket)
+	case status >= 300:
+		return fmt.Errorf("cannot check bucket %s: HEAD returned %d", req.Bucket, status)
+	}
+
+	// HEAD only needs read access, so also try to write
+	probeURL := bucketURL + "/" + probePrefix + uuid.NewString()
+	status, err = v.do(ctx, http.MethodPut, probeURL, req)
+	if err != nil {
+		return err
+	}
+	switch {
+	case status == http.StatusNotFound:
+		return fmt.Errorf("%w: %s", ErrNotFound, req.Bucket)
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return fmt.Errorf("%w: %s", ErrNotWritable, req.Bucket)
+	case status >= 300:
+		return fmt.Errorf("cannot check bucket %s: PUT returned %d", req.Bucket, status)
+	}
+	status, err = v.do(ctx, http.MethodDelete, probeURL, req)
+	if err != nil || status >= 300 && status != http.StatusNotFound {
+		// not a reason to reject the bucket, the probe is an empty object
+		logg.Error("bucket: cannot delete write probe %s: status %d, error %v", probeURL, status, err)
+	}
+	return nil
+}
+
+func (v *HTTPVerifier) do(ctx context.Context, method, target string, req Request) (int, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, method, target, http.NoBody)
+	if err != nil {
+		return 0, err
+	}
+	httpReq.Header.Set("X-Auth-Token", req.Token)
+	httpReq.Header.Set("User-Agent", bininfo.Component()+"-bucket-verifier")
+	resp, err := v.Client.Do(httpReq)
+	if err != nil {
+		return 0, fmt.Errorf("cannot check bucket %s: %w", req.Bucket, err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// Ensure HTTPVerifier implements Verifier.
+var _ Verifier = (*HTTPVerifier)(nil)
@@ -0,0 +1,63 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+// Package bucket checks that the target bucket of a dataplane config exists
+// and belongs to the project before hermez routes events into it.
+package bucket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotFound is returned by Verifier.Verify when the bucket does not exist.
+	ErrNotFound = errors.New("bucket does not exist")
+	// ErrNotOwned is returned by Verifier.Verify when the bucket exists, but
+	// belongs to another project or cannot be accessed by the project.
+	ErrNotOwned = errors.New("bucket is not owned by the project")
+	// ErrNotWritable is returned by Verifier.Verify when the project cannot
+	// write objects into the bucket.
+	ErrNotWritable = errors.New("bucket does not accept writes from the project")
+)
+
+// Request identifies the bucket to verify and the credentials to verify it with.
+type Request struct {
+	ProjectID string
+	Bucket    string
+	// Token is the Keystone token of the caller, which is scoped to the project.
+	Token string
+}
+
+// Verifier checks that a bucket exists, belongs to the project and accepts
+// writes. It returns ErrNotFound, ErrNotOwned or ErrNotWritable (possibly
+// wrapped) if the bucket is unsuitable, or another error if the check itself
+// failed.
+type Verifier interface {
+	Verify(ctx context.Context, req Request) error
+}
+
+// Mode selects what hermez does with the result of a Verifier.
+type Mode string
+
+const (
+	// ModeOff skips the verification.
+	ModeOff Mode = "off"
+	// ModeWarn verifies buckets, but only logs failures.
+	ModeWarn Mode = "warn"
+	// ModeEnforce rejects configs with buckets that fail the verification.
+	ModeEnforce Mode = "enforce"
+)
+
+// ParseMode parses the value of the dataplane.bucket_verification option.
+func ParseMode(value string) (Mode, error) {
+	switch mode := Mode(value); mode {
+	case ModeOff, ModeWarn, ModeEnforce:
+		return mode, nil
+	case "":
+		return ModeOff, nil
+	default:
+		return "", fmt.Errorf("bucket verification mode must be %q, %q or %q, got %q", ModeOff, ModeWarn, ModeEnforce, value)
+	}
+}
@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package routing
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// ChangesChannel is the Postgres notification channel on which every
+	// change to dataplane_config is announced with its sequence number.
+	ChangesChannel = "dataplane_config_changes"
+	// ChangeRetention is how long the change feed keeps changes. Consumers
+	// that fall further behind have to reload all configs.
+	ChangeRetention = 30 * 24 * time.Hour
+	// changePollInterval bounds how long WaitForChanges sleeps between two
+	// checks, in case a notification is lost (e.g. while reconnecting).
+	changePollInterval = 10 * time.Second
+)
+
+// ChangeOperation identifies the kind of a Change.
+type ChangeOperation string
+
+const (
+	// ChangeUpsert means that the config of the project was created or
+	// modified, including its sinks.
+	ChangeUpsert ChangeOperation = "upsert"
+	// ChangeDelete means that the config of the project was deleted.
+	ChangeDelete ChangeOperation = "delete"
+	// ChangeDomainUpsert means that the config of the domain was created or
+	// modified. The effective config of all projects of the domain without a
+	// config of their own may have changed.
+	ChangeDomainUpsert ChangeOperation = "domain_upsert"
+	// ChangeDomainDelete means that the config of the domain was deleted.
+	ChangeDomainDelete ChangeOperation = "domain_delete"
+	// ChangeMembership means that the project was recorded as a member of the
+	// domain, or removed from it, so its effective config may have changed.
+	ChangeMembership ChangeOperation = "membership"
+)
+
+// Change is one entry of the change feed of all dataplane configs. Every
+// write of a project config through Store produces exactly one change. Writes
+// of domain configs produce one change without ProjectID, and
+// SetDomainProjects one change per project that joins or leaves the domain. Sequence numbers increase
+// in commit order, so a consumer can resume after the last change it saw.
+type Change struct {
+	Sequence  int64           `json:"sequence"`
+	ProjectID string          `json:"project_id,omitempty"`
+	DomainID  string          `json:"domain_id,omitempty"`
+	Operation ChangeOperation `json:"operation"`
+	// Version is DataplaneConfig.Version after the change, or the last version
+	// before it for deletions. It is 0 for changes of domains.
+	Version   int64     `json:"version"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// changeSignal wakes up all goroutines in WaitForChanges whenever a change
+// may have been recorded.
+type changeSignal struct {
+	mu sync.Mutex
+	ch chan struct{}
+}
+
+// wait returns a channel that is closed by the next broadcast.
+func (s *changeSignal) wait() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ch == nil {
+		s.ch = make(chan struct{})
+	}
+	return s.ch
+}
+
+func (s *changeSignal) broadcast() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ch != nil {
+		close(s.ch)
+		s.ch = nil
+	}
+}
+
+// waitForChanges implements Store.WaitForChanges on top of a changeSignal
+// and Store.LatestChange.
+func waitForChanges(ctx context.Context, signal *changeSignal, since int64, latestChange func(context.Context) (int64, error)) error {
+	for {
+		// take the channel before checking, so that no broadcast is missed in between
+		wakeup := signal.wait()
+		latest, err := latestChange(ctx)
+		if err != nil {
+			return err
+		}
+		if latest > since {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wakeup:
+		case <-time.After(changePollInterval):
+		}
+	}
+}
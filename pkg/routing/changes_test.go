@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package routing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMockChanges(t *testing.T) {
+	ctx := t.Context()
+	m := NewMock()
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	latest, err := m.LatestChange(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), latest)
+
+	require.NoError(t, m.Upsert(ctx, DataplaneConfig{ProjectID: "p1", Enabled: true, TargetBucket: "bucket", UpdatedAt: now}))
+	require.NoError(t, m.UpsertSink(ctx, Sink{ProjectID: "p2", Name: "dr", TargetBucket: "dr-bucket", UpdatedAt: now}))
+	_, err = m.Delete(ctx, "p1", "alice")
+	require.NoError(t, err)
+	// no-op deletes do not produce changes
+	_, err = m.Delete(ctx, "p1", "alice")
+	require.NoError(t, err)
+
+	changes, err := m.Changes(ctx, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	assert.Equal(t, Change{Sequence: 1, ProjectID: "p1", Operation: ChangeUpsert, Version: 1, ChangedAt: now}, changes[0])
+	assert.Equal(t, Change{Sequence: 2, ProjectID: "p2", Operation: ChangeUpsert, Version: 1, ChangedAt: now}, changes[1])
+	// deletions carry the last version before them
+	assert.Equal(t, ChangeDelete, changes[2].Operation)
+	assert.Equal(t, int64(1), changes[2].Version)
+
+	// the cursor resumes after the last change seen, in pages of limit
+	changes, err = m.Changes(ctx, 1, 1)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, int64(2), changes[0].Sequence)
+	changes, err = m.Changes(ctx, 3, 10)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	// consumers that fall behind the retention have to reload all configs
+	m.ExpireChanges(2)
+	_, err = m.Changes(ctx, 1, 10)
+	assert.ErrorIs(t, err, ErrChangesExpired)
+	changes, err = m.Changes(ctx, 2, 10)
+	require.NoError(t, err)
+	assert.Len(t, changes, 1)
+	// the latest change is retained
+	m.ExpireChanges(3)
+	changes, err = m.Changes(ctx, 2, 10)
+	require.NoError(t, err)
+	assert.Len(t, changes, 1)
+}
+
+func TestMockWaitForChanges(t *testing.T) {
+	ctx := t.Context()
+	m := NewMock()
+
+	// changes after the cursor return immediately
+	require.NoError(t, m.Upsert(ctx, DataplaneConfig{ProjectID: "p1"}))
+	require.NoError(t, m.WaitForChanges(ctx, 0))
+
+	// otherwise the wait ends with the next change
+	done := make(chan error)
+	go func() { done <- m.WaitForChanges(ctx, 1) }()
+	select {
+	case err := <-done:
+		t.Fatalf("WaitForChanges returned before a change: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	require.NoError(t, m.Upsert(ctx, DataplaneConfig{ProjectID: "p1", Enabled: false}))
+	require.NoError(t, <-done)
+
+	// or with the context
+	waitCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, m.WaitForChanges(waitCtx, 2), context.DeadlineExceeded)
+}
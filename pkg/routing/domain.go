@@ -0,0 +1,219 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package routing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/sapcc/go-bits/logg"
+	"github.com/sapcc/go-bits/must"
+)
+
+// DomainConfig holds the routing configuration for all projects of a domain
+// that do not have a DataplaneConfig of their own (see EffectiveConfig).
+// Domain configs have a single sink only.
+type DomainConfig struct {
+	DomainID     string    `json:"domain_id"`
+	Enabled      bool      `json:"enabled"`
+	TargetBucket string    `json:"target_bucket,omitempty"`
+	Filter       string    `json:"filter,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	UpdatedBy    string    `json:"updated_by"`
+}
+
+// DefaultDomainConfig returns the default (disabled) config for a domain
+// that has no stored configuration.
+func DefaultDomainConfig(domainID string) DomainConfig {
+	return DomainConfig{DomainID: domainID}
+}
+
+// Render implements the audittools.Target interface so DomainConfig can be
+// used directly in audittools.Event.Target.
+func (c DomainConfig) Render() cadf.Resource {
+	payload := map[string]any{
+		"enabled":       c.Enabled,
+		"target_bucket": c.TargetBucket,
+	}
+	if c.Filter != "" {
+		payload["filter"] = c.Filter
+	}
+	return cadf.Resource{
+		TypeURI:  "service/hermes/dataplane-domain-config",
+		ID:       c.DomainID,
+		DomainID: c.DomainID,
+		Attachments: []cadf.Attachment{
+			must.Return(cadf.NewJSONAttachment("payload", payload)),
+		},
+	}
+}
+
+// ConfigSource identifies where the EffectiveConfig of a project comes from.
+type ConfigSource string
+
+const (
+	// SourceProject means that the project has a DataplaneConfig of its own,
+	// which overrides the config of its domain completely if its default sink
+	// is enabled. Without a domain config, it applies even if disabled.
+	SourceProject ConfigSource = "project"
+	// SourceDomain means that the project inherits the config of its domain,
+	// because it has no config of its own or its default sink is not enabled
+	// (e.g. when it only has other sinks). The other sinks do not apply then.
+	SourceDomain ConfigSource = "domain"
+	// SourceDefault means that neither the project nor its domain have a
+	// config, so routing is disabled.
+	SourceDefault ConfigSource = "default"
+)
+
+// EffectiveConfig is the routing configuration that log-router applies to a
+// project: its own DataplaneConfig if its default sink is enabled, otherwise
+// the DomainConfig of its domain, otherwise its own disabled DataplaneConfig
+// or the default (disabled) config.
+type EffectiveConfig struct {
+	ProjectID    string       `json:"project_id"`
+	DomainID     string       `json:"domain_id,omitempty"`
+	Source       ConfigSource `json:"source"`
+	Enabled      bool         `json:"enabled"`
+	TargetBucket string       `json:"target_bucket,omitempty"`
+	// Sinks are the sinks of the project's own config, or a single sink named
+	// DefaultSinkName with the bucket of the domain config.
+	Sinks  []Sink `json:"sinks"`
+	Filter string `json:"filter,omitempty"`
+	// RetentionDays, GraceMinutes and RateLimit are only taken from the
+	// project's own config; domain configs do not have them.
+	RetentionDays int `json:"retention_days,omitempty"`
+	GraceMinutes  int `json:"grace_minutes,omitempty"`
+	RateLimit     int `json:"rate_limit,omitempty"`
+}
+
+// ResolveEffectiveConfig computes the EffectiveConfig of a project from its
+// stored config and the stored config of its domain, either of which may be nil.
+func ResolveEffectiveConfig(projectID, domainID string, project *DataplaneConfig, domain *DomainConfig) EffectiveConfig {
+	result := EffectiveConfig{ProjectID: projectID, DomainID: domainID, Source: SourceDefault, Sinks: []Sink{}}
+	switch {
+	case project != nil && (project.Enabled || domain == nil):
+		result.Source = SourceProject
+		result.Enabled = project.Enabled
+		result.TargetBucket = project.TargetBucket
+		result.Filter = project.Filter
+		result.RetentionDays = project.RetentionDays
+		result.GraceMinutes = project.GraceMinutes
+		result.RateLimit = project.RateLimit
+		if project.Sinks != nil {
+			result.Sinks = project.Sinks
+		}
+	case domain != nil:
+		result.Source = SourceDomain
+		result.Enabled = domain.Enabled
+		result.TargetBucket = domain.TargetBucket
+		result.Filter = domain.Filter
+		if domain.TargetBucket != "" {
+			result.Sinks = []Sink{{
+				ProjectID:    projectID,
+				Name:         DefaultSinkName,
+				Enabled:      domain.Enabled,
+				Type:         SinkS3,
+				TargetBucket: domain.TargetBucket,
+				UpdatedAt:    domain.UpdatedAt,
+				UpdatedBy:    domain.UpdatedBy,
+			}}
+		}
+	}
+	return result
+}
+
+// effectiveConfig implements Store.EffectiveConfig for both stores. If
+// domainID is empty, the recorded domain of the project is used.
+func effectiveConfig(ctx context.Context, s Store, projectID, domainID string, recordedDomain func(context.Context, string) (string, error)) (*EffectiveConfig, error) {
+	project, err := s.Get(ctx, projectID)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		project = nil
+	case err != nil:
+		return nil, err
+	}
+	if domainID == "" {
+		domainID, err = recordedDomain(ctx, projectID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var domain *DomainConfig
+	if (project == nil || !project.Enabled) && domainID != "" {
+		domain, err = s.GetDomain(ctx, domainID)
+		switch {
+		case errors.Is(err, ErrNotFound):
+			domain = nil
+		case err != nil:
+			return nil, err
+		}
+	}
+	result := ResolveEffectiveConfig(projectID, domainID, project, domain)
+	return &result, nil
+}
+
+// ProjectLister lists the projects of a domain, e.g. in Keystone.
+type ProjectLister interface {
+	ListProjects(ctx context.Context, domainID string) ([]string, error)
+}
+
+// DomainSyncer periodically records the projects of every domain that has a
+// DomainConfig (see Store.SetDomainProjects), so that log-router can resolve
+// the effective config of a project without asking Keystone.
+type DomainSyncer struct {
+	Store  Store
+	Lister ProjectLister
+	// Interval is the time between two sync runs.
+	Interval time.Duration
+}
+
+// NewDomainSyncer builds a DomainSyncer with the default interval.
+func NewDomainSyncer(store Store, lister ProjectLister) *DomainSyncer {
+	return &DomainSyncer{
+		Store:    store,
+		Lister:   lister,
+		Interval: 5 * time.Minute,
+	}
+}
+
+// Run syncs all domains every Interval until ctx is cancelled.
+func (s *DomainSyncer) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		err := s.SyncAll(ctx)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			logg.Error("routing: domain sync failed: %s", err.Error())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncAll records the projects of every domain that has a DomainConfig.
+// Failures of individual domains do not stop the others; all of them are
+// returned together.
+func (s *DomainSyncer) SyncAll(ctx context.Context) error {
+	domains, err := s.Store.ListDomains(ctx)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, domain := range domains {
+		projectIDs, err := s.Lister.ListProjects(ctx, domain.DomainID)
+		if err == nil {
+			err = s.Store.SetDomainProjects(ctx, domain.DomainID, projectIDs)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("domain %s: %w", domain.DomainID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,183 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package routing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveEffectiveConfig(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	project := &DataplaneConfig{
+		ProjectID:     "p1",
+		Enabled:       false,
+		TargetBucket:  "project-bucket",
+		Sinks:         []Sink{{ProjectID: "p1", Name: DefaultSinkName, Type: SinkS3, TargetBucket: "project-bucket"}},
+		Filter:        `event.action != "read"`,
+		RetentionDays: 30,
+		GraceMinutes:  60,
+		RateLimit:     100,
+	}
+	domain := &DomainConfig{DomainID: "d1", Enabled: true, TargetBucket: "domain-bucket", Filter: `event.outcome == "failure"`, UpdatedAt: now, UpdatedBy: "alice"}
+
+	// the project's own config overrides the domain config completely if its default sink is enabled
+	enabled := *project
+	enabled.Enabled = true
+	enabled.Sinks = []Sink{{ProjectID: "p1", Name: DefaultSinkName, Enabled: true, Type: SinkS3, TargetBucket: "project-bucket"}}
+	assert.Equal(t, EffectiveConfig{
+		ProjectID:     "p1",
+		DomainID:      "d1",
+		Source:        SourceProject,
+		Enabled:       true,
+		TargetBucket:  "project-bucket",
+		Sinks:         enabled.Sinks,
+		Filter:        `event.action != "read"`,
+		RetentionDays: 30,
+		GraceMinutes:  60,
+		RateLimit:     100,
+	}, ResolveEffectiveConfig("p1", "d1", &enabled, domain))
+
+	// without a domain config, it applies even if disabled
+	assert.Equal(t, EffectiveConfig{
+		ProjectID:     "p1",
+		DomainID:      "d1",
+		Source:        SourceProject,
+		TargetBucket:  "project-bucket",
+		Sinks:         project.Sinks,
+		Filter:        `event.action != "read"`,
+		RetentionDays: 30,
+		GraceMinutes:  60,
+		RateLimit:     100,
+	}, ResolveEffectiveConfig("p1", "d1", project, nil))
+
+	// otherwise the domain config is inherited as a single default sink
+	inherited := EffectiveConfig{
+		ProjectID:    "p1",
+		DomainID:     "d1",
+		Source:       SourceDomain,
+		Enabled:      true,
+		TargetBucket: "domain-bucket",
+		Sinks: []Sink{
+			{ProjectID: "p1", Name: DefaultSinkName, Enabled: true, Type: SinkS3, TargetBucket: "domain-bucket", UpdatedAt: now, UpdatedBy: "alice"},
+		},
+		Filter: `event.outcome == "failure"`,
+	}
+	assert.Equal(t, inherited, ResolveEffectiveConfig("p1", "d1", nil, domain))
+	assert.Equal(t, inherited, ResolveEffectiveConfig("p1", "d1", project, domain))
+
+	// a domain config without a bucket has no sinks
+	assert.Equal(t, []Sink{}, ResolveEffectiveConfig("p1", "d1", nil, &DomainConfig{DomainID: "d1"}).Sinks)
+
+	// without either, routing is disabled
+	assert.Equal(t, EffectiveConfig{ProjectID: "p1", Source: SourceDefault, Sinks: []Sink{}}, ResolveEffectiveConfig("p1", "", nil, nil))
+}
+
+func TestMockDomains(t *testing.T) {
+	ctx := t.Context()
+	m := NewMock()
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, m.UpsertDomain(ctx, DomainConfig{DomainID: "d1", Enabled: true, TargetBucket: "domain-bucket", UpdatedAt: now}))
+	require.NoError(t, m.SetDomainProjects(ctx, "d1", []string{"p1", "p2"}))
+	require.NoError(t, m.Upsert(ctx, DataplaneConfig{ProjectID: "p2", Enabled: false}))
+
+	// members without a config of their own inherit the domain config
+	cfg, err := m.EffectiveConfig(ctx, "p1", "")
+	require.NoError(t, err)
+	assert.Equal(t, SourceDomain, cfg.Source)
+	assert.Equal(t, "d1", cfg.DomainID)
+	assert.Equal(t, "domain-bucket", cfg.TargetBucket)
+	// so do members whose config has no enabled default sink, e.g. one that UpsertSink created
+	cfg, err = m.EffectiveConfig(ctx, "p2", "")
+	require.NoError(t, err)
+	assert.Equal(t, SourceDomain, cfg.Source)
+	require.NoError(t, m.UpsertSink(ctx, Sink{ProjectID: "p2", Name: "dr", Enabled: true, TargetBucket: "dr-bucket"}))
+	cfg, err = m.EffectiveConfig(ctx, "p2", "")
+	require.NoError(t, err)
+	assert.Equal(t, SourceDomain, cfg.Source)
+	assert.Equal(t, "domain-bucket", cfg.TargetBucket)
+	require.NoError(t, m.Upsert(ctx, DataplaneConfig{ProjectID: "p2", Enabled: true, TargetBucket: "project-bucket"}))
+	cfg, err = m.EffectiveConfig(ctx, "p2", "")
+	require.NoError(t, err)
+	assert.Equal(t, SourceProject, cfg.Source)
+	assert.Equal(t, "project-bucket", cfg.TargetBucket)
+	cfg, err = m.EffectiveConfig(ctx, "p3", "")
+	require.NoError(t, err)
+	assert.Equal(t, SourceDefault, cfg.Source)
+	// an explicit domain takes precedence over the recorded one
+	cfg, err = m.EffectiveConfig(ctx, "p3", "d1")
+	require.NoError(t, err)
+	assert.Equal(t, SourceDomain, cfg.Source)
+
+	// projects that join or leave the domain produce one change each
+	latest, err := m.LatestChange(ctx)
+	require.NoError(t, err)
+	require.NoError(t, m.SetDomainProjects(ctx, "d1", []string{"p2", "p3"}))
+	changes, err := m.Changes(ctx, latest, 10)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, "p1", changes[0].ProjectID)
+	assert.Equal(t, "p3", changes[1].ProjectID)
+	for _, change := range changes {
+		assert.Equal(t, ChangeMembership, change.Operation)
+		assert.Equal(t, "d1", change.DomainID)
+	}
+	cfg, err = m.EffectiveConfig(ctx, "p1", "")
+	require.NoError(t, err)
+	assert.Equal(t, SourceDefault, cfg.Source)
+
+	// deleting the domain config removes all members
+	latest, err = m.LatestChange(ctx)
+	require.NoError(t, err)
+	deleted, err := m.DeleteDomain(ctx, "d1")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	changes, err = m.Changes(ctx, latest, 10)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	assert.Equal(t, ChangeDomainDelete, changes[0].Operation)
+	cfg, err = m.EffectiveConfig(ctx, "p3", "")
+	require.NoError(t, err)
+	assert.Equal(t, SourceDefault, cfg.Source)
+	_, err = m.GetDomain(ctx, "d1")
+	assert.ErrorIs(t, err, ErrNotFound)
+	deleted, err = m.DeleteDomain(ctx, "d1")
+	require.NoError(t, err)
+	assert.False(t, deleted)
+}
+
+// staticLister implements ProjectLister with a fixed list of projects per domain.
+type staticLister map[string][]string
+
+func (l staticLister) ListProjects(_ context.Context, domainID string) ([]string, error) {
+	projectIDs, ok := l[domainID]
+	if !ok {
+		return nil, errors.New("no such domain")
+	}
+	return projectIDs, nil
+}
+
+func TestDomainSyncer(t *testing.T) {
+	ctx := t.Context()
+	m := NewMock()
+	for _, domainID := range []string{"d1", "d2", "d3"} {
+		require.NoError(t, m.UpsertDomain(ctx, DomainConfig{DomainID: domainID, Enabled: true, TargetBucket: "bucket-" + domainID}))
+	}
+
+	// failures of one domain do not stop the others
+	syncer := NewDomainSyncer(m, staticLister{"d1": {"p1"}, "d3": {"p3"}})
+	err := syncer.SyncAll(ctx)
+	assert.ErrorContains(t, err, "domain d2: no such domain")
+	for projectID, domainID := range map[string]string{"p1": "d1", "p3": "d3"} {
+		cfg, err := m.EffectiveConfig(ctx, projectID, "")
+		require.NoError(t, err)
+		assert.Equal(t, domainID, cfg.DomainID)
+		assert.Equal(t, "bucket-"+domainID, cfg.TargetBucket)
+	}
+}
@@ -0,0 +1,145 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+// Package filter evaluates the routing filter expressions of dataplane configs
+// (see routing.DataplaneConfig.Filter) against CADF events. It only depends on
+// CEL and the CADF types, so that log-router can import it to apply exactly
+// the same semantics that hermez validates and previews.
+//
+// Filters are CEL expressions (https://cel.dev) that evaluate to a bool. The
+// event is available as the map "event", with the JSON field names of CADF:
+//
+//	event.action != "read" && event.observer.typeURI == "service/storage/object"
+//	event.outcome == "failure" || event.target.typeURI.startsWith("storage/bucket")
+//
+// All fields listed in eventValue are always present (as empty strings when
+// unset), so expressions do not need to guard them with has().
+package filter
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/sapcc/go-api-declarations/cadf"
+)
+
+const (
+	// MaxLength is the maximum length of a filter expression in bytes.
+	MaxLength = 1024
+	// costLimit bounds the evaluation cost of a single event, so that an
+	// expensive filter cannot stall routing.
+	costLimit = 10000
+)
+
+var env = func() *cel.Env {
+	e, err := cel.NewEnv(cel.Variable("event", cel.MapType(cel.StringType, cel.DynType)))
+	if err != nil {
+		panic(err.Error())
+	}
+	return e
+}()
+
+// Program is a compiled filter expression. It is safe for concurrent use.
+type Program struct {
+	expr    string
+	program cel.Program
+}
+
+// Compile parses and type-checks a filter expression.
+func Compile(expr string) (*Program, error) {
+	if expr == "" {
+		return nil, errors.New("filter must not be empty")
+	}
+	if len(expr) > MaxLength {
+		return nil, fmt.Errorf("filter must be at most %d bytes long", MaxLength)
+	}
+	ast, issues := env.Compile(expr)
+	if issues.Err() != nil {
+		return nil, fmt.Errorf("invalid filter: %w", issues.Err())
+	}
+	if ast.OutputType() != cel.BoolType {
+		return nil, fmt.Errorf("filter must evaluate to a bool, not %s", ast.OutputType())
+	}
+	program, err := env.Program(ast, cel.CostLimit(costLimit))
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+	return &Program{expr: expr, program: program}, nil
+}
+
+// String returns the source of the expression.
+func (p *Program) String() string {
+	return p.expr
+}
+
+// Match reports whether the event passes the filter. When the expression
+// cannot be evaluated for an event (e.g. because it refers to an attribute
+// that does not exist), Match returns true together with the error: routing
+// an unwanted event is better than losing an audit event.
+func (p *Program) Match(event *cadf.Event) (bool, error) {
+	out, _, err := p.program.Eval(map[string]any{"event": eventValue(event)})
+	if err != nil {
+		return true, err
+	}
+	matched, ok := out.Value().(bool)
+	if !ok {
+		return true, fmt.Errorf("filter evaluated to %s instead of a bool", out.Type().TypeName())
+	}
+	return matched, nil
+}
+
+// eventValue converts the event into the value of the "event" variable.
+func eventValue(event *cadf.Event) map[string]any {
+	attachments := make([]any, len(event.Attachments))
+	for idx, a := range event.Attachments {
+		attachments[idx] = map[string]any{"name": a.Name, "typeURI": a.TypeURI, "content": contentValue(a.Content)}
+	}
+	return map[string]any{
+		"typeURI":     event.TypeURI,
+		"id":          event.ID,
+		"eventTime":   event.EventTime,
+		"eventType":   event.EventType,
+		"action":      string(event.Action),
+		"outcome":     string(event.Outcome),
+		"requestPath": event.RequestPath,
+		"reason": map[string]any{
+			"reasonType": event.Reason.ReasonType,
+			"reasonCode": event.Reason.ReasonCode,
+		},
+		"initiator":   resourceValue(event.Initiator),
+		"target":      resourceValue(event.Target),
+		"observer":    resourceValue(event.Observer),
+		"attachments": attachments,
+	}
+}
+
+func resourceValue(r cadf.Resource) map[string]any {
+	host := map[string]any{"id": "", "address": "", "agent": "", "platform": ""}
+	if r.Host != nil {
+		host = map[string]any{"id": r.Host.ID, "address": r.Host.Address, "agent": r.Host.Agent, "platform": r.Host.Platform}
+	}
+	return map[string]any{
+		"typeURI":             r.TypeURI,
+		"id":                  r.ID,
+		"name":                r.Name,
+		"domain":              r.Domain,
+		"project_id":          r.ProjectID,
+		"domain_id":           r.DomainID,
+		"project_name":        r.ProjectName,
+		"project_domain_name": r.ProjectDomainName,
+		"domain_name":         r.DomainName,
+		"host":                host,
+	}
+}
+
+// contentValue passes decoded JSON through and renders everything else as a
+// string, since CEL only understands JSON-like Go values.
+func contentValue(content any) any {
+	switch content.(type) {
+	case nil, string, bool, float64, map[string]any, []any:
+		return content
+	default:
+		return fmt.Sprint(content)
+	}
+}
@@ -0,0 +1,67 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package filter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile(t *testing.T) {
+	for _, tc := range []struct{ expr, err string }{
+		{"", "filter must not be empty"},
+		{strings.Repeat(" ", MaxLength+1), "filter must be at most 1024 bytes long"},
+		{`event.action ==`, "invalid filter"},
+		{`action == "read"`, "undeclared reference to 'action'"},
+		{`event.action`, "filter must evaluate to a bool"},
+		{`event.action != "read"`, ""},
+	} {
+		_, err := Compile(tc.expr)
+		if tc.err == "" {
+			assert.NoError(t, err, tc.expr)
+		} else {
+			assert.ErrorContains(t, err, tc.err, tc.expr)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	event := &cadf.Event{
+		Action:   "read/get",
+		Outcome:  cadf.SuccessOutcome,
+		Observer: cadf.Resource{TypeURI: "service/storage/object"},
+		Target:   cadf.Resource{TypeURI: "storage/object", Name: "backup.tar"},
+		Attachments: []cadf.Attachment{
+			{Name: "request", Content: map[string]any{"size": float64(42)}},
+		},
+	}
+	for _, tc := range []struct {
+		expr  string
+		match bool
+	}{
+		{`!event.action.startsWith("read")`, false},
+		{`event.action.startsWith("read") && event.outcome == "failure"`, false},
+		{`event.observer.typeURI == "service/storage/object" && event.target.name.endsWith(".tar")`, true},
+		// unset fields are empty instead of missing
+		{`event.initiator.name == "" && event.target.host.address == ""`, true},
+		{`event.attachments.exists(a, a.name == "request" && a.content.size > 40.0)`, true},
+	} {
+		p, err := Compile(tc.expr)
+		require.NoError(t, err, tc.expr)
+		matched, err := p.Match(event)
+		require.NoError(t, err, tc.expr)
+		assert.Equal(t, tc.match, matched, tc.expr)
+	}
+
+	// evaluation errors let the event through
+	p, err := Compile(`event.target.nmae == "backup.tar"`)
+	require.NoError(t, err)
+	matched, err := p.Match(event)
+	assert.True(t, matched)
+	assert.ErrorContains(t, err, "no such key: nmae")
+}
@@ -6,10 +6,25 @@ package routing
 import (
 	"context"
 	"errors"
+	"time"
 )
 
-// ErrNotFound is returned by Store.Get when no config exists for a project.
-var ErrNotFound = errors.New("routing: config not found for project")
+var (
+	// ErrNotFound is returned by Store.Get and Store.GetDomain when no config
+	// exists for a project or domain.
+	ErrNotFound = errors.New("routing: config not found")
+	// ErrVersionNotFound is returned by Store.Rollback when the project has no such version.
+	ErrVersionNotFound = errors.New("routing: config version not found for project")
+	// ErrVersionDeleted is returned by Store.Rollback when the version records a
+	// deletion, since there is no config to restore.
+	ErrVersionDeleted = errors.New("routing: config version records a deletion")
+	// ErrPreconditionFailed is returned by the conditional writes of Store when
+	// the Precondition does not hold.
+	ErrPreconditionFailed = errors.New("routing: precondition failed")
+	// ErrChangesExpired is returned by Store.Changes when changes after the
+	// requested position are no longer retained (see ChangeRetention).
+	ErrChangesExpired = errors.New("routing: changes are no longer retained")
+)
 
 // Store is the persistence interface for dataplane routing configuration.
 // The Postgres implementation is the production backend;
@@ -22,11 +37,96 @@ type Store interface {
 	// the default disabled config rather than an error visible to the client.
 	Get(ctx context.Context, projectID string) (*DataplaneConfig, error)
 
-	// Upsert creates or replaces the config for a project.
+	// Upsert creates or replaces the config for a project. Its Enabled and
+	// TargetBucket fields replace the sink named DefaultSinkName, which is
+	// removed if TargetBucket is empty. cfg.Sinks is ignored; other sinks
+	// are not affected.
 	Upsert(ctx context.Context, cfg DataplaneConfig) error
 
-	// Delete removes the config for a project.
+	// UpsertIf is like Upsert, but returns ErrPreconditionFailed without
+	// writing anything unless pre holds for the stored config.
+	UpsertIf(ctx context.Context, cfg DataplaneConfig, pre Precondition) error
+
+	// Update reads the config of a project (or DefaultDataplaneConfig if none
+	// is stored), lets update modify it, and writes the result like Upsert, all
+	// in one transaction. If update returns an error, nothing is written and
+	// Update returns that error. Returns the stored config, or
+	// ErrPreconditionFailed unless pre holds.
+	Update(ctx context.Context, projectID string, pre Precondition, update func(*DataplaneConfig) error) (*DataplaneConfig, error)
+
+	// Delete removes the config for a project, including all of its sinks.
 	// Returns (true, nil) if a config existed and was removed.
 	// Returns (false, nil) if no config existed (idempotent: not an error).
-	Delete(ctx context.Context, projectID string) (bool, error)
+	// deletedBy is recorded in the history.
+	Delete(ctx context.Context, projectID, deletedBy string) (bool, error)
+
+	// DeleteIf is like Delete, but returns ErrPreconditionFailed without
+	// deleting anything unless pre holds for the stored config.
+	DeleteIf(ctx context.Context, projectID, deletedBy string, pre Precondition) (bool, error)
+
+	// UpsertSink creates or replaces one sink of a project, creating the
+	// project's (disabled) config if necessary. Changes to the sink named
+	// DefaultSinkName are mirrored into the config's Enabled and TargetBucket.
+	// An empty Type is stored as SinkS3.
+	UpsertSink(ctx context.Context, sink Sink) error
+
+	// DeleteSink removes one sink of a project.
+	// Returns (true, nil) if the sink existed and was removed.
+	// Returns (false, nil) if it did not exist (idempotent: not an error).
+	// deletedBy is recorded in the history.
+	DeleteSink(ctx context.Context, projectID, name, deletedBy string) (bool, error)
+
+	// List returns up to limit stored configs (including their sinks) that
+	// match the filter, ordered by project ID and skipping the first offset
+	// ones, together with the total number of matching configs.
+	List(ctx context.Context, filter ListFilter, offset, limit int) ([]DataplaneConfig, int, error)
+
+	// History returns up to limit versions of the config of a project, newest
+	// first and skipping the newest offset versions, together with the total
+	// number of versions. All writes above record a version in the same
+	// transaction; no-op deletes do not.
+	History(ctx context.Context, projectID string, offset, limit int) ([]HistoryEntry, int, error)
+
+	// Rollback replaces the config of a project, including all of its sinks,
+	// with the config recorded in the given version, and records that as a new
+	// version. Returns the restored config. Returns ErrVersionNotFound or
+	// ErrVersionDeleted if there is nothing to restore.
+	Rollback(ctx context.Context, projectID string, version int64, updatedAt time.Time, updatedBy string) (*DataplaneConfig, error)
+
+	// Changes returns up to limit changes of all projects with a sequence
+	// number greater than since, oldest first. Returns ErrChangesExpired if
+	// some of these changes are no longer retained.
+	Changes(ctx context.Context, since int64, limit int) ([]Change, error)
+
+	// LatestChange returns the sequence number of the latest change, or 0 if
+	// there is none. Consumers that start from there and load all configs
+	// afterwards do not miss any change.
+	LatestChange(ctx context.Context) (int64, error)
+
+	// WaitForChanges blocks until there is a change with a sequence number
+	// greater than since, or until ctx is done.
+	WaitForChanges(ctx context.Context, since int64) error
+
+	// GetDomain retrieves the config for a domain.
+	// Returns ErrNotFound if none exists.
+	GetDomain(ctx context.Context, domainID string) (*DomainConfig, error)
+
+	// UpsertDomain creates or replaces the config for a domain.
+	UpsertDomain(ctx context.Context, cfg DomainConfig) error
+
+	// DeleteDomain removes the config for a domain and the recorded projects
+	// of the domain. Returns (true, nil) if a config existed and was removed.
+	DeleteDomain(ctx context.Context, domainID string) (bool, error)
+
+	// ListDomains returns the configs of all domains, ordered by domain ID.
+	ListDomains(ctx context.Context) ([]DomainConfig, error)
+
+	// SetDomainProjects records that the given projects (and no others)
+	// belong to a domain. Projects recorded for another domain are moved.
+	SetDomainProjects(ctx context.Context, domainID string, projectIDs []string) error
+
+	// EffectiveConfig returns the config that applies to a project (see
+	// EffectiveConfig). If domainID is empty, the domain recorded by
+	// SetDomainProjects is used.
+	EffectiveConfig(ctx context.Context, projectID, domainID string) (*EffectiveConfig, error)
 }
@@ -5,6 +5,9 @@ package routing
 
 import (
 	"context"
+	"maps"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 )
@@ -13,11 +16,25 @@ import (
 type Mock struct {
 	mu      sync.RWMutex
 	configs map[string]DataplaneConfig
+	// history holds the versions of each project, oldest first.
+	history map[string][]HistoryEntry
+	// changes holds the retained change feed, oldest first.
+	changes      []Change
+	lastSequence int64
+	signal       changeSignal
+	domains      map[string]DomainConfig
+	// domainOf holds the recorded domain of each project.
+	domainOf map[string]string
 }
 
 // NewMock creates an empty Mock store.
 func NewMock() *Mock {
-	return &Mock{configs: make(map[string]DataplaneConfig)}
+	return &Mock{
+		configs:  make(map[string]DataplaneConfig),
+		history:  make(map[string][]HistoryEntry),
+		domains:  make(map[string]DomainConfig),
+		domainOf: make(map[string]string),
+	}
 }
 
 // Get retrieves the config for a project.
@@ -29,29 +46,365 @@ func (m *Mock) Get(_ context.Context, projectID string) (*DataplaneConfig, error
 		return nil, ErrNotFound
 	}
 	c := cfg
+	c.Sinks = slices.Clone(cfg.Sinks)
 	return &c, nil
 }
 
 // Upsert creates or replaces the config for a project.
-func (m *Mock) Upsert(_ context.Context, cfg DataplaneConfig) error {
+func (m *Mock) Upsert(ctx context.Context, cfg DataplaneConfig) error {
+	return m.UpsertIf(ctx, cfg, Precondition{})
+}
+
+// UpsertIf creates or replaces the config for a project if pre holds.
+func (m *Mock) UpsertIf(_ context.Context, cfg DataplaneConfig, pre Precondition) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !pre.Check(m.stored(cfg.ProjectID)) {
+		return ErrPreconditionFailed
+	}
+	m.upsert(cfg)
+	return nil
+}
+
+// Update modifies the config for a project atomically.
+func (m *Mock) Update(_ context.Context, projectID string, pre Precondition, update func(*DataplaneConfig) error) (*DataplaneConfig, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	stored := m.stored(projectID)
+	if !pre.Check(stored) {
+		return nil, ErrPreconditionFailed
+	}
+	cfg := DefaultDataplaneConfig(projectID)
+	if stored != nil {
+		cfg = *stored
+		cfg.Sinks = slices.Clone(stored.Sinks)
+	}
+	if err := update(&cfg); err != nil {
+		return nil, err
+	}
+	cfg.ProjectID = projectID
+	m.upsert(cfg)
+	c := m.configs[projectID]
+	c.Sinks = slices.Clone(c.Sinks)
+	return &c, nil
+}
+
+// upsert writes a config like Upsert. The caller must hold m.mu.
+func (m *Mock) upsert(cfg DataplaneConfig) {
 	if cfg.UpdatedAt.IsZero() {
 		cfg.UpdatedAt = time.Now().UTC()
 	}
+	cfg.Sinks = m.configs[cfg.ProjectID].Sinks
+	if cfg.TargetBucket == "" {
+		cfg.Sinks = deleteSink(cfg.Sinks, DefaultSinkName)
+	} else {
+		cfg.Sinks = upsertSink(cfg.Sinks, Sink{
+			ProjectID:    cfg.ProjectID,
+			Name:         DefaultSinkName,
+			Enabled:      cfg.Enabled,
+			Type:         SinkS3,
+			TargetBucket: cfg.TargetBucket,
+			UpdatedAt:    cfg.UpdatedAt,
+			UpdatedBy:    cfg.UpdatedBy,
+		})
+	}
 	m.configs[cfg.ProjectID] = cfg
-	return nil
+	m.recordHistory(cfg.ProjectID, HistoryUpdate, 0, cfg.UpdatedAt, cfg.UpdatedBy)
 }
 
 // Delete removes the config for a project. Idempotent.
 // Returns (true, nil) if a config existed and was removed; (false, nil) if none existed.
-func (m *Mock) Delete(_ context.Context, projectID string) (bool, error) {
+func (m *Mock) Delete(ctx context.Context, projectID, deletedBy string) (bool, error) {
+	return m.DeleteIf(ctx, projectID, deletedBy, Precondition{})
+}
+
+// DeleteIf removes the config for a project if pre holds.
+func (m *Mock) DeleteIf(_ context.Context, projectID, deletedBy string, pre Precondition) (bool, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	if !pre.Check(m.stored(projectID)) {
+		return false, ErrPreconditionFailed
+	}
 	_, existed := m.configs[projectID]
-	delete(m.configs, projectID)
+	if existed {
+		delete(m.configs, projectID)
+		m.recordHistory(projectID, HistoryDelete, 0, time.Now().UTC(), deletedBy)
+	}
 	return existed, nil
 }
 
+// UpsertSink creates or replaces one sink of a project.
+func (m *Mock) UpsertSink(_ context.Context, sink Sink) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if sink.UpdatedAt.IsZero() {
+		sink.UpdatedAt = time.Now().UTC()
+	}
+	if sink.Type == "" {
+		sink.Type = SinkS3
+	}
+	cfg, ok := m.configs[sink.ProjectID]
+	if !ok {
+		cfg = DataplaneConfig{ProjectID: sink.ProjectID, UpdatedAt: sink.UpdatedAt, UpdatedBy: sink.UpdatedBy}
+	}
+	cfg.Sinks = upsertSink(cfg.Sinks, sink)
+	if sink.Name == DefaultSinkName {
+		cfg.Enabled, cfg.TargetBucket = sink.Enabled, sink.TargetBucket
+		cfg.UpdatedAt, cfg.UpdatedBy = sink.UpdatedAt, sink.UpdatedBy
+	}
+	m.configs[sink.ProjectID] = cfg
+	m.recordHistory(sink.ProjectID, HistorySinkUpdate, 0, sink.UpdatedAt, sink.UpdatedBy)
+	return nil
+}
+
+// DeleteSink removes one sink of a project. Idempotent.
+func (m *Mock) DeleteSink(_ context.Context, projectID, name, deletedBy string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cfg, ok := m.configs[projectID]
+	if !ok || cfg.Sink(name) == nil {
+		return false, nil
+	}
+	cfg.Sinks = deleteSink(cfg.Sinks, name)
+	if name == DefaultSinkName {
+		cfg.Enabled, cfg.TargetBucket = false, ""
+	}
+	m.configs[projectID] = cfg
+	m.recordHistory(projectID, HistorySinkDelete, 0, time.Now().UTC(), deletedBy)
+	return true, nil
+}
+
+// List returns the configs that match the filter, ordered by project ID.
+func (m *Mock) List(_ context.Context, filter ListFilter, offset, limit int) ([]DataplaneConfig, int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var matches []DataplaneConfig
+	for _, projectID := range slices.Sorted(maps.Keys(m.configs)) {
+		cfg := m.configs[projectID]
+		if filter.Matches(cfg) {
+			cfg.Sinks = slices.Clone(cfg.Sinks)
+			matches = append(matches, cfg)
+		}
+	}
+	configs := []DataplaneConfig{}
+	if offset < len(matches) {
+		configs = append(configs, matches[offset:min(offset+limit, len(matches))]...)
+	}
+	return configs, len(matches), nil
+}
+
+// History returns versions of the config of a project, newest first.
+func (m *Mock) History(_ context.Context, projectID string, offset, limit int) ([]HistoryEntry, int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	history := m.history[projectID]
+	entries := []HistoryEntry{}
+	for idx := len(history) - 1 - offset; idx >= 0 && len(entries) < limit; idx-- {
+		entries = append(entries, history[idx])
+	}
+	return entries, len(history), nil
+}
+
+// Rollback restores the config recorded in a prior version.
+func (m *Mock) Rollback(_ context.Context, projectID string, version int64, updatedAt time.Time, updatedBy string) (*DataplaneConfig, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	history := m.history[projectID]
+	if version < 1 || version > int64(len(history)) {
+		return nil, ErrVersionNotFound
+	}
+	old := history[version-1].Config
+	if old == nil {
+		return nil, ErrVersionDeleted
+	}
+	cfg := *old
+	cfg.UpdatedAt, cfg.UpdatedBy = updatedAt, updatedBy
+	cfg.Sinks = slices.Clone(old.Sinks)
+	for idx := range cfg.Sinks {
+		cfg.Sinks[idx].UpdatedAt, cfg.Sinks[idx].UpdatedBy = updatedAt, updatedBy
+	}
+	m.configs[projectID] = cfg
+	m.recordHistory(projectID, HistoryRollback, version, updatedAt, updatedBy)
+	c := m.configs[projectID]
+	c.Sinks = slices.Clone(cfg.Sinks)
+	return &c, nil
+}
+
+// stored returns the config of a project, or nil. The caller must hold m.mu.
+func (m *Mock) stored(projectID string) *DataplaneConfig {
+	cfg, ok := m.configs[projectID]
+	if !ok {
+		return nil
+	}
+	return &cfg
+}
+
+// recordHistory appends the current config of a project (or its deletion) as
+// the next version, and stores that version in the config. The caller must hold m.mu.
+func (m *Mock) recordHistory(projectID string, op HistoryOperation, restoredVersion int64, changedAt time.Time, changedBy string) {
+	entry := HistoryEntry{
+		Version:         int64(len(m.history[projectID]) + 1),
+		Operation:       op,
+		RestoredVersion: restoredVersion,
+		ChangedAt:       changedAt,
+		ChangedBy:       changedBy,
+	}
+	if cfg, ok := m.configs[projectID]; ok && op != HistoryDelete {
+		cfg.Version = entry.Version
+		m.configs[projectID] = cfg
+		entry.Config = &cfg
+		entry.Config.Sinks = slices.Clone(cfg.Sinks)
+		if entry.Config.Sinks == nil {
+			entry.Config.Sinks = []Sink{}
+		}
+	}
+	m.history[projectID] = append(m.history[projectID], entry)
+
+	change := Change{ProjectID: projectID, Operation: ChangeUpsert, Version: entry.Version, ChangedAt: changedAt}
+	if op == HistoryDelete {
+		change.Operation, change.Version = ChangeDelete, entry.Version-1
+	}
+	m.recordChange(change)
+}
+
+// recordChange appends a change to the change feed. The caller must hold m.mu.
+func (m *Mock) recordChange(change Change) {
+	m.lastSequence++
+	change.Sequence = m.lastSequence
+	m.changes = append(m.changes, change)
+	m.signal.broadcast()
+}
+
+// GetDomain implements Store.
+func (m *Mock) GetDomain(_ context.Context, domainID string) (*DomainConfig, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	cfg, ok := m.domains[domainID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &cfg, nil
+}
+
+// UpsertDomain implements Store.
+func (m *Mock) UpsertDomain(_ context.Context, cfg DomainConfig) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.domains[cfg.DomainID] = cfg
+	m.recordChange(Change{DomainID: cfg.DomainID, Operation: ChangeDomainUpsert, ChangedAt: cfg.UpdatedAt})
+	return nil
+}
+
+// DeleteDomain implements Store.
+func (m *Mock) DeleteDomain(_ context.Context, domainID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, existed := m.domains[domainID]
+	if !existed {
+		return false, nil
+	}
+	delete(m.domains, domainID)
+	now := time.Now().UTC()
+	m.recordChange(Change{DomainID: domainID, Operation: ChangeDomainDelete, ChangedAt: now})
+	m.setDomainProjects(domainID, nil, now)
+	return true, nil
+}
+
+// ListDomains implements Store.
+func (m *Mock) ListDomains(_ context.Context) ([]DomainConfig, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := []DomainConfig{}
+	for _, id := range slices.Sorted(maps.Keys(m.domains)) {
+		result = append(result, m.domains[id])
+	}
+	return result, nil
+}
+
+// SetDomainProjects implements Store.
+func (m *Mock) SetDomainProjects(_ context.Context, domainID string, projectIDs []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.setDomainProjects(domainID, projectIDs, time.Now().UTC())
+	return nil
+}
+
+// setDomainProjects records the projects of a domain and a change for every
+// project that joins or leaves it. The caller must hold m.mu.
+func (m *Mock) setDomainProjects(domainID string, projectIDs []string, now time.Time) {
+	for _, projectID := range slices.Sorted(maps.Keys(m.domainOf)) {
+		if m.domainOf[projectID] == domainID && !slices.Contains(projectIDs, projectID) {
+			delete(m.domainOf, projectID)
+			m.recordChange(Change{ProjectID: projectID, DomainID: domainID, Operation: ChangeMembership, ChangedAt: now})
+		}
+	}
+	for _, projectID := range projectIDs {
+		if m.domainOf[projectID] != domainID {
+			m.domainOf[projectID] = domainID
+			m.recordChange(Change{ProjectID: projectID, DomainID: domainID, Operation: ChangeMembership, ChangedAt: now})
+		}
+	}
+}
+
+// EffectiveConfig implements Store.
+func (m *Mock) EffectiveConfig(ctx context.Context, projectID, domainID string) (*EffectiveConfig, error) {
+	return effectiveConfig(ctx, m, projectID, domainID, func(context.Context, string) (string, error) {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+		return m.domainOf[projectID], nil
+	})
+}
+
+// Changes implements Store.
+func (m *Mock) Changes(_ context.Context, since int64, limit int) ([]Change, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if len(m.changes) > 0 && since < m.changes[0].Sequence-1 {
+		return nil, ErrChangesExpired
+	}
+	changes := []Change{}
+	for _, c := range m.changes {
+		if c.Sequence > since && len(changes) < limit {
+			changes = append(changes, c)
+		}
+	}
+	return changes, nil
+}
+
+// LatestChange implements Store.
+func (m *Mock) LatestChange(_ context.Context) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.lastSequence, nil
+}
+
+// WaitForChanges implements Store.
+func (m *Mock) WaitForChanges(ctx context.Context, since int64) error {
+	return waitForChanges(ctx, &m.signal, since, m.LatestChange)
+}
+
+// ExpireChanges drops all changes up to the given sequence number from the
+// change feed, like the retention of the Postgres store does. The latest
+// change is always retained.
+func (m *Mock) ExpireChanges(sequence int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.changes = slices.DeleteFunc(m.changes, func(c Change) bool {
+		return c.Sequence <= sequence && c.Sequence < m.lastSequence
+	})
+}
+
+// upsertSink returns a copy of sinks with the given sink added or replaced, sorted by name.
+func upsertSink(sinks []Sink, sink Sink) []Sink {
+	result := append(deleteSink(sinks, sink.Name), sink)
+	slices.SortFunc(result, func(a, b Sink) int { return strings.Compare(a.Name, b.Name) })
+	return result
+}
+
+// deleteSink returns a copy of sinks without the sink of the given name.
+func deleteSink(sinks []Sink, name string) []Sink {
+	return slices.DeleteFunc(slices.Clone(sinks), func(s Sink) bool { return s.Name == name })
+}
+
 // Ensure Mock implements Store.
 var _ Store = (*Mock)(nil)
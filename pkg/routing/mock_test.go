@@ -0,0 +1,165 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package routing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMockSinks(t *testing.T) {
+	ctx := t.Context()
+	m := NewMock()
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	// the single-sink view of the config is mirrored into the default sink
+	require.NoError(t, m.Upsert(ctx, DataplaneConfig{ProjectID: "p1", Enabled: true, TargetBucket: "primary", UpdatedAt: now, UpdatedBy: "alice"}))
+	require.NoError(t, m.UpsertSink(ctx, Sink{ProjectID: "p1", Name: "dr", Enabled: true, TargetBucket: "dr-bucket", UpdatedAt: now, UpdatedBy: "bob"}))
+	cfg, err := m.Get(ctx, "p1")
+	require.NoError(t, err)
+	assert.Equal(t, []Sink{
+		{ProjectID: "p1", Name: DefaultSinkName, Enabled: true, Type: SinkS3, TargetBucket: "primary", UpdatedAt: now, UpdatedBy: "alice"},
+		{ProjectID: "p1", Name: "dr", Enabled: true, Type: SinkS3, TargetBucket: "dr-bucket", UpdatedAt: now, UpdatedBy: "bob"},
+	}, cfg.Sinks)
+
+	// writes of the config keep the other sinks
+	require.NoError(t, m.Upsert(ctx, DataplaneConfig{ProjectID: "p1", Enabled: false, TargetBucket: "primary", UpdatedAt: now}))
+	cfg, err = m.Get(ctx, "p1")
+	require.NoError(t, err)
+	assert.Len(t, cfg.Sinks, 2)
+	assert.False(t, cfg.Sink(DefaultSinkName).Enabled)
+	assert.True(t, cfg.Sink("dr").Enabled)
+
+	// and the default sink is mirrored back into the single-sink view
+	require.NoError(t, m.UpsertSink(ctx, Sink{ProjectID: "p1", Name: DefaultSinkName, Enabled: true, TargetBucket: "new-primary"}))
+	cfg, err = m.Get(ctx, "p1")
+	require.NoError(t, err)
+	assert.True(t, cfg.Enabled)
+	assert.Equal(t, "new-primary", cfg.TargetBucket)
+
+	deleted, err := m.DeleteSink(ctx, "p1", DefaultSinkName, "alice")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	cfg, err = m.Get(ctx, "p1")
+	require.NoError(t, err)
+	assert.False(t, cfg.Enabled)
+	assert.Empty(t, cfg.TargetBucket)
+	assert.Nil(t, cfg.Sink(DefaultSinkName))
+	assert.NotNil(t, cfg.Sink("dr"))
+
+	deleted, err = m.DeleteSink(ctx, "p1", DefaultSinkName, "alice")
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	// the returned configs do not share their sinks with the store
+	cfg.Sinks[0].TargetBucket = "modified"
+	cfg, err = m.Get(ctx, "p1")
+	require.NoError(t, err)
+	assert.Equal(t, "dr-bucket", cfg.Sink("dr").TargetBucket)
+
+	// a config without a target bucket has no default sink
+	require.NoError(t, m.Upsert(ctx, DataplaneConfig{ProjectID: "p2", Enabled: false}))
+	cfg, err = m.Get(ctx, "p2")
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Sinks)
+}
+
+func TestMockHistoryAndRollback(t *testing.T) {
+	ctx := t.Context()
+	m := NewMock()
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	require.NoError(t, m.Upsert(ctx, DataplaneConfig{ProjectID: "p1", Enabled: true, TargetBucket: "first", UpdatedAt: now}))
+	require.NoError(t, m.UpsertSink(ctx, Sink{ProjectID: "p1", Name: "dr", Enabled: true, TargetBucket: "dr-bucket", UpdatedAt: now}))
+	require.NoError(t, m.Upsert(ctx, DataplaneConfig{ProjectID: "p1", Enabled: true, TargetBucket: "second", UpdatedAt: now}))
+	_, err := m.Delete(ctx, "p1", "alice")
+	require.NoError(t, err)
+
+	// every write records a version, newest first
+	entries, total, err := m.History(ctx, "p1", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 4, total)
+	var ops []HistoryOperation
+	for idx, entry := range entries {
+		assert.Equal(t, int64(4-idx), entry.Version)
+		ops = append(ops, entry.Operation)
+	}
+	assert.Equal(t, []HistoryOperation{HistoryDelete, HistoryUpdate, HistorySinkUpdate, HistoryUpdate}, ops)
+	assert.Nil(t, entries[0].Config)
+	assert.Equal(t, "second", entries[1].Config.TargetBucket)
+	assert.Len(t, entries[2].Config.Sinks, 2)
+
+	entries, total, err = m.History(ctx, "p1", 3, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 4, total)
+	require.Len(t, entries, 1)
+	assert.Equal(t, int64(1), entries[0].Version)
+
+	// rollback restores a version, including its sinks, as a new version
+	later := now.Add(time.Hour)
+	cfg, err := m.Rollback(ctx, "p1", 2, later, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), cfg.Version)
+	assert.Equal(t, "first", cfg.TargetBucket)
+	assert.Equal(t, "bob", cfg.UpdatedBy)
+	require.Len(t, cfg.Sinks, 2)
+	for _, sink := range cfg.Sinks {
+		assert.Equal(t, later, sink.UpdatedAt)
+	}
+	entries, _, err = m.History(ctx, "p1", 0, 1)
+	require.NoError(t, err)
+	assert.Equal(t, HistoryRollback, entries[0].Operation)
+	assert.Equal(t, int64(2), entries[0].RestoredVersion)
+
+	// the stored config carries the latest version, which is never reused
+	_, err = m.Delete(ctx, "p1", "alice")
+	require.NoError(t, err)
+	require.NoError(t, m.Upsert(ctx, DataplaneConfig{ProjectID: "p1", UpdatedAt: now}))
+	cfg, err = m.Get(ctx, "p1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), cfg.Version)
+
+	_, err = m.Rollback(ctx, "p1", 4, later, "bob")
+	assert.ErrorIs(t, err, ErrVersionDeleted)
+	_, err = m.Rollback(ctx, "p1", 8, later, "bob")
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+	_, err = m.Rollback(ctx, "p1", 0, later, "bob")
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+}
+
+func TestMockPreconditions(t *testing.T) {
+	ctx := t.Context()
+	m := NewMock()
+
+	require.ErrorIs(t, m.UpsertIf(ctx, DataplaneConfig{ProjectID: "p1"}, Precondition{IfExists: true}), ErrPreconditionFailed)
+	require.NoError(t, m.UpsertIf(ctx, DataplaneConfig{ProjectID: "p1"}, Precondition{IfNotExists: true}))
+	require.ErrorIs(t, m.UpsertIf(ctx, DataplaneConfig{ProjectID: "p1"}, Precondition{IfNotExists: true}), ErrPreconditionFailed)
+	require.NoError(t, m.UpsertIf(ctx, DataplaneConfig{ProjectID: "p1", Enabled: false}, Precondition{IfVersion: []int64{1}}))
+
+	// the update function sees the stored config and can reject the write
+	cfg, err := m.Update(ctx, "p1", Precondition{IfVersion: []int64{2}}, func(cfg *DataplaneConfig) error {
+		assert.Equal(t, int64(2), cfg.Version)
+		cfg.Filter = `event.action != "read"`
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), cfg.Version)
+	_, err = m.Update(ctx, "p1", Precondition{IfVersion: []int64{2}}, func(*DataplaneConfig) error { return nil })
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+	_, err = m.Update(ctx, "p1", Precondition{}, func(*DataplaneConfig) error { return ErrNotFound })
+	assert.ErrorIs(t, err, ErrNotFound)
+	cfg, err = m.Get(ctx, "p1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), cfg.Version)
+
+	deleted, err := m.DeleteIf(ctx, "p1", "alice", Precondition{IfVersion: []int64{1}})
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+	assert.False(t, deleted)
+	deleted, err = m.DeleteIf(ctx, "p1", "alice", Precondition{IfVersion: []int64{3}})
+	require.NoError(t, err)
+	assert.True(t, deleted)
+}
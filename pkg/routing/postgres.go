@@ -6,21 +6,25 @@ package routing
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
 
-	"github.com/sapcc/go-api-declarations/bininfo"
 	"github.com/sapcc/go-bits/logg"
-	"github.com/sapcc/go-bits/osext"
 	"go.xyrillian.de/gg/gsql"
 	"go.xyrillian.de/gg/pgruntime"
 
-	// load DB driver
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
-// DBMigrations contains the SQL migrations for the dataplane_config table.
-// The keys are the schema versions. They need not be contiguous, but must be in ascending order.
+// DBMigrations contains the SQL migrations for the tables of this package in
+// the hermez database (see package database). The keys are the schema versions.
+// They need not be contiguous, but must be in ascending order and must not be
+// used by the migrations of other packages.
 var DBMigrations = map[int64]string{
 	1: `
 		CREATE TABLE IF NOT EXISTS dataplane_config (
@@ -37,99 +41,1040 @@ var DBMigrations = map[int64]string{
 		-- in the chart, not in app migrations.
 		GRANT SELECT ON dataplane_config TO "log-router";
 	`,
+	7: `
+		-- Named routing sinks per project. The enabled and target_bucket columns
+		-- of dataplane_config mirror the sink named 'default', so that log-router
+		-- versions that only know one bucket per project keep working.
+		CREATE TABLE IF NOT EXISTS dataplane_sinks (
+			project_id    VARCHAR(64) NOT NULL REFERENCES dataplane_config (project_id) ON DELETE CASCADE,
+			name          VARCHAR(32) NOT NULL,
+			enabled       BOOLEAN     NOT NULL DEFAULT FALSE,
+			target_bucket TEXT        NOT NULL,
+			updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			updated_by    VARCHAR(64) NOT NULL DEFAULT '',
+			PRIMARY KEY (project_id, name)
+		);
+
+		INSERT INTO dataplane_sinks (project_id, name, enabled, target_bucket, updated_at, updated_by)
+		SELECT project_id, 'default', enabled, target_bucket, updated_at, updated_by
+		  FROM dataplane_config WHERE target_bucket <> ''
+		    ON CONFLICT DO NOTHING;
+
+		GRANT SELECT ON dataplane_sinks TO "log-router";
+	`,
+	8: `
+		-- Optional CEL expression selecting the events routed into the sinks (see package routing/filter).
+		ALTER TABLE dataplane_config ADD COLUMN IF NOT EXISTS filter TEXT NOT NULL DEFAULT '';
+	`,
+	9: `
+		-- Every version of the config of every project (see routing.HistoryEntry).
+		-- There is no foreign key, so the history survives deletes. config is the
+		-- JSON of routing.DataplaneConfig, or NULL for a deletion. Log-router does
+		-- not need this table, so it is not granted.
+		CREATE TABLE IF NOT EXISTS dataplane_config_history (
+			project_id       VARCHAR(64) NOT NULL,
+			version          BIGINT      NOT NULL,
+			operation        VARCHAR(16) NOT NULL,
+			config           JSONB,
+			restored_version BIGINT,
+			changed_at       TIMESTAMPTZ NOT NULL,
+			changed_by       VARCHAR(64) NOT NULL DEFAULT '',
+			PRIMARY KEY (project_id, version)
+		);
+
+		-- Existing configs become version 1, so that they can be rolled back to.
+		INSERT INTO dataplane_config_history (project_id, version, operation, config, changed_at, changed_by)
+		SELECT c.project_id, 1, 'update', json_build_object(
+		           'project_id', c.project_id, 'enabled', c.enabled, 'target_bucket', c.target_bucket,
+		           'filter', c.filter, 'updated_at', c.updated_at, 'updated_by', c.updated_by,
+		           'sinks', COALESCE((
+		               SELECT json_agg(json_build_object(
+		                          'name', s.name, 'enabled', s.enabled, 'target_bucket', s.target_bucket,
+		                          'updated_at', s.updated_at, 'updated_by', s.updated_by) ORDER BY s.name)
+		                 FROM dataplane_sinks s WHERE s.project_id = c.project_id), '[]')),
+		       c.updated_at, c.updated_by
+		  FROM dataplane_config c
+		    ON CONFLICT DO NOTHING;
+	`,
+	10: `
+		-- The number of the history version that recorded the last write, used as ETag.
+		ALTER TABLE dataplane_config ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 0;
+		UPDATE dataplane_config c SET version = h.version
+		  FROM (SELECT project_id, MAX(version) AS version FROM dataplane_config_history GROUP BY project_id) h
+		 WHERE h.project_id = c.project_id;
+	`,
+	11: `
+		-- The change feed of all configs (see routing.Change). Every write through
+		-- routing.Store sets dataplane_config.version exactly once, so the trigger
+		-- records one change per write. The transaction-scoped advisory lock
+		-- (0x6865726d65730004) serializes the writers from the change until their
+		-- commit, so that sequence numbers become visible in ascending order and
+		-- consumers can resume after the last sequence that they have seen.
+		CREATE TABLE IF NOT EXISTS dataplane_config_changes (
+			sequence   BIGSERIAL   NOT NULL PRIMARY KEY,
+			project_id VARCHAR(64) NOT NULL,
+			operation  VARCHAR(16) NOT NULL,
+			version    BIGINT      NOT NULL,
+			changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+
+		CREATE OR REPLACE FUNCTION dataplane_config_notify() RETURNS trigger AS $$
+		DECLARE
+			seq BIGINT;
+		BEGIN
+			IF TG_OP = 'UPDATE' AND OLD.version = NEW.version THEN
+				RETURN NULL;
+			END IF;
+			PERFORM pg_advisory_xact_lock(7522544566771318788);
+			IF TG_OP = 'DELETE' THEN
+				INSERT INTO dataplane_config_changes (project_id, operation, version)
+				VALUES (OLD.project_id, 'delete', OLD.version) RETURNING sequence INTO seq;
+			ELSE
+				INSERT INTO dataplane_config_changes (project_id, operation, version)
+				VALUES (NEW.project_id, 'upsert', NEW.version) RETURNING sequence INTO seq;
+			END IF;
+			-- keep 30 days (routing.ChangeRetention), but always the latest change
+			DELETE FROM dataplane_config_changes
+			 WHERE changed_at < NOW() - INTERVAL '30 days' AND sequence < seq;
+			PERFORM pg_notify('dataplane_config_changes', seq::text);
+			RETURN NULL;
+		END;
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS dataplane_config_notify ON dataplane_config;
+		CREATE TRIGGER dataplane_config_notify
+			AFTER UPDATE OF version OR DELETE ON dataplane_config
+			FOR EACH ROW EXECUTE FUNCTION dataplane_config_notify();
+
+		GRANT SELECT ON dataplane_config_changes TO "log-router";
+	`,
+	12: `
+		-- Domain configs apply to all projects of a domain without a config of
+		-- their own (see routing.EffectiveConfig). The projects of each domain
+		-- are recorded by routing.DomainSyncer, since log-router does not know them.
+		CREATE TABLE IF NOT EXISTS dataplane_domain_config (
+			domain_id     VARCHAR(64) NOT NULL PRIMARY KEY,
+			enabled       BOOLEAN     NOT NULL DEFAULT FALSE,
+			target_bucket TEXT        NOT NULL DEFAULT '',
+			filter        TEXT        NOT NULL DEFAULT '',
+			updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			updated_by    VARCHAR(64) NOT NULL DEFAULT ''
+		);
+		CREATE TABLE IF NOT EXISTS dataplane_domain_projects (
+			project_id VARCHAR(64) NOT NULL PRIMARY KEY,
+			domain_id  VARCHAR(64) NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS dataplane_domain_projects_domain_id ON dataplane_domain_projects (domain_id);
+
+		-- Changes of domains and of their projects go into the change feed of
+		-- migration 11, with the same advisory lock and retention.
+		ALTER TABLE dataplane_config_changes ADD COLUMN IF NOT EXISTS domain_id VARCHAR(64) NOT NULL DEFAULT '';
+		CREATE OR REPLACE FUNCTION dataplane_domain_notify() RETURNS trigger AS $$
+		DECLARE
+			seq BIGINT;
+		BEGIN
+			PERFORM pg_advisory_xact_lock(7522544566771318788);
+			IF TG_TABLE_NAME = 'dataplane_domain_projects' THEN
+				IF TG_OP = 'UPDATE' AND OLD.domain_id = NEW.domain_id THEN
+					RETURN NULL;
+				END IF;
+				IF TG_OP = 'INSERT' THEN
+					INSERT INTO dataplane_config_changes (project_id, domain_id, operation, version)
+					VALUES (NEW.project_id, NEW.domain_id, 'membership', 0) RETURNING sequence INTO seq;
+				ELSE
+					INSERT INTO dataplane_config_changes (project_id, domain_id, operation, version)
+					VALUES (OLD.project_id, OLD.domain_id, 'membership', 0) RETURNING sequence INTO seq;
+				END IF;
+				IF TG_OP = 'UPDATE' THEN
+					INSERT INTO dataplane_config_changes (project_id, domain_id, operation, version)
+					VALUES (NEW.project_id, NEW.domain_id, 'membership', 0) RETURNING sequence INTO seq;
+				END IF;
+			ELSIF TG_OP = 'DELETE' THEN
+				INSERT INTO dataplane_config_changes (project_id, domain_id, operation, version)
+				VALUES ('', OLD.domain_id, 'domain_delete', 0) RETURNING sequence INTO seq;
+			ELSE
+				INSERT INTO dataplane_config_changes (project_id, domain_id, operation, version)
+				VALUES ('', NEW.domain_id, 'domain_upsert', 0) RETURNING sequence INTO seq;
+			END IF;
+			DELETE FROM dataplane_config_changes
+			 WHERE changed_at < NOW() - INTERVAL '30 days' AND sequence < seq;
+			PERFORM pg_notify('dataplane_config_changes', seq::text);
+			RETURN NULL;
+		END;
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS dataplane_domain_notify ON dataplane_domain_config;
+		CREATE TRIGGER dataplane_domain_notify
+			AFTER INSERT OR UPDATE OR DELETE ON dataplane_domain_config
+			FOR EACH ROW EXECUTE FUNCTION dataplane_domain_notify();
+		DROP TRIGGER IF EXISTS dataplane_domain_notify ON dataplane_domain_projects;
+		CREATE TRIGGER dataplane_domain_notify
+			AFTER INSERT OR UPDATE OR DELETE ON dataplane_domain_projects
+			FOR EACH ROW EXECUTE FUNCTION dataplane_domain_notify();
+
+		-- The single-sink view of the effective config of every project that has
+		-- a config of its own or belongs to a domain with a config. Projects
+		-- without a row here are disabled.
+		CREATE OR REPLACE VIEW dataplane_effective_config AS
+		SELECT c.project_id, COALESCE(m.domain_id, '') AS domain_id, 'project' AS source,
+		       c.enabled, c.target_bucket, c.filter
+		  FROM dataplane_config c
+		  LEFT JOIN dataplane_domain_projects m ON m.project_id = c.project_id
+		 UNION ALL
+		SELECT m.project_id, d.domain_id, 'domain' AS source,
+		       d.enabled, d.target_bucket, d.filter
+		  FROM dataplane_domain_projects m
+		  JOIN dataplane_domain_config d ON d.domain_id = m.domain_id
+		 WHERE NOT EXISTS (SELECT 1 FROM dataplane_config c WHERE c.project_id = m.project_id);
+
+		GRANT SELECT ON dataplane_domain_config, dataplane_domain_projects, dataplane_effective_config TO "log-router";
+	`,
+	13: `
+		-- Retention is enforced by hermez (see package retention), the rate limit by log-router.
+		ALTER TABLE dataplane_config ADD COLUMN IF NOT EXISTS retention_days INTEGER NOT NULL DEFAULT 0;
+		ALTER TABLE dataplane_config ADD COLUMN IF NOT EXISTS rate_limit INTEGER NOT NULL DEFAULT 0;
+
+		-- new columns of a view can only be appended
+		CREATE OR REPLACE VIEW dataplane_effective_config AS
+		SELECT c.project_id, COALESCE(m.domain_id, '') AS domain_id, 'project' AS source,
+		       c.enabled, c.target_bucket, c.filter, c.rate_limit
+		  FROM dataplane_config c
+		  LEFT JOIN dataplane_domain_projects m ON m.project_id = c.project_id
+		 UNION ALL
+		SELECT m.project_id, d.domain_id, 'domain' AS source,
+		       d.enabled, d.target_bucket, d.filter, 0 AS rate_limit
+		  FROM dataplane_domain_projects m
+		  JOIN dataplane_domain_config d ON d.domain_id = m.domain_id
+		 WHERE NOT EXISTS (SELECT 1 FROM dataplane_config c WHERE c.project_id = m.project_id);
+	`,
+	14: `
+		-- Sinks of other types than 's3' keep target_bucket empty and hold their destination in settings.
+		ALTER TABLE dataplane_sinks ADD COLUMN IF NOT EXISTS type TEXT NOT NULL DEFAULT 's3';
+		ALTER TABLE dataplane_sinks ADD COLUMN IF NOT EXISTS settings JSONB NOT NULL DEFAULT '{}';
+	`,
+	15: `
+		-- Extends retention_days, which is enforced by hermez.
+		ALTER TABLE dataplane_config ADD COLUMN IF NOT EXISTS grace_minutes INTEGER NOT NULL DEFAULT 0;
+	`,
+	16: `
+		-- Projects whose default sink is not enabled inherit the domain config,
+		-- e.g. when routing.Store.UpsertSink created their config for another sink.
+		CREATE OR REPLACE VIEW dataplane_effective_config AS
+		SELECT c.project_id, COALESCE(m.domain_id, '') AS domain_id, 'project' AS source,
+		       c.enabled, c.target_bucket, c.filter, c.rate_limit
+		  FROM dataplane_config c
+		  LEFT JOIN dataplane_domain_projects m ON m.project_id = c.project_id
+		  LEFT JOIN dataplane_domain_config d ON d.domain_id = m.domain_id
+		 WHERE c.enabled OR d.domain_id IS NULL
+		 UNION ALL
+		SELECT m.project_id, d.domain_id, 'domain' AS source,
+		       d.enabled, d.target_bucket, d.filter, 0 AS rate_limit
+		  FROM dataplane_domain_projects m
+		  JOIN dataplane_domain_config d ON d.domain_id = m.domain_id
+		 WHERE NOT EXISTS (SELECT 1 FROM dataplane_config c WHERE c.project_id = m.project_id AND c.enabled);
+	`,
+}
+
+// querier is implemented by both *gsql.DB and *gsql.Tx.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
 }
 
 // Postgres implements Store using a PostgreSQL database.
 type Postgres struct {
-	db *gsql.DB
-}
-
-// NewPostgres connects to postgres using env-var based connection params and
-// runs any pending migrations. The env vars are:
-//
-//	HERMES_PG_HOSTNAME (default: localhost)
-//	HERMES_PG_PORT     (default: 5432)
-//	HERMES_PG_USERNAME (default: hermes)
-//	HERMES_PG_PASSWORD
-//	HERMES_PG_DBNAME   (default: hermes)
-//	HERMES_PG_CONNECTION_OPTIONS
-func NewPostgres(ctx context.Context) (*Postgres, error) {
-	target := pgruntime.ConnectionTarget{
-		HostName:          osext.GetenvOrDefault("HERMES_PG_HOSTNAME", "localhost"),
-		Port:              osext.GetenvOrDefault("HERMES_PG_PORT", "5432"),
-		UserName:          osext.GetenvOrDefault("HERMES_PG_USERNAME", "hermes"),
-		Password:          osext.GetenvOrDefault("HERMES_PG_PASSWORD", ""),
-		ConnectionOptions: osext.GetenvOrDefault("HERMES_PG_CONNECTION_OPTIONS", ""),
-		DatabaseName:      osext.GetenvOrDefault("HERMES_PG_DBNAME", "hermes"),
-		ApplicationName:   bininfo.Component(),
-	}
-	db, err := pgruntime.StdConnector("postgres").Connect(ctx, target, pgruntime.ConnectionBehavior{
-		Migrations: DBMigrations,
-	})
+	db       *gsql.DB
+	listener *pq.Listener
+	signal   changeSignal
+}
+
+// NewPostgres wraps an already connected and migrated database. The target of
+// the connection is used to listen for the notifications of the change feed.
+func NewPostgres(db *gsql.DB, target pgruntime.ConnectionTarget) (*Postgres, error) {
+	// WaitForChanges is woken up by the notifications of the trigger of migration 11
+	dbURL, err := target.IntoURL()
 	if err != nil {
-		return nil, fmt.Errorf("routing: cannot connect to postgres: %w", err)
+		return nil, fmt.Errorf("routing: cannot listen on %s: %w", ChangesChannel, err)
 	}
-	db.SetMaxOpenConns(16)
-	db.SetMaxIdleConns(4)
-	logg.Info("routing: postgres connected and migrations applied")
-	return &Postgres{db: db}, nil
+	p := &Postgres{db: db}
+	p.listener = pq.NewListener(dbURL.String(), 10*time.Second, time.Minute,
+		func(event pq.ListenerEventType, err error) {
+			if err != nil {
+				logg.Error("routing: listener for %s: %s", ChangesChannel, err)
+			}
+		})
+	if err := p.listener.Listen(ChangesChannel); err != nil {
+		p.listener.Close()
+		return nil, fmt.Errorf("routing: cannot listen on %s: %w", ChangesChannel, err)
+	}
+	go func() {
+		// a nil notification follows a reconnect, after which changes may have been missed
+		for range p.listener.Notify {
+			p.signal.broadcast()
+		}
+	}()
+	return p, nil
 }
 
 // Get retrieves the config for a project.
 func (p *Postgres) Get(ctx context.Context, projectID string) (*DataplaneConfig, error) {
+	return getConfig(ctx, p.db, projectID)
+}
+
+func getConfig(ctx context.Context, db querier, projectID string) (*DataplaneConfig, error) {
 	var cfg DataplaneConfig
-	err := p.db.QueryRowContext(ctx,
-		`SELECT project_id, enabled, target_bucket, updated_at, updated_by
+	err := db.QueryRowContext(ctx,
+		`SELECT project_id, enabled, target_bucket, filter, retention_days, grace_minutes, rate_limit, version, updated_at, updated_by
 		   FROM dataplane_config WHERE project_id = $1`,
 		projectID,
-	).Scan(&cfg.ProjectID, &cfg.Enabled, &cfg.TargetBucket, &cfg.UpdatedAt, &cfg.UpdatedBy)
+	).Scan(&cfg.ProjectID, &cfg.Enabled, &cfg.TargetBucket, &cfg.Filter, &cfg.RetentionDays, &cfg.GraceMinutes, &cfg.RateLimit, &cfg.Version, &cfg.UpdatedAt, &cfg.UpdatedBy)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, ErrNotFound
 	}
 	if err != nil {
 		return nil, fmt.Errorf("routing: cannot get config for project %s: %w", projectID, err)
 	}
-	return &cfg, nil
+
+	rows, err := db.QueryContext(ctx,
+		`SELECT `+sinkColumns+` FROM dataplane_sinks WHERE project_id = $1 ORDER BY name`,
+		projectID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("routing: cannot get sinks for project %s: %w", projectID, err)
+	}
+	defer rows.Close()
+	cfg.Sinks = []Sink{}
+	for rows.Next() {
+		s, err := scanSink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("routing: cannot get sinks for project %s: %w", projectID, err)
+		}
+		cfg.Sinks = append(cfg.Sinks, s)
+	}
+	return &cfg, rows.Err()
 }
 
 // Upsert creates or replaces the config for a project.
 func (p *Postgres) Upsert(ctx context.Context, cfg DataplaneConfig) error {
-	_, err := p.db.ExecContext(ctx,
-		`INSERT INTO dataplane_config (project_id, enabled, target_bucket, updated_at, updated_by)
-		 VALUES ($1, $2, $3, $4, $5)
+	return p.UpsertIf(ctx, cfg, Precondition{})
+}
+
+// UpsertIf creates or replaces the config for a project if pre holds.
+func (p *Postgres) UpsertIf(ctx context.Context, cfg DataplaneConfig, pre Precondition) error {
+	err := p.db.WithinTransaction(ctx, func(tx *gsql.Tx) error {
+		if err := lockProject(ctx, tx, cfg.ProjectID); err != nil {
+			return err
+		}
+		if err := checkPrecondition(ctx, tx, cfg.ProjectID, pre); err != nil {
+			return err
+		}
+		return upsertConfig(ctx, tx, cfg)
+	})
+	if err != nil {
+		return fmt.Errorf("routing: cannot upsert config for project %s: %w", cfg.ProjectID, err)
+	}
+	return nil
+}
+
+// Update modifies the config for a project in one transaction.
+func (p *Postgres) Update(ctx context.Context, projectID string, pre Precondition, update func(*DataplaneConfig) error) (*DataplaneConfig, error) {
+	var updated *DataplaneConfig
+	err := p.db.WithinTransaction(ctx, func(tx *gsql.Tx) error {
+		if err := lockProject(ctx, tx, projectID); err != nil {
+			return err
+		}
+		if err := checkPrecondition(ctx, tx, projectID, pre); err != nil {
+			return err
+		}
+		cfg, err := getConfig(ctx, tx, projectID)
+		if errors.Is(err, ErrNotFound) {
+			defaultCfg := DefaultDataplaneConfig(projectID)
+			cfg, err = &defaultCfg, nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := update(cfg); err != nil {
+			return err
+		}
+		cfg.ProjectID = projectID
+		if err := upsertConfig(ctx, tx, *cfg); err != nil {
+			return err
+		}
+		updated, err = getConfig(ctx, tx, projectID)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("routing: cannot update config for project %s: %w", projectID, err)
+	}
+	return updated, nil
+}
+
+// upsertConfig writes a config like Upsert. The caller must hold lockProject.
+func upsertConfig(ctx context.Context, tx *gsql.Tx, cfg DataplaneConfig) error {
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO dataplane_config (project_id, enabled, target_bucket, filter, retention_days, grace_minutes, rate_limit, updated_at, updated_by)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		 ON CONFLICT (project_id) DO UPDATE SET
+		     enabled        = EXCLUDED.enabled,
+		     target_bucket  = EXCLUDED.target_bucket,
+		     filter         = EXCLUDED.filter,
+		     retention_days = EXCLUDED.retention_days,
+		     grace_minutes  = EXCLUDED.grace_minutes,
+		     rate_limit     = EXCLUDED.rate_limit,
+		     updated_at     = EXCLUDED.updated_at,
+		     updated_by     = EXCLUDED.updated_by`,
+		cfg.ProjectID, cfg.Enabled, cfg.TargetBucket, cfg.Filter, cfg.RetentionDays, cfg.GraceMinutes, cfg.RateLimit, cfg.UpdatedAt, cfg.UpdatedBy,
+	)
+	if err != nil {
+		return err
+	}
+	if cfg.TargetBucket == "" {
+		_, err = tx.ExecContext(ctx,
+			`DELETE FROM dataplane_sinks WHERE project_id = $1 AND name = $2`,
+			cfg.ProjectID, DefaultSinkName,
+		)
+	} else {
+		err = upsertSinkRow(ctx, tx, Sink{
+			ProjectID:    cfg.ProjectID,
+			Name:         DefaultSinkName,
+			Enabled:      cfg.Enabled,
+			Type:         SinkS3,
+			TargetBucket: cfg.TargetBucket,
+			UpdatedAt:    cfg.UpdatedAt,
+			UpdatedBy:    cfg.UpdatedBy,
+		})
+	}
+	if err != nil {
+		return err
+	}
+	return recordHistory(ctx, tx, cfg.ProjectID, HistoryUpdate, 0, cfg.UpdatedAt, cfg.UpdatedBy)
+}
+
+// Delete removes the config for a project. Idempotent.
+// Returns (true, nil) if a row was deleted; (false, nil) if none existed.
+// The sinks are removed by ON DELETE CASCADE.
+func (p *Postgres) Delete(ctx context.Context, projectID, deletedBy string) (bool, error) {
+	return p.DeleteIf(ctx, projectID, deletedBy, Precondition{})
+}
+
+// DeleteIf removes the config for a project if pre holds.
+func (p *Postgres) DeleteIf(ctx context.Context, projectID, deletedBy string, pre Precondition) (bool, error) {
+	var deleted bool
+	err := p.db.WithinTransaction(ctx, func(tx *gsql.Tx) error {
+		if err := lockProject(ctx, tx, projectID); err != nil {
+			return err
+		}
+		if err := checkPrecondition(ctx, tx, projectID, pre); err != nil {
+			return err
+		}
+		result, err := tx.ExecContext(ctx,
+			`DELETE FROM dataplane_config WHERE project_id = $1`,
+			projectID,
+		)
+		if err != nil {
+			return err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		deleted = n > 0
+		if !deleted {
+			return nil
+		}
+		return recordHistory(ctx, tx, projectID, HistoryDelete, 0, time.Now().UTC(), deletedBy)
+	})
+	if err != nil {
+		return false, fmt.Errorf("routing: cannot delete config for project %s: %w", projectID, err)
+	}
+	return deleted, nil
+}
+
+// UpsertSink creates or replaces one sink of a project.
+func (p *Postgres) UpsertSink(ctx context.Context, sink Sink) error {
+	err := p.db.WithinTransaction(ctx, func(tx *gsql.Tx) error {
+		if err := lockProject(ctx, tx, sink.ProjectID); err != nil {
+			return err
+		}
+		// The sink needs a config row to refer to. Only the default sink changes an existing row.
+		query := `INSERT INTO dataplane_config (project_id, enabled, target_bucket, updated_at, updated_by)
+			VALUES ($1, FALSE, '', $2, $3) ON CONFLICT (project_id) DO NOTHING`
+		args := []any{sink.ProjectID, sink.UpdatedAt, sink.UpdatedBy}
+		if sink.Name == DefaultSinkName {
+			query = `INSERT INTO dataplane_config (project_id, enabled, target_bucket, updated_at, updated_by)
+				VALUES ($1, $4, $5, $2, $3)
+				ON CONFLICT (project_id) DO UPDATE SET
+				    enabled       = EXCLUDED.enabled,
+				    target_bucket = EXCLUDED.target_bucket,
+				    updated_at    = EXCLUDED.updated_at,
+				    updated_by    = EXCLUDED.updated_by`
+			args = append(args, sink.Enabled, sink.TargetBucket)
+		}
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+		if err := upsertSinkRow(ctx, tx, sink); err != nil {
+			return err
+		}
+		return recordHistory(ctx, tx, sink.ProjectID, HistorySinkUpdate, 0, sink.UpdatedAt, sink.UpdatedBy)
+	})
+	if err != nil {
+		return fmt.Errorf("routing: cannot upsert sink %s for project %s: %w", sink.Name, sink.ProjectID, err)
+	}
+	return nil
+}
+
+// DeleteSink removes one sink of a project. Idempotent.
+// Returns (true, nil) if a row was deleted; (false, nil) if none existed.
+func (p *Postgres) DeleteSink(ctx context.Context, projectID, name, deletedBy string) (bool, error) {
+	var deleted bool
+	err := p.db.WithinTransaction(ctx, func(tx *gsql.Tx) error {
+		if err := lockProject(ctx, tx, projectID); err != nil {
+			return err
+		}
+		result, err := tx.ExecContext(ctx,
+			`DELETE FROM dataplane_sinks WHERE project_id = $1 AND name = $2`,
+			projectID, name,
+		)
+		if err != nil {
+			return err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		deleted = n > 0
+		if !deleted {
+			return nil
+		}
+		if name == DefaultSinkName {
+			_, err = tx.ExecContext(ctx,
+				`UPDATE dataplane_config SET enabled = FALSE, target_bucket = '' WHERE project_id = $1`,
+				projectID,
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return recordHistory(ctx, tx, projectID, HistorySinkDelete, 0, time.Now().UTC(), deletedBy)
+	})
+	if err != nil {
+		return false, fmt.Errorf("routing: cannot delete sink %s for project %s: %w", name, projectID, err)
+	}
+	return deleted, nil
+}
+
+// listOperators maps the keys of ListFilter.UpdatedAt to SQL.
+var listOperators = map[string]string{"lt": "<", "lte": "<=", "gt": ">", "gte": ">="}
+
+// List implements Store.
+func (p *Postgres) List(ctx context.Context, filter ListFilter, offset, limit int) ([]DataplaneConfig, int, error) {
+	var (
+		conditions = []string{"TRUE"}
+		args       []any
+	)
+	addCondition := func(format string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+	if filter.Enabled != nil {
+		addCondition(`EXISTS (SELECT 1 FROM dataplane_sinks s WHERE s.project_id = c.project_id AND s.enabled) = $%d`, *filter.Enabled)
+	}
+	if filter.TargetBucket != "" {
+		addCondition(`EXISTS (SELECT 1 FROM dataplane_sinks s WHERE s.project_id = c.project_id
+			AND (s.target_bucket = $%[1]d OR s.settings->>'container' = $%[1]d))`, filter.TargetBucket)
+	}
+	if filter.UpdatedBy != "" {
+		addCondition("c.updated_by = $%d", filter.UpdatedBy)
+	}
+	for _, op := range slices.Sorted(maps.Keys(filter.UpdatedAt)) {
+		sqlOp, ok := listOperators[op]
+		if !ok {
+			return nil, 0, fmt.Errorf("routing: invalid operator %q for updated_at", op)
+		}
+		addCondition("c.updated_at "+sqlOp+" $%d", filter.UpdatedAt[op])
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	err := p.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM dataplane_config c WHERE `+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("routing: cannot list configs: %w", err)
+	}
+
+	rows, err := p.db.QueryContext(ctx,
+		`SELECT c.project_id, c.enabled, c.target_bucket, c.filter, c.retention_days, c.grace_minutes, c.rate_limit, c.version, c.updated_at, c.updated_by
+		   FROM dataplane_config c WHERE `+where+fmt.Sprintf(` ORDER BY c.project_id LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2),
+		append(args, limit, offset)...,
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("routing: cannot list configs: %w", err)
+	}
+	defer rows.Close()
+	configs := []DataplaneConfig{}
+	indexes := make(map[string]int)
+	for rows.Next() {
+		cfg := DataplaneConfig{Sinks: []Sink{}}
+		err := rows.Scan(&cfg.ProjectID, &cfg.Enabled, &cfg.TargetBucket, &cfg.Filter, &cfg.RetentionDays, &cfg.GraceMinutes, &cfg.RateLimit, &cfg.Version, &cfg.UpdatedAt, &cfg.UpdatedBy)
+		if err != nil {
+			return nil, 0, fmt.Errorf("routing: cannot list configs: %w", err)
+		}
+		indexes[cfg.ProjectID] = len(configs)
+		configs = append(configs, cfg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("routing: cannot list configs: %w", err)
+	}
+	if len(configs) == 0 {
+		return configs, total, nil
+	}
+
+	sinkRows, err := p.db.QueryContext(ctx,
+		`SELECT `+sinkColumns+` FROM dataplane_sinks WHERE project_id = ANY($1) ORDER BY project_id, name`,
+		pq.Array(slices.Collect(maps.Keys(indexes))),
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("routing: cannot list sinks: %w", err)
+	}
+	defer sinkRows.Close()
+	for sinkRows.Next() {
+		s, err := scanSink(sinkRows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("routing: cannot list sinks: %w", err)
+		}
+		cfg := &configs[indexes[s.ProjectID]]
+		cfg.Sinks = append(cfg.Sinks, s)
+	}
+	if err := sinkRows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("routing: cannot list sinks: %w", err)
+	}
+	return configs, total, nil
+}
+
+// History returns versions of the config of a project, newest first.
+func (p *Postgres) History(ctx context.Context, projectID string, offset, limit int) ([]HistoryEntry, int, error) {
+	var total int
+	err := p.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM dataplane_config_history WHERE project_id = $1`,
+		projectID,
+	).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("routing: cannot count history for project %s: %w", projectID, err)
+	}
+
+	rows, err := p.db.QueryContext(ctx,
+		`SELECT version, operation, config, COALESCE(restored_version, 0), changed_at, changed_by
+		   FROM dataplane_config_history WHERE project_id = $1
+		  ORDER BY version DESC LIMIT $2 OFFSET $3`,
+		projectID, limit, offset,
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("routing: cannot get history for project %s: %w", projectID, err)
+	}
+	defer rows.Close()
+	entries := []HistoryEntry{}
+	for rows.Next() {
+		var (
+			e      HistoryEntry
+			config []byte
+		)
+		err := rows.Scan(&e.Version, &e.Operation, &config, &e.RestoredVersion, &e.ChangedAt, &e.ChangedBy)
+		if err == nil && config != nil {
+			e.Config, err = unmarshalHistoryConfig(projectID, config)
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("routing: cannot get history for project %s: %w", projectID, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, total, rows.Err()
+}
+
+// Rollback restores the config recorded in a prior version.
+func (p *Postgres) Rollback(ctx context.Context, projectID string, version int64, updatedAt time.Time, updatedBy string) (*DataplaneConfig, error) {
+	var restored *DataplaneConfig
+	err := p.db.WithinTransaction(ctx, func(tx *gsql.Tx) error {
+		if err := lockProject(ctx, tx, projectID); err != nil {
+			return err
+		}
+		var config []byte
+		err := tx.QueryRowContext(ctx,
+			`SELECT config FROM dataplane_config_history WHERE project_id = $1 AND version = $2`,
+			projectID, version,
+		).Scan(&config)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrVersionNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if config == nil {
+			return ErrVersionDeleted
+		}
+		cfg, err := unmarshalHistoryConfig(projectID, config)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO dataplane_config (project_id, enabled, target_bucket, filter, retention_days, grace_minutes, rate_limit, updated_at, updated_by)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			 ON CONFLICT (project_id) DO UPDATE SET
+			     enabled        = EXCLUDED.enabled,
+			     target_bucket  = EXCLUDED.target_bucket,
+			     filter         = EXCLUDED.filter,
+			     retention_days = EXCLUDED.retention_days,
+			     grace_minutes  = EXCLUDED.grace_minutes,
+			     rate_limit     = EXCLUDED.rate_limit,
+			     updated_at     = EXCLUDED.updated_at,
+			     updated_by     = EXCLUDED.updated_by`,
+			projectID, cfg.Enabled, cfg.TargetBucket, cfg.Filter, cfg.RetentionDays, cfg.GraceMinutes, cfg.RateLimit, updatedAt, updatedBy,
+		)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `DELETE FROM dataplane_sinks WHERE project_id = $1`, projectID)
+		if err != nil {
+			return err
+		}
+		for _, sink := range cfg.Sinks {
+			sink.UpdatedAt, sink.UpdatedBy = updatedAt, updatedBy
+			if err := upsertSinkRow(ctx, tx, sink); err != nil {
+				return err
+			}
+		}
+		err = recordHistory(ctx, tx, projectID, HistoryRollback, version, updatedAt, updatedBy)
+		if err != nil {
+			return err
+		}
+		restored, err = getConfig(ctx, tx, projectID)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("routing: cannot roll back config for project %s to version %d: %w", projectID, version, err)
+	}
+	return restored, nil
+}
+
+// lockProject serializes the writes to the config of a project until the end
+// of the transaction, so that concurrent writes get consecutive history versions.
+func lockProject(ctx context.Context, tx *gsql.Tx, projectID string) error {
+	_, err := tx.ExecContext(ctx,
+		`SELECT pg_advisory_xact_lock(hashtext('dataplane_config'), hashtext($1))`,
+		projectID,
+	)
+	return err
+}
+
+// checkPrecondition returns ErrPreconditionFailed unless pre holds for the
+// stored config. The caller must hold lockProject.
+func checkPrecondition(ctx context.Context, tx *gsql.Tx, projectID string, pre Precondition) error {
+	stored := &DataplaneConfig{ProjectID: projectID}
+	err := tx.QueryRowContext(ctx,
+		`SELECT version FROM dataplane_config WHERE project_id = $1`,
+		projectID,
+	).Scan(&stored.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		stored, err = nil, nil
+	}
+	if err != nil {
+		return err
+	}
+	if !pre.Check(stored) {
+		return ErrPreconditionFailed
+	}
+	return nil
+}
+
+// recordHistory records the current config of a project (or its deletion) as
+// the next version, and stores that version in the config. The caller must
+// hold lockProject.
+func recordHistory(ctx context.Context, tx *gsql.Tx, projectID string, op HistoryOperation, restoredVersion int64, changedAt time.Time, changedBy string) error {
+	var version int64
+	err := tx.QueryRowContext(ctx,
+		`SELECT COALESCE(MAX(version), 0) + 1 FROM dataplane_config_history WHERE project_id = $1`,
+		projectID,
+	).Scan(&version)
+	if err != nil {
+		return err
+	}
+	var config []byte
+	if op != HistoryDelete {
+		_, err := tx.ExecContext(ctx,
+			`UPDATE dataplane_config SET version = $2 WHERE project_id = $1`,
+			projectID, version,
+		)
+		if err != nil {
+			return err
+		}
+		cfg, err := getConfig(ctx, tx, projectID)
+		if err != nil {
+			return err
+		}
+		config, err = json.Marshal(cfg)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO dataplane_config_history (project_id, version, operation, config, restored_version, changed_at, changed_by)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		projectID, version, string(op), nullableJSON(config),
+		sql.NullInt64{Int64: restoredVersion, Valid: restoredVersion != 0}, changedAt, changedBy,
+	)
+	return err
+}
+
+// nullableJSON passes a JSON document as a query argument, or NULL if it is nil.
+func nullableJSON(buf []byte) sql.NullString {
+	return sql.NullString{String: string(buf), Valid: buf != nil}
+}
+
+// unmarshalHistoryConfig decodes the config column of dataplane_config_history.
+func unmarshalHistoryConfig(projectID string, buf []byte) (*DataplaneConfig, error) {
+	var cfg DataplaneConfig
+	if err := json.Unmarshal(buf, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid config in history: %w", err)
+	}
+	if cfg.Sinks == nil {
+		cfg.Sinks = []Sink{}
+	}
+	for idx := range cfg.Sinks {
+		cfg.Sinks[idx].ProjectID = projectID
+		// versions from before typed sinks
+		if cfg.Sinks[idx].Type == "" {
+			cfg.Sinks[idx].Type = SinkS3
+		}
+	}
+	return &cfg, nil
+}
+
+// sinkColumns are the columns of dataplane_sinks that scanSink reads.
+const sinkColumns = `project_id, name, enabled, type, target_bucket, settings, updated_at, updated_by`
+
+// scanSink reads a row of sinkColumns.
+func scanSink(rows *sql.Rows) (Sink, error) {
+	var (
+		s        Sink
+		settings []byte
+	)
+	err := rows.Scan(&s.ProjectID, &s.Name, &s.Enabled, &s.Type, &s.TargetBucket, &settings, &s.UpdatedAt, &s.UpdatedBy)
+	if err != nil || s.Type == SinkS3 {
+		return s, err
+	}
+	s.Settings = &SinkSettings{}
+	if err := json.Unmarshal(settings, s.Settings); err != nil {
+		return s, fmt.Errorf("invalid settings of sink %s: %w", s.Name, err)
+	}
+	return s, nil
+}
+
+func upsertSinkRow(ctx context.Context, tx *gsql.Tx, sink Sink) error {
+	if sink.Type == "" {
+		sink.Type = SinkS3
+	}
+	settings := []byte("{}")
+	if sink.Settings != nil {
+		buf, err := json.Marshal(sink.Settings)
+		if err != nil {
+			return err
+		}
+		settings = buf
+	}
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO dataplane_sinks (project_id, name, enabled, type, target_bucket, settings, updated_at, updated_by)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		 ON CONFLICT (project_id, name) DO UPDATE SET
 		     enabled       = EXCLUDED.enabled,
+		     type          = EXCLUDED.type,
 		     target_bucket = EXCLUDED.target_bucket,
+		     settings      = EXCLUDED.settings,
 		     updated_at    = EXCLUDED.updated_at,
 		     updated_by    = EXCLUDED.updated_by`,
-		cfg.ProjectID, cfg.Enabled, cfg.TargetBucket, cfg.UpdatedAt, cfg.UpdatedBy,
+		sink.ProjectID, sink.Name, sink.Enabled, sink.Type, sink.TargetBucket, settings, sink.UpdatedAt, sink.UpdatedBy,
+	)
+	return err
+}
+
+// Changes implements Store.
+func (p *Postgres) Changes(ctx context.Context, since int64, limit int) ([]Change, error) {
+	var oldest sql.NullInt64
+	err := p.db.QueryRowContext(ctx, `SELECT MIN(sequence) FROM dataplane_config_changes`).Scan(&oldest)
+	if err != nil {
+		return nil, fmt.Errorf("routing: cannot list changes: %w", err)
+	}
+	if oldest.Valid && since < oldest.Int64-1 {
+		return nil, ErrChangesExpired
+	}
+
+	rows, err := p.db.QueryContext(ctx,
+		`SELECT sequence, project_id, domain_id, operation, version, changed_at
+		   FROM dataplane_config_changes WHERE sequence > $1 ORDER BY sequence LIMIT $2`,
+		since, limit,
 	)
 	if err != nil {
-		return fmt.Errorf("routing: cannot upsert config for project %s: %w", cfg.ProjectID, err)
+		return nil, fmt.Errorf("routing: cannot list changes: %w", err)
 	}
-	return nil
+	defer rows.Close()
+	changes := []Change{}
+	for rows.Next() {
+		var (
+			c  Change
+			op string
+		)
+		if err := rows.Scan(&c.Sequence, &c.ProjectID, &c.DomainID, &op, &c.Version, &c.ChangedAt); err != nil {
+			return nil, fmt.Errorf("routing: cannot list changes: %w", err)
+		}
+		c.Operation = ChangeOperation(op)
+		changes = append(changes, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("routing: cannot list changes: %w", err)
+	}
+	return changes, nil
 }
 
-// Delete removes the config for a project. Idempotent.
-// Returns (true, nil) if a row was deleted; (false, nil) if none existed.
-func (p *Postgres) Delete(ctx context.Context, projectID string) (bool, error) {
-	result, err := p.db.ExecContext(ctx,
-		`DELETE FROM dataplane_config WHERE project_id = $1`,
-		projectID,
+// LatestChange implements Store.
+func (p *Postgres) LatestChange(ctx context.Context) (int64, error) {
+	var latest int64
+	err := p.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(sequence), 0) FROM dataplane_config_changes`).Scan(&latest)
+	if err != nil {
+		return 0, fmt.Errorf("routing: cannot get latest change: %w", err)
+	}
+	return latest, nil
+}
+
+// WaitForChanges implements Store.
+func (p *Postgres) WaitForChanges(ctx context.Context, since int64) error {
+	return waitForChanges(ctx, &p.signal, since, p.LatestChange)
+}
+
+// GetDomain implements Store.
+func (p *Postgres) GetDomain(ctx context.Context, domainID string) (*DomainConfig, error) {
+	cfg := DomainConfig{DomainID: domainID}
+	err := p.db.QueryRowContext(ctx,
+		`SELECT enabled, target_bucket, filter, updated_at, updated_by
+		   FROM dataplane_domain_config WHERE domain_id = $1`,
+		domainID,
+	).Scan(&cfg.Enabled, &cfg.TargetBucket, &cfg.Filter, &cfg.UpdatedAt, &cfg.UpdatedBy)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("routing: cannot get config of domain %s: %w", domainID, err)
+	}
+	return &cfg, nil
+}
+
+// UpsertDomain implements Store.
+func (p *Postgres) UpsertDomain(ctx context.Context, cfg DomainConfig) error {
+	_, err := p.db.ExecContext(ctx,
+		`INSERT INTO dataplane_domain_config (domain_id, enabled, target_bucket, filter, updated_at, updated_by)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 ON CONFLICT (domain_id) DO UPDATE
+		   SET enabled       = EXCLUDED.enabled,
+		       target_bucket = EXCLUDED.target_bucket,
+		       filter        = EXCLUDED.filter,
+		       updated_at    = EXCLUDED.updated_at,
+		       updated_by    = EXCLUDED.updated_by`,
+		cfg.DomainID, cfg.Enabled, cfg.TargetBucket, cfg.Filter, cfg.UpdatedAt, cfg.UpdatedBy,
 	)
 	if err != nil {
-		return false, fmt.Errorf("routing: cannot delete config for project %s: %w", projectID, err)
+		return fmt.Errorf("routing: cannot write config of domain %s: %w", cfg.DomainID, err)
 	}
-	n, err := result.RowsAffected()
+	return nil
+}
+
+// DeleteDomain implements Store.
+func (p *Postgres) DeleteDomain(ctx context.Context, domainID string) (bool, error) {
+	var deleted bool
+	err := p.db.WithinTransaction(ctx, func(tx *gsql.Tx) error {
+		result, err := tx.ExecContext(ctx, `DELETE FROM dataplane_domain_config WHERE domain_id = $1`, domainID)
+		if err != nil {
+			return err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		deleted = n > 0
+		_, err = tx.ExecContext(ctx, `DELETE FROM dataplane_domain_projects WHERE domain_id = $1`, domainID)
+		return err
+	})
 	if err != nil {
-		return false, fmt.Errorf("routing: cannot get rows affected after delete for project %s: %w", projectID, err)
+		return false, fmt.Errorf("routing: cannot delete config of domain %s: %w", domainID, err)
 	}
-	return n > 0, nil
+	return deleted, nil
+}
+
+// ListDomains implements Store.
+func (p *Postgres) ListDomains(ctx context.Context) ([]DomainConfig, error) {
+	rows, err := p.db.QueryContext(ctx,
+		`SELECT domain_id, enabled, target_bucket, filter, updated_at, updated_by
+		   FROM dataplane_domain_config ORDER BY domain_id`)
+	if err != nil {
+		return nil, fmt.Errorf("routing: cannot list domain configs: %w", err)
+	}
+	defer rows.Close()
+	result := []DomainConfig{}
+	for rows.Next() {
+		var cfg DomainConfig
+		if err := rows.Scan(&cfg.DomainID, &cfg.Enabled, &cfg.TargetBucket, &cfg.Filter, &cfg.UpdatedAt, &cfg.UpdatedBy); err != nil {
+			return nil, fmt.Errorf("routing: cannot list domain configs: %w", err)
+		}
+		result = append(result, cfg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("routing: cannot list domain configs: %w", err)
+	}
+	return result, nil
+}
+
+// SetDomainProjects implements Store. Only the differences are written, so
+// that the change feed records projects that join or leave the domain only.
+func (p *Postgres) SetDomainProjects(ctx context.Context, domainID string, projectIDs []string) error {
+	err := p.db.WithinTransaction(ctx, func(tx *gsql.Tx) error {
+		_, err := tx.ExecContext(ctx,
+			`DELETE FROM dataplane_domain_projects WHERE domain_id = $1 AND NOT (project_id = ANY($2))`,
+			domainID, pq.Array(projectIDs),
+		)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO dataplane_domain_projects (project_id, domain_id)
+			 SELECT UNNEST($2::TEXT[]), $1
+			 ON CONFLICT (project_id) DO UPDATE SET domain_id = EXCLUDED.domain_id
+			  WHERE dataplane_domain_projects.domain_id <> EXCLUDED.domain_id`,
+			domainID, pq.Array(projectIDs),
+		)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("routing: cannot record projects of domain %s: %w", domainID, err)
+	}
+	return nil
+}
+
+// EffectiveConfig implements Store.
+func (p *Postgres) EffectiveConfig(ctx context.Context, projectID, domainID string) (*EffectiveConfig, error) {
+	return effectiveConfig(ctx, p, projectID, domainID, func(ctx context.Context, projectID string) (string, error) {
+		var domainID string
+		err := p.db.QueryRowContext(ctx,
+			`SELECT domain_id FROM dataplane_domain_projects WHERE project_id = $1`, projectID,
+		).Scan(&domainID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return "", fmt.Errorf("routing: cannot get domain of project %s: %w", projectID, err)
+		}
+		return domainID, nil
+	})
 }
 
-// Close releases the database connection pool.
+// Close stops listening for changes. The database connection pool is shared
+// with other hermez subsystems and stays open.
 func (p *Postgres) Close() error {
-	return p.db.Close()
+	return p.listener.Close()
 }
 
 // Ensure Postgres implements Store.
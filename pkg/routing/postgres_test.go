@@ -0,0 +1,376 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package routing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.xyrillian.de/gg/gsql"
+
+	"github.com/sapcc/hermes/pkg/test"
+)
+
+func TestMain(m *testing.M) {
+	test.WithTestDB(m)
+}
+
+// newPostgresForTest returns a Postgres store on an empty test database,
+// together with the database for checking the tables directly.
+func newPostgresForTest(t *testing.T) (*Postgres, *gsql.DB) {
+	t.Helper()
+	db, target := test.ConnectForTest(t, DBMigrations)
+	p, err := NewPostgres(db, target)
+	require.NoError(t, err)
+	t.Cleanup(func() { p.Close() })
+	return p, db
+}
+
+func TestPostgresPreconditions(t *testing.T) {
+	ctx := t.Context()
+	p, _ := newPostgresForTest(t)
+
+	require.ErrorIs(t, p.UpsertIf(ctx, DataplaneConfig{ProjectID: "p1"}, Precondition{IfExists: true}), ErrPreconditionFailed)
+	require.NoError(t, p.UpsertIf(ctx, DataplaneConfig{ProjectID: "p1"}, Precondition{IfNotExists: true}))
+	require.ErrorIs(t, p.UpsertIf(ctx, DataplaneConfig{ProjectID: "p1"}, Precondition{IfNotExists: true}), ErrPreconditionFailed)
+	require.NoError(t, p.UpsertIf(ctx, DataplaneConfig{ProjectID: "p1"}, Precondition{IfVersion: []int64{1}}))
+
+	// writes based on an outdated version conflict
+	require.ErrorIs(t, p.UpsertIf(ctx, DataplaneConfig{ProjectID: "p1"}, Precondition{IfVersion: []int64{1}}), ErrPreconditionFailed)
+	cfg, err := p.Update(ctx, "p1", Precondition{IfVersion: []int64{2}}, func(cfg *DataplaneConfig) error {
+		assert.Equal(t, int64(2), cfg.Version)
+		cfg.Filter = `event.action != "read"`
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), cfg.Version)
+	assert.Equal(t, `event.action != "read"`, cfg.Filter)
+	_, err = p.Update(ctx, "p1", Precondition{IfVersion: []int64{2}}, func(*DataplaneConfig) error { return nil })
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+
+	// a rejected update writes nothing
+	_, err = p.Update(ctx, "p1", Precondition{}, func(cfg *DataplaneConfig) error {
+		cfg.Filter = ""
+		return ErrNotFound
+	})
+	assert.ErrorIs(t, err, ErrNotFound)
+	cfg, err = p.Get(ctx, "p1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), cfg.Version)
+	assert.Equal(t, `event.action != "read"`, cfg.Filter)
+
+	deleted, err := p.DeleteIf(ctx, "p1", "alice", Precondition{IfVersion: []int64{1}})
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+	assert.False(t, deleted)
+	deleted, err = p.DeleteIf(ctx, "p1", "alice", Precondition{IfVersion: []int64{3}})
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	_, err = p.Get(ctx, "p1")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestPostgresConcurrentWrites(t *testing.T) {
+	ctx := t.Context()
+	p, db := newPostgresForTest(t)
+
+	// lockProject serializes the writers, so that every write gets its own version
+	const writers = 10
+	var wg sync.WaitGroup
+	for idx := range writers {
+		wg.Go(func() {
+			cfg := DataplaneConfig{ProjectID: "p1", Enabled: true, TargetBucket: fmt.Sprintf("bucket-%d", idx), UpdatedAt: time.Now()}
+			assert.NoError(t, p.Upsert(ctx, cfg))
+		})
+	}
+	wg.Wait()
+
+	var count, maxVersion int64
+	err := db.QueryRowContext(ctx,
+		`SELECT COUNT(*), MAX(version) FROM dataplane_config_history WHERE project_id = $1`, "p1",
+	).Scan(&count, &maxVersion)
+	require.NoError(t, err)
+	assert.Equal(t, int64(writers), count)
+	assert.Equal(t, int64(writers), maxVersion)
+	cfg, err := p.Get(ctx, "p1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(writers), cfg.Version)
+}
+
+func TestPostgresHistoryAndRollback(t *testing.T) {
+	ctx := t.Context()
+	p, db := newPostgresForTest(t)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	require.NoError(t, p.Upsert(ctx, DataplaneConfig{ProjectID: "p1", Enabled: true, TargetBucket: "first", RetentionDays: 30, UpdatedAt: now, UpdatedBy: "alice"}))
+	require.NoError(t, p.UpsertSink(ctx, Sink{ProjectID: "p1", Name: "dr", Enabled: true, TargetBucket: "dr-bucket", UpdatedAt: now}))
+	require.NoError(t, p.Upsert(ctx, DataplaneConfig{ProjectID: "p1", Enabled: true, TargetBucket: "second", UpdatedAt: now}))
+	_, err := p.Delete(ctx, "p1", "alice")
+	require.NoError(t, err)
+	// no-op deletes do not record a version
+	_, err = p.Delete(ctx, "p1", "alice")
+	require.NoError(t, err)
+
+	// recordHistory writes one row per write, with the config after the write
+	var rows int
+	require.NoError(t, db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM dataplane_config_history WHERE project_id = $1`, "p1",
+	).Scan(&rows))
+	assert.Equal(t, 4, rows)
+
+	entries, total, err := p.History(ctx, "p1", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 4, total)
+	var ops []HistoryOperation
+	for idx, entry := range entries {
+		assert.Equal(t, int64(4-idx), entry.Version)
+		ops = append(ops, entry.Operation)
+	}
+	assert.Equal(t, []HistoryOperation{HistoryDelete, HistoryUpdate, HistorySinkUpdate, HistoryUpdate}, ops)
+	assert.Nil(t, entries[0].Config)
+	assert.Equal(t, "alice", entries[0].ChangedBy)
+	assert.Equal(t, "second", entries[1].Config.TargetBucket)
+	assert.Len(t, entries[2].Config.Sinks, 2)
+	assert.Equal(t, 30, entries[3].Config.RetentionDays)
+	assert.True(t, now.Equal(entries[3].ChangedAt))
+
+	entries, total, err = p.History(ctx, "p1", 3, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 4, total)
+	require.Len(t, entries, 1)
+	assert.Equal(t, int64(1), entries[0].Version)
+
+	// rollback restores a version, including its sinks, as a new version
+	later := now.Add(time.Hour)
+	cfg, err := p.Rollback(ctx, "p1", 2, later, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), cfg.Version)
+	assert.Equal(t, "first", cfg.TargetBucket)
+	assert.Equal(t, 30, cfg.RetentionDays)
+	assert.Equal(t, "bob", cfg.UpdatedBy)
+	require.Len(t, cfg.Sinks, 2)
+	for _, sink := range cfg.Sinks {
+		assert.True(t, later.Equal(sink.UpdatedAt))
+		assert.Equal(t, "p1", sink.ProjectID)
+	}
+	entries, _, err = p.History(ctx, "p1", 0, 1)
+	require.NoError(t, err)
+	assert.Equal(t, HistoryRollback, entries[0].Operation)
+	assert.Equal(t, int64(2), entries[0].RestoredVersion)
+
+	// versions are never reused, even after a delete
+	_, err = p.Delete(ctx, "p1", "alice")
+	require.NoError(t, err)
+	require.NoError(t, p.Upsert(ctx, DataplaneConfig{ProjectID: "p1", UpdatedAt: now}))
+	cfg, err = p.Get(ctx, "p1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), cfg.Version)
+
+	_, err = p.Rollback(ctx, "p1", 4, later, "bob")
+	assert.ErrorIs(t, err, ErrVersionDeleted)
+	_, err = p.Rollback(ctx, "p1", 8, later, "bob")
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+	_, err = p.Rollback(ctx, "p2", 1, later, "bob")
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+}
+
+func TestPostgresChanges(t *testing.T) {
+	ctx := t.Context()
+	p, _ := newPostgresForTest(t)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	latest, err := p.LatestChange(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), latest)
+
+	require.NoError(t, p.Upsert(ctx, DataplaneConfig{ProjectID: "p1", Enabled: true, TargetBucket: "bucket", UpdatedAt: now}))
+	require.NoError(t, p.UpsertSink(ctx, Sink{ProjectID: "p2", Name: "dr", TargetBucket: "dr-bucket", UpdatedAt: now}))
+	_, err = p.Delete(ctx, "p1", "alice")
+	require.NoError(t, err)
+	_, err = p.Delete(ctx, "p1", "alice")
+	require.NoError(t, err)
+
+	// the trigger records one change per write
+	changes, err := p.Changes(ctx, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	assert.Equal(t, "p1", changes[0].ProjectID)
+	assert.Equal(t, ChangeUpsert, changes[0].Operation)
+	assert.Equal(t, int64(1), changes[0].Version)
+	assert.Equal(t, "p2", changes[1].ProjectID)
+	assert.Equal(t, ChangeUpsert, changes[1].Operation)
+	assert.Equal(t, ChangeDelete, changes[2].Operation)
+	assert.Equal(t, int64(1), changes[2].Version)
+
+	changes, err = p.Changes(ctx, changes[0].Sequence, 1)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "p2", changes[0].ProjectID)
+	latest, err = p.LatestChange(ctx)
+	require.NoError(t, err)
+	changes, err = p.Changes(ctx, latest, 10)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	// changes after the cursor return immediately
+	require.NoError(t, p.WaitForChanges(ctx, 0))
+
+	// otherwise the wait ends with the NOTIFY of the next change
+	done := make(chan error)
+	go func() { done <- p.WaitForChanges(ctx, latest) }()
+	select {
+	case err := <-done:
+		t.Fatalf("WaitForChanges returned before a change: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	require.NoError(t, p.Upsert(ctx, DataplaneConfig{ProjectID: "p1", UpdatedAt: now}))
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("WaitForChanges did not return after a change")
+	}
+
+	// or with the context
+	latest, err = p.LatestChange(ctx)
+	require.NoError(t, err)
+	waitCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, p.WaitForChanges(waitCtx, latest), context.DeadlineExceeded)
+}
+
+func TestPostgresList(t *testing.T) {
+	ctx := t.Context()
+	p, _ := newPostgresForTest(t)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, projectID := range []string{"p3", "p1", "p2", "p4"} {
+		require.NoError(t, p.Upsert(ctx, DataplaneConfig{ProjectID: projectID, Enabled: projectID == "p1" || projectID == "p3", TargetBucket: "bucket-" + projectID, UpdatedAt: now, UpdatedBy: "alice"}))
+	}
+	require.NoError(t, p.UpsertSink(ctx, Sink{ProjectID: "p2", Name: "dr", Enabled: true, TargetBucket: "dr-bucket", UpdatedAt: now}))
+	require.NoError(t, p.Upsert(ctx, DataplaneConfig{ProjectID: "p3", Enabled: true, TargetBucket: "bucket-p3", UpdatedAt: now.Add(time.Hour), UpdatedBy: "bob"}))
+
+	// configs are ordered by project ID and paged, and carry their sinks
+	configs, total, err := p.List(ctx, ListFilter{}, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 4, total)
+	require.Len(t, configs, 1)
+	assert.Equal(t, "p2", configs[0].ProjectID)
+	assert.Len(t, configs[0].Sinks, 2)
+
+	// p2 routes into its enabled dr sink, while its default sink is disabled
+	enabled, disabled := true, false
+	configs, total, err = p.List(ctx, ListFilter{Enabled: &enabled}, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	require.Len(t, configs, 3)
+	assert.Equal(t, "p1", configs[0].ProjectID)
+	assert.Equal(t, "p2", configs[1].ProjectID)
+	assert.Equal(t, "p3", configs[2].ProjectID)
+	configs, total, err = p.List(ctx, ListFilter{Enabled: &disabled}, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, configs, 1)
+	assert.Equal(t, "p4", configs[0].ProjectID)
+
+	configs, total, err = p.List(ctx, ListFilter{TargetBucket: "dr-bucket"}, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, configs, 1)
+	assert.Equal(t, "p2", configs[0].ProjectID)
+
+	configs, _, err = p.List(ctx, ListFilter{UpdatedAt: map[string]time.Time{"gt": now}}, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+	assert.Equal(t, "p3", configs[0].ProjectID)
+	configs, total, err = p.List(ctx, ListFilter{UpdatedBy: "alice"}, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Len(t, configs, 3)
+
+	configs, total, err = p.List(ctx, ListFilter{}, 5, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 4, total)
+	assert.Empty(t, configs)
+
+	_, _, err = p.List(ctx, ListFilter{UpdatedAt: map[string]time.Time{"eq": now}}, 0, 10)
+	assert.ErrorContains(t, err, `invalid operator "eq"`)
+}
+
+func TestPostgresEffectiveConfig(t *testing.T) {
+	ctx := t.Context()
+	p, db := newPostgresForTest(t)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, p.UpsertDomain(ctx, DomainConfig{DomainID: "d1", Enabled: true, TargetBucket: "domain-bucket", Filter: `event.outcome == "failure"`, UpdatedAt: now}))
+	require.NoError(t, p.SetDomainProjects(ctx, "d1", []string{"p1", "p2", "p3", "p4"}))
+	require.NoError(t, p.Upsert(ctx, DataplaneConfig{ProjectID: "p2", Enabled: true, TargetBucket: "project-bucket", RateLimit: 100, UpdatedAt: now}))
+	require.NoError(t, p.Upsert(ctx, DataplaneConfig{ProjectID: "p3", Enabled: false, TargetBucket: "disabled-bucket", UpdatedAt: now}))
+	// UpsertSink creates a config without a default sink for p4
+	require.NoError(t, p.UpsertSink(ctx, Sink{ProjectID: "p4", Name: "dr", Enabled: true, TargetBucket: "dr-bucket", UpdatedAt: now}))
+	require.NoError(t, p.Upsert(ctx, DataplaneConfig{ProjectID: "p5", Enabled: false, TargetBucket: "other-bucket", UpdatedAt: now}))
+
+	// only an enabled default sink overrides the domain config
+	for projectID, source := range map[string]ConfigSource{"p1": SourceDomain, "p2": SourceProject, "p3": SourceDomain, "p4": SourceDomain, "p5": SourceProject} {
+		cfg, err := p.EffectiveConfig(ctx, projectID, "")
+		require.NoError(t, err)
+		assert.Equal(t, source, cfg.Source, projectID)
+	}
+
+	// log-router reads the same from the dataplane_effective_config view
+	type viewRow struct {
+		DomainID     string
+		Source       ConfigSource
+		Enabled      bool
+		TargetBucket string
+		Filter       string
+		RateLimit    int
+	}
+	readView := func() map[string]viewRow {
+		t.Helper()
+		rows, err := db.QueryContext(ctx,
+			`SELECT project_id, domain_id, source, enabled, target_bucket, filter, rate_limit FROM dataplane_effective_config`)
+		require.NoError(t, err)
+		defer rows.Close()
+		result := make(map[string]viewRow)
+		for rows.Next() {
+			var (
+				projectID string
+				row       viewRow
+			)
+			require.NoError(t, rows.Scan(&projectID, &row.DomainID, &row.Source, &row.Enabled, &row.TargetBucket, &row.Filter, &row.RateLimit))
+			_, duplicate := result[projectID]
+			assert.False(t, duplicate, projectID)
+			result[projectID] = row
+		}
+		require.NoError(t, rows.Err())
+		return result
+	}
+	inherited := viewRow{DomainID: "d1", Source: SourceDomain, Enabled: true, TargetBucket: "domain-bucket", Filter: `event.outcome == "failure"`}
+	assert.Equal(t, map[string]viewRow{
+		"p1": inherited,
+		"p2": {DomainID: "d1", Source: SourceProject, Enabled: true, TargetBucket: "project-bucket", RateLimit: 100},
+		"p3": inherited,
+		"p4": inherited,
+		"p5": {Source: SourceProject, Enabled: false, TargetBucket: "other-bucket"},
+	}, readView())
+
+	// projects that leave the domain lose the domain config
+	require.NoError(t, p.SetDomainProjects(ctx, "d1", []string{"p2", "p3"}))
+	view := readView()
+	assert.NotContains(t, view, "p1")
+	assert.Equal(t, SourceProject, view["p4"].Source)
+	assert.Equal(t, inherited, view["p3"])
+	deleted, err := p.DeleteDomain(ctx, "d1")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	view = readView()
+	assert.Equal(t, viewRow{Source: SourceProject, Enabled: false, TargetBucket: "disabled-bucket"}, view["p3"])
+	assert.Empty(t, view["p2"].DomainID)
+	cfg, err := p.EffectiveConfig(ctx, "p1", "")
+	require.NoError(t, err)
+	assert.Equal(t, SourceDefault, cfg.Source)
+}
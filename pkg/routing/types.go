@@ -1,27 +1,155 @@
 // SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
 // SPDX-License-Identifier: Apache-2.0
 
-// Package routing manages per-project dataplane routing configuration.
+// Package routing manages per-project and per-domain dataplane routing configuration.
 // Hermez is the authoritative writer; log-router is a read-only consumer
 // via direct postgres SELECT (see docs/dataplane-config-read-contract.md).
 package routing
 
 import (
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/sapcc/go-api-declarations/cadf"
 	"github.com/sapcc/go-bits/must"
 )
 
+// DefaultSinkName is the name of the sink that mirrors the Enabled and
+// TargetBucket fields of DataplaneConfig. Log-router versions that only
+// know a single bucket per project route to this sink only.
+const DefaultSinkName = "default"
+
 // DataplaneConfig holds the routing configuration for a single project.
 // When Enabled is true, log-router routes dataplane events from Ceph RGW
 // into the project's TargetBucket in addition to the shared admin bucket.
+//
+// Enabled and TargetBucket are the single-sink view of the config: they are
+// always equal to the sink named DefaultSinkName (or false and empty if that
+// sink does not exist). Sinks lists all sinks, including the default one.
 type DataplaneConfig struct {
-	ProjectID    string    `json:"project_id"`
-	Enabled      bool      `json:"enabled"`
-	TargetBucket string    `json:"target_bucket,omitempty"`
-	UpdatedAt    time.Time `json:"updated_at"`
-	UpdatedBy    string    `json:"updated_by"`
+	ProjectID    string `json:"project_id"`
+	Enabled      bool   `json:"enabled"`
+	TargetBucket string `json:"target_bucket,omitempty"`
+	Sinks        []Sink `json:"sinks"`
+	// Filter is an optional CEL expression that selects the events routed into
+	// the project's sinks (see package filter). Empty means all events.
+	Filter string `json:"filter,omitempty"`
+	// RetentionDays is how long routed events are kept in the project's sinks
+	// before the retention job deletes them (see package retention). 0 keeps
+	// them forever.
+	RetentionDays int `json:"retention_days,omitempty"`
+	// GraceMinutes extends RetentionDays, so that e.g. consumers that copy the
+	// events out of the sinks on a daily schedule can catch up before the
+	// events are deleted. It has no effect while RetentionDays is 0.
+	GraceMinutes int `json:"grace_minutes,omitempty"`
+	// RateLimit is the maximum number of events per second that log-router
+	// routes into the project's sinks. 0 means unlimited.
+	RateLimit int `json:"rate_limit,omitempty"`
+	// Version is the number of the HistoryEntry that recorded the last write.
+	// It increases with every write, including writes to sinks, and is never
+	// reused for a project, even after Delete.
+	Version   int64     `json:"version,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UpdatedBy string    `json:"updated_by"`
+}
+
+const (
+	// MaxRetentionDays bounds DataplaneConfig.RetentionDays.
+	MaxRetentionDays = 3650
+	// MaxGraceMinutes bounds DataplaneConfig.GraceMinutes.
+	MaxGraceMinutes = 1440
+	// MaxRateLimit bounds DataplaneConfig.RateLimit.
+	MaxRateLimit = 100000
+)
+
+// Sink is one named routing destination of a project. Log-router routes the
+// project's dataplane events into every enabled sink.
+type Sink struct {
+	ProjectID string   `json:"-"`
+	Name      string   `json:"name"`
+	Enabled   bool     `json:"enabled"`
+	Type      SinkType `json:"type"`
+	// TargetBucket is the destination of sinks of type SinkS3, and empty for
+	// all other types.
+	TargetBucket string `json:"target_bucket,omitempty"`
+	// Settings holds the destination of sinks of all other types.
+	Settings  *SinkSettings `json:"settings,omitempty"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	UpdatedBy string        `json:"updated_by"`
+}
+
+// SinkType identifies where a Sink routes events to.
+type SinkType string
+
+const (
+	// SinkS3 routes into the S3 bucket TargetBucket of the project. It is the
+	// only type that log-router versions without typed sinks know, and the
+	// only type of the sink named DefaultSinkName.
+	SinkS3 SinkType = "s3"
+	// SinkSwift routes into the Swift container Settings.Container of the project.
+	SinkSwift SinkType = "swift"
+	// SinkWebhook posts events to the HTTPS URL Settings.URL.
+	SinkWebhook SinkType = "webhook"
+	// SinkKafka produces events into the Kafka topic Settings.Topic on Settings.Brokers.
+	SinkKafka SinkType = "kafka"
+)
+
+// SinkTypes lists all valid values of SinkType.
+var SinkTypes = []SinkType{SinkS3, SinkSwift, SinkWebhook, SinkKafka}
+
+// IsValid reports whether t is one of SinkTypes.
+func (t SinkType) IsValid() bool {
+	return slices.Contains(SinkTypes, t)
+}
+
+// SinkSettings holds the type-specific destination of a Sink. Only the
+// fields of the sink's type are set.
+type SinkSettings struct {
+	Container string   `json:"container,omitempty"`
+	URL       string   `json:"url,omitempty"`
+	Brokers   []string `json:"brokers,omitempty"`
+	Topic     string   `json:"topic,omitempty"`
+}
+
+// Bucket returns the bucket or container that the sink writes objects into,
+// or "" for types that do not write into the object storage of the project.
+// An empty Type means SinkS3, the only type before there were others.
+func (s Sink) Bucket() string {
+	switch s.Type {
+	case SinkS3, "":
+		return s.TargetBucket
+	case SinkSwift:
+		if s.Settings != nil {
+			return s.Settings.Container
+		}
+	}
+	return ""
+}
+
+// Destination identifies where the sink routes events to. S3 buckets and
+// Swift containers share a namespace, so both are identified by their name.
+func (s Sink) Destination() string {
+	if bucket := s.Bucket(); bucket != "" || s.Settings == nil {
+		return bucket
+	}
+	switch s.Type {
+	case SinkWebhook:
+		return s.Settings.URL
+	case SinkKafka:
+		return strings.Join(s.Settings.Brokers, ",") + "/" + s.Settings.Topic
+	}
+	return ""
+}
+
+// Sink returns the sink with the given name, or nil.
+func (c DataplaneConfig) Sink(name string) *Sink {
+	for _, s := range c.Sinks {
+		if s.Name == name {
+			return &s
+		}
+	}
+	return nil
 }
 
 // DefaultDataplaneConfig returns the default (disabled) config for a project
@@ -30,22 +158,146 @@ func DefaultDataplaneConfig(projectID string) DataplaneConfig {
 	return DataplaneConfig{
 		ProjectID: projectID,
 		Enabled:   false,
+		Sinks:     []Sink{},
+	}
+}
+
+// Precondition restricts a conditional write (see Store.UpsertIf and
+// Store.DeleteIf) to a particular state of the stored config. The zero value
+// does not restrict anything.
+type Precondition struct {
+	// IfVersion, if not empty, requires the stored config to have one of these versions.
+	IfVersion []int64
+	// IfExists requires that a config is stored.
+	IfExists bool
+	// IfNotExists requires that no config is stored.
+	IfNotExists bool
+}
+
+// Check reports whether the precondition holds for the stored config, which
+// is nil if none is stored.
+func (p Precondition) Check(stored *DataplaneConfig) bool {
+	switch {
+	case stored == nil:
+		return !p.IfExists && len(p.IfVersion) == 0
+	case p.IfNotExists:
+		return false
+	case len(p.IfVersion) > 0:
+		return slices.Contains(p.IfVersion, stored.Version)
+	default:
+		return true
+	}
+}
+
+// ListFilter selects the configs returned by Store.List. The zero value
+// selects all stored configs.
+type ListFilter struct {
+	// Enabled selects configs with at least one enabled sink (true) or
+	// without any (false), i.e. configs that route events or do not.
+	Enabled *bool
+	// TargetBucket selects configs with a sink (enabled or not) into this
+	// bucket or Swift container.
+	TargetBucket string
+	UpdatedBy    string
+	// UpdatedAt bounds the updated_at of the configs. The keys are the
+	// comparison operators "lt", "lte", "gt" and "gte".
+	UpdatedAt map[string]time.Time
+}
+
+// Matches reports whether a config is selected by the filter.
+func (f ListFilter) Matches(cfg DataplaneConfig) bool {
+	if f.Enabled != nil && slices.ContainsFunc(cfg.Sinks, func(s Sink) bool { return s.Enabled }) != *f.Enabled {
+		return false
+	}
+	if f.TargetBucket != "" && cfg.TargetBucket != f.TargetBucket &&
+		!slices.ContainsFunc(cfg.Sinks, func(s Sink) bool { return s.Bucket() == f.TargetBucket }) {
+		return false
 	}
+	if f.UpdatedBy != "" && cfg.UpdatedBy != f.UpdatedBy {
+		return false
+	}
+	for op, bound := range f.UpdatedAt {
+		c := cfg.UpdatedAt.Compare(bound)
+		if op == "lt" && c >= 0 || op == "lte" && c > 0 || op == "gt" && c <= 0 || op == "gte" && c < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// HistoryOperation identifies the kind of write that produced a HistoryEntry.
+type HistoryOperation string
+
+const (
+	HistoryUpdate     HistoryOperation = "update"
+	HistoryDelete     HistoryOperation = "delete"
+	HistorySinkUpdate HistoryOperation = "sink_update"
+	HistorySinkDelete HistoryOperation = "sink_delete"
+	HistoryRollback   HistoryOperation = "rollback"
+)
+
+// HistoryEntry is one version of the config of a project. Every write through
+// Store records a new version with the config as it was after the write.
+// Versions are numbered per project, starting at 1, and survive Delete.
+type HistoryEntry struct {
+	Version   int64            `json:"version"`
+	Operation HistoryOperation `json:"operation"`
+	// Config is nil if the write deleted the config.
+	Config *DataplaneConfig `json:"config"`
+	// RestoredVersion is the version that a rollback restored.
+	RestoredVersion int64     `json:"restored_version,omitempty"`
+	ChangedAt       time.Time `json:"changed_at"`
+	ChangedBy       string    `json:"changed_by"`
 }
 
 // Render implements the audittools.Target interface so DataplaneConfig can be
 // used directly in audittools.Event.Target. The full config is attached as JSON
 // so audit consumers can see exactly what was written.
 func (c DataplaneConfig) Render() cadf.Resource {
+	payload := map[string]any{
+		"enabled":       c.Enabled,
+		"target_bucket": c.TargetBucket,
+	}
+	if c.Filter != "" {
+		payload["filter"] = c.Filter
+	}
+	if c.RetentionDays != 0 {
+		payload["retention_days"] = c.RetentionDays
+	}
+	if c.GraceMinutes != 0 {
+		payload["grace_minutes"] = c.GraceMinutes
+	}
+	if c.RateLimit != 0 {
+		payload["rate_limit"] = c.RateLimit
+	}
 	return cadf.Resource{
 		TypeURI:   "service/hermes/dataplane-config",
 		ID:        c.ProjectID,
 		ProjectID: c.ProjectID,
 		Attachments: []cadf.Attachment{
-			must.Return(cadf.NewJSONAttachment("payload", map[string]any{
-				"enabled":       c.Enabled,
-				"target_bucket": c.TargetBucket,
-			})),
+			must.Return(cadf.NewJSONAttachment("payload", payload)),
+		},
+	}
+}
+
+// Render implements the audittools.Target interface so Sink can be used
+// directly in audittools.Event.Target.
+func (s Sink) Render() cadf.Resource {
+	payload := map[string]any{
+		"enabled":       s.Enabled,
+		"target_bucket": s.TargetBucket,
+	}
+	if s.Type != "" && s.Type != SinkS3 {
+		payload["type"] = s.Type
+		payload["settings"] = s.Settings
+	}
+	return cadf.Resource{
+		TypeURI:   "service/hermes/dataplane-config/sink",
+		ID:        s.ProjectID + "/" + s.Name,
+		Name:      s.Name,
+		ProjectID: s.ProjectID,
+		Attachments: []cadf.Attachment{
+			must.Return(cadf.NewJSONAttachment("payload", payload)),
 		},
 	}
 }
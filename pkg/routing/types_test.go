@@ -0,0 +1,98 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package routing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListFilterMatches(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	enabled, disabled := true, false
+	cfg := DataplaneConfig{
+		ProjectID:    "p1",
+		Enabled:      true,
+		TargetBucket: "primary",
+		Sinks: []Sink{
+			{Name: DefaultSinkName, Enabled: true, Type: SinkS3, TargetBucket: "primary"},
+			{Name: "dr", Enabled: false, Type: SinkS3, TargetBucket: "dr-bucket"},
+			{Name: "archive", Enabled: true, Type: SinkSwift, Settings: &SinkSettings{Container: "archive-container"}},
+			{Name: "siem", Enabled: true, Type: SinkWebhook, Settings: &SinkSettings{URL: "https://siem.example.com/"}},
+		},
+		UpdatedAt: now,
+		UpdatedBy: "alice",
+	}
+
+	for _, tc := range []struct {
+		name    string
+		filter  ListFilter
+		matches bool
+	}{
+		{"zero value", ListFilter{}, true},
+		{"enabled", ListFilter{Enabled: &enabled}, true},
+		{"disabled", ListFilter{Enabled: &disabled}, false},
+		{"default bucket", ListFilter{TargetBucket: "primary"}, true},
+		{"bucket of disabled sink", ListFilter{TargetBucket: "dr-bucket"}, true},
+		{"swift container", ListFilter{TargetBucket: "archive-container"}, true},
+		{"webhook URL", ListFilter{TargetBucket: "https://siem.example.com/"}, false},
+		{"other bucket", ListFilter{TargetBucket: "other"}, false},
+		{"updated by", ListFilter{UpdatedBy: "alice"}, true},
+		{"updated by other", ListFilter{UpdatedBy: "bob"}, false},
+		{"updated before", ListFilter{UpdatedAt: map[string]time.Time{"lt": now}}, false},
+		{"updated until", ListFilter{UpdatedAt: map[string]time.Time{"lte": now}}, true},
+		{"updated after", ListFilter{UpdatedAt: map[string]time.Time{"gt": now}}, false},
+		{"updated since", ListFilter{UpdatedAt: map[string]time.Time{"gte": now}}, true},
+		{"updated within", ListFilter{UpdatedAt: map[string]time.Time{"gt": now.Add(-time.Hour), "lt": now.Add(time.Hour)}}, true},
+		{"all fields", ListFilter{Enabled: &enabled, TargetBucket: "dr-bucket", UpdatedBy: "alice"}, true},
+		{"one field mismatches", ListFilter{Enabled: &enabled, TargetBucket: "dr-bucket", UpdatedBy: "bob"}, false},
+	} {
+		assert.Equal(t, tc.matches, tc.filter.Matches(cfg), tc.name)
+	}
+
+	// enabled refers to the sinks, not only to the default sink
+	cfg.Enabled = false
+	cfg.Sinks[0].Enabled = false
+	assert.True(t, ListFilter{Enabled: &enabled}.Matches(cfg))
+	cfg.Sinks = cfg.Sinks[:2]
+	assert.False(t, ListFilter{Enabled: &enabled}.Matches(cfg))
+	assert.True(t, ListFilter{Enabled: &disabled}.Matches(cfg))
+}
+
+func TestMockList(t *testing.T) {
+	ctx := t.Context()
+	m := NewMock()
+	for _, projectID := range []string{"p3", "p1", "p2"} {
+		require.NoError(t, m.Upsert(ctx, DataplaneConfig{ProjectID: projectID, Enabled: projectID != "p2", TargetBucket: "bucket-" + projectID}))
+	}
+
+	// configs are ordered by project ID and paged
+	configs, total, err := m.List(ctx, ListFilter{}, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	require.Len(t, configs, 1)
+	assert.Equal(t, "p2", configs[0].ProjectID)
+
+	enabled := true
+	configs, total, err = m.List(ctx, ListFilter{Enabled: &enabled}, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, configs, 2)
+	assert.Equal(t, "p1", configs[0].ProjectID)
+	assert.Equal(t, "p3", configs[1].ProjectID)
+
+	// a project routes events if any of its sinks is enabled
+	require.NoError(t, m.UpsertSink(ctx, Sink{ProjectID: "p2", Name: "dr", Enabled: true, TargetBucket: "dr-bucket"}))
+	_, total, err = m.List(ctx, ListFilter{Enabled: &enabled}, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+
+	configs, total, err = m.List(ctx, ListFilter{}, 5, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Empty(t, configs)
+}
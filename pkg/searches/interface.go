@@ -0,0 +1,41 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package searches
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrNotFound is returned by Store.Get and Store.Update when no saved search has the given ID.
+	ErrNotFound = errors.New("searches: saved search not found")
+	// ErrDuplicateName is returned by Store.Create and Store.Update when the
+	// project already has a saved search with the same name.
+	ErrDuplicateName = errors.New("searches: a saved search with this name already exists in the project")
+)
+
+// Store is the persistence interface for saved searches.
+// The Postgres implementation is the production backend;
+// the Mock implementation is used in unit tests.
+type Store interface {
+	// List returns the saved searches owned by projectID and the domain-visible
+	// saved searches of other projects in domainID, ordered by name.
+	// Callers must still filter the result with SavedSearch.VisibleTo.
+	List(ctx context.Context, projectID, domainID string) ([]SavedSearch, error)
+
+	// Get returns the saved search with the given ID, or ErrNotFound.
+	Get(ctx context.Context, id string) (*SavedSearch, error)
+
+	// Create stores a new saved search.
+	Create(ctx context.Context, search SavedSearch) error
+
+	// Update replaces the name, description, query, visibility and updated_*
+	// fields of an existing saved search.
+	Update(ctx context.Context, search SavedSearch) error
+
+	// Delete removes the saved search with the given ID.
+	// Returns (false, nil) if it did not exist.
+	Delete(ctx context.Context, id string) (bool, error)
+}
@@ -0,0 +1,111 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package searches
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Mock implements Store with in-memory storage for use in unit tests.
+type Mock struct {
+	mu       sync.RWMutex
+	searches map[string]SavedSearch
+}
+
+// NewMock creates an empty Mock store.
+func NewMock() *Mock {
+	return &Mock{searches: make(map[string]SavedSearch)}
+}
+
+// List implements the Store interface.
+func (m *Mock) List(_ context.Context, projectID, domainID string) ([]SavedSearch, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []SavedSearch
+	for _, s := range m.searches {
+		if s.ProjectID == projectID || (s.Visibility == VisibilityDomain && domainID != "" && s.DomainID == domainID) {
+			result = append(result, clone(s))
+		}
+	}
+	slices.SortFunc(result, func(lhs, rhs SavedSearch) int {
+		return strings.Compare(lhs.Name, rhs.Name)
+	})
+	return result, nil
+}
+
+// Get implements the Store interface.
+func (m *Mock) Get(_ context.Context, id string) (*SavedSearch, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s, ok := m.searches[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	s = clone(s)
+	return &s, nil
+}
+
+// Create implements the Store interface.
+func (m *Mock) Create(_ context.Context, search SavedSearch) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.nameTaken(search) {
+		return ErrDuplicateName
+	}
+	m.searches[search.ID] = clone(search)
+	return nil
+}
+
+// Update implements the Store interface.
+func (m *Mock) Update(_ context.Context, search SavedSearch) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	existing, ok := m.searches[search.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	// like in Postgres, the project of a saved search cannot be changed
+	search.ProjectID = existing.ProjectID
+	if m.nameTaken(search) {
+		return ErrDuplicateName
+	}
+	existing.Name = search.Name
+	existing.Description = search.Description
+	existing.Query = maps.Clone(search.Query)
+	existing.Visibility = search.Visibility
+	existing.UpdatedAt = search.UpdatedAt
+	existing.UpdatedBy = search.UpdatedBy
+	m.searches[search.ID] = existing
+	return nil
+}
+
+// Delete implements the Store interface.
+func (m *Mock) Delete(_ context.Context, id string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, existed := m.searches[id]
+	delete(m.searches, id)
+	return existed, nil
+}
+
+func (m *Mock) nameTaken(search SavedSearch) bool {
+	for _, s := range m.searches {
+		if s.ID != search.ID && s.ProjectID == search.ProjectID && s.Name == search.Name {
+			return true
+		}
+	}
+	return false
+}
+
+func clone(s SavedSearch) SavedSearch {
+	s.Query = maps.Clone(s.Query)
+	return s
+}
+
+// Ensure Mock implements Store.
+var _ Store = (*Mock)(nil)
@@ -0,0 +1,183 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package searches
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"go.xyrillian.de/gg/gsql"
+)
+
+// uniqueViolation is the SQLSTATE for unique constraint violations.
+const uniqueViolation = "23505"
+
+// DBMigrations contains the SQL migrations for the tables of this package in
+// the hermez database (see package database).
+var DBMigrations = map[int64]string{
+	3: `
+		-- Named GET /v1/events filter sets.
+		CREATE TABLE IF NOT EXISTS saved_searches (
+			id          UUID         PRIMARY KEY,
+			project_id  VARCHAR(64)  NOT NULL,
+			domain_id   VARCHAR(64)  NOT NULL DEFAULT '',
+			name        VARCHAR(255) NOT NULL,
+			description TEXT         NOT NULL DEFAULT '',
+			query       JSONB        NOT NULL DEFAULT '{}',
+			visibility  VARCHAR(16)  NOT NULL DEFAULT 'private',
+			created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+			created_by  VARCHAR(64)  NOT NULL DEFAULT '',
+			updated_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+			updated_by  VARCHAR(64)  NOT NULL DEFAULT '',
+			UNIQUE (project_id, name)
+		);
+		CREATE INDEX IF NOT EXISTS saved_searches_domain ON saved_searches (domain_id) WHERE visibility = 'domain';
+	`,
+}
+
+// Postgres implements Store using the hermez PostgreSQL database.
+type Postgres struct {
+	db *gsql.DB
+}
+
+// NewPostgres wraps an already connected and migrated database.
+func NewPostgres(db *gsql.DB) *Postgres {
+	return &Postgres{db: db}
+}
+
+const savedSearchColumns = `id, project_id, domain_id, name, description, query, visibility,
+	created_at, created_by, updated_at, updated_by`
+
+// List implements the Store interface.
+func (p *Postgres) List(ctx context.Context, projectID, domainID string) ([]SavedSearch, error) {
+	rows, err := p.db.QueryContext(ctx,
+		`SELECT `+savedSearchColumns+` FROM saved_searches
+		  WHERE project_id = $1 OR (visibility = 'domain' AND domain_id = $2 AND domain_id != '')
+		  ORDER BY name, id`,
+		projectID, domainID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("searches: cannot list saved searches for project %s: %w", projectID, err)
+	}
+	defer rows.Close()
+
+	var result []SavedSearch
+	for rows.Next() {
+		s, err := scanSavedSearch(rows)
+		if err != nil {
+			return nil, fmt.Errorf("searches: cannot list saved searches for project %s: %w", projectID, err)
+		}
+		result = append(result, *s)
+	}
+	return result, rows.Err()
+}
+
+// Get implements the Store interface.
+func (p *Postgres) Get(ctx context.Context, id string) (*SavedSearch, error) {
+	s, err := scanSavedSearch(p.db.QueryRowContext(ctx,
+		`SELECT `+savedSearchColumns+` FROM saved_searches WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("searches: cannot get saved search %s: %w", id, err)
+	}
+	return s, nil
+}
+
+// Create implements the Store interface.
+func (p *Postgres) Create(ctx context.Context, s SavedSearch) error {
+	query, err := json.Marshal(s.Query)
+	if err != nil {
+		return err
+	}
+	_, err = p.db.ExecContext(ctx,
+		`INSERT INTO saved_searches (`+savedSearchColumns+`)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		s.ID, s.ProjectID, s.DomainID, s.Name, s.Description, query, string(s.Visibility),
+		s.CreatedAt, s.CreatedBy, s.UpdatedAt, s.UpdatedBy,
+	)
+	if isUniqueViolation(err) {
+		return ErrDuplicateName
+	}
+	if err != nil {
+		return fmt.Errorf("searches: cannot create saved search in project %s: %w", s.ProjectID, err)
+	}
+	return nil
+}
+
+// Update implements the Store interface.
+func (p *Postgres) Update(ctx context.Context, s SavedSearch) error {
+	query, err := json.Marshal(s.Query)
+	if err != nil {
+		return err
+	}
+	result, err := p.db.ExecContext(ctx,
+		`UPDATE saved_searches
+		    SET name = $2, description = $3, query = $4, visibility = $5, updated_at = $6, updated_by = $7
+		  WHERE id = $1`,
+		s.ID, s.Name, s.Description, query, string(s.Visibility), s.UpdatedAt, s.UpdatedBy,
+	)
+	if isUniqueViolation(err) {
+		return ErrDuplicateName
+	}
+	if err != nil {
+		return fmt.Errorf("searches: cannot update saved search %s: %w", s.ID, err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("searches: cannot get rows affected after update of saved search %s: %w", s.ID, err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Delete implements the Store interface.
+func (p *Postgres) Delete(ctx context.Context, id string) (bool, error) {
+	result, err := p.db.ExecContext(ctx, `DELETE FROM saved_searches WHERE id = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("searches: cannot delete saved search %s: %w", id, err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("searches: cannot get rows affected after delete of saved search %s: %w", id, err)
+	}
+	return n > 0, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSavedSearch(row rowScanner) (*SavedSearch, error) {
+	var (
+		s          SavedSearch
+		query      []byte
+		visibility string
+	)
+	err := row.Scan(&s.ID, &s.ProjectID, &s.DomainID, &s.Name, &s.Description, &query, &visibility,
+		&s.CreatedAt, &s.CreatedBy, &s.UpdatedAt, &s.UpdatedBy)
+	if err != nil {
+		return nil, err
+	}
+	s.Visibility = Visibility(visibility)
+	if err := json.Unmarshal(query, &s.Query); err != nil {
+		return nil, fmt.Errorf("cannot decode query of saved search %s: %w", s.ID, err)
+	}
+	return &s, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation
+}
+
+// Ensure Postgres implements Store.
+var _ Store = (*Postgres)(nil)
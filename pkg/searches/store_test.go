@@ -0,0 +1,138 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package searches
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapcc/hermes/pkg/test"
+)
+
+func TestMain(m *testing.M) {
+	test.WithTestDB(m)
+}
+
+// forEachStore runs the test against the Mock and the Postgres store.
+func forEachStore(t *testing.T, action func(t *testing.T, store Store)) {
+	t.Run("Mock", func(t *testing.T) {
+		action(t, NewMock())
+	})
+	t.Run("Postgres", func(t *testing.T) {
+		db, _ := test.ConnectForTest(t, DBMigrations)
+		action(t, NewPostgres(db))
+	})
+}
+
+const (
+	searchID1 = "00000000-0000-0000-0000-000000000001"
+	searchID2 = "00000000-0000-0000-0000-000000000002"
+	searchID3 = "00000000-0000-0000-0000-000000000003"
+	searchID4 = "00000000-0000-0000-0000-000000000004"
+	searchID5 = "00000000-0000-0000-0000-000000000005"
+	searchID6 = "00000000-0000-0000-0000-000000000006"
+)
+
+func names(result []SavedSearch) []string {
+	var names []string
+	for _, s := range result {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+func TestStore_ProjectIsolation(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		ctx := t.Context()
+		for _, s := range []SavedSearch{
+			{ID: searchID1, ProjectID: "project-a", DomainID: "domain-1", Name: "private", Visibility: VisibilityPrivate, CreatedBy: "alice"},
+			{ID: searchID2, ProjectID: "project-a", DomainID: "domain-1", Name: "shared", Visibility: VisibilityDomain, CreatedBy: "alice"},
+			{ID: searchID3, ProjectID: "project-b", DomainID: "domain-1", Name: "project", Visibility: VisibilityProject, CreatedBy: "bob"},
+			{ID: searchID4, ProjectID: "project-c", DomainID: "domain-2", Name: "shared elsewhere", Visibility: VisibilityDomain, CreatedBy: "carol"},
+		} {
+			require.NoError(t, store.Create(ctx, s))
+		}
+
+		// names are unique per project only
+		err := store.Create(ctx, SavedSearch{ID: searchID5, ProjectID: "project-a", Name: "private", Visibility: VisibilityPrivate})
+		assert.ErrorIs(t, err, ErrDuplicateName)
+		err = store.Create(ctx, SavedSearch{ID: searchID6, ProjectID: "project-b", DomainID: "domain-1", Name: "private", Visibility: VisibilityPrivate, CreatedBy: "bob"})
+		require.NoError(t, err)
+
+		// other projects only see the domain-shared searches of their own domain
+		result, err := store.List(ctx, "project-a", "domain-1")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"private", "shared"}, names(result))
+		result, err = store.List(ctx, "project-b", "domain-1")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"private", "project", "shared"}, names(result))
+		result, err = store.List(ctx, "project-c", "domain-2")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"shared elsewhere"}, names(result))
+		result, err = store.List(ctx, "project-d", "")
+		require.NoError(t, err)
+		assert.Empty(t, result)
+
+		// List does not filter private searches of the own project; callers use VisibleTo
+		search, err := store.Get(ctx, searchID1)
+		require.NoError(t, err)
+		assert.True(t, search.VisibleTo("project-a", "domain-1", "alice"))
+		assert.False(t, search.VisibleTo("project-a", "domain-1", "bob"))
+		assert.False(t, search.VisibleTo("project-b", "domain-1", "alice"))
+		search, err = store.Get(ctx, searchID2)
+		require.NoError(t, err)
+		assert.True(t, search.VisibleTo("project-b", "domain-1", "bob"))
+		assert.False(t, search.VisibleTo("project-c", "domain-2", "carol"))
+	})
+}
+
+func TestStore_UpdateKeepsOwner(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		ctx := t.Context()
+		created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		require.NoError(t, store.Create(ctx, SavedSearch{
+			ID: searchID1, ProjectID: "project-a", DomainID: "domain-1", Name: "mine",
+			Query: map[string]string{"action": "delete"}, Visibility: VisibilityPrivate,
+			CreatedAt: created, CreatedBy: "alice", UpdatedAt: created, UpdatedBy: "alice",
+		}))
+		require.NoError(t, store.Create(ctx, SavedSearch{ID: searchID2, ProjectID: "project-a", Name: "other", Visibility: VisibilityPrivate}))
+		require.NoError(t, store.Create(ctx, SavedSearch{ID: searchID3, ProjectID: "project-b", Name: "renamed", Visibility: VisibilityPrivate}))
+
+		// an update can neither move a search into another project nor change its creator
+		updated := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+		require.NoError(t, store.Update(ctx, SavedSearch{
+			ID: searchID1, ProjectID: "project-b", DomainID: "domain-2", Name: "renamed",
+			Query: map[string]string{"action": "create"}, Visibility: VisibilityProject,
+			CreatedAt: updated, CreatedBy: "mallory", UpdatedAt: updated, UpdatedBy: "admin",
+		}))
+		search, err := store.Get(ctx, searchID1)
+		require.NoError(t, err)
+		assert.Equal(t, "project-a", search.ProjectID)
+		assert.Equal(t, "domain-1", search.DomainID)
+		assert.Equal(t, "alice", search.CreatedBy)
+		assert.True(t, created.Equal(search.CreatedAt), "created_at changed to %s", search.CreatedAt)
+		assert.Equal(t, "renamed", search.Name)
+		assert.Equal(t, map[string]string{"action": "create"}, search.Query)
+		assert.Equal(t, VisibilityProject, search.Visibility)
+		assert.Equal(t, "admin", search.UpdatedBy)
+		assert.True(t, updated.Equal(search.UpdatedAt), "updated_at is %s", search.UpdatedAt)
+
+		err = store.Update(ctx, SavedSearch{ID: searchID1, ProjectID: "project-a", Name: "other", Visibility: VisibilityPrivate})
+		assert.ErrorIs(t, err, ErrDuplicateName)
+		err = store.Update(ctx, SavedSearch{ID: searchID4, Name: "missing", Visibility: VisibilityPrivate})
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		deleted, err := store.Delete(ctx, searchID1)
+		require.NoError(t, err)
+		assert.True(t, deleted)
+		deleted, err = store.Delete(ctx, searchID1)
+		require.NoError(t, err)
+		assert.False(t, deleted)
+		_, err = store.Get(ctx, searchID1)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
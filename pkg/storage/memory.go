@@ -0,0 +1,265 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package storage
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+)
+
+// Memory is an in-memory Storage implementation for tests that need events
+// to change over time (e.g. background jobs that poll for new events).
+// Unlike Mock, it honors tenant scoping, term filters (including "!" negation),
+// time ranges, sorting and pagination. Full-text search is approximated by a
+// substring match on the event's JSON representation.
+type Memory struct {
+	mu       sync.RWMutex
+	entries  []memoryEntry
+	maxLimit uint
+}
+
+type memoryEntry struct {
+	event     cadf.Event
+	tenantIDs []string
+}
+
+// NewMemory creates an empty Memory storage with the given MaxLimit.
+func NewMemory(maxLimit uint) *Memory {
+	return &Memory{maxLimit: maxLimit}
+}
+
+// Add stores events that are visible to each of the given tenant IDs.
+// Events are copied, so later modifications by the caller are not visible.
+func (m *Memory) Add(tenantIDs []string, events ...cadf.Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, event := range events {
+		m.entries = append(m.entries, memoryEntry{event: event, tenantIDs: slices.Clone(tenantIDs)})
+	}
+}
+
+// Replace overwrites the stored event with the same ID, e.g. to simulate tampering.
+// Returns false if no such event exists.
+func (m *Memory) Replace(event cadf.Event) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for idx := range m.entries {
+		if m.entries[idx].event.ID == event.ID {
+			m.entries[idx].event = event
+			return true
+		}
+	}
+	return false
+}
+
+// Remove deletes the stored event with the given ID.
+// Returns false if no such event exists.
+func (m *Memory) Remove(eventID string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for idx := range m.entries {
+		if m.entries[idx].event.ID == eventID {
+			m.entries = slices.Delete(m.entries, idx, idx+1)
+			return true
+		}
+	}
+	return false
+}
+
+// GetEvents implements the Storage interface.
+func (m *Memory) GetEvents(_ context.Context, filter *EventFilter, tenantID string) ([]*cadf.Event, int, error) {
+	if err := validateTenantID(tenantID); err != nil {
+		return nil, 0, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var matches []*cadf.Event
+	for _, entry := range m.entries {
+		if !entry.visibleTo(tenantID) || !matchesFilter(&entry.event, filter) {
+			continue
+		}
+		event := entry.event
+		matches = append(matches, &event)
+	}
+
+	slices.SortStableFunc(matches, func(lhs, rhs *cadf.Event) int {
+		for _, order := range filter.Sort {
+			getter, ok := memorySortFields[order.Fieldname]
+			if !ok {
+				continue
+			}
+			c := cmp.Compare(getter(lhs), getter(rhs))
+			if order.Order != "asc" {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		// like OpenSearch, always fall back to time descending
+		return -cmp.Compare(lhs.EventTime, rhs.EventTime)
+	})
+
+	total := len(matches)
+	start := min(int(filter.Offset), total) //nolint:gosec // offsets are capped by MaxLimit
+	end := total
+	if filter.Limit > 0 {
+		end = min(start+int(filter.Limit), total) //nolint:gosec // limits are capped by MaxLimit
+	}
+	return matches[start:end], total, nil
+}
+
+// GetEvent implements the Storage interface.
+// Returns (nil, nil) when the event does not exist or is not visible to the tenant.
+func (m *Memory) GetEvent(_ context.Context, eventID, tenantID string) (*cadf.Event, error) {
+	if err := validateTenantID(tenantID); err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, entry := range m.entries {
+		if entry.event.ID == eventID && entry.visibleTo(tenantID) {
+			event := entry.event
+			return &event, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetAttributes implements the Storage interface.
+func (m *Memory) GetAttributes(_ context.Context, filter *AttributeFilter, tenantID string) ([]string, error) {
+	if _, ok := CADFFieldMapping[filter.QueryName]; !ok {
+		return nil, ErrUnknownAttributeName
+	}
+	if err := validateTenantID(tenantID); err != nil {
+		return nil, err
+	}
+	getter, ok := memorySortFields[filter.QueryName]
+	if !ok {
+		return nil, nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var values []string
+	for _, entry := range m.entries {
+		if !entry.visibleTo(tenantID) {
+			continue
+		}
+		value := TruncateSlashPath(getter(&entry.event), int(filter.MaxDepth)) //nolint:gosec // depth is small
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		values = append(values, value)
+	}
+	slices.Sort(values)
+	if filter.Limit > 0 && uint(len(values)) > filter.Limit {
+		values = values[:filter.Limit]
+	}
+	return values, nil
+}
+
+// MaxLimit implements the Storage interface.
+func (m *Memory) MaxLimit() uint {
+	return m.maxLimit
+}
+
+func (e memoryEntry) visibleTo(tenantID string) bool {
+	return tenantID == AllTenants || slices.Contains(e.tenantIDs, tenantID)
+}
+
+// memorySortFields maps the API field names from CADFFieldMapping to getters on cadf.Event.
+var memorySortFields = map[string]func(*cadf.Event) string{
+	"time":           func(e *cadf.Event) string { return e.EventTime },
+	"action":         func(e *cadf.Event) string { return string(e.Action) },
+	"outcome":        func(e *cadf.Event) string { return string(e.Outcome) },
+	"request_path":   func(e *cadf.Event) string { return e.RequestPath },
+	"observer_id":    func(e *cadf.Event) string { return e.Observer.ID },
+	"observer_type":  func(e *cadf.Event) string { return e.Observer.TypeURI },
+	"target_id":      func(e *cadf.Event) string { return e.Target.ID },
+	"target_type":    func(e *cadf.Event) string { return e.Target.TypeURI },
+	"resource_type":  func(e *cadf.Event) string { return e.Target.TypeURI },
+	"initiator_id":   func(e *cadf.Event) string { return e.Initiator.ID },
+	"initiator_type": func(e *cadf.Event) string { return e.Initiator.TypeURI },
+	"initiator_name": func(e *cadf.Event) string { return e.Initiator.Name },
+}
+
+func matchesFilter(event *cadf.Event, filter *EventFilter) bool {
+	terms := []struct {
+		value string
+		field string
+	}{
+		{filter.ObserverType, "observer_type"},
+		{filter.TargetType, "target_type"},
+		{filter.TargetID, "target_id"},
+		{filter.InitiatorType, "initiator_type"},
+		{filter.InitiatorID, "initiator_id"},
+		{filter.InitiatorName, "initiator_name"},
+		{filter.Action, "action"},
+		{filter.Outcome, "outcome"},
+		{filter.RequestPath, "request_path"},
+	}
+	for _, term := range terms {
+		if term.value == "" {
+			continue
+		}
+		actual := memorySortFields[term.field](event)
+		if negated, ok := strings.CutPrefix(term.value, "!"); ok {
+			if actual == negated {
+				return false
+			}
+		} else if actual != term.value {
+			return false
+		}
+	}
+
+	if len(filter.Time) > 0 {
+		eventTime, err := ParseEventTime(event.EventTime)
+		if err != nil {
+			return false
+		}
+		for operator, value := range filter.Time {
+			bound, err := ParseEventTime(value)
+			if err != nil {
+				return false
+			}
+			var ok bool
+			switch operator {
+			case "lt":
+				ok = eventTime.Before(bound)
+			case "lte":
+				ok = !eventTime.After(bound)
+			case "gt":
+				ok = eventTime.After(bound)
+			case "gte":
+				ok = !eventTime.Before(bound)
+			default:
+				ok = true
+			}
+			if !ok {
+				return false
+			}
+		}
+	}
+
+	if filter.Search != "" {
+		buf, err := json.Marshal(event)
+		if err != nil || !strings.Contains(string(buf), filter.Search) {
+			return false
+		}
+	}
+	return true
+}
+
+// Ensure Memory implements Storage.
+var _ Storage = (*Memory)(nil)
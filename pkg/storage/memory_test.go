@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/stretchr/testify/assert"
+)
+
+func memoryTestEvents() *Memory {
+	m := NewMemory(100)
+	m.Add([]string{"project-a"},
+		cadf.Event{ID: "e1", EventTime: "2026-01-01T00:00:01.000000+00:00", Action: cadf.CreateAction, Outcome: cadf.SuccessOutcome},
+		cadf.Event{ID: "e2", EventTime: "2026-01-01T00:00:03.000000+00:00", Action: cadf.DeleteAction, Outcome: cadf.FailureOutcome},
+	)
+	m.Add([]string{"project-b"},
+		cadf.Event{ID: "e3", EventTime: "2026-01-01T00:00:02.000000+00:00", Action: cadf.CreateAction, Outcome: cadf.SuccessOutcome},
+	)
+	return m
+}
+
+func eventIDs(events []*cadf.Event) []string {
+	ids := make([]string, len(events))
+	for i, e := range events {
+		ids[i] = e.ID
+	}
+	return ids
+}
+
+func Test_MemoryStorage_Events(t *testing.T) {
+	m := memoryTestEvents()
+	ctx := context.Background()
+
+	tt := []struct {
+		name     string
+		tenantID string
+		filter   EventFilter
+		expected []string
+		total    int
+	}{
+		{"TenantScoped", "project-a", EventFilter{}, []string{"e2", "e1"}, 2},
+		{"AllTenants", AllTenants, EventFilter{}, []string{"e2", "e3", "e1"}, 3},
+		{"Negation", AllTenants, EventFilter{Outcome: "!failure"}, []string{"e3", "e1"}, 2},
+		{"TimeRange", AllTenants, EventFilter{Time: map[string]string{"gte": "2026-01-01T00:00:02", "lt": "2026-01-01T00:00:03+00:00"}}, []string{"e3"}, 1},
+		{"SortAsc", AllTenants, EventFilter{Sort: []FieldOrder{{Fieldname: "time", Order: "asc"}}}, []string{"e1", "e3", "e2"}, 3},
+		{"Pagination", AllTenants, EventFilter{Offset: 1, Limit: 1}, []string{"e3"}, 3},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			events, total, err := m.GetEvents(ctx, &tc.filter, tc.tenantID)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.total, total)
+			assert.Equal(t, tc.expected, eventIDs(events))
+		})
+	}
+
+	_, _, err := m.GetEvents(ctx, &EventFilter{}, "")
+	assert.ErrorIs(t, err, ErrEmptyTenantID)
+}
+
+func Test_MemoryStorage_ReplaceAndRemove(t *testing.T) {
+	m := memoryTestEvents()
+	ctx := context.Background()
+
+	event, err := m.GetEvent(ctx, "e3", "project-a")
+	assert.NoError(t, err)
+	assert.Nil(t, event, "events of other tenants must not be visible")
+
+	assert.True(t, m.Replace(cadf.Event{ID: "e3", EventTime: "2026-01-01T00:00:02.000000+00:00", Action: cadf.UpdateAction}))
+	event, err = m.GetEvent(ctx, "e3", "project-b")
+	assert.NoError(t, err)
+	assert.Equal(t, cadf.UpdateAction, event.Action)
+
+	assert.True(t, m.Remove("e3"))
+	assert.False(t, m.Remove("e3"))
+	event, err = m.GetEvent(ctx, "e3", "project-b")
+	assert.NoError(t, err)
+	assert.Nil(t, event)
+}
@@ -0,0 +1,24 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package storage
+
+import "time"
+
+// eventTimeFormats are the timestamp formats accepted by ParseEventTime.
+// They cover CADF eventTime values and the formats accepted by the time filter of GET /v1/events.
+var eventTimeFormats = []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999-0700", "2006-01-02T15:04:05-0700", "2006-01-02T15:04:05"}
+
+// ParseEventTime parses a CADF eventTime or a time filter value.
+// Values without a zone offset are interpreted as UTC.
+func ParseEventTime(value string) (time.Time, error) {
+	var err error
+	for _, format := range eventTimeFormats {
+		var t time.Time
+		t, err = time.Parse(format, value)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
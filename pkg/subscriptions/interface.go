@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package subscriptions
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned by Store.GetSubscription and Store.UpdateSubscription
+	// when no subscription has the given ID.
+	ErrNotFound = errors.New("subscriptions: subscription not found")
+	// ErrDuplicateName is returned by Store.CreateSubscription and Store.UpdateSubscription
+	// when the project already has a subscription with the same name.
+	ErrDuplicateName = errors.New("subscriptions: a subscription with this name already exists in the project")
+	// ErrCursorMoved is returned by Store.Enqueue when the subscription's
+	// QueuedUntil is no longer the expected value.
+	ErrCursorMoved = errors.New("subscriptions: cursor was moved concurrently")
+)
+
+// Store is the persistence interface for subscriptions and their deliveries.
+// The Postgres implementation is the production backend;
+// the Mock implementation is used in unit tests.
+type Store interface {
+	// ListSubscriptions returns the subscriptions of a project, ordered by name.
+	// If projectID is empty, the subscriptions of all projects are returned.
+	ListSubscriptions(ctx context.Context, projectID string) ([]Subscription, error)
+
+	// GetSubscription returns the subscription with the given ID, or ErrNotFound.
+	GetSubscription(ctx context.Context, id string) (*Subscription, error)
+
+	// CreateSubscription stores a new subscription.
+	CreateSubscription(ctx context.Context, sub Subscription) error
+
+	// UpdateSubscription replaces name, filter, URL, secret, enabled and the
+	// updated_* fields of an existing subscription.
+	UpdateSubscription(ctx context.Context, sub Subscription) error
+
+	// DeleteSubscription removes the subscription with the given ID and all its deliveries.
+	// Returns (false, nil) if it did not exist.
+	DeleteSubscription(ctx context.Context, id string) (bool, error)
+
+	// Enqueue stores the deliveries and moves the subscription's QueuedUntil
+	// from `from` to `until`, atomically. Deliveries for events that were
+	// already queued for the subscription are skipped.
+	// Returns ErrCursorMoved if QueuedUntil is not `from`.
+	Enqueue(ctx context.Context, subscriptionID string, from, until time.Time, deliveries []Delivery) error
+
+	// DueDeliveries returns up to limit pending deliveries of enabled
+	// subscriptions whose NextAttemptAt is not after now, oldest first.
+	DueDeliveries(ctx context.Context, now time.Time, limit int) ([]Delivery, error)
+
+	// CompleteDelivery removes a successful delivery.
+	CompleteDelivery(ctx context.Context, id string) error
+
+	// UpdateDelivery stores the Status, Attempts, NextAttemptAt and LastError of a failed delivery.
+	UpdateDelivery(ctx context.Context, delivery Delivery) error
+
+	// DeadLetters returns the dead deliveries of a subscription, oldest first.
+	DeadLetters(ctx context.Context, subscriptionID string) ([]Delivery, error)
+
+	// TryLock acquires the worker lock without waiting, so that only one
+	// hermez replica queues and delivers at a time. If ok is true, the caller
+	// must call release when done.
+	TryLock(ctx context.Context) (release func(), ok bool, err error)
+}
@@ -0,0 +1,221 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package subscriptions
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Mock implements Store with in-memory storage for use in unit tests.
+type Mock struct {
+	mu            sync.RWMutex
+	subscriptions map[string]Subscription
+	deliveries    map[string]Delivery
+	locked        sync.Mutex
+}
+
+// NewMock creates an empty Mock store.
+func NewMock() *Mock {
+	return &Mock{
+		subscriptions: make(map[string]Subscription),
+		deliveries:    make(map[string]Delivery),
+	}
+}
+
+// ListSubscriptions implements the Store interface.
+func (m *Mock) ListSubscriptions(_ context.Context, projectID string) ([]Subscription, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []Subscription
+	for _, s := range m.subscriptions {
+		if projectID == "" || s.ProjectID == projectID {
+			result = append(result, s)
+		}
+	}
+	slices.SortFunc(result, func(lhs, rhs Subscription) int {
+		return cmp.Or(strings.Compare(lhs.ProjectID, rhs.ProjectID), strings.Compare(lhs.Name, rhs.Name))
+	})
+	return result, nil
+}
+
+// GetSubscription implements the Store interface.
+func (m *Mock) GetSubscription(_ context.Context, id string) (*Subscription, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s, ok := m.subscriptions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &s, nil
+}
+
+// CreateSubscription implements the Store interface.
+func (m *Mock) CreateSubscription(_ context.Context, sub Subscription) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.nameTaken(sub) {
+		return ErrDuplicateName
+	}
+	m.subscriptions[sub.ID] = sub
+	return nil
+}
+
+// UpdateSubscription implements the Store interface.
+func (m *Mock) UpdateSubscription(_ context.Context, sub Subscription) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	existing, ok := m.subscriptions[sub.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if m.nameTaken(sub) {
+		return ErrDuplicateName
+	}
+	existing.Name = sub.Name
+	existing.Filter = sub.Filter
+	existing.URL = sub.URL
+	existing.Secret = sub.Secret
+	existing.Enabled = sub.Enabled
+	existing.UpdatedAt = sub.UpdatedAt
+	existing.UpdatedBy = sub.UpdatedBy
+	m.subscriptions[sub.ID] = existing
+	return nil
+}
+
+// DeleteSubscription implements the Store interface.
+func (m *Mock) DeleteSubscription(_ context.Context, id string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, existed := m.subscriptions[id]
+	delete(m.subscriptions, id)
+	for deliveryID, d := range m.deliveries {
+		if d.SubscriptionID == id {
+			delete(m.deliveries, deliveryID)
+		}
+	}
+	return existed, nil
+}
+
+// Enqueue implements the Store interface.
+func (m *Mock) Enqueue(_ context.Context, subscriptionID string, from, until time.Time, deliveries []Delivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sub, ok := m.subscriptions[subscriptionID]
+	if !ok {
+		return ErrNotFound
+	}
+	if !sub.QueuedUntil.Equal(from) {
+		return ErrCursorMoved
+	}
+	for _, d := range deliveries {
+		if !m.queued(subscriptionID, d.EventID) {
+			m.deliveries[d.ID] = d
+		}
+	}
+	sub.QueuedUntil = until
+	m.subscriptions[subscriptionID] = sub
+	return nil
+}
+
+// DueDeliveries implements the Store interface.
+func (m *Mock) DueDeliveries(_ context.Context, now time.Time, limit int) ([]Delivery, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []Delivery
+	for _, d := range m.deliveries {
+		if d.Status == DeliveryPending && !d.NextAttemptAt.After(now) && m.subscriptions[d.SubscriptionID].Enabled {
+			result = append(result, d)
+		}
+	}
+	sortDeliveries(result)
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// CompleteDelivery implements the Store interface.
+func (m *Mock) CompleteDelivery(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.deliveries, id)
+	return nil
+}
+
+// UpdateDelivery implements the Store interface.
+func (m *Mock) UpdateDelivery(_ context.Context, delivery Delivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	existing, ok := m.deliveries[delivery.ID]
+	if !ok {
+		return nil
+	}
+	existing.Status = delivery.Status
+	existing.Attempts = delivery.Attempts
+	existing.NextAttemptAt = delivery.NextAttemptAt
+	existing.LastError = delivery.LastError
+	m.deliveries[delivery.ID] = existing
+	return nil
+}
+
+// DeadLetters implements the Store interface.
+func (m *Mock) DeadLetters(_ context.Context, subscriptionID string) ([]Delivery, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []Delivery
+	for _, d := range m.deliveries {
+		if d.SubscriptionID == subscriptionID && d.Status == DeliveryDead {
+			result = append(result, d)
+		}
+	}
+	slices.SortFunc(result, func(lhs, rhs Delivery) int {
+		return cmp.Or(lhs.CreatedAt.Compare(rhs.CreatedAt), lhs.EventTime.Compare(rhs.EventTime), strings.Compare(lhs.ID, rhs.ID))
+	})
+	return result, nil
+}
+
+// TryLock implements the Store interface.
+func (m *Mock) TryLock(_ context.Context) (release func(), ok bool, err error) {
+	if !m.locked.TryLock() {
+		return nil, false, nil
+	}
+	return m.locked.Unlock, true, nil
+}
+
+func (m *Mock) nameTaken(sub Subscription) bool {
+	for _, s := range m.subscriptions {
+		if s.ID != sub.ID && s.ProjectID == sub.ProjectID && s.Name == sub.Name {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Mock) queued(subscriptionID, eventID string) bool {
+	for _, d := range m.deliveries {
+		if d.SubscriptionID == subscriptionID && d.EventID == eventID {
+			return true
+		}
+	}
+	return false
+}
+
+// sortDeliveries orders deliveries like the Postgres implementation of DueDeliveries.
+func sortDeliveries(deliveries []Delivery) {
+	slices.SortFunc(deliveries, func(lhs, rhs Delivery) int {
+		return cmp.Or(
+			lhs.NextAttemptAt.Compare(rhs.NextAttemptAt),
+			lhs.EventTime.Compare(rhs.EventTime),
+			strings.Compare(lhs.ID, rhs.ID),
+		)
+	})
+}
+
+// Ensure Mock implements Store.
+var _ Store = (*Mock)(nil)
@@ -0,0 +1,334 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package subscriptions
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/sapcc/go-bits/logg"
+	"go.xyrillian.de/gg/gsql"
+)
+
+const (
+	// uniqueViolation is the SQLSTATE for unique constraint violations.
+	uniqueViolation = "23505"
+	// workerLockID is the key of the Postgres advisory lock taken by TryLock
+	// ("hermes" in ASCII, followed by a number per lock).
+	workerLockID int64 = 0x6865726d65730002
+)
+
+// DBMigrations contains the SQL migrations for the tables of this package in
+// the hermez database (see package database).
+var DBMigrations = map[int64]string{
+	5: `
+		-- Per-project webhook subscriptions and their queued deliveries.
+		CREATE TABLE IF NOT EXISTS subscriptions (
+			id           UUID         PRIMARY KEY,
+			project_id   VARCHAR(64)  NOT NULL,
+			name         VARCHAR(255) NOT NULL,
+			filter       JSONB        NOT NULL DEFAULT '{}',
+			url          TEXT         NOT NULL,
+			secret       TEXT         NOT NULL,
+			enabled      BOOLEAN      NOT NULL DEFAULT TRUE,
+			queued_until TIMESTAMPTZ  NOT NULL,
+			created_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+			created_by   VARCHAR(64)  NOT NULL DEFAULT '',
+			updated_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+			updated_by   VARCHAR(64)  NOT NULL DEFAULT '',
+			UNIQUE (project_id, name)
+		);
+
+		CREATE TABLE IF NOT EXISTS subscription_deliveries (
+			id              UUID         PRIMARY KEY,
+			subscription_id UUID         NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+			event_id        VARCHAR(64)  NOT NULL,
+			event_time      TIMESTAMPTZ  NOT NULL,
+			payload         TEXT         NOT NULL,
+			status          VARCHAR(16)  NOT NULL,
+			attempts        INTEGER      NOT NULL DEFAULT 0,
+			next_attempt_at TIMESTAMPTZ  NOT NULL,
+			last_error      TEXT         NOT NULL DEFAULT '',
+			created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+			UNIQUE (subscription_id, event_id)
+		);
+		CREATE INDEX IF NOT EXISTS subscription_deliveries_due ON subscription_deliveries (next_attempt_at) WHERE status = 'pending';
+	`,
+}
+
+// Postgres implements Store using the hermez PostgreSQL database.
+type Postgres struct {
+	db *gsql.DB
+}
+
+// NewPostgres wraps an already connected and migrated database.
+func NewPostgres(db *gsql.DB) *Postgres {
+	return &Postgres{db: db}
+}
+
+const subscriptionColumns = `id, project_id, name, filter, url, secret, enabled, queued_until,
+	created_at, created_by, updated_at, updated_by`
+
+const deliveryColumns = `id, subscription_id, event_id, event_time, payload, status, attempts,
+	next_attempt_at, last_error, created_at`
+
+// ListSubscriptions implements the Store interface.
+func (p *Postgres) ListSubscriptions(ctx context.Context, projectID string) ([]Subscription, error) {
+	rows, err := p.db.QueryContext(ctx,
+		`SELECT `+subscriptionColumns+` FROM subscriptions
+		  WHERE $1 = '' OR project_id = $1
+		  ORDER BY project_id, name`,
+		projectID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("subscriptions: cannot list subscriptions for project %q: %w", projectID, err)
+	}
+	defer rows.Close()
+
+	var result []Subscription
+	for rows.Next() {
+		s, err := scanSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("subscriptions: cannot list subscriptions for project %q: %w", projectID, err)
+		}
+		result = append(result, *s)
+	}
+	return result, rows.Err()
+}
+
+// GetSubscription implements the Store interface.
+func (p *Postgres) GetSubscription(ctx context.Context, id string) (*Subscription, error) {
+	s, err := scanSubscription(p.db.QueryRowContext(ctx,
+		`SELECT `+subscriptionColumns+` FROM subscriptions WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("subscriptions: cannot get subscription %s: %w", id, err)
+	}
+	return s, nil
+}
+
+// CreateSubscription implements the Store interface.
+func (p *Postgres) CreateSubscription(ctx context.Context, s Subscription) error {
+	filter, err := json.Marshal(s.Filter)
+	if err != nil {
+		return err
+	}
+	_, err = p.db.ExecContext(ctx,
+		`INSERT INTO subscriptions (`+subscriptionColumns+`)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+		s.ID, s.ProjectID, s.Name, filter, s.URL, s.Secret, s.Enabled, s.QueuedUntil,
+		s.CreatedAt, s.CreatedBy, s.UpdatedAt, s.UpdatedBy,
+	)
+	if isUniqueViolation(err) {
+		return ErrDuplicateName
+	}
+	if err != nil {
+		return fmt.Errorf("subscriptions: cannot create subscription in project %s: %w", s.ProjectID, err)
+	}
+	return nil
+}
+
+// UpdateSubscription implements the Store interface.
+func (p *Postgres) UpdateSubscription(ctx context.Context, s Subscription) error {
+	filter, err := json.Marshal(s.Filter)
+	if err != nil {
+		return err
+	}
+	result, err := p.db.ExecContext(ctx,
+		`UPDATE subscriptions
+		    SET name = $2, filter = $3, url = $4, secret = $5, enabled = $6, updated_at = $7, updated_by = $8
+		  WHERE id = $1`,
+		s.ID, s.Name, filter, s.URL, s.Secret, s.Enabled, s.UpdatedAt, s.UpdatedBy,
+	)
+	if isUniqueViolation(err) {
+		return ErrDuplicateName
+	}
+	if err != nil {
+		return fmt.Errorf("subscriptions: cannot update subscription %s: %w", s.ID, err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("subscriptions: cannot get rows affected after update of subscription %s: %w", s.ID, err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteSubscription implements the Store interface.
+// The deliveries are removed by ON DELETE CASCADE.
+func (p *Postgres) DeleteSubscription(ctx context.Context, id string) (bool, error) {
+	result, err := p.db.ExecContext(ctx, `DELETE FROM subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("subscriptions: cannot delete subscription %s: %w", id, err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("subscriptions: cannot get rows affected after delete of subscription %s: %w", id, err)
+	}
+	return n > 0, nil
+}
+
+// Enqueue implements the Store interface.
+func (p *Postgres) Enqueue(ctx context.Context, subscriptionID string, from, until time.Time, deliveries []Delivery) error {
+	return p.db.WithinTransaction(ctx, func(tx *gsql.Tx) error {
+		result, err := tx.ExecContext(ctx,
+			`UPDATE subscriptions SET queued_until = $3 WHERE id = $1 AND queued_until = $2`,
+			subscriptionID, from, until)
+		if err != nil {
+			return fmt.Errorf("subscriptions: cannot move cursor of subscription %s: %w", subscriptionID, err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("subscriptions: cannot get rows affected after moving cursor of subscription %s: %w", subscriptionID, err)
+		}
+		if n == 0 {
+			return ErrCursorMoved
+		}
+
+		for _, d := range deliveries {
+			_, err := tx.ExecContext(ctx,
+				`INSERT INTO subscription_deliveries (`+deliveryColumns+`)
+				 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+				 ON CONFLICT (subscription_id, event_id) DO NOTHING`,
+				d.ID, d.SubscriptionID, d.EventID, d.EventTime, string(d.Payload), string(d.Status), d.Attempts,
+				d.NextAttemptAt, d.LastError, d.CreatedAt,
+			)
+			if err != nil {
+				return fmt.Errorf("subscriptions: cannot queue event %s for subscription %s: %w", d.EventID, subscriptionID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// DueDeliveries implements the Store interface.
+func (p *Postgres) DueDeliveries(ctx context.Context, now time.Time, limit int) ([]Delivery, error) {
+	return p.queryDeliveries(ctx,
+		`SELECT `+deliveryColumns+` FROM subscription_deliveries
+		  WHERE status = 'pending' AND next_attempt_at <= $1
+		    AND subscription_id IN (SELECT id FROM subscriptions WHERE enabled)
+		  ORDER BY next_attempt_at, event_time, id
+		  LIMIT $2`,
+		now, limit)
+}
+
+// CompleteDelivery implements the Store interface.
+func (p *Postgres) CompleteDelivery(ctx context.Context, id string) error {
+	_, err := p.db.ExecContext(ctx, `DELETE FROM subscription_deliveries WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("subscriptions: cannot complete delivery %s: %w", id, err)
+	}
+	return nil
+}
+
+// UpdateDelivery implements the Store interface.
+func (p *Postgres) UpdateDelivery(ctx context.Context, d Delivery) error {
+	_, err := p.db.ExecContext(ctx,
+		`UPDATE subscription_deliveries
+		    SET status = $2, attempts = $3, next_attempt_at = $4, last_error = $5
+		  WHERE id = $1`,
+		d.ID, string(d.Status), d.Attempts, d.NextAttemptAt, d.LastError)
+	if err != nil {
+		return fmt.Errorf("subscriptions: cannot update delivery %s: %w", d.ID, err)
+	}
+	return nil
+}
+
+// DeadLetters implements the Store interface.
+func (p *Postgres) DeadLetters(ctx context.Context, subscriptionID string) ([]Delivery, error) {
+	return p.queryDeliveries(ctx,
+		`SELECT `+deliveryColumns+` FROM subscription_deliveries
+		  WHERE subscription_id = $1 AND status = 'dead'
+		  ORDER BY created_at, event_time, id`,
+		subscriptionID)
+}
+
+// TryLock implements the Store interface using a session-level advisory lock.
+// The lock is held on a dedicated connection, which is returned to the pool on release.
+func (p *Postgres) TryLock(ctx context.Context) (release func(), ok bool, err error) {
+	conn, err := p.db.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("subscriptions: cannot get connection for worker lock: %w", err)
+	}
+	err = conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, workerLockID).Scan(&ok)
+	if err != nil || !ok {
+		conn.Close()
+		if err != nil {
+			return nil, false, fmt.Errorf("subscriptions: cannot take worker lock: %w", err)
+		}
+		return nil, false, nil
+	}
+	release = func() {
+		// use a fresh context: the lock must be released even if ctx was cancelled
+		_, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, workerLockID)
+		if err != nil {
+			logg.Error("subscriptions: cannot release worker lock: %s", err.Error())
+		}
+		conn.Close()
+	}
+	return release, true, nil
+}
+
+func (p *Postgres) queryDeliveries(ctx context.Context, query string, args ...any) ([]Delivery, error) {
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("subscriptions: cannot list deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var result []Delivery
+	for rows.Next() {
+		var (
+			d       Delivery
+			payload string
+			status  string
+		)
+		err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventTime, &payload, &status, &d.Attempts,
+			&d.NextAttemptAt, &d.LastError, &d.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("subscriptions: cannot list deliveries: %w", err)
+		}
+		d.Payload = json.RawMessage(payload)
+		d.Status = DeliveryStatus(status)
+		result = append(result, d)
+	}
+	return result, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSubscription(row rowScanner) (*Subscription, error) {
+	var (
+		s      Subscription
+		filter []byte
+	)
+	err := row.Scan(&s.ID, &s.ProjectID, &s.Name, &filter, &s.URL, &s.Secret, &s.Enabled, &s.QueuedUntil,
+		&s.CreatedAt, &s.CreatedBy, &s.UpdatedAt, &s.UpdatedBy)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(filter, &s.Filter); err != nil {
+		return nil, fmt.Errorf("cannot decode filter of subscription %s: %w", s.ID, err)
+	}
+	return &s, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation
+}
+
+// Ensure Postgres implements Store.
+var _ Store = (*Postgres)(nil)
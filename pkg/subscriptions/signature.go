@@ -0,0 +1,37 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package subscriptions
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+// Headers that the Worker sets on every delivery request.
+const (
+	// DeliveryHeader carries the ID of the delivery. It stays the same across
+	// retries, so receivers can use it to discard duplicates.
+	DeliveryHeader = "X-Hermes-Delivery"
+	// SubscriptionHeader carries the ID of the subscription.
+	SubscriptionHeader = "X-Hermes-Subscription"
+	// TimestampHeader carries the time of the attempt as Unix seconds.
+	TimestampHeader = "X-Hermes-Timestamp"
+	// SignatureHeader carries the value returned by Sign.
+	SignatureHeader = "X-Hermes-Signature"
+)
+
+// Sign computes the value of the signature header for a request body sent at
+// the given Unix timestamp: "sha256=" followed by the hex-encoded
+// HMAC-SHA256 of "<timestamp>.<body>" keyed with the subscription's secret.
+// Receivers should recompute it, compare it in constant time and reject old
+// timestamps to prevent replays.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
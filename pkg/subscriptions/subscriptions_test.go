@@ -0,0 +1,332 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package subscriptions
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/sapcc/go-bits/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapcc/hermes/pkg/hermes"
+	"github.com/sapcc/hermes/pkg/storage"
+	"github.com/sapcc/hermes/pkg/webhook"
+)
+
+// receivedRequest is a request recorded by testReceiver.
+type receivedRequest struct {
+	Header http.Header
+	Body   []byte
+}
+
+// testReceiver is a webhook that records all requests and responds with status.
+type testReceiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []receivedRequest
+	server   *httptest.Server
+}
+
+func newTestReceiver(t *testing.T) *testReceiver {
+	t.Helper()
+	r := &testReceiver{status: http.StatusNoContent}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodPost, req.Method)
+		body, err := io.ReadAll(req.Body)
+		assert.NoError(t, err)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.requests = append(r.requests, receivedRequest{req.Header.Clone(), body})
+		w.WriteHeader(r.status)
+	}))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func (r *testReceiver) setStatus(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+}
+
+// take returns and forgets the recorded requests.
+func (r *testReceiver) take() []receivedRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := r.requests
+	r.requests = nil
+	return result
+}
+
+type workerTest struct {
+	store    *Mock
+	events   *storage.Memory
+	clock    *mock.Clock
+	worker   *Worker
+	receiver *testReceiver
+	eventSeq int
+}
+
+func newWorkerTest(t *testing.T) *workerTest {
+	t.Helper()
+	wt := &workerTest{
+		store:    NewMock(),
+		events:   storage.NewMemory(100),
+		clock:    mock.NewClock(),
+		receiver: newTestReceiver(t),
+	}
+	wt.clock.StepBy(24 * time.Hour)
+	// the test receiver listens on a loopback address, which must be allowed explicitly
+	wt.worker = NewWorker(wt.store, wt.events, webhook.Guard{AllowedNetworks: []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")}})
+	wt.worker.Now = wt.clock.Now
+	return wt
+}
+
+// addSubscription stores a subscription that starts at the current time.
+func (wt *workerTest) addSubscription(t *testing.T, id, projectID string, filter hermes.FieldFilter) Subscription {
+	t.Helper()
+	now := wt.clock.Now().UTC()
+	sub := Subscription{
+		ID:          id,
+		ProjectID:   projectID,
+		Name:        id,
+		Filter:      filter,
+		URL:         wt.receiver.server.URL,
+		Secret:      "secret-of-" + id,
+		Enabled:     true,
+		QueuedUntil: now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	require.NoError(t, wt.store.CreateSubscription(t.Context(), sub))
+	return sub
+}
+
+// addEvent stores an event that happens now.
+func (wt *workerTest) addEvent(projectID, action string) string {
+	wt.eventSeq++
+	id := fmt.Sprintf("event-%d", wt.eventSeq)
+	wt.events.Add([]string{projectID}, cadf.Event{
+		ID:          id,
+		EventTime:   wt.clock.Now().UTC().Format("2006-01-02T15:04:05.000000+00:00"),
+		Action:      cadf.Action(action),
+		Outcome:     cadf.Outcome("success"),
+		RequestPath: "/v3/users/" + id,
+	})
+	return id
+}
+
+// runAfter advances the clock past the settle delay and runs the worker.
+func (wt *workerTest) runAfter(t *testing.T, d time.Duration) {
+	t.Helper()
+	wt.clock.StepBy(d)
+	require.NoError(t, wt.worker.RunOnce(t.Context()))
+}
+
+func deliveredEventIDs(t *testing.T, requests []receivedRequest) []string {
+	t.Helper()
+	var ids []string
+	for _, req := range requests {
+		var event cadf.Event
+		require.NoError(t, json.Unmarshal(req.Body, &event))
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
+
+func TestDeliverNewEvents(t *testing.T) {
+	wt := newWorkerTest(t)
+	sub := wt.addSubscription(t, "sub-1", "project-a", hermes.FieldFilter{Action: "delete"})
+
+	wt.addEvent("project-a", "create")
+	deleteID := wt.addEvent("project-a", "delete")
+	wt.addEvent("project-b", "delete")
+
+	// events are not queued before the settle delay has passed
+	wt.runAfter(t, 10*time.Second)
+	assert.Empty(t, wt.receiver.take())
+
+	wt.runAfter(t, wt.worker.SettleDelay)
+	requests := wt.receiver.take()
+	assert.Equal(t, []string{deleteID}, deliveredEventIDs(t, requests))
+
+	// check the headers and the signature
+	header := requests[0].Header
+	assert.Equal(t, "application/json", header.Get("Content-Type"))
+	assert.Equal(t, sub.ID, header.Get(SubscriptionHeader))
+	assert.NotEmpty(t, header.Get(DeliveryHeader))
+	timestamp, err := strconv.ParseInt(header.Get(TimestampHeader), 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, wt.clock.Now().Unix(), timestamp)
+	assert.Equal(t, Sign(sub.Secret, timestamp, requests[0].Body), header.Get(SignatureHeader))
+	assert.NotEqual(t, Sign("wrong secret", timestamp, requests[0].Body), header.Get(SignatureHeader))
+
+	// successful deliveries are removed; later runs do not deliver the event again
+	due, err := wt.store.DueDeliveries(t.Context(), wt.clock.Now().Add(24*time.Hour), 100)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+	wt.runAfter(t, time.Hour)
+	assert.Empty(t, wt.receiver.take())
+}
+
+func TestEventsBeforeCreationAreNotDelivered(t *testing.T) {
+	wt := newWorkerTest(t)
+	wt.addEvent("project-a", "delete")
+	wt.clock.StepBy(time.Second)
+	wt.addSubscription(t, "sub-1", "project-a", hermes.FieldFilter{})
+	newID := wt.addEvent("project-a", "delete")
+
+	wt.runAfter(t, 2*wt.worker.SettleDelay)
+	assert.Equal(t, []string{newID}, deliveredEventIDs(t, wt.receiver.take()))
+}
+
+func TestDisabledSubscription(t *testing.T) {
+	wt := newWorkerTest(t)
+	sub := wt.addSubscription(t, "sub-1", "project-a", hermes.FieldFilter{})
+
+	// events while the subscription is disabled are skipped, not delivered later
+	sub.Enabled = false
+	require.NoError(t, wt.store.UpdateSubscription(t.Context(), sub))
+	wt.addEvent("project-a", "delete")
+	wt.runAfter(t, 2*wt.worker.SettleDelay)
+	assert.Empty(t, wt.receiver.take())
+
+	sub.Enabled = true
+	require.NoError(t, wt.store.UpdateSubscription(t.Context(), sub))
+	newID := wt.addEvent("project-a", "delete")
+	wt.runAfter(t, 2*wt.worker.SettleDelay)
+	assert.Equal(t, []string{newID}, deliveredEventIDs(t, wt.receiver.take()))
+}
+
+func TestRetryAndDeadLetter(t *testing.T) {
+	wt := newWorkerTest(t)
+	wt.worker.MaxAttempts = 3
+	wt.worker.InitialBackoff = time.Minute
+	wt.worker.MaxBackoff = 90 * time.Second
+	sub := wt.addSubscription(t, "sub-1", "project-a", hermes.FieldFilter{})
+	wt.addEvent("project-a", "delete")
+	wt.addEvent("project-a", "update")
+
+	// first attempt fails; the second event is not attempted in the same run
+	wt.receiver.setStatus(http.StatusServiceUnavailable)
+	wt.runAfter(t, 2*wt.worker.SettleDelay)
+	require.Len(t, wt.receiver.take(), 1)
+	due, err := wt.store.DueDeliveries(t.Context(), wt.clock.Now().Add(time.Hour), 100)
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	assert.Equal(t, 1, due[1].Attempts)
+	assert.Equal(t, wt.clock.Now().UTC().Add(time.Minute), due[1].NextAttemptAt)
+	assert.Contains(t, due[1].LastError, "503")
+	assert.Equal(t, 0, due[0].Attempts)
+
+	// the other event is attempted in the next run (and fails as well)
+	wt.runAfter(t, 30*time.Second)
+	require.Len(t, wt.receiver.take(), 1)
+
+	// the first event is retried after its backoff; the second failure doubles
+	// the backoff, capped at MaxBackoff
+	wt.runAfter(t, 30*time.Second)
+	require.Len(t, wt.receiver.take(), 1)
+	wt.runAfter(t, 30*time.Second) // retry of the other event
+	require.Len(t, wt.receiver.take(), 1)
+	wt.runAfter(t, 59*time.Second)
+	assert.Empty(t, wt.receiver.take())
+	wt.runAfter(t, time.Second)
+	require.Len(t, wt.receiver.take(), 1)
+
+	// third failure turns the delivery into a dead letter
+	dead, err := wt.store.DeadLetters(t.Context(), sub.ID)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, DeliveryDead, dead[0].Status)
+	assert.Equal(t, 3, dead[0].Attempts)
+
+	// once the receiver recovers, the remaining delivery goes through and the dead letter stays
+	wt.receiver.setStatus(http.StatusOK)
+	wt.runAfter(t, time.Hour)
+	assert.Len(t, wt.receiver.take(), 1)
+	dead, err = wt.store.DeadLetters(t.Context(), sub.ID)
+	require.NoError(t, err)
+	assert.Len(t, dead, 1)
+}
+
+func TestInternalAddressesAreRefused(t *testing.T) {
+	wt := newWorkerTest(t)
+	wt.worker.Client = webhook.Guard{}.NewClient(time.Second)
+	wt.addSubscription(t, "sub-1", "project-a", hermes.FieldFilter{})
+	wt.addEvent("project-a", "delete")
+
+	// the receiver listens on a loopback address, so the delivery fails without reaching it
+	wt.runAfter(t, 2*wt.worker.SettleDelay)
+	assert.Empty(t, wt.receiver.take())
+	due, err := wt.store.DueDeliveries(t.Context(), wt.clock.Now().Add(time.Hour), 100)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, 1, due[0].Attempts)
+	assert.Contains(t, due[0].LastError, webhook.ErrForbiddenAddress.Error())
+}
+
+func TestRedaction(t *testing.T) {
+	wt := newWorkerTest(t)
+	redactor, err := hermes.NewRedactor(hermes.Operators{ProjectIDs: []string{"admin-project"}},
+		[]hermes.RedactionRule{{Field: "requestPath", Policy: "event:show_request_path", Action: hermes.RedactRemove}})
+	require.NoError(t, err)
+	wt.worker.Redactor = redactor
+	wt.addSubscription(t, "sub-1", "project-a", hermes.FieldFilter{})
+	tenantEventID := wt.addEvent("project-a", "delete")
+	wt.events.Add([]string{"project-a"}, cadf.Event{
+		ID:          "operator-event",
+		EventTime:   wt.clock.Now().UTC().Format("2006-01-02T15:04:05.000000+00:00"),
+		Action:      cadf.Action("delete"),
+		Outcome:     cadf.Outcome("success"),
+		Initiator:   cadf.Resource{ID: "admin", ProjectID: "admin-project"},
+		RequestPath: "/v3/users/operator-event",
+	})
+
+	// only events caused by cloud operators are redacted
+	wt.runAfter(t, 2*wt.worker.SettleDelay)
+	requests := wt.receiver.take()
+	require.Len(t, requests, 2)
+	requestPaths := make(map[string]string)
+	for _, req := range requests {
+		var event cadf.Event
+		require.NoError(t, json.Unmarshal(req.Body, &event))
+		requestPaths[event.ID] = event.RequestPath
+	}
+	assert.Equal(t, map[string]string{tenantEventID: "/v3/users/" + tenantEventID, "operator-event": ""}, requestPaths)
+}
+
+func TestDeleteSubscriptionRemovesDeliveries(t *testing.T) {
+	wt := newWorkerTest(t)
+	sub := wt.addSubscription(t, "sub-1", "project-a", hermes.FieldFilter{})
+	wt.addEvent("project-a", "delete")
+	wt.receiver.setStatus(http.StatusInternalServerError)
+	wt.runAfter(t, 2*wt.worker.SettleDelay)
+	require.Len(t, wt.receiver.take(), 1)
+
+	existed, err := wt.store.DeleteSubscription(t.Context(), sub.ID)
+	require.NoError(t, err)
+	assert.True(t, existed)
+	wt.runAfter(t, time.Hour)
+	assert.Empty(t, wt.receiver.take())
+}
+
+func TestSign(t *testing.T) {
+	// reference value computed with: printf '1700000000.{}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t,
+		"sha256=b8569b78799ff9e3cbff0fc2d63a33a2b57f3282abd07c37ae5e8e7d79a5f163",
+		Sign("secret", 1700000000, []byte("{}")),
+	)
+}
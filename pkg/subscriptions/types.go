@@ -0,0 +1,89 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+// Package subscriptions delivers newly stored audit events to webhooks.
+//
+// A subscription belongs to a project and selects events with a filter. The
+// Worker periodically queries the event storage for events that arrived since
+// the subscription's cursor and queues one Delivery per event. Deliveries are
+// POSTed to the subscription's URL with an HMAC signature (see Sign). Failed
+// deliveries are retried with exponential backoff and end up as dead letters
+// once the attempts are exhausted.
+package subscriptions
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/sapcc/go-bits/must"
+
+	"github.com/sapcc/hermes/pkg/hermes"
+)
+
+// Subscription is a webhook subscription owned by a project.
+type Subscription struct {
+	ID        string             `json:"id"`
+	ProjectID string             `json:"project_id"`
+	Name      string             `json:"name"`
+	Filter    hermes.FieldFilter `json:"filter"`
+	URL       string             `json:"url"`
+	// Secret is the HMAC key for the signature header. The API only returns it
+	// when the subscription is created.
+	Secret  string `json:"secret,omitempty"`
+	Enabled bool   `json:"enabled"`
+	// QueuedUntil is the cursor of the Worker: events with an eventTime before
+	// it have been queued for delivery (or skipped while the subscription was disabled).
+	QueuedUntil time.Time `json:"queued_until"`
+	CreatedAt   time.Time `json:"created_at"`
+	CreatedBy   string    `json:"created_by"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	UpdatedBy   string    `json:"updated_by"`
+}
+
+// Render implements the audittools.Target interface so Subscription can be
+// used directly in audittools.Event.Target.
+//
+// URL and secret are left out because they may contain credentials.
+func (s Subscription) Render() cadf.Resource {
+	return cadf.Resource{
+		TypeURI:   "service/hermes/subscription",
+		ID:        s.ID,
+		Name:      s.Name,
+		ProjectID: s.ProjectID,
+		Attachments: []cadf.Attachment{
+			must.Return(cadf.NewJSONAttachment("payload", map[string]any{
+				"name":    s.Name,
+				"filter":  s.Filter,
+				"enabled": s.Enabled,
+			})),
+		},
+	}
+}
+
+// DeliveryStatus is the state of a Delivery.
+type DeliveryStatus string
+
+const (
+	// DeliveryPending means that the delivery will be attempted at NextAttemptAt.
+	DeliveryPending DeliveryStatus = "pending"
+	// DeliveryDead means that all attempts failed. Dead letters are kept for
+	// inspection until the subscription is deleted.
+	DeliveryDead DeliveryStatus = "dead"
+)
+
+// Delivery is one event queued for delivery to one subscription.
+// Successful deliveries are removed from the Store.
+type Delivery struct {
+	ID             string    `json:"id"`
+	SubscriptionID string    `json:"subscription_id"`
+	EventID        string    `json:"event_id"`
+	EventTime      time.Time `json:"event_time"`
+	// Payload is the request body: the CADF event as JSON, with redaction applied.
+	Payload       json.RawMessage `json:"payload"`
+	Status        DeliveryStatus  `json:"status"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	LastError     string          `json:"last_error,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
@@ -0,0 +1,305 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package subscriptions
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sapcc/go-api-declarations/bininfo"
+	"github.com/sapcc/go-api-declarations/cadf"
+	"github.com/sapcc/go-bits/logg"
+
+	"github.com/sapcc/hermes/pkg/hermes"
+	"github.com/sapcc/hermes/pkg/storage"
+	"github.com/sapcc/hermes/pkg/webhook"
+)
+
+// minWindow is the smallest window that the Worker will shrink to when a
+// window contains more events than storage can return in one query.
+const minWindow = time.Second
+
+// Worker periodically queues newly stored events for the matching
+// subscriptions and delivers the queued events to their webhooks.
+type Worker struct {
+	Store   Store
+	Storage storage.Storage
+	// Redactor is applied to every event before it is queued, as for a caller
+	// without any exemptions. May be nil.
+	Redactor *hermes.Redactor
+	Client   *http.Client
+	// Interval is the time between two runs.
+	Interval time.Duration
+	// SettleDelay is how long events are given to arrive in storage before
+	// they are queued. Events stored later than that are not delivered.
+	SettleDelay time.Duration
+	// MaxWindow caps the time span queued in one step.
+	MaxWindow time.Duration
+	// MaxAttempts is the number of failed attempts after which a delivery becomes a dead letter.
+	MaxAttempts int
+	// InitialBackoff is the delay after the first failed attempt. It doubles
+	// with every further failure, up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// BatchSize is the maximum number of deliveries attempted per run.
+	BatchSize int
+	// Now returns the current time. Tests replace it with a mock clock.
+	Now func() time.Time
+}
+
+// NewWorker builds a Worker with the default timings and a 10-second timeout
+// per request that only connects to the addresses allowed by the guard.
+func NewWorker(store Store, eventStore storage.Storage, guard webhook.Guard) *Worker {
+	return &Worker{
+		Store:          store,
+		Storage:        eventStore,
+		Client:         guard.NewClient(10 * time.Second),
+		Interval:       10 * time.Second,
+		SettleDelay:    time.Minute,
+		MaxWindow:      time.Hour,
+		MaxAttempts:    8,
+		InitialBackoff: 30 * time.Second,
+		MaxBackoff:     time.Hour,
+		BatchSize:      100,
+		Now:            time.Now,
+	}
+}
+
+// Run queues and delivers events every Interval until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+	for {
+		err := w.RunOnce(ctx)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			logg.Error("subscriptions: worker run failed: %s", err.Error())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce queues new events and attempts all due deliveries once. If another
+// replica holds the worker lock, nothing is done.
+func (w *Worker) RunOnce(ctx context.Context) error {
+	release, ok, err := w.Store.TryLock(ctx)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		logg.Debug("subscriptions: another process is running the delivery worker")
+		return nil
+	}
+	defer release()
+
+	queueErr := w.QueueNewEvents(ctx)
+	if errors.Is(queueErr, context.Canceled) {
+		return queueErr
+	}
+	return errors.Join(queueErr, w.DeliverDue(ctx))
+}
+
+// QueueNewEvents moves the cursor of every subscription up to now minus
+// SettleDelay and queues a delivery for every matching event on the way.
+// Events of disabled subscriptions are skipped. Errors of individual
+// subscriptions are logged and do not stop the other subscriptions.
+func (w *Worker) QueueNewEvents(ctx context.Context) error {
+	subs, err := w.Store.ListSubscriptions(ctx, "")
+	if err != nil {
+		return err
+	}
+	horizon := w.Now().UTC().Add(-w.SettleDelay).Truncate(time.Second)
+	failed := 0
+	for _, sub := range subs {
+		err := w.queue(ctx, sub, horizon)
+		if errors.Is(err, context.Canceled) {
+			return err
+		}
+		if err != nil {
+			logg.Error("subscriptions: cannot queue events for subscription %s in project %s: %s", sub.ID, sub.ProjectID, err.Error())
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("events could not be queued for %d of %d subscriptions", failed, len(subs))
+	}
+	return nil
+}
+
+func (w *Worker) queue(ctx context.Context, sub Subscription, horizon time.Time) error {
+	cursor := sub.QueuedUntil
+	for cursor.Before(horizon) {
+		until := cursor.Add(w.MaxWindow)
+		if until.After(horizon) {
+			until = horizon
+		}
+		var (
+			deliveries []Delivery
+			err        error
+		)
+		if sub.Enabled {
+			deliveries, err = w.fetchWindow(ctx, sub, cursor, until)
+			for errors.Is(err, hermes.ErrTooManyEvents) && until.Sub(cursor) > minWindow {
+				until = cursor.Add(until.Sub(cursor) / 2)
+				deliveries, err = w.fetchWindow(ctx, sub, cursor, until)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		err = w.Store.Enqueue(ctx, sub.ID, cursor, until, deliveries)
+		if errors.Is(err, ErrCursorMoved) || errors.Is(err, ErrNotFound) {
+			// the subscription was changed or deleted in the meantime; pick it up on the next run
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(deliveries) > 0 {
+			logg.Debug("subscriptions: queued %d events for subscription %s in [%s, %s)", len(deliveries), sub.ID,
+				cursor.Format(time.RFC3339), until.Format(time.RFC3339))
+		}
+		cursor = until
+	}
+	return nil
+}
+
+// fetchWindow builds the deliveries for the subscription's events with eventTime in [from, until).
+func (w *Worker) fetchWindow(ctx context.Context, sub Subscription, from, until time.Time) ([]Delivery, error) {
+	filter := sub.Filter.Between(from, until)
+	filter.Sort = []hermes.FieldOrder{{Fieldname: "time", Order: "asc"}}
+	view := &hermes.EventView{Redactor: w.Redactor}
+	now := w.Now().UTC()
+
+	var deliveries []Delivery
+	_, err := hermes.ForEachEvent(ctx, filter, sub.ProjectID, w.Storage, view, 1000, func(event *cadf.Event) error {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("cannot serialize event %s: %w", event.ID, err)
+		}
+		eventTime, err := storage.ParseEventTime(event.EventTime)
+		if err != nil {
+			logg.Error("subscriptions: skipping event %s with unparseable eventTime %q", event.ID, event.EventTime)
+			return nil
+		}
+		deliveries = append(deliveries, Delivery{
+			ID:             uuid.NewString(),
+			SubscriptionID: sub.ID,
+			EventID:        event.ID,
+			EventTime:      eventTime.UTC(),
+			Payload:        payload,
+			Status:         DeliveryPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+		})
+		return nil
+	})
+	return deliveries, err
+}
+
+// DeliverDue attempts up to BatchSize due deliveries. After a failed attempt,
+// the remaining deliveries of the same subscription are left for the next run,
+// so that an unreachable receiver does not use up the whole batch.
+func (w *Worker) DeliverDue(ctx context.Context) error {
+	now := w.Now().UTC()
+	deliveries, err := w.Store.DueDeliveries(ctx, now, w.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	subs := make(map[string]*Subscription)
+	unreachable := make(map[string]bool)
+	for _, d := range deliveries {
+		if unreachable[d.SubscriptionID] {
+			continue
+		}
+		sub, ok := subs[d.SubscriptionID]
+		if !ok {
+			sub, err = w.Store.GetSubscription(ctx, d.SubscriptionID)
+			if errors.Is(err, ErrNotFound) {
+				unreachable[d.SubscriptionID] = true
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			subs[d.SubscriptionID] = sub
+		}
+
+		err := w.deliver(ctx, *sub, d, now)
+		if errors.Is(err, context.Canceled) {
+			return err
+		}
+		if err == nil {
+			if err := w.Store.CompleteDelivery(ctx, d.ID); err != nil {
+				return err
+			}
+			continue
+		}
+
+		unreachable[d.SubscriptionID] = true
+		d.Attempts++
+		d.LastError = err.Error()
+		if d.Attempts >= w.MaxAttempts {
+			d.Status = DeliveryDead
+			logg.Error("subscriptions: giving up on delivery %s of event %s to subscription %s after %d attempts: %s",
+				d.ID, d.EventID, d.SubscriptionID, d.Attempts, d.LastError)
+		} else {
+			d.NextAttemptAt = now.Add(w.backoff(d.Attempts))
+			logg.Info("subscriptions: delivery %s of event %s to subscription %s failed (attempt %d, retrying at %s): %s",
+				d.ID, d.EventID, d.SubscriptionID, d.Attempts, d.NextAttemptAt.Format(time.RFC3339), d.LastError)
+		}
+		if err := w.Store.UpdateDelivery(ctx, d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backoff returns the delay after the given number of failed attempts.
+func (w *Worker) backoff(attempts int) time.Duration {
+	delay := w.InitialBackoff
+	for i := 1; i < attempts && delay < w.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, w.MaxBackoff)
+}
+
+// deliver POSTs the delivery's payload to the subscription's URL.
+// Any response status other than 2xx is an error.
+func (w *Worker) deliver(ctx context.Context, sub Subscription, d Delivery, now time.Time) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return err
+	}
+	timestamp := now.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", bininfo.Component()+"-subscriptions")
+	req.Header.Set(DeliveryHeader, d.ID)
+	req.Header.Set(SubscriptionHeader, sub.ID)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(sub.Secret, timestamp, d.Payload))
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) //nolint:errcheck // only drained for connection reuse
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package test
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+
+	"go.xyrillian.de/gg/gsql"
+	"go.xyrillian.de/gg/pgruntime"
+
+	// load DB driver
+	_ "github.com/lib/pq"
+)
+
+// haveTestDB is set by WithTestDB when a test database is running.
+var haveTestDB bool
+
+// WithTestDB runs the tests of a package with a PostgreSQL test database
+// (see pgruntime.WithTestDB). Call it from TestMain:
+//
+//	func TestMain(m *testing.M) {
+//		test.WithTestDB(m)
+//	}
+//
+// When PostgreSQL is not installed (initdb is not in $PATH), the tests run
+// without a database, and ConnectForTest skips the tests that need one.
+func WithTestDB(m *testing.M) {
+	if _, err := exec.LookPath("initdb"); err != nil {
+		os.Exit(m.Run())
+	}
+	os.Exit(pgruntime.WithTestDB(m, func() int {
+		if err := createRoles(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		haveTestDB = true
+		return m.Run()
+	}))
+}
+
+// createRoles creates the roles that the migrations grant access to. In
+// production, they are provisioned by the helm chart.
+func createRoles() error {
+	// the server of pgruntime.WithTestDB listens on this port
+	db, err := sql.Open("postgres", "host=127.0.0.1 port=54320 user=postgres dbname=postgres sslmode=disable")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	_, err = db.Exec(`DO $$ BEGIN
+		CREATE ROLE "log-router";
+	EXCEPTION WHEN duplicate_object THEN NULL;
+	END $$`)
+	if err != nil {
+		return fmt.Errorf("cannot create roles in test database: %w", err)
+	}
+	return nil
+}
+
+// ConnectForTest connects to an empty database in the test database server
+// and applies the given migrations. It skips the test when no test database
+// is running.
+func ConnectForTest(t *testing.T, migrations map[int64]string) (*gsql.DB, pgruntime.ConnectionTarget) {
+	t.Helper()
+	if !haveTestDB {
+		t.Skip("PostgreSQL is not installed, skipping test that needs a database")
+	}
+	return pgruntime.StdConnector("postgres").ConnectForTest(t, pgruntime.ConnectionBehavior{
+		Migrations: migrations,
+	})
+}
@@ -0,0 +1,119 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+// Package webhook protects the HTTP requests that Hermes sends to URLs chosen
+// by its users, e.g. the webhooks of alert rules, against server-side request
+// forgery: such requests must not reach Hermes itself, the cloud's metadata
+// service or other services in internal networks.
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// ErrForbiddenAddress is returned when a webhook resolves to an internal address.
+var ErrForbiddenAddress = errors.New("webhook: address is not allowed")
+
+// Guard decides which URLs and addresses webhooks may be sent to. The zero
+// value only allows https URLs of public addresses.
+type Guard struct {
+	// AllowedNetworks are internal networks that webhooks may be sent to
+	// nonetheless, e.g. for receivers in the same data center.
+	AllowedNetworks []netip.Prefix
+}
+
+// NewGuardFromConfig builds a Guard from the webhooks.allowed_networks config key.
+func NewGuardFromConfig() (Guard, error) {
+	var guard Guard
+	for _, network := range viper.GetStringSlice("webhooks.allowed_networks") {
+		prefix, err := netip.ParsePrefix(network)
+		if err != nil {
+			return Guard{}, fmt.Errorf("cannot parse webhooks.allowed_networks: %w", err)
+		}
+		guard.AllowedNetworks = append(guard.AllowedNetworks, prefix.Masked())
+	}
+	return guard, nil
+}
+
+// CheckURL checks a webhook URL before it is stored or used: it must be an
+// absolute https URL, and its host must not be localhost or a forbidden IP
+// address. Host names are resolved only when the webhook is sent.
+func (g Guard) CheckURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return errors.New("not an absolute URL")
+	}
+	if u.Scheme != "https" {
+		return errors.New("scheme must be https")
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return g.CheckAddr(addr)
+	}
+	return nil
+}
+
+// CheckAddr returns ErrForbiddenAddress for loopback, private, link-local,
+// multicast and unspecified addresses outside the AllowedNetworks.
+func (g Guard) CheckAddr(addr netip.Addr) error {
+	addr = addr.Unmap()
+	for _, network := range g.AllowedNetworks {
+		if network.Contains(addr) {
+			return nil
+		}
+	}
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified() {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addr)
+	}
+	return nil
+}
+
+// NewClient returns an HTTP client for sending webhooks. It checks the
+// address of every connection after the host name was resolved, so that DNS
+// records and redirects cannot point it to a forbidden address. Redirects to
+// URLs that fail CheckURL are not followed. Proxies are not used, since they
+// would make the resolved address unknown.
+func (g Guard) NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			return g.CheckAddr(addrPort.Addr())
+		},
+	}
+	transport := &http.Transport{
+		Proxy:               nil,
+		DialContext:         dialer.DialContext,
+		ForceAttemptHTTP2:   true,
+		TLSHandshakeTimeout: timeout,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			return g.CheckURL(req.URL.String())
+		},
+	}
+}
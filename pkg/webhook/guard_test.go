@@ -0,0 +1,94 @@
+// SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckURL(t *testing.T) {
+	guard := Guard{AllowedNetworks: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}}
+	for rawURL, allowed := range map[string]bool{
+		"https://hooks.example.com/alerts":   true,
+		"https://203.0.113.7:8443/hook":      true,
+		"https://10.1.2.3/hook":              true,
+		"/hook":                              false,
+		"http://hooks.example.com/alerts":    false,
+		"https://localhost/hook":             false,
+		"https://api.LOCALHOST./hook":        false,
+		"https://127.0.0.1/hook":             false,
+		"https://[::1]/hook":                 false,
+		"https://[::ffff:127.0.0.1]/hook":    false,
+		"https://0.0.0.0/hook":               false,
+		"https://172.16.0.1/hook":            false,
+		"https://192.168.0.1/hook":           false,
+		"https://[fd00::1]/hook":             false,
+		"https://169.254.169.254/latest":     false,
+		"https://[fe80::1%25eth0]/hook":      false,
+		"https://224.0.0.1/hook":             false,
+		"https://hooks.example.com:443/path": true,
+	} {
+		err := guard.CheckURL(rawURL)
+		if allowed {
+			assert.NoError(t, err, rawURL)
+		} else {
+			assert.Error(t, err, rawURL)
+		}
+	}
+}
+
+func TestNewGuardFromConfig(t *testing.T) {
+	viper.Set("webhooks.allowed_networks", []string{"10.180.1.2/16"})
+	defer viper.Set("webhooks.allowed_networks", nil)
+	guard, err := NewGuardFromConfig()
+	require.NoError(t, err)
+	assert.Equal(t, []netip.Prefix{netip.MustParsePrefix("10.180.0.0/16")}, guard.AllowedNetworks)
+
+	viper.Set("webhooks.allowed_networks", []string{"10.180.0.0"})
+	_, err = NewGuardFromConfig()
+	assert.ErrorContains(t, err, "cannot parse webhooks.allowed_networks")
+}
+
+func TestNewClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "http://hooks.example.com/hook", http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	// the connection is checked after resolving, so host names do not help
+	for _, host := range []string{"127.0.0.1", "localhost"} {
+		hookURL := strings.Replace(server.URL, "127.0.0.1", host, 1) + "/hook"
+		resp, err := Guard{}.NewClient(time.Second).Get(hookURL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		assert.ErrorIs(t, err, ErrForbiddenAddress, host)
+	}
+
+	client := Guard{AllowedNetworks: []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")}}.NewClient(time.Second)
+	resp, err := client.Get(server.URL + "/hook")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	// redirects are checked like stored URLs
+	resp, err = client.Get(server.URL + "/redirect")
+	if err == nil {
+		resp.Body.Close()
+	}
+	assert.ErrorContains(t, err, "scheme must be https")
+}